@@ -20,6 +20,8 @@ func main() {
 	ioStreams := genericiooptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
 
 	root := cmd.NewCmdAPIResourceVersions(restClientGetter, ioStreams)
+	// The plugin is also invoked as kubectl arv through a kubectl-arv symlink.
+	cmd.UseInvokedName(root, os.Args[0])
 
 	err := root.Execute()
 	if err != nil {
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+// errNotAggregated is returned by [aggregatedDiscoveryClient] for the requests made without the aggregated discovery.
+const errNotAggregated = constError("not discovered through the aggregated discovery")
+
+// aggregatedDiscoveryClient is a fake discovery client serving its resources only through the aggregated discovery.
+type aggregatedDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+
+	failed map[schema.GroupVersion]error
+}
+
+func (c *aggregatedDiscoveryClient) GroupsAndMaybeResources() (
+	*metav1.APIGroupList,
+	map[schema.GroupVersion]*metav1.APIResourceList,
+	map[schema.GroupVersion]error,
+	error,
+) {
+	groupList, err := c.ServerGroups()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	resources := make(map[schema.GroupVersion]*metav1.APIResourceList)
+
+	for _, group := range groupList.Groups {
+		for _, version := range group.Versions {
+			resourceList, err := c.FakeCachedDiscoveryClient.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			resources[schema.GroupVersion{Group: group.Name, Version: version.Version}] = resourceList
+		}
+	}
+
+	return groupList, resources, c.failed, nil
+}
+
+func (c *aggregatedDiscoveryClient) ServerResourcesForGroupVersion(string) (*metav1.APIResourceList, error) {
+	return nil, errNotAggregated
+}
+
+func (c *aggregatedDiscoveryClient) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return nil, errNotAggregated
+}
+
+// TestAggregatedDiscovery tests discovering the resources through the aggregated discovery only.
+func TestAggregatedDiscovery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Aggregated", aggregatedDiscoveryTest{failed: nil, wantErr: nil}.Test)
+	t.Run("FailedGroupVersion", aggregatedDiscoveryTest{
+		failed:  map[schema.GroupVersion]error{{Group: "autoscaling", Version: "v1"}: errDiscoveryDirectory},
+		wantErr: errDiscoveryDirectory,
+	}.Test)
+}
+
+type aggregatedDiscoveryTest struct {
+	failed  map[schema.GroupVersion]error
+	wantErr error
+}
+
+func (tt aggregatedDiscoveryTest) Test(t *testing.T) {
+	t.Parallel()
+
+	want, err := getGroupResources(t.Context(), NewTestOptionsBuilder().APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	discoveryClient := &aggregatedDiscoveryClient{FakeCachedDiscoveryClient: discoverytesting.New(), failed: tt.failed}
+
+	got, err := getGroupResources(t.Context(), NewTestOptionsBuilder().WithDiscoveryClient(discoveryClient).
+		APIResourceVersionsOptions())
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
+	} else if err != nil {
+		return
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b groupResource) bool {
+		return a.fullname() == b.fullname() && a.Preferred == b.Preferred
+	}) {
+		t.Errorf("getGroupResources() = %v, want %v", got, want)
+	}
+}
+
+// TestDiscoverGroupsPartialFailure tests that the group versions which couldn't be discovered are only an error if
+// their resources are requested.
+func TestDiscoverGroupsPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Excluded", discoverGroupsPartialFailureTest{apiGroup: "", wantErr: nil}.Test)
+	t.Run("Included", discoverGroupsPartialFailureTest{apiGroup: "apps", wantErr: errDiscoveryDirectory}.Test)
+}
+
+type discoverGroupsPartialFailureTest struct {
+	apiGroup string
+	wantErr  error
+}
+
+func (tt discoverGroupsPartialFailureTest) Test(t *testing.T) {
+	t.Parallel()
+
+	// The apps/v1 group version is missing from the discovery directory.
+	directory := t.TempDir()
+
+	for _, filename := range []string{serverGroupsFilename, filepath.Join("v1", serverResourcesFilename)} {
+		content, err := os.ReadFile(filepath.Join("testdata", "discovery", filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.MkdirAll(filepath.Dir(filepath.Join(directory, filename)), 0o750)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filepath.Join(directory, filename), content, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options := NewTestOptionsBuilder().WithDiscoveryClient(newDirectoryDiscoveryClient(directory)).
+		SetAPIGroup(tt.apiGroup).APIResourceVersionsOptions()
+
+	_, err := getGroupResources(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Errorf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// commandAlias is the short alias of the command, e.g. kubectl arv, so that its long name doesn't have to be typed
+// for every interactive use.
+const commandAlias = "arv"
+
+// aliasPluginName is the name of the plugin binary invoked as kubectl arv, usually a symlink to
+// kubectl-api_resource_versions.
+const aliasPluginName = "kubectl-" + commandAlias
+
+// UseInvokedName renames the root command after its alias when the binary is invoked as the kubectl-arv plugin, given
+// the first argument of the process, so that its usage and its completion scripts refer to kubectl arv.
+func UseInvokedName(root *cobra.Command, arg0 string) {
+	binary := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	if binary != aliasPluginName {
+		return
+	}
+
+	root.Use = commandAlias + strings.TrimPrefix(root.Use, root.Name())
+}
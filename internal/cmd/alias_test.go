@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestUseInvokedName tests renaming the root command after its alias when invoked as the kubectl-arv plugin.
+func TestUseInvokedName(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Plugin", useInvokedNameTest{
+		arg0: "/usr/local/bin/kubectl-api_resource_versions",
+		want: "api-resource-versions",
+	}.Test)
+	t.Run("Alias", useInvokedNameTest{arg0: "/usr/local/bin/kubectl-arv", want: "arv"}.Test)
+	t.Run("WindowsAlias", useInvokedNameTest{arg0: "kubectl-arv.exe", want: "arv"}.Test)
+}
+
+type useInvokedNameTest struct {
+	arg0 string
+	want string
+}
+
+func (tt useInvokedNameTest) Test(t *testing.T) {
+	t.Parallel()
+
+	root := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), genericiooptions.NewTestIOStreamsDiscard())
+	UseInvokedName(root, tt.arg0)
+
+	if got := root.Name(); got != tt.want {
+		t.Errorf("UseInvokedName() name = %q, want %q", got, tt.want)
+	}
+
+	if !root.HasAlias(commandAlias) {
+		t.Errorf("UseInvokedName() aliases = %v, want %q", root.Aliases, commandAlias)
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+)
+
+// errSystemUserDefined is returned when both --system-only and --user-defined-only are requested.
+const errSystemUserDefined = constError("system-only and user-defined-only are mutually exclusive")
+
+// excludeGroupOrigin checks if the API group should be excluded by --user-defined-only or --system-only, depending on
+// whether it is built into Kubernetes, see [lifecycle.BuiltInGroup].
+func excludeGroupOrigin(group string, options *apiResourceVersionsOptions) bool {
+	if !options.UserDefinedOnly && !options.SystemOnly {
+		return false
+	}
+
+	return lifecycle.BuiltInGroup(group) == options.UserDefinedOnly
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+// newOriginDiscoveryClient returns a discovery client serving the built-in groups of [discoverytesting.New], and the
+// stable.example.com group of a custom resource definition.
+func newOriginDiscoveryClient() *cmdtesting.FakeCachedDiscoveryClient {
+	client := discoverytesting.New()
+
+	version := metav1.GroupVersionForDiscovery{GroupVersion: "stable.example.com/v1", Version: "v1"}
+	client.Groups = append(client.Groups, &metav1.APIGroup{
+		Name:             "stable.example.com",
+		Versions:         []metav1.GroupVersionForDiscovery{version},
+		PreferredVersion: version,
+	})
+
+	resources := &metav1.APIResourceList{
+		GroupVersion: version.GroupVersion,
+		APIResources: []metav1.APIResource{{
+			Name:       "crontabs",
+			ShortNames: []string{"ct"},
+			Namespaced: true,
+			Kind:       "CronTab",
+			Verbs:      []string{"get", "list"},
+		}},
+	}
+	client.Resources = append(client.Resources, resources)
+	client.PreferredResources = append(client.PreferredResources, resources)
+
+	return client
+}
+
+// TestRunGroupOrigin tests limiting the resources to the API groups which are built into Kubernetes or not.
+func TestRunGroupOrigin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("UserDefinedOnly", runGroupOriginTest{
+		builder: NewTestOptionsBuilder().SetUserDefinedOnly(true),
+		want:    "crontabs.v1.stable.example.com\n",
+	}.Test)
+	t.Run("SystemOnly", runGroupOriginTest{
+		builder: NewTestOptionsBuilder().SetSystemOnly(true).SetAPIGroup("autoscaling"),
+		want: "horizontalpodautoscalers.v2.autoscaling\n" +
+			"horizontalpodautoscalers.v1.autoscaling\n" +
+			"horizontalpodautoscalers.v2beta2.autoscaling\n",
+	}.Test)
+	t.Run("SystemOnlyCustomGroup", runGroupOriginTest{
+		builder: NewTestOptionsBuilder().SetSystemOnly(true).SetAPIGroup("stable.example.com"),
+		want:    "",
+	}.Test)
+}
+
+type runGroupOriginTest struct {
+	builder *APIResourceVersionsOptionsBuilder
+	want    string
+}
+
+func (tt runGroupOriginTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := tt.builder.SetOutput(nameOutput).WithDiscoveryClient(newOriginDiscoveryClient())
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+}
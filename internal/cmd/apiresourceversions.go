@@ -18,22 +18,35 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
 	"github.com/liggitt/tabwriter"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apimachineryerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // Enable all auth plugins (for CSPs)
+	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -41,9 +54,27 @@ import (
 const (
 	wideOutput = "wide"
 	nameOutput = "name"
-
-	nameSortBy = "name"
-	kindSortBy = "kind"
+	// name0Output prints the names like nameOutput, but terminated by NUL characters, see [printGroupResourceName0].
+	name0Output = "name0"
+	// apiVersionsOutput prints the group versions like kubectl api-versions, see [printAPIVersions].
+	apiVersionsOutput = "api-versions"
+	// scriptOutput prints a bash script reading the objects of the resources, see [printScript].
+	scriptOutput = "script"
+	// yamlOutput prints the resources as a stream of YAML documents, see [printYAML].
+	yamlOutput = "yaml"
+	// gvkOutput prints the kinds of the resources as Kind.version.group, see [printGVK].
+	gvkOutput = "gvk"
+	// gvkJSONOutput prints the kinds of the resources as a JSON list of group version kinds, see [printGVKJSON].
+	gvkJSONOutput = "gvk-json"
+	// mappingOutput prints the plural, singular, kind, and short names of the resources, see [printMapping].
+	mappingOutput = "mapping"
+	// mappingJSONOutput prints the names of the resources of each group version as JSON, see [printMappingJSON].
+	mappingJSONOutput = "mapping-json"
+	// countsOutput prints the count of the objects and the versions of each resource, see [printCountsSummaries].
+	countsOutput = "counts"
+
+	nameSortBy = string(apiresourceversions.SortByName)
+	kindSortBy = string(apiresourceversions.SortByKind)
 )
 
 var (
@@ -54,6 +85,9 @@ var (
 		# Print all API resources with their group versions (including deprecated or unstable versions)
 		kubectl api-resource-versions
 
+		# Search the resources whose name, short names, or kind fuzzily match a query, best matches first
+		kubectl api-resource-versions hpa
+
 		# Print in the 'name' format for use with kubectl get
 		kubectl api-resource-versions --output=name
 
@@ -64,12 +98,21 @@ var (
 		kubectl api-resource-versions --api-group=apps
 
 		# List all non-namespaced resources
-		kubectl api-resource-versions --namespaced=false`
+		kubectl api-resource-versions --namespaced=false
+
+		# Show an approximate count of the objects stored for each resource version
+		kubectl api-resource-versions --show-counts
+
+		# Find custom resources which have no objects
+		kubectl api-resource-versions --empty-only --categories=all
+
+		# Watch the resources added, removed, or whose preferred version changes while installing an operator
+		kubectl api-resource-versions --watch --interval=10s`
 )
 
 // NewCmdAPIResourceVersions returns a command that lists all API resources and their versions.
 //
-// TODO(Izzette): Output only supports default, wide, and name; it would be interesting to export to JSON or YAML.
+// TODO(Izzette): Output supports YAML, but not JSON; it would be interesting to export a JSON list as well.
 // TODO(Izzette): Subresources are not included in the output; they are potentially useful, but it's unclear how to
 // expose them in a useful, machine-readable output.
 func NewCmdAPIResourceVersions(
@@ -77,24 +120,104 @@ func NewCmdAPIResourceVersions(
 	ioStreams genericiooptions.IOStreams,
 ) *cobra.Command {
 	options := newAPIResourceVersionsOptions(ioStreams)
+	restClientGetter := newFromDumpFlags(configFlags)
 
 	cmd := &cobra.Command{
-		Use:   "api-resource-versions",
-		Short: "List all API resources and versions",
+		Use:     "api-resource-versions [QUERY]",
+		Aliases: []string{commandAlias},
+		Short:   "List all API resources and versions",
 		Long: "List all API resources and their API group versions along with whether the version is preferred.\n" +
-			"Subresources are not included.",
+			"Subresources are not included.\n" +
+			"With a QUERY, only the resources whose plural name, singular name, short names, or kind fuzzily match " +
+			"it are listed, ranked from the exact matches to those containing its characters in order.",
 		Example: templates.Examples(apiresourceversionsExample),
+		// The query is validated by complete, as cobra would otherwise report it as an unknown command.
+		Args: cobra.ArbitraryArgs,
+		// The flag defaults and the tracing apply to every command, as none of the other commands have a
+		// PersistentPreRun or a PersistentPostRun.
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			checkErr(cmd, applyFlagDefaults(cmd))
+			checkErr(cmd, startTracing(cmd, configFlags))
+		},
+		PersistentPostRun: func(cmd *cobra.Command, _ []string) {
+			finishTracing(cmd, nil)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			cmdutil.CheckErr(options.complete(configFlags, cmd, args))
-			cmdutil.CheckErr(options.validate())
-			cmdutil.CheckErr(runAPIResourceVersions(options))
+			runAPIResourceVersionsCommand(cmd, options, restClientGetter, args)
 		},
 	}
 
+	addAPIResourceVersionsFlags(cmd, options, restClientGetter)
+
+	configFlags.AddFlags(cmd.PersistentFlags())
+	addKlogFlags(cmd.PersistentFlags())
+	addErrorFormatFlag(cmd.PersistentFlags())
+	addConfigFlag(cmd.PersistentFlags())
+	addTracingFlag(cmd.PersistentFlags())
+	restClientGetter.AddFlags(cmd.PersistentFlags())
+
+	addCommandGroup(cmd, &cobra.Group{ID: "resources", Title: "Resource Commands:"},
+		newCmdList(restClientGetter, ioStreams),
+		newCmdGroups(restClientGetter, ioStreams),
+		newCmdVersions(restClientGetter, ioStreams),
+		newCmdRenderVersions(restClientGetter, ioStreams),
+		newCmdExplainFields(restClientGetter, ioStreams),
+		newCmdSkeleton(restClientGetter, ioStreams),
+		newCmdStorageVersions(restClientGetter, ioStreams),
+		newCmdCheckPreferred(restClientGetter, ioStreams),
+		newCmdOrphanedAPIServices(restClientGetter, ioStreams),
+		newCmdSnapshot(restClientGetter, ioStreams),
+		newCmdDiff(configFlags, ioStreams),
+		newCmdMatrix(configFlags, ioStreams),
+		newCmdVerbs(restClientGetter, ioStreams),
+		newCmdCategories(restClientGetter, ioStreams),
+		newCmdDump(restClientGetter, ioStreams),
+	)
+	addCommandGroup(cmd, &cobra.Group{ID: "migration", Title: "Migration Commands:"},
+		newCmdCheck(restClientGetter, ioStreams),
+		newCmdAppliedVersions(restClientGetter, ioStreams),
+		newCmdConvert(restClientGetter, ioStreams),
+		newCmdMigrateStorage(restClientGetter, ioStreams),
+		newCmdGeneratePolicy(restClientGetter, ioStreams),
+		newCmdReport(restClientGetter, ioStreams),
+	)
+	addCommandGroup(cmd, &cobra.Group{ID: "server", Title: "Server Commands:"},
+		newCmdServe(restClientGetter, ioStreams),
+		newCmdServeWebhook(restClientGetter, ioStreams),
+		newCmdController(restClientGetter, ioStreams),
+	)
+	cmd.AddCommand(newCmdVersion(ioStreams))
+	cmd.AddCommand(newCmdGenDocs(ioStreams))
+
+	return cmd
+}
+
+// addCommandGroup adds the commands to the command under the group, so that they are listed together in its help.
+func addCommandGroup(cmd *cobra.Command, group *cobra.Group, commands ...*cobra.Command) {
+	cmd.AddGroup(group)
+
+	for _, command := range commands {
+		command.GroupID = group.ID
+		cmd.AddCommand(command)
+	}
+}
+
+// addAPIResourceVersionsFlags adds the flags listing the API resources to the command, shared between the
+// api-resource-versions command and its list command.
+func addAPIResourceVersionsFlags(
+	cmd *cobra.Command,
+	options *apiResourceVersionsOptions,
+	restClientGetter *fromDumpFlags,
+) {
 	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
 		"When using the default or custom-column output format, don't print headers (default print headers).")
 	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
-		"Output format. One of: ("+wideOutput+", "+nameOutput+").")
+		"Output format. One of: ("+wideOutput+", "+nameOutput+", "+name0Output+", "+apiVersionsOutput+", "+
+			scriptOutput+", "+yamlOutput+", "+gvkOutput+", "+gvkJSONOutput+", "+mappingOutput+", "+
+			mappingJSONOutput+", "+countsOutput+").")
+	cmd.Flags().StringVar(&options.OutputFile, "output-file", options.OutputFile,
+		"Write the output to this file instead of stdout, atomically: the file is replaced only once the output has "+
+			"been completely written.")
 
 	cmd.Flags().StringVar(&options.APIGroup, "api-group", options.APIGroup,
 		"Limit to resources in the specified API group.")
@@ -105,51 +228,260 @@ func NewCmdAPIResourceVersions(
 	cmd.Flags().StringVar(&options.SortBy, "sort-by", options.SortBy,
 		"If non-empty, sort list of resources using specified field. One of ("+nameSortBy+", "+kindSortBy+").")
 	cmd.Flags().BoolVar(&options.Cached, "cached", options.Cached, "Use the cached list of resources if available.")
+	cmd.Flags().DurationVar(&options.CacheTTL, "cache-ttl", options.CacheTTL,
+		"Use the cached list of resources if it is newer than the TTL, otherwise refresh it. 0 always refreshes it.")
 	cmd.Flags().StringSliceVar(&options.Categories, "categories", options.Categories,
 		"Limit to resources that belong to the specified categories.")
 	cmd.Flags().BoolVar(&options.Preferred, "preferred", options.Preferred,
 		"Filter resources by whether their version is in the server preferred resources.")
+	cmd.Flags().BoolVar(&options.UserDefinedOnly, "user-defined-only", options.UserDefinedOnly,
+		"Limit to the API groups which aren't built into Kubernetes, i.e. those of the custom resource definitions "+
+			"and the aggregated APIs.")
+	cmd.Flags().BoolVar(&options.SystemOnly, "system-only", options.SystemOnly,
+		"Limit to the API groups built into Kubernetes, i.e. the core group and the groups served by the API server "+
+			"itself.")
 	cmd.Flags().BoolVar(&options.IncludeSubresources, "include-subresources", options.IncludeSubresources,
 		"Include subresources in the output.")
-	configFlags.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&options.Exec, "exec", options.Exec,
+		"Run the shell command for each resource version instead of printing it, with the {group}, {version}, "+
+			"{resource}, and {fullname} placeholders replaced by single-quoted shell words, e.g. "+
+			"'kubectl get {fullname} -A --no-headers | wc -l'.")
+	cmd.Flags().IntVar(&options.ExecConcurrency, "exec-concurrency", options.ExecConcurrency,
+		"Number of --exec commands which run concurrently.")
+	cmd.Flags().BoolVar(&options.Fzf, "fzf", options.Fzf,
+		"Pipe the names of the resources to the fzf fuzzy-finder and print the selected ones.")
+	cmd.Flags().BoolVar(&options.ShowCounts, "show-counts", options.ShowCounts,
+		"Show an approximate count of the objects for each resource version which supports the list verb.")
+	cmd.Flags().IntVar(&options.CountConcurrency, "count-concurrency", options.CountConcurrency,
+		"Number of resource versions whose objects are counted concurrently.")
+	cmd.Flags().Int64Var(&options.CountChunkSize, "count-chunk-size", options.CountChunkSize,
+		"Number of objects listed per request to count the objects of the resource versions for which the API server "+
+			"doesn't report the remaining item count. Pass 0 to list them all at once.")
+	cmd.Flags().DurationVar(&options.CountTimeout, "count-timeout", options.CountTimeout,
+		"Maximum time to count the objects of each resource version, e.g. 10s. 0 means no timeout.")
+	cmd.Flags().BoolVar(&options.ShowCommands, "show-commands", options.ShowCommands,
+		"Show the kubectl get invocation reading each resource version, or its raw path if it can't be listed.")
+	cmd.Flags().BoolVar(&options.ShowServerVersion, "show-server-version", options.ShowServerVersion,
+		"Show the Kubernetes version of the cluster of each resource, with all-contexts, contexts, or clusters-file.")
+	cmd.Flags().BoolVar(&options.ShowMinK8s, "show-min-k8s", options.ShowMinK8s,
+		"Show the Kubernetes release in which each built-in resource version was introduced.")
+	cmd.Flags().BoolVar(&options.ShowVersionsServed, "show-versions-served", options.ShowVersionsServed,
+		"Show the number of versions of the group serving each resource, counting those filtered out.")
+	cmd.Flags().BoolVar(&options.ShowGroupPriority, "show-group-priority", options.ShowGroupPriority,
+		"Show the priority of the group of each resource, the highest groupPriorityMinimum of its APIServices, which "+
+			"orders the groups when several serve the same kind.")
+	cmd.Flags().BoolVar(&options.ShowUsage, "show-usage", options.ShowUsage,
+		"Show whether each resource version has been requested since the API server started, according to its "+
+			"apiserver_request_total and apiserver_requested_deprecated_apis metrics.")
+	cmd.Flags().StringSliceVar(&options.MaxColumnWidths, "max-column-width", options.MaxColumnWidths,
+		"Truncate the columns of the default and wide outputs to a maximum width, ending them with an ellipsis. "+
+			"Either a WIDTH for all the columns, or COLUMN=WIDTH for a single column, e.g. VERBS=40, may be given "+
+			"multiple times.")
+	cmd.Flags().BoolVar(&options.ShowEmptyGroups, "show-empty-groups", options.ShowEmptyGroups,
+		"List the group versions which matched the group filters but have no resources left after filtering, "+
+			"named <none>.")
+	cmd.Flags().StringVar(&options.CoreGroupName, "core-group-name", options.CoreGroupName,
+		"Display the core group under this name, e.g. core/v1 instead of v1, in the tables and the structured outputs. "+
+			"The name, api-versions, script, and gvk outputs keep the kubectl format.")
+	cmd.Flags().BoolVar(&options.EmptyOnly, "empty-only", options.EmptyOnly,
+		"Limit to resources which have no objects. Resources which can't be counted are excluded.")
+	cmd.Flags().BoolVar(&options.NonEmptyOnly, "non-empty-only", options.NonEmptyOnly,
+		"Limit to resources which have at least one object. Resources which can't be counted are excluded.")
+	cmd.Flags().BoolVarP(&options.Watch, "watch", "w", options.Watch,
+		"After listing the resources, re-discover them every --interval and print the changes.")
+	cmd.Flags().DurationVar(&options.Interval, "interval", options.Interval,
+		"Interval at which the resources are re-discovered with --watch.")
+	options.Notify.addFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&options.Offline, "offline", options.Offline,
+		"Read the resources from the kubectl discovery cache, without contacting the API server.")
+	cmd.Flags().BoolVar(&options.StaleOK, "stale-ok", options.StaleOK,
+		"If the API server is unreachable, print the resources of the kubectl discovery cache with a STALE warning.")
+	cmd.Flags().StringVar(&options.CompareRelease, "compare-release", options.CompareRelease,
+		"Compare the kinds served by the cluster with those of a stock Kubernetes release, e.g. v1.33.")
+	cmd.Flags().BoolVar(&options.AllContexts, "all-contexts", options.AllContexts,
+		"List the resources of every context of the kubeconfig concurrently, with a CLUSTER column.")
+	cmd.Flags().StringSliceVar(&options.Contexts, "contexts", options.Contexts,
+		"List the resources of the specified kubeconfig contexts concurrently, with a CLUSTER column.")
+	cmd.Flags().StringVar(&options.ClustersFile, "clusters-file", options.ClustersFile,
+		"List the resources of the clusters of the YAML file concurrently, with a CLUSTER column.")
+	cmd.Flags().StringVar(&options.ClusterSelector, "cluster-selector", options.ClusterSelector,
+		"Label selector limiting the clusters of the --clusters-file.")
+	cmd.Flags().DurationVar(&options.Timeout, "timeout", options.Timeout,
+		"Maximum time to discover and count the resources of a cluster, e.g. 30s. 0 means no timeout.")
+	cmd.Flags().IntVar(&options.Retries, "retries", options.Retries,
+		"Number of times the discovery of an API group version is retried on transient errors, e.g. 503 or timeouts.")
+	cmd.Flags().DurationVar(&options.RetryBackoff, "retry-backoff", options.RetryBackoff,
+		"Delay before the first retry of the discovery of an API group version, doubled after each retry.")
+	cmd.Flags().DurationVar(&options.MaxWait, "max-wait", options.MaxWait,
+		"Maximum delay after which a request is retried when the API server asks to retry it later, e.g. with 429 "+
+			"Too Many Requests. Requests asked to wait longer fail instead. 0 means no maximum.")
+	cmd.Flags().BoolVar(&options.Quiet, "quiet", options.Quiet,
+		"Don't display the progress of the discovery, which is only displayed when stderr is a terminal.")
+	cmd.Flags().BoolVar(&options.Stream, "stream", options.Stream,
+		"Print the resources of each API group version as soon as they are discovered, in the discovery order.")
+	cmd.Flags().IntVar(&options.DiscoveryConcurrency, "discovery-concurrency", options.DiscoveryConcurrency,
+		"Number of API group versions which are discovered concurrently.")
+	cmd.Flags().IntVar(&options.ClusterConcurrency, "cluster-concurrency", options.ClusterConcurrency,
+		"Number of clusters which are discovered concurrently.")
+	cmdutil.CheckErr(cmd.Flags().MarkDeprecated("cached", "use --cache-ttl instead"))
+	registerAPIResourceVersionsCompletions(cmd, restClientGetter)
+}
 
-	return cmd
+// runAPIResourceVersionsCommand lists the API resources for the api-resource-versions command and its list command.
+func runAPIResourceVersionsCommand(
+	cmd *cobra.Command,
+	options *apiResourceVersionsOptions,
+	restClientGetter *fromDumpFlags,
+	args []string,
+) {
+	checkErr(cmd, options.complete(restClientGetter, cmd, args))
+	checkErr(cmd, invalidArgument(options.validate()))
+
+	err := runAPIResourceVersionsOutput(cmd.Context(), options)
+	// The warnings are printed even if the command failed, as they may explain the failure.
+	options.warnings.print(options.ErrOut)
+	options.throttling.print(options.ErrOut)
+	checkErr(cmd, err)
+}
+
+// registerAPIResourceVersionsCompletions registers the completion functions of the flags of the command.
+func registerAPIResourceVersionsCompletions(cmd *cobra.Command, restClientGetter genericclioptions.RESTClientGetter) {
+	completions := map[string]cobra.CompletionFunc{
+		"api-group": completeAPIGroups(restClientGetter),
+		"verbs": completeResourceValues(restClientGetter, func(resource *metav1.APIResource) []string {
+			return resource.Verbs
+		}),
+		"categories": completeResourceValues(restClientGetter, func(resource *metav1.APIResource) []string {
+			return resource.Categories
+		}),
+		"output": cobra.FixedCompletions(
+			[]cobra.Completion{
+				wideOutput, nameOutput, name0Output, apiVersionsOutput, scriptOutput, yamlOutput, gvkOutput,
+				gvkJSONOutput, mappingOutput, mappingJSONOutput, countsOutput,
+			},
+			cobra.ShellCompDirectiveNoFileComp),
+		"sort-by": cobra.FixedCompletions([]cobra.Completion{nameSortBy, kindSortBy}, cobra.ShellCompDirectiveNoFileComp),
+	}
+
+	for name, completion := range completions {
+		cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc(name, completion))
+	}
 }
 
 // apiResourceVersionsOptions contains the options for the api-resource-versions command.
 type apiResourceVersionsOptions struct {
 	genericiooptions.IOStreams
 
-	Output              string
-	SortBy              string
-	APIGroup            string
-	Namespaced          bool
-	Verbs               []string
-	NoHeaders           bool
-	Cached              bool
-	Categories          []string
-	Preferred           bool
-	IncludeSubresources bool
-
+	Output               string
+	OutputFile           string
+	SortBy               string
+	APIGroup             string
+	Namespaced           bool
+	Verbs                []string
+	NoHeaders            bool
+	Cached               bool
+	CacheTTL             time.Duration
+	Categories           []string
+	Preferred            bool
+	UserDefinedOnly      bool
+	SystemOnly           bool
+	IncludeSubresources  bool
+	ShowCounts           bool
+	CountConcurrency     int
+	CountChunkSize       int64
+	CountTimeout         time.Duration
+	ShowCommands         bool
+	ShowServerVersion    bool
+	ShowMinK8s           bool
+	ShowVersionsServed   bool
+	ShowGroupPriority    bool
+	ShowUsage            bool
+	ShowEmptyGroups      bool
+	CoreGroupName        string
+	Exec                 string
+	ExecConcurrency      int
+	Fzf                  bool
+	EmptyOnly            bool
+	NonEmptyOnly         bool
+	Watch                bool
+	Interval             time.Duration
+	Notify               notifyOptions
+	AllContexts          bool
+	Contexts             []string
+	ClustersFile         string
+	ClusterSelector      string
+	ClusterConcurrency   int
+	CompareRelease       string
+	Offline              bool
+	FromDump             string
+	FromFiles            []string
+	StaleOK              bool
+	DiscoveryConcurrency int
+	Stream               bool
+	Timeout              time.Duration
+	Retries              int
+	RetryBackoff         time.Duration
+	MaxWait              time.Duration
+	Quiet                bool
+	MaxColumnWidths      []string
+
+	// query is the lower case query which the names of the resources fuzzily match, if any.
+	query            string
 	groupChanged     bool
 	nsChanged        bool
 	preferredChanged bool
+	// inherited are the flags set from their environment variables or the config file rather than on the command
+	// line, see [inheritedFlags].
+	inherited      sets.Set[string]
+	compareRelease *utilversion.Version
+	// maxColumnWidths are the maximum widths of the columns of the table, 0 for the unbounded ones, with
+	// --max-column-width.
+	maxColumnWidths []int
 
 	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+	// staleDiscoveryClient reads the kubectl discovery cache when the discovery fails with --stale-ok.
+	staleDiscoveryClient *directoryDiscoveryClient
+	// streamResources is called with the resources of each group version as soon as they are discovered with --stream.
+	streamResources func(resources []groupResource) error
+	// showProgress is true if the progress of the discovery is displayed, on a terminal stderr without --quiet.
+	showProgress bool
+	// warnings collects the warnings returned by the API servers, printed after the output.
+	warnings *warningCollector
+	// throttling monitors the throttling of the requests, summarized after the output.
+	throttling *throttleMonitor
+	// fzfCommand is the fuzzy-finder which the names of the resources are piped to with --fzf.
+	fzfCommand []string
+	// clusters are the clients of the clusters selected by --all-contexts, --contexts, or --clusters-file, if any.
+	clusters []clusterClients
+	// contextName is the kubeconfig context of the cluster, if it could be determined, without clusters.
+	contextName string
+	// matchedGroupVersions are the discovered group versions which matched the group filters, with
+	// --show-empty-groups.
+	matchedGroupVersions []clusterGroupVersion
 }
 
 // newAPIResourceVersionsOptions returns a new [apiResourceVersionsOptions] with default values.
 func newAPIResourceVersionsOptions(ioStreams genericiooptions.IOStreams) *apiResourceVersionsOptions {
 	return &apiResourceVersionsOptions{
-		IOStreams:  ioStreams,
-		Namespaced: true,
+		IOStreams:            ioStreams,
+		Namespaced:           true,
+		Interval:             defaultWatchInterval,
+		Notify:               newNotifyOptions(),
+		ClusterConcurrency:   defaultClusterConcurrency,
+		DiscoveryConcurrency: defaultDiscoveryConcurrency,
+		RetryBackoff:         defaultRetryBackoff,
+		CountConcurrency:     defaultCountConcurrency,
+		CountChunkSize:       defaultCountChunkSize,
+		ExecConcurrency:      defaultExecConcurrency,
+		fzfCommand:           defaultFzfCommand,
 	}
 }
 
 // groupResource is represents a versioned API resource.
 type groupResource struct {
 	// APIGroup is the API group of the resource.
-	APIGroup *metav1.APIGroup
+	APIGroup *resourceGroup
 	// APIGroupVersion is the group version of the resource, e.g. "apps/v1".
 	APIGroupVersion string
 	// APIResource is the API resource for this version.
@@ -158,6 +490,22 @@ type groupResource struct {
 	Preferred bool
 	// Subresource is true if this resource is a subresource.
 	Subresource bool
+	// Count is the approximate number of objects of this resource version, if they have been counted.
+	Count *int64
+	// Cluster is the kubeconfig context the resource was discovered in, when listing multiple contexts.
+	Cluster string
+	// ServerVersion is the version of the API server of the Cluster, with --show-server-version.
+	ServerVersion string
+	// VersionsServed is the number of versions of the group serving the resource, with --show-versions-served.
+	VersionsServed int
+	// GroupPriority is the priority of the group of the resource, with --show-group-priority, if the APIServices of
+	// the API server could be listed.
+	GroupPriority *int64
+	// Requested is whether the resource has been requested since the API server started, with --show-usage, if the
+	// metrics of the API server could be read.
+	Requested *bool
+	// QueryScore is how well the resource matches the query, zero without a query, see [queryScore].
+	QueryScore int
 }
 
 // PreferredGroupVersion returns true if the version is the preferred version for the API group.
@@ -166,34 +514,223 @@ func (gr groupResource) PreferredGroupVersion() bool {
 	return gr.APIGroup.PreferredVersion.GroupVersion == gr.APIGroupVersion
 }
 
-// fullname returns the name of the resource with its version and api group in the format expected by kubectl.
+// fullname returns the name of the resource with its version and api group in the format expected by kubectl, see
+// [apiresourceversions.Resource.FullName].
 func (gr groupResource) fullname() string {
-	groupVersion, err := schema.ParseGroupVersion(gr.APIGroupVersion)
-	if err != nil {
-		panic(fmt.Errorf("error parsing group version: %w", err))
-	} else if groupVersion.Group != gr.APIGroup.Name {
-		panic(fmt.Sprintf("group version %s does not match group %s", groupVersion.Group, gr.APIGroup.Name))
-	}
+	return gr.resource().FullName()
+}
 
-	baseName, subName := splitResourceName(gr.APIResource.Name)
+// groupVersionResource returns the group, version, and resource name of the resource.
+// Subresources are mapped to the group version resource of their parent resource.
+func (gr groupResource) groupVersionResource() schema.GroupVersionResource {
+	return gr.resource().GroupVersionResource()
+}
 
-	fullname := fmt.Sprintf("%s.%s.%s", baseName, groupVersion.Version, gr.APIGroup.Name)
-	if subName != nil {
-		fullname = fmt.Sprintf("%s %s", fullname, *subName)
+// resource returns the resource as a [apiresourceversions.Resource], which shares its API resource.
+func (gr groupResource) resource() apiresourceversions.Resource {
+	return apiresourceversions.Resource{
+		GroupVersion: schema.GroupVersion{
+			Group:   gr.APIGroup.Name,
+			Version: strings.TrimPrefix(gr.APIGroupVersion, gr.APIGroup.Name+"/"),
+		},
+		APIResource:           gr.APIResource,
+		Preferred:             gr.Preferred,
+		PreferredGroupVersion: gr.PreferredGroupVersion(),
+		Subresource:           gr.Subresource,
+		QueryScore:            gr.QueryScore,
 	}
-
-	return fullname
 }
 
 // errWrongOutput is a returned when the output format is not supported.
-const errWrongOutput = constError("output must be one of: (" + wideOutput + ", " + nameOutput + ")")
+const errWrongOutput = constError(
+	"output must be one of: (" + wideOutput + ", " + nameOutput + ", " + name0Output + ", " + apiVersionsOutput + ", " +
+		scriptOutput + ", " + yamlOutput + ", " + gvkOutput + ", " + gvkJSONOutput + ", " + mappingOutput + ", " +
+		mappingJSONOutput + ", " + countsOutput + ")")
 
 // errSortBy is a returned when the sort-by field is not supported.
 const errSortBy = constError("sort-by must be one of: (" + nameSortBy + ", " + kindSortBy + ")")
 
+// errEmptyNonEmpty is returned when both --empty-only and --non-empty-only are requested.
+const errEmptyNonEmpty = constError("empty-only and non-empty-only are mutually exclusive")
+
+// errAllContexts is returned when more than one of --all-contexts, --contexts, and --clusters-file are requested.
+const errAllContexts = constError("all-contexts, contexts, and clusters-file are mutually exclusive")
+
+// errWatchContexts is returned when --watch is requested with multiple clusters.
+const errWatchContexts = constError("watch is not supported with all-contexts, contexts, or clusters-file")
+
+// errOfflineCounts is returned when the objects are counted with --offline, --from-dump, or --from-file.
+const errOfflineCounts = constError(
+	"show-counts, empty-only, and non-empty-only are not supported with offline, from-dump, or from-file")
+
+// errFromDump is returned when --from-dump is requested with another source of the resources.
+const errFromDump = constError("from-dump is not supported with offline, all-contexts, contexts, or clusters-file")
+
+// errFromFile is returned when --from-file is requested with another source of the resources.
+const errFromFile = constError(
+	"from-file is not supported with from-dump, offline, all-contexts, contexts, or clusters-file")
+
+// errInterval is returned when the --interval value is not positive with --watch.
+const errInterval = constError("interval must be positive")
+
+// errClusterSelector is returned when --cluster-selector is requested without --clusters-file.
+const errClusterSelector = constError("cluster-selector requires clusters-file")
+
+// errClusterConcurrency is returned when the cluster concurrency is not positive.
+const errClusterConcurrency = constError("cluster-concurrency must be positive")
+
+// errDiscoveryConcurrency is returned when the discovery concurrency is not positive.
+const errDiscoveryConcurrency = constError("discovery-concurrency must be positive")
+
+// errNameNoHeaders is returned when --no-headers is requested with --output=name, name0, api-versions, script, or
+// yaml, which never print headers.
+const errNameNoHeaders = constError("no-headers has no effect with output=name, name0, api-versions, script, or " +
+	"yaml, which never print headers: remove no-headers")
+
+// errNameCounts is returned when --show-counts is requested with --output=name, name0, api-versions, or script,
+// which don't print the counts.
+const errNameCounts = constError("show-counts has no effect with output=name, name0, api-versions, or script, " +
+	"which don't print the counts: remove show-counts or use the default or wide output")
+
+// errNameCommands is returned when --show-commands is requested with --output=name, name0, api-versions, or script,
+// which don't print the commands.
+const errNameCommands = constError("show-commands has no effect with output=name, name0, api-versions, or script, " +
+	"which don't print the commands: remove show-commands or use the default or wide output")
+
+// errCountsVerbs is returned when the objects are counted while the resources are filtered by verbs without list.
+const errCountsVerbs = constError("show-counts, empty-only, and non-empty-only count the objects with the list verb: " +
+	"add list to verbs")
+
+// defaultDiscoveryConcurrency is the default number of API group versions which are discovered concurrently.
+const defaultDiscoveryConcurrency = apiresourceversions.DefaultConcurrency
+
 // validate checks that options are valid for the command.
+//
+//nolint:cyclop
 func (o *apiResourceVersionsOptions) validate() error {
-	supportedOutputTypes := sets.New("", wideOutput, nameOutput)
+	if o.EmptyOnly && o.NonEmptyOnly {
+		return errEmptyNonEmpty
+	}
+
+	if o.SystemOnly && o.UserDefinedOnly {
+		return errSystemUserDefined
+	}
+
+	if o.OutputFile != "" && o.Watch {
+		return errOutputFileWatch
+	}
+
+	err := o.validateClusters()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateCompareRelease()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateStream()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateExec()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateServerVersion()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateName0()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateMaxColumnWidth()
+	if err != nil {
+		return err
+	}
+
+	if (o.Offline || o.fromDocuments()) && o.countsRequired() {
+		return errOfflineCounts
+	}
+
+	if (o.Offline || o.fromDocuments()) && o.ShowUsage {
+		return errOfflineUsage
+	}
+
+	if (o.Offline || o.fromDocuments()) && o.ShowGroupPriority {
+		return errOfflineGroupPriority
+	}
+
+	if o.FromDump != "" && (o.Offline || o.AllContexts || len(o.Contexts) > 0 || o.ClustersFile != "") {
+		return errFromDump
+	}
+
+	if len(o.FromFiles) > 0 && (o.FromDump != "" || o.Offline || o.AllContexts || len(o.Contexts) > 0 ||
+		o.ClustersFile != "") {
+		return errFromFile
+	}
+
+	if o.StaleOK && (o.Offline || o.fromDocuments()) {
+		return errStaleOK
+	}
+
+	if o.DiscoveryConcurrency <= 0 {
+		return fmt.Errorf("%w: got %d", errDiscoveryConcurrency, o.DiscoveryConcurrency)
+	}
+
+	if o.Timeout < 0 {
+		return fmt.Errorf("%w: got %s", errNegativeTimeout, o.Timeout)
+	}
+
+	if o.Retries < 0 {
+		return fmt.Errorf("%w: got %d", errRetries, o.Retries)
+	}
+
+	if o.RetryBackoff <= 0 {
+		return fmt.Errorf("%w: got %s", errRetryBackoff, o.RetryBackoff)
+	}
+
+	if o.CountConcurrency <= 0 {
+		return fmt.Errorf("%w: got %d", errCountConcurrency, o.CountConcurrency)
+	}
+
+	if o.CountChunkSize < 0 {
+		return fmt.Errorf("%w: got %d", errCountChunkSize, o.CountChunkSize)
+	}
+
+	if o.CountTimeout < 0 {
+		return fmt.Errorf("%w: got %s", errCountTimeout, o.CountTimeout)
+	}
+
+	if o.MaxWait < 0 {
+		return fmt.Errorf("%w: got %s", errMaxWait, o.MaxWait)
+	}
+
+	if o.CacheTTL < 0 {
+		return fmt.Errorf("%w: got %s", errCacheTTL, o.CacheTTL)
+	}
+
+	if o.Watch && o.Interval <= 0 {
+		return fmt.Errorf("%w: got %s", errInterval, o.Interval)
+	}
+
+	if o.Notify.URL != "" && !o.Watch {
+		return errNotifyWatch
+	}
+
+	err = o.Notify.validate()
+	if err != nil {
+		return err
+	}
+
+	supportedOutputTypes := sets.New("", wideOutput, nameOutput, name0Output, apiVersionsOutput, scriptOutput,
+		yamlOutput, gvkOutput, gvkJSONOutput, mappingOutput, mappingJSONOutput, countsOutput)
 	if !supportedOutputTypes.Has(o.Output) {
 		return fmt.Errorf("%w: %s is not available", errWrongOutput, o.Output)
 	}
@@ -205,52 +742,383 @@ func (o *apiResourceVersionsOptions) validate() error {
 		}
 	}
 
+	return o.validateCombinations()
+}
+
+// headerless checks if the output never prints headers nor extra columns.
+func (o *apiResourceVersionsOptions) headerless() bool {
+	return o.namesOnly() || o.Output == apiVersionsOutput || o.Output == scriptOutput
+}
+
+// ignoreInheritedCombinations unsets the headers and the counts with the outputs which don't print them, unless both
+// flags were given on the command line, as their values from the environment or the config file are the defaults of
+// the table outputs, e.g. -o name ignores show-counts from the config file rather than failing validateCombinations.
+func (o *apiResourceVersionsOptions) ignoreInheritedCombinations() {
+	inherited := func(name string) bool {
+		return o.inherited.Has("output") || o.inherited.Has(name)
+	}
+
+	if (o.headerless() || o.Output == yamlOutput) && inherited("no-headers") {
+		o.NoHeaders = false
+	}
+
+	if o.headerless() && inherited("show-counts") {
+		o.ShowCounts = false
+	}
+}
+
+// validateCombinations checks that the options don't combine flags which have no effect together, as they are
+// usually a mistake of the user, e.g. expecting counts in the name output.
+func (o *apiResourceVersionsOptions) validateCombinations() error {
+	headerless := o.headerless()
+
+	if (headerless || o.Output == yamlOutput) && o.NoHeaders {
+		return errNameNoHeaders
+	}
+
+	if headerless && o.ShowCounts {
+		return errNameCounts
+	}
+
+	if headerless && o.ShowCommands {
+		return errNameCommands
+	}
+
+	if headerless && o.ShowMinK8s {
+		return errNameMinK8s
+	}
+
+	if headerless && o.ShowVersionsServed {
+		return errNameVersionsServed
+	}
+
+	if headerless && o.ShowGroupPriority {
+		return errNameGroupPriority
+	}
+
+	if headerless && o.ShowUsage {
+		return errNameUsage
+	}
+
+	if o.Stream && o.ShowVersionsServed {
+		return errVersionsServedStream
+	}
+
+	multipleOrStreamed := o.Stream || o.Watch || o.AllContexts || len(o.Contexts) > 0 || o.ClustersFile != ""
+
+	if o.Output == apiVersionsOutput && multipleOrStreamed {
+		return errAPIVersionsMode
+	}
+
+	if o.Output == scriptOutput && multipleOrStreamed {
+		return errScriptMode
+	}
+
+	if o.Output == yamlOutput && (o.Stream || o.Watch) {
+		return errYAMLMode
+	}
+
+	err := o.validateGVK()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateMapping()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateCounts()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateEmptyGroups()
+	if err != nil {
+		return err
+	}
+
+	err = o.validateCoreGroupName()
+	if err != nil {
+		return err
+	}
+
+	if o.countsRequired() && len(o.Verbs) > 0 && !slices.Contains(o.Verbs, "list") {
+		return fmt.Errorf("%w: got %s", errCountsVerbs, strings.Join(o.Verbs, ","))
+	}
+
+	return nil
+}
+
+// validateClusters checks that the options selecting multiple clusters are valid.
+func (o *apiResourceVersionsOptions) validateClusters() error {
+	sources := 0
+
+	for _, selected := range []bool{o.AllContexts, len(o.Contexts) > 0, o.ClustersFile != ""} {
+		if selected {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return errAllContexts
+	}
+
+	if o.Watch && sources > 0 {
+		return errWatchContexts
+	}
+
+	if o.ClusterSelector != "" && o.ClustersFile == "" {
+		return errClusterSelector
+	}
+
+	if o.ClusterConcurrency <= 0 {
+		return fmt.Errorf("%w: got %d", errClusterConcurrency, o.ClusterConcurrency)
+	}
+
 	return nil
 }
 
 // complete completes all the required options for the api-resource-versions command.
 func (o *apiResourceVersionsOptions) complete(
-	restClientGetter genericclioptions.RESTClientGetter,
+	restClientGetter *fromDumpFlags,
 	cmd *cobra.Command,
 	args []string,
 ) error {
-	if len(args) != 0 {
+	if len(args) > 1 {
 		//nolint:wrapcheck
-		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+		return cmdutil.UsageErrorf(cmd, "at most one query is allowed, got: %v", args)
+	}
+
+	if len(args) == 1 {
+		o.query = strings.ToLower(args[0])
 	}
 
-	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	configFlags := restClientGetter.ConfigFlags
+	o.FromDump = restClientGetter.Directory
+	o.FromFiles = restClientGetter.Files
+
+	applyRequestTimeout(configFlags, o.Timeout)
+
+	// The warnings handler and the throttle monitor are installed before the config flags of the clusters of a fleet
+	// are derived from them.
+	o.warnings = newWarningCollector()
+	configFlags.WrapConfigFn = o.warnings.wrapConfig(configFlags.WrapConfigFn)
+	o.throttling = newThrottleMonitor(o.ErrOut, o.MaxWait)
+	configFlags.WrapConfigFn = o.throttling.wrapConfig(configFlags.WrapConfigFn)
+
+	selectedClusters, err := o.fleetClusters(configFlags)
 	if err != nil {
-		return fmt.Errorf("couldn't create discovery client: %w", err)
+		return err
 	}
 
-	o.discoveryClient = discoveryClient
+	if selectedClusters != nil {
+		for _, selected := range selectedClusters {
+			cluster, err := o.newClusterClients(selected.configFlags(configFlags), selected.Name)
+			if err != nil {
+				return fmt.Errorf("cluster %s: %w", selected.Name, err)
+			}
+
+			o.clusters = append(o.clusters, cluster)
+		}
+	} else {
+		cluster, err := o.newClusterClients(configFlags, "")
+		if err != nil {
+			return err
+		}
+
+		o.discoveryClient = cluster.discoveryClient
+		o.dynamicClient = cluster.dynamicClient
+		o.staleDiscoveryClient = cluster.staleDiscoveryClient
+		o.contextName = restClientGetter.contextName()
+		// The progress would be mixed with the resources printed with --stream, and the progress of the clusters of a
+		// fleet with one another.
+		o.showProgress = !o.Quiet && !o.Stream && isTerminal(o.ErrOut)
+	}
 
 	o.groupChanged = cmd.Flags().Changed("api-group")
 	o.nsChanged = cmd.Flags().Changed("namespaced")
 	o.preferredChanged = cmd.Flags().Changed("preferred")
+	o.inherited = inheritedFlags(cmd.Flags())
+	o.ignoreInheritedCombinations()
 
 	return nil
 }
 
+// errNoClusters is returned when none of the clusters of the clusters file match the cluster selector.
+const errNoClusters = constError("no clusters selected")
+
+// fleetClusters returns the clusters selected by --all-contexts, --contexts, or --clusters-file, or nil if the
+// current context is used.
+func (o *apiResourceVersionsOptions) fleetClusters(configFlags *genericclioptions.ConfigFlags) ([]fleetCluster, error) {
+	contexts := o.Contexts
+
+	switch {
+	case o.ClustersFile != "":
+		f, err := readFleet(o.ClustersFile)
+		if err != nil {
+			return nil, err
+		}
+
+		selector, err := labels.Parse(o.ClusterSelector)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse cluster selector: %w", err)
+		}
+
+		clusters := f.selectClusters(selector)
+		if len(clusters) == 0 {
+			return nil, errNoClusters
+		}
+
+		return clusters, nil
+	case o.AllContexts:
+		var err error
+
+		contexts, err = kubeconfigContexts(configFlags)
+		if err != nil {
+			return nil, err
+		}
+	case len(contexts) == 0:
+		return nil, nil
+	}
+
+	clusters := make([]fleetCluster, 0, len(contexts))
+	for _, contextName := range contexts {
+		clusters = append(clusters, fleetCluster{Name: contextName, Context: contextName})
+	}
+
+	return clusters, nil
+}
+
 // errNoResourcesFound is a constant error returned when no resources are found.
 const errNoResourcesFound = constError("no resources found")
 
+// runAPIResourceVersionsOutput runs the mode of the command selected by the options, writing its output to the
+// --output-file atomically if requested, see [writeFileAtomically].
+func runAPIResourceVersionsOutput(ctx context.Context, options *apiResourceVersionsOptions) error {
+	if options.OutputFile == "" {
+		return runAPIResourceVersionsMode(ctx, options)
+	}
+
+	return writeFileAtomically(options.OutputFile, outputFilePermissions, func(out io.Writer) error {
+		options.Out = out
+
+		return runAPIResourceVersionsMode(ctx, options)
+	})
+}
+
+// runAPIResourceVersionsMode runs the mode of the command selected by the options, e.g. --watch.
+func runAPIResourceVersionsMode(ctx context.Context, options *apiResourceVersionsOptions) error {
+	switch {
+	case options.CompareRelease != "":
+		return runCompareRelease(ctx, options)
+	case options.Stream:
+		return runStreamAPIResourceVersions(ctx, options)
+	case options.Watch:
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return runWatch(ctx, options)
+	default:
+		return runAPIResourceVersions(ctx, options)
+	}
+}
+
 // runAPIResourceVersions prints the API resources and their group versions.
-func runAPIResourceVersions(options *apiResourceVersionsOptions) error {
-	resources, err := getGroupResources(options)
+func runAPIResourceVersions(ctx context.Context, options *apiResourceVersionsOptions) error {
+	resources, err := listClusterGroupResources(ctx, options)
 	if err != nil {
 		return err
 	}
 
-	if len(resources) == 0 && options.Output != nameOutput {
+	if len(resources) == 0 && !options.namesOnly() && len(options.matchedGroupVersions) == 0 {
 		// If no resources are found, we return an error.
 		return errNoResourcesFound
 	}
 
+	if options.Exec != "" {
+		return runExec(ctx, resources, options)
+	}
+
+	if options.Fzf {
+		return runFzf(ctx, resources, options)
+	}
+
+	_, printSpan := startSpan(ctx, "print", attribute.String("output", options.Output))
+	err = printOutput(resources, options)
+	endSpan(printSpan, err)
+
+	return err
+}
+
+// printOutput prints the API resources in the output format selected by [apiResourceVersionsOptions].
+func printOutput(resources []groupResource, options *apiResourceVersionsOptions) error {
+	switch options.Output {
+	case apiVersionsOutput:
+		return printAPIVersions(resources, options)
+	case scriptOutput:
+		return printScript(options.Out, resources, options)
+	case yamlOutput:
+		return printYAML(options.Out, resources, options)
+	case gvkOutput:
+		return printGVK(options.Out, resources, options)
+	case gvkJSONOutput:
+		return printGVKJSON(options.Out, resources, options)
+	case mappingOutput:
+		return printMapping(options.Out, resources, options)
+	case mappingJSONOutput:
+		return printMappingJSON(options.Out, resources, options)
+	case countsOutput:
+		return printCountsSummaries(resources, options)
+	}
+
 	return printGroupResources(resources, options)
 }
 
+// listGroupResources retrieves the API resources and their group versions, counting and filtering them by their counts
+// if required, within the --timeout.
+func listGroupResources(ctx context.Context, options *apiResourceVersionsOptions) ([]groupResource, error) {
+	ctx, cancel := withTimeout(ctx, options.Timeout)
+	defer cancel()
+
+	discoveryCtx, discoverySpan := startSpan(ctx, "discover resources")
+
+	resources, err := getGroupResources(discoveryCtx, options)
+	if err != nil && options.staleDiscoveryClient != nil {
+		resources, err = getStaleGroupResources(discoveryCtx, options, err)
+	}
+
+	endSpan(discoverySpan, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if options.countsRequired() {
+		countCtx, countSpan := startSpan(ctx, "count objects")
+		countGroupResources(countCtx, resources, options)
+		endSpan(countSpan, nil)
+
+		resources = slices.DeleteFunc(resources, func(resource groupResource) bool {
+			return excludeCountedResource(resource, options)
+		})
+	}
+
+	if options.ShowGroupPriority {
+		priorityCtx, prioritySpan := startSpan(ctx, "read group priorities")
+		annotateGroupPriorities(priorityCtx, resources, options)
+		endSpan(prioritySpan, nil)
+	}
+
+	if options.ShowUsage {
+		usageCtx, usageSpan := startSpan(ctx, "read usage")
+		annotateUsage(usageCtx, resources, options)
+		endSpan(usageSpan, nil)
+	}
+
+	return resources, nil
+}
+
 // splitResourceName splits the resource name into its resource and subresource parts.
 // The first return value is the resource name, and the second return value is the subresource name if it exists.
 func splitResourceName(resourceName string) (string, *string) {
@@ -277,155 +1145,246 @@ func unversionedResourceName(resource metav1.APIResource) (string, *string) {
 	return fmt.Sprintf("%s.%s", resourceName, resource.Group), subresourceName
 }
 
-// getPreferredResourceVersions retrieves the server preferred resource versions.
-// For the returned map:
-//
-//   - The key is in the format returned by [unversionedResourceName], which is "<resource>.<group>".
-//   - The value is the version for the group (e.g. "v1", "v1beta1", "v2").
-//
-// Subresources are not included in the map.
-func getPreferredResourceVersions(options *apiResourceVersionsOptions) (map[string]string, error) {
-	preferredResources, err := options.discoveryClient.ServerPreferredResources()
+// getGroupResources retrieves the API resources and their group versions from the discovery client.
+func getGroupResources(ctx context.Context, options *apiResourceVersionsOptions) ([]groupResource, error) {
+	if !options.Cached && options.CacheTTL <= 0 {
+		options.discoveryClient.Invalidate()
+	}
+
+	start := time.Now()
+	_, groupsSpan := startSpan(ctx, "discover groups")
+
+	groups, err := callWithContext(ctx, func() (*apiresourceversions.Groups, error) {
+		return apiresourceversions.DiscoverGroups(options.discoveryClient)
+	})
+	endSpan(groupsSpan, err)
+
 	if err != nil {
-		return nil, fmt.Errorf("couldn't get server preferred resources: %w", err)
+		return []groupResource{}, fmt.Errorf("couldn't get server groups: %w", err)
 	}
 
-	preferredVersions := make(map[string]string, len(preferredResources))
-	for _, resourceList := range preferredResources {
-		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+	klog.V(discoveryLogLevel).InfoS("Discovered the groups",
+		"groups", len(groups.List.Groups), "aggregated", groups.Aggregated, "duration", time.Since(start))
+
+	groupList := groups.List
+
+	preferredResources := groups.PreferredVersions()
+	if preferredResources == nil {
+		preferredResources, err = callWithContext(ctx, func() (map[schema.GroupResource]string, error) {
+			return apiresourceversions.ServerPreferredVersions(options.discoveryClient)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("couldn't parse group version %s: %w", resourceList.GroupVersion, err)
+			return nil, fmt.Errorf("couldn't get preferred resource versions: %w", err)
 		}
+	}
 
-		for _, resource := range resourceList.APIResources {
-			resource.Group = groupVersion.Group // Why is this not set?
+	// The filter is built once for all the groups and resources, which are filtered in the hot path.
+	filter := options.filter()
 
-			resourceKey, subresourceName := unversionedResourceName(resource)
-			if subresourceName != nil {
-				// If the resource is a subresource, we skip it.
-				continue
-			}
+	includeGroup := func(group *metav1.APIGroup) bool { return !excludeGroup(group, filter, options) }
+	includedGroups := groups.Select(includeGroup)
 
-			preferredVersions[resourceKey] = groupVersion.Version
-		}
+	if options.ShowEmptyGroups {
+		options.matchedGroupVersions = matchedGroupVersions(includedGroups)
 	}
 
-	return preferredVersions, nil
-}
-
-// getGroupResources retrieves the API resources and their group versions from the discovery client.
-func getGroupResources(options *apiResourceVersionsOptions) ([]groupResource, error) {
-	if !options.Cached {
-		options.discoveryClient.Invalidate()
+	resourceGroups := make([]*resourceGroup, len(includedGroups))
+	for i, group := range includedGroups {
+		resourceGroups[i] = newResourceGroup(group)
 	}
 
-	groupList, err := options.discoveryClient.ServerGroups()
-	if err != nil {
-		return []groupResource{}, fmt.Errorf("couldn't get server groups: %w", err)
+	var onGroupVersion func(int, int, *metav1.APIResourceList) error
+	if options.streamResources != nil {
+		onGroupVersion = func(groupIndex, versionIndex int, resourceList *metav1.APIResourceList) error {
+			group := resourceGroups[groupIndex]
+
+			return options.streamResources(appendGroupVersionResources(
+				nil, options, filter, group, group.Versions[versionIndex], resourceList, preferredResources))
+		}
 	}
 
-	preferredResources, err := getPreferredResourceVersions(options)
+	groupResourceLists, err := getGroupVersionResourceLists(ctx, options, groups, includeGroup, onGroupVersion)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't get preferred resource versions: %w", err)
+		return nil, err
 	}
 
-	// We could quickly calculate the total number of resources in the server groups to avoid having to re-size the
-	// underlying slice-buffer during an append operation.
-	// However, when the number of resources is large, this could result in very high memory usage even when heavily
-	// filtering the group resources.
-	resources := make([]groupResource, 0)
+	for _, warning := range validateDiscovery(groupList.Groups, groupResourceLists) {
+		options.warnings.add(warning)
+	}
 
-	for i := range groupList.Groups {
-		group := &groupList.Groups[i]
+	// The resources are allocated at once for all the resources of the included groups, which is at most a few
+	// thousand even on large clusters, rather than re-sizing the underlying slice-buffer during the append operations.
+	resourcesCount := 0
 
-		if excludeGroup(group, options) {
-			// If the group is excluded, we skip it.
-			continue
+	for _, resourceLists := range groupResourceLists {
+		for _, resourceList := range resourceLists {
+			resourcesCount += len(resourceList.APIResources)
 		}
+	}
 
-		groupResources, err := processGroupResources(options, group, preferredResources)
-		if err != nil {
-			return nil, err
-		}
+	resources := make([]groupResource, 0, resourcesCount)
 
-		resources = append(resources, groupResources...)
+	for i, group := range resourceGroups {
+		resources = appendGroupResources(resources, options, filter, group, groupResourceLists[i], preferredResources)
 	}
 
 	return resources, nil
 }
 
-func processGroupResources(
+// getGroupVersionResourceLists retrieves the resources of every version of the included groups with
+// [apiresourceversions.DiscoverGroupVersions], with at most --discovery-concurrency group versions discovered
+// concurrently, each retried up to --retries times on transient errors.
+// The resource lists are returned in the order of the included groups and of their versions, and handed to
+// onGroupVersion in the same order as soon as they are discovered, if it isn't nil.
+func getGroupVersionResourceLists(
+	ctx context.Context,
 	options *apiResourceVersionsOptions,
-	group *metav1.APIGroup,
-	preferredResources map[string]string,
-) ([]groupResource, error) {
-	resources := make([]groupResource, 0)
+	groups *apiresourceversions.Groups,
+	includeGroup func(group *metav1.APIGroup) bool,
+	onGroupVersion func(groupIndex, versionIndex int, resourceList *metav1.APIResourceList) error,
+) ([][]*metav1.APIResourceList, error) {
+	total := 0
+
+	for _, group := range groups.Select(includeGroup) {
+		total += len(group.Versions)
+	}
 
-	for _, version := range group.Versions {
-		resourceList, err := options.discoveryClient.ServerResourcesForGroupVersion(version.GroupVersion)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't get server resources for group version %s: %w", version.GroupVersion, err)
+	progress := startDiscoveryProgress(options, total)
+	defer progress.stop()
+
+	//nolint:wrapcheck
+	return apiresourceversions.DiscoverGroupVersions(ctx, options.discoveryClient, groups, includeGroup,
+		apiresourceversions.GroupVersionOptions{
+			Concurrency:    options.DiscoveryConcurrency,
+			Retries:        options.Retries,
+			RetryBackoff:   options.RetryBackoff,
+			OnGroupVersion: onGroupVersion,
+			OnRetry: func(groupVersion string, retry int, backoff time.Duration, err error) {
+				klog.V(retryLogLevel).InfoS("Retrying the discovery of the group version",
+					"groupVersion", groupVersion, "retry", retry, "backoff", backoff, "err", err)
+			},
+			OnDiscovered: func(groupVersion string, duration time.Duration, err error) {
+				progress.increment()
+				klog.V(discoveryLogLevel).InfoS("Discovered the group version",
+					"groupVersion", groupVersion, "duration", duration, "err", err)
+			},
+		})
+}
+
+// appendGroupResources appends the resources of the group which aren't excluded, from the resource lists of its
+// versions.
+func appendGroupResources(
+	resources []groupResource,
+	options *apiResourceVersionsOptions,
+	filter apiresourceversions.Filter,
+	group *resourceGroup,
+	resourceLists []*metav1.APIResourceList,
+	preferredResources map[schema.GroupResource]string,
+) []groupResource {
+	start := len(resources)
+
+	for i, version := range group.Versions {
+		resources = appendGroupVersionResources(resources, options, filter, group, version, resourceLists[i],
+			preferredResources)
+	}
+
+	if options.ShowVersionsServed {
+		// The versions are counted before the resources are filtered, so that the filtered out versions are counted.
+		counts := servedVersionCounts(resourceLists)
+		for i := start; i < len(resources); i++ {
+			resources[i].VersionsServed = counts[resources[i].APIResource.Name]
 		}
+	}
 
-		for _, apiResource := range resourceList.APIResources {
-			apiResource.Group = group.Name // Why is this not set?
+	return resources
+}
 
-			resourceName, subresourceName := unversionedResourceName(apiResource)
+// appendGroupVersionResources appends the resources of the group version which aren't excluded.
+// The API resources are copied to a single allocation for the group version, with their strings interned, so that the
+// listed resources don't retain the resource list.
+func appendGroupVersionResources(
+	resources []groupResource,
+	options *apiResourceVersionsOptions,
+	filter apiresourceversions.Filter,
+	group *resourceGroup,
+	version metav1.GroupVersionForDiscovery,
+	resourceList *metav1.APIResourceList,
+	preferredResources map[schema.GroupResource]string,
+) []groupResource {
+	// The capacity is never exceeded, so that the pointers to the API resources remain valid.
+	apiResources := make([]metav1.APIResource, 0, len(resourceList.APIResources))
+
+	for _, apiResource := range resourceList.APIResources {
+		apiResources = append(apiResources, apiResource)
+		apiResource := &apiResources[len(apiResources)-1]
+		apiResource.Group = group.Name // Why is this not set?
+
+		resourceName, subresourceName := splitResourceName(apiResource.Name)
+
+		preferredVersion, ok := preferredResources[schema.GroupResource{Group: group.Name, Resource: resourceName}]
+		preferred := ok && preferredVersion == version.Version
+
+		resource := groupResource{
+			APIGroup:        group,
+			APIGroupVersion: version.GroupVersion,
+			APIResource:     apiResource,
+			Preferred:       preferred,
+			Subresource:     subresourceName != nil,
+		}
 
-			preferredVersion, ok := preferredResources[resourceName]
-			preferred := ok && preferredVersion == version.Version
+		if options.query != "" {
+			resource.QueryScore = apiresourceversions.QueryScore(apiResource, options.query)
+		}
 
-			resource := groupResource{
-				APIGroup:        group,
-				APIGroupVersion: version.GroupVersion,
-				APIResource:     &apiResource,
-				Preferred:       preferred,
-				Subresource:     subresourceName != nil,
-			}
+		if excludeGroupResource(resource, filter) {
+			// The copy of the excluded resource is overwritten by the next one.
+			apiResources = apiResources[:len(apiResources)-1]
 
-			if !excludeGroupResource(resource, options) {
-				resources = append(resources, resource)
-			}
+			continue
 		}
-	}
 
-	return resources, nil
-}
+		internAPIResource(apiResource)
 
-// excludeGroup checks if the group should be excluded based on the options.
-func excludeGroup(group *metav1.APIGroup, options *apiResourceVersionsOptions) bool {
-	if options.groupChanged && options.APIGroup != group.Name {
-		return true
+		resources = append(resources, resource)
 	}
 
-	return false
+	return resources
 }
 
-// excludeGroupResource checks if the resource should be excluded based on the options.
-//
-//nolint:cyclop
-func excludeGroupResource(resource groupResource, options *apiResourceVersionsOptions) bool {
-	if options.nsChanged && options.Namespaced != resource.APIResource.Namespaced {
-		return true
+// filter returns the filter of the resources selected by the options.
+func (o *apiResourceVersionsOptions) filter() apiresourceversions.Filter {
+	filter := apiresourceversions.Filter{
+		Verbs:               o.Verbs,
+		Categories:          o.Categories,
+		IncludeSubresources: o.IncludeSubresources,
+		Query:               o.query,
 	}
 
-	if len(options.Verbs) > 0 && !sets.New(resource.APIResource.Verbs...).HasAll(options.Verbs...) {
-		return true
+	if o.groupChanged {
+		filter.APIGroup = &o.APIGroup
 	}
 
-	if len(options.Categories) > 0 && !sets.New(resource.APIResource.Categories...).HasAll(options.Categories...) {
-		return true
+	if o.nsChanged {
+		filter.Namespaced = &o.Namespaced
 	}
 
-	if options.preferredChanged && options.Preferred != resource.Preferred {
-		return true
+	if o.preferredChanged {
+		filter.Preferred = &o.Preferred
 	}
 
-	if !options.IncludeSubresources && resource.Subresource {
-		return true
-	}
+	return filter
+}
+
+// excludeGroup checks if the group should be excluded based on the filter of the options, see
+// [apiResourceVersionsOptions.filter], and on their origin filters.
+func excludeGroup(group *metav1.APIGroup, filter apiresourceversions.Filter, options *apiResourceVersionsOptions) bool {
+	return !filter.MatchesGroup(group.Name) || excludeGroupOrigin(group.Name, options)
+}
 
-	return false
+// excludeGroupResource checks if the resource should be excluded based on the filter of the options, see
+// [apiResourceVersionsOptions.filter].
+func excludeGroupResource(resource groupResource, filter apiresourceversions.Filter) bool {
+	return !filter.Matches(resource.resource())
 }
 
 // printGroupResources prints the API resources and their group versions in the format specified by
@@ -434,8 +1393,8 @@ func printGroupResources(resources []groupResource, options *apiResourceVersions
 	writer := printers.GetNewTabWriter(options.Out)
 	defer mustFlushWriter(writer)
 
-	if !options.NoHeaders && options.Output != nameOutput {
-		err := printHeaders(writer, options.Output)
+	if !options.NoHeaders && !options.namesOnly() {
+		err := printHeaders(writer, options)
 		if err != nil {
 			return err
 		}
@@ -445,18 +1404,20 @@ func printGroupResources(resources []groupResource, options *apiResourceVersions
 
 	var errs []error
 
+	// The columns are reused between the rows, rather than allocated for each of them.
+	columns := make([]string, 0, maxRowColumns)
+
 	for _, resource := range resources {
 		var err error
 
-		switch options.Output {
-		case nameOutput:
-			err = printGroupResourcesByName(writer, resource)
-		case wideOutput:
-			err = printGroupResourcesWide(writer, resource)
-		default:
-			err = printGroupResourcesDefault(writer, resource)
+		columns, err = printGroupResource(writer, columns, resource, options)
+		if err != nil {
+			errs = append(errs, err)
 		}
+	}
 
+	if options.ShowEmptyGroups {
+		err := printEmptyGroupVersions(writer, resources, options)
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -470,18 +1431,82 @@ func printGroupResources(resources []groupResource, options *apiResourceVersions
 }
 
 // printHeaders prints the headers for the output table.
-func printHeaders(out io.Writer, output string) error {
+func printHeaders(out io.Writer, options *apiResourceVersionsOptions) error {
+	// The headers are never truncated with --max-column-width, only the values of the columns.
+	_, err := fmt.Fprintf(out, "%s\n", strings.Join(tableHeaders(options), "\t"))
+	if err != nil {
+		return fmt.Errorf("error printing headers: %w", err)
+	}
+
+	return nil
+}
+
+// tableHeaders returns the headers of the columns of the output table, including any optional columns.
+func tableHeaders(options *apiResourceVersionsOptions) []string {
 	headers := []string{"NAME", "SHORTNAMES", "APIVERSION", "NAMESPACED", "KIND", "PREFERRED"}
-	if output == "wide" {
+	if options.ShowServerVersion {
+		headers = append([]string{"SERVER-VERSION"}, headers...)
+	}
+
+	if len(options.clusters) > 0 {
+		headers = append([]string{"CLUSTER"}, headers...)
+	}
+
+	if options.Output == wideOutput {
 		headers = append(headers, "GROUPPREFERRED", "VERBS", "CATEGORIES")
 	}
 
-	_, err := fmt.Fprintf(out, "%s\n", strings.Join(headers, "\t"))
-	if err != nil {
-		return fmt.Errorf("error printing headers: %w", err)
+	if options.ShowVersionsServed {
+		headers = append(headers, "VERSIONS-SERVED")
 	}
 
-	return nil
+	if options.ShowGroupPriority {
+		headers = append(headers, "GROUP-PRIORITY")
+	}
+
+	if options.ShowMinK8s {
+		headers = append(headers, "MIN-K8S")
+	}
+
+	if options.ShowUsage {
+		headers = append(headers, "REQUESTED")
+	}
+
+	if options.ShowCounts {
+		headers = append(headers, "COUNT")
+	}
+
+	if options.ShowCommands {
+		headers = append(headers, "COMMAND")
+	}
+
+	return headers
+}
+
+// printGroupResource prints the API resource in the format selected by [apiResourceVersionsOptions].
+// The columns are a buffer reused between the rows, which is returned to be reused for the next row.
+func printGroupResource(
+	writer io.Writer,
+	columns []string,
+	resource groupResource,
+	options *apiResourceVersionsOptions,
+) ([]string, error) {
+	switch options.Output {
+	case nameOutput:
+		if len(options.clusters) > 0 {
+			columns = append(columns[:0], resource.Cluster, resource.fullname())
+
+			return columns, printRow(writer, columns)
+		}
+
+		return columns, printGroupResourcesByName(writer, resource)
+	case name0Output:
+		return columns, printGroupResourceName0(writer, resource)
+	default:
+		columns = truncateColumns(appendRowColumns(columns[:0], resource, options), options.maxColumnWidths)
+
+		return columns, printRow(writer, columns)
+	}
 }
 
 // printGroupResourcesByName prints the API resource name in the format expected by kubectl.
@@ -494,38 +1519,97 @@ func printGroupResourcesByName(writer io.Writer, resource groupResource) error {
 	return nil
 }
 
-// printGroupResourcesWide prints the API resources in wide format.
-func printGroupResourcesWide(writer io.Writer, resource groupResource) error {
-	_, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%s\t%t\t%t\t%s\t%s\n",
-		resource.APIResource.Name,
-		strings.Join(resource.APIResource.ShortNames, ","),
-		resource.APIGroupVersion,
-		resource.APIResource.Namespaced,
-		resource.APIResource.Kind,
-		resource.Preferred,
-		resource.PreferredGroupVersion(),
-		strings.Join(resource.APIResource.Verbs, ","),
-		strings.Join(resource.APIResource.Categories, ","),
-	)
-	if err != nil {
-		return fmt.Errorf("error printing resource in wide format: %w", err)
+// maxRowColumns is the maximum number of columns of a row: the cluster and its server version, the default and wide
+// columns, the number of versions served, the priority of the group, the release of introduction, whether it has been
+// requested, the count, and the command.
+const maxRowColumns = 17
+
+// appendRowColumns appends the columns of the resource in the tabular format selected by
+// [apiResourceVersionsOptions], including any optional columns.
+func appendRowColumns(columns []string, resource groupResource, options *apiResourceVersionsOptions) []string {
+	if len(options.clusters) > 0 {
+		columns = append(columns, resource.Cluster)
 	}
 
-	return nil
+	if options.ShowServerVersion {
+		columns = append(columns, resource.ServerVersion)
+	}
+
+	columns = appendDefaultColumns(columns, resource, options.displayGroupVersion(resource.APIGroupVersion))
+
+	if options.Output == wideOutput {
+		columns = appendWideColumns(columns, resource)
+	}
+
+	if options.ShowVersionsServed {
+		columns = append(columns, resource.versionsServedString())
+	}
+
+	if options.ShowGroupPriority {
+		columns = append(columns, resource.groupPriorityString())
+	}
+
+	if options.ShowMinK8s {
+		columns = append(columns, resource.minK8sString())
+	}
+
+	if options.ShowUsage {
+		columns = append(columns, resource.requestedString())
+	}
+
+	if options.ShowCounts {
+		columns = append(columns, resource.countString())
+	}
+
+	if options.ShowCommands {
+		columns = append(columns, resource.command())
+	}
+
+	return columns
 }
 
-// printGroupResourcesDefault prints the API resources in the default format.
-func printGroupResourcesDefault(writer io.Writer, resource groupResource) error {
-	_, err := fmt.Fprintf(writer, "%s\t%s\t%s\t%v\t%s\t%t\n",
+// appendDefaultColumns appends the columns printed for the resource in the default format, with its group version as
+// displayed.
+func appendDefaultColumns(columns []string, resource groupResource, apiVersion string) []string {
+	return append(columns,
 		resource.APIResource.Name,
 		strings.Join(resource.APIResource.ShortNames, ","),
-		resource.APIGroupVersion,
-		resource.APIResource.Namespaced,
+		apiVersion,
+		strconv.FormatBool(resource.APIResource.Namespaced),
 		resource.APIResource.Kind,
-		resource.Preferred,
+		strconv.FormatBool(resource.Preferred),
 	)
+}
+
+// appendWideColumns appends the additional columns printed for the resource in wide format.
+func appendWideColumns(columns []string, resource groupResource) []string {
+	return append(columns,
+		strconv.FormatBool(resource.PreferredGroupVersion()),
+		strings.Join(resource.APIResource.Verbs, ","),
+		strings.Join(resource.APIResource.Categories, ","),
+	)
+}
+
+// printRow prints the tab-separated columns as a single row.
+// The columns are written one by one, rather than joined into a new string.
+func printRow(writer io.Writer, columns []string) error {
+	for i, column := range columns {
+		if i > 0 {
+			_, err := io.WriteString(writer, "\t")
+			if err != nil {
+				return fmt.Errorf("error printing resource row: %w", err)
+			}
+		}
+
+		_, err := io.WriteString(writer, column)
+		if err != nil {
+			return fmt.Errorf("error printing resource row: %w", err)
+		}
+	}
+
+	_, err := io.WriteString(writer, "\n")
 	if err != nil {
-		return fmt.Errorf("error printing resource in default format: %w", err)
+		return fmt.Errorf("error printing resource row: %w", err)
 	}
 
 	return nil
@@ -559,18 +1643,11 @@ func (s sortableResource) Swap(i, j int) {
 func (s sortableResource) Less(i, j int) bool {
 	left, right := s.resources[i], s.resources[j]
 
-	switch s.sortBy {
-	case nameSortBy:
-		return left.APIResource.Name < right.APIResource.Name
-	case kindSortBy:
-		return left.APIResource.Kind < right.APIResource.Kind
-	default:
-		if left.APIGroup.Name != right.APIGroup.Name {
-			return left.APIGroup.Name < right.APIGroup.Name
-		}
-
-		return left.APIResource.Name < right.APIResource.Name
+	if left.Cluster != right.Cluster {
+		return left.Cluster < right.Cluster
 	}
+
+	return apiresourceversions.Less(left.resource(), right.resource(), apiresourceversions.SortBy(s.sortBy))
 }
 
 // constError is a simple implementation of the error interface that returns a constant string.
@@ -10,10 +10,12 @@ import (
 	"slices"
 	"sort"
 	"testing"
+	"time"
 
-	"github.com/Izzette/kubectl-api-resource-versions/internal/discoverytesting"
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
 	"github.com/liggitt/tabwriter"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // TestValidateOptions tests validation of command options.
@@ -36,16 +38,325 @@ func TestValidateOptions(t *testing.T) {
 		options: NewTestOptionsBuilder().SetSortBy(nameSortBy).APIResourceVersionsOptions(),
 		wantErr: nil,
 	}.Test)
+	t.Run("EmptyAndNonEmpty", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetEmptyOnly(true).SetNonEmptyOnly(true).APIResourceVersionsOptions(),
+		wantErr: errEmptyNonEmpty,
+	}.Test)
+	t.Run("WatchZeroInterval", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetWatch(true, 0).APIResourceVersionsOptions(),
+		wantErr: errInterval,
+	}.Test)
+	t.Run("WatchInterval", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetWatch(true, time.Second).APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("NegativeCacheTTL", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetCacheTTL(-time.Minute).APIResourceVersionsOptions(),
+		wantErr: errCacheTTL,
+	}.Test)
+	t.Run("StaleOKOffline", validateOptionsTest{
+		options: NewTestOptionsBuilder().WithStaleDiscoveryClient("testdata/discovery").SetOffline(true).
+			APIResourceVersionsOptions(),
+		wantErr: errStaleOK,
+	}.Test)
+	t.Run("ZeroDiscoveryConcurrency", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetDiscoveryConcurrency(0).APIResourceVersionsOptions(),
+		wantErr: errDiscoveryConcurrency,
+	}.Test)
+	t.Run("StreamSortBy", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetStream(true).SetSortBy(kindSortBy).APIResourceVersionsOptions(),
+		wantErr: errStream,
+	}.Test)
+	t.Run("NegativeTimeout", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetTimeout(-time.Second).APIResourceVersionsOptions(),
+		wantErr: errNegativeTimeout,
+	}.Test)
+	t.Run("ZeroCountConcurrency", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetShowCounts(true).SetCountConcurrency(0).APIResourceVersionsOptions(),
+		wantErr: errCountConcurrency,
+	}.Test)
+	t.Run("NegativeCountChunkSize", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetShowCounts(true).SetCountChunkSize(-1).APIResourceVersionsOptions(),
+		wantErr: errCountChunkSize,
+	}.Test)
+	t.Run("NegativeCountTimeout", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetShowCounts(true).SetCountTimeout(-time.Second).APIResourceVersionsOptions(),
+		wantErr: errCountTimeout,
+	}.Test)
+	t.Run("NegativeMaxWait", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetMaxWait(-time.Second).APIResourceVersionsOptions(),
+		wantErr: errMaxWait,
+	}.Test)
+	t.Run("AllContextsAndContexts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetContexts(true, []string{"prod"}).APIResourceVersionsOptions(),
+		wantErr: errAllContexts,
+	}.Test)
+	t.Run("WatchContexts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetContexts(true, nil).SetWatch(true, time.Second).
+			APIResourceVersionsOptions(),
+		wantErr: errWatchContexts,
+	}.Test)
+	t.Run("ContextsAndClustersFile", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetContexts(false, []string{"prod"}).SetClustersFile("fleet.yaml", "").
+			APIResourceVersionsOptions(),
+		wantErr: errAllContexts,
+	}.Test)
+	t.Run("ClusterSelectorWithoutClustersFile", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetClustersFile("", "env=prod").APIResourceVersionsOptions(),
+		wantErr: errClusterSelector,
+	}.Test)
+	t.Run("InvalidCompareRelease", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetCompareRelease("latest").APIResourceVersionsOptions(),
+		wantErr: errCompareRelease,
+	}.Test)
+	t.Run("CompareReleaseOutput", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetCompareRelease("v1.33").SetOutput(nameOutput).APIResourceVersionsOptions(),
+		wantErr: errCompareReleaseMode,
+	}.Test)
+	t.Run("OfflineCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOffline(true).SetShowCounts(true).APIResourceVersionsOptions(),
+		wantErr: errOfflineCounts,
+	}.Test)
+	t.Run("FromDumpOffline", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetFromDump("testdata/discovery").SetOffline(true).APIResourceVersionsOptions(),
+		wantErr: errFromDump,
+	}.Test)
+	t.Run("FromFileFromDump", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetFromFiles("testdata/discoveryfiles/apis.json").
+			SetFromDump("testdata/discovery").APIResourceVersionsOptions(),
+		wantErr: errFromFile,
+	}.Test)
+	t.Run("FromFileShowCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetFromFiles("testdata/discoveryfiles/apis.json").SetShowCounts(true).
+			APIResourceVersionsOptions(),
+		wantErr: errOfflineCounts,
+	}.Test)
+	t.Run("NameNoHeaders", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetNoHeaders(true).APIResourceVersionsOptions(),
+		wantErr: errNameNoHeaders,
+	}.Test)
+	t.Run("NameShowCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetShowCounts(true).APIResourceVersionsOptions(),
+		wantErr: errNameCounts,
+	}.Test)
+	t.Run("NameInheritedNoHeaders", validateOptionsTest{
+		options:   NewTestOptionsBuilder().SetOutput(nameOutput).SetNoHeaders(true).APIResourceVersionsOptions(),
+		inherited: []string{"no-headers"},
+		wantErr:   nil,
+	}.Test)
+	t.Run("NameInheritedShowCounts", validateOptionsTest{
+		options:   NewTestOptionsBuilder().SetOutput(nameOutput).SetShowCounts(true).APIResourceVersionsOptions(),
+		inherited: []string{"show-counts"},
+		wantErr:   nil,
+	}.Test)
+	t.Run("InheritedNameShowCounts", validateOptionsTest{
+		options:   NewTestOptionsBuilder().SetOutput(nameOutput).SetShowCounts(true).APIResourceVersionsOptions(),
+		inherited: []string{"output"},
+		wantErr:   nil,
+	}.Test)
+	t.Run("NameInheritedShowCommands", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetShowCommands(true).
+			APIResourceVersionsOptions(),
+		inherited: []string{"show-commands"},
+		wantErr:   errNameCommands,
+	}.Test)
+	t.Run("APIVersionsShowCommands", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(apiVersionsOutput).SetShowCommands(true).
+			APIResourceVersionsOptions(),
+		wantErr: errNameCommands,
+	}.Test)
+	t.Run("ServerVersionSingleCluster", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetShowServerVersion(true).APIResourceVersionsOptions(),
+		wantErr: errServerVersionClusters,
+	}.Test)
+	t.Run("ServerVersionName", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetContexts(true, nil).SetShowServerVersion(true).
+			APIResourceVersionsOptions(),
+		wantErr: errNameServerVersion,
+	}.Test)
+	t.Run("ServerVersionContexts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetContexts(true, nil).SetShowServerVersion(true).APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("ScriptShowMinK8s", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(scriptOutput).SetShowMinK8s(true).APIResourceVersionsOptions(),
+		wantErr: errNameMinK8s,
+	}.Test)
+	t.Run("GVKWatch", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(gvkOutput).SetWatch(true, time.Minute).APIResourceVersionsOptions(),
+		wantErr: errGVKMode,
+	}.Test)
+	t.Run("GVKJSONShowCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(gvkJSONOutput).SetShowCounts(true).APIResourceVersionsOptions(),
+		wantErr: errGVKColumns,
+	}.Test)
+	t.Run("CountsStream", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(countsOutput).SetStream(true).APIResourceVersionsOptions(),
+		wantErr: errStream,
+	}.Test)
+	t.Run("CountsWatch", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(countsOutput).SetWatch(true, time.Minute).APIResourceVersionsOptions(),
+		wantErr: errCountsMode,
+	}.Test)
+	t.Run("CountsShowCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(countsOutput).SetShowCounts(true).APIResourceVersionsOptions(),
+		wantErr: errCountsColumns,
+	}.Test)
+	t.Run("CountsVerbs", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(countsOutput).SetVerbs([]string{"get"}).APIResourceVersionsOptions(),
+		wantErr: errCountsVerbs,
+	}.Test)
+	t.Run("MappingStream", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(mappingOutput).SetStream(true).APIResourceVersionsOptions(),
+		wantErr: errMappingMode,
+	}.Test)
+	t.Run("MappingNoHeaders", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(mappingOutput).SetNoHeaders(true).APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("MappingJSONNoHeaders", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(mappingJSONOutput).SetNoHeaders(true).APIResourceVersionsOptions(),
+		wantErr: errMappingJSONNoHeaders,
+	}.Test)
+	t.Run("VersionsServedStream", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetStream(true).SetShowVersionsServed(true).APIResourceVersionsOptions(),
+		wantErr: errVersionsServedStream,
+	}.Test)
+	t.Run("EmptyGroupsYAML", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(yamlOutput).SetShowEmptyGroups(true).APIResourceVersionsOptions(),
+		wantErr: errEmptyGroupsOutput,
+	}.Test)
+	t.Run("CoreGroupNameSlash", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetCoreGroupName("core/v1").APIResourceVersionsOptions(),
+		wantErr: errCoreGroupName,
+	}.Test)
+	t.Run("CoreGroupNameName", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetCoreGroupName("core").APIResourceVersionsOptions(),
+		wantErr: errCoreGroupNameOutput,
+	}.Test)
+	t.Run("SystemUserDefined", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetSystemOnly(true).SetUserDefinedOnly(true).APIResourceVersionsOptions(),
+		wantErr: errSystemUserDefined,
+	}.Test)
+	t.Run("NameShowUsage", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetShowUsage(true).APIResourceVersionsOptions(),
+		wantErr: errNameUsage,
+	}.Test)
+	t.Run("OfflineShowUsage", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOffline(true).SetShowUsage(true).APIResourceVersionsOptions(),
+		wantErr: errOfflineUsage,
+	}.Test)
+	t.Run("NameShowGroupPriority", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetShowGroupPriority(true).APIResourceVersionsOptions(),
+		wantErr: errNameGroupPriority,
+	}.Test)
+	t.Run("OfflineShowGroupPriority", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOffline(true).SetShowGroupPriority(true).APIResourceVersionsOptions(),
+		wantErr: errOfflineGroupPriority,
+	}.Test)
+	t.Run("StreamShowGroupPriority", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetStream(true).SetShowGroupPriority(true).APIResourceVersionsOptions(),
+		wantErr: errStream,
+	}.Test)
+	t.Run("NameMaxColumnWidth", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetMaxColumnWidths("20").APIResourceVersionsOptions(),
+		wantErr: errMaxColumnWidthOutput,
+	}.Test)
+	t.Run("InvalidMaxColumnWidth", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetMaxColumnWidths("KIND=0").APIResourceVersionsOptions(),
+		wantErr: errMaxColumnWidth,
+	}.Test)
+	t.Run("MaxColumnWidthNotPrinted", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetMaxColumnWidths("VERBS=40").APIResourceVersionsOptions(),
+		wantErr: errMaxColumnWidthColumn,
+	}.Test)
+	t.Run("WideMaxColumnWidth", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(wideOutput).SetMaxColumnWidths("20", "verbs=40").
+			APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("OutputFileWatch", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutputFile("resources.txt").SetWatch(true, time.Minute).
+			APIResourceVersionsOptions(),
+		wantErr: errOutputFileWatch,
+	}.Test)
+	t.Run("NotifyWithoutWatch", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetNotify("https://hooks.example.com/api", notifyFormatGeneric).
+			APIResourceVersionsOptions(),
+		wantErr: errNotifyWatch,
+	}.Test)
+	t.Run("NotifyURL", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetWatch(true, time.Minute).SetNotify("hooks.example.com", notifyFormatSlack).
+			APIResourceVersionsOptions(),
+		wantErr: errNotifyURL,
+	}.Test)
+	t.Run("NotifyFormat", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetWatch(true, time.Minute).SetNotify("https://hooks.example.com/api", "teams").
+			APIResourceVersionsOptions(),
+		wantErr: errNotifyFormat,
+	}.Test)
+	t.Run("ExecOutput", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(wideOutput).SetExec("echo {fullname}").APIResourceVersionsOptions(),
+		wantErr: errExecMode,
+	}.Test)
+	t.Run("ScriptWatch", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(scriptOutput).SetWatch(true, time.Minute).
+			APIResourceVersionsOptions(),
+		wantErr: errScriptMode,
+	}.Test)
+	t.Run("Name0NoHeaders", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(name0Output).SetNoHeaders(true).APIResourceVersionsOptions(),
+		wantErr: errNameNoHeaders,
+	}.Test)
+	t.Run("Name0Contexts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(name0Output).SetContexts(true, nil).APIResourceVersionsOptions(),
+		wantErr: errName0Mode,
+	}.Test)
+	t.Run("NameEmptyOnly", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(nameOutput).SetEmptyOnly(true).APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("APIVersionsNoHeaders", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(apiVersionsOutput).SetNoHeaders(true).APIResourceVersionsOptions(),
+		wantErr: errNameNoHeaders,
+	}.Test)
+	t.Run("APIVersionsStream", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(apiVersionsOutput).SetStream(true).APIResourceVersionsOptions(),
+		wantErr: errAPIVersionsMode,
+	}.Test)
+	t.Run("YAMLStream", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(yamlOutput).SetStream(true).APIResourceVersionsOptions(),
+		wantErr: errYAMLMode,
+	}.Test)
+	t.Run("YAMLShowCounts", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetOutput(yamlOutput).SetShowCounts(true).SetShowCommands(true).
+			APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
+	t.Run("CountsWithoutListVerb", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetVerbs([]string{"get"}).SetNonEmptyOnly(true).APIResourceVersionsOptions(),
+		wantErr: errCountsVerbs,
+	}.Test)
+	t.Run("CountsWithListVerb", validateOptionsTest{
+		options: NewTestOptionsBuilder().SetVerbs([]string{"get", "list"}).SetShowCounts(true).
+			APIResourceVersionsOptions(),
+		wantErr: nil,
+	}.Test)
 }
 
 type validateOptionsTest struct {
 	options *apiResourceVersionsOptions
-	wantErr error
+	// inherited are the flags set from the environment or the config file, as recorded by complete.
+	inherited []string
+	wantErr   error
 }
 
 func (tt validateOptionsTest) Test(t *testing.T) {
 	t.Parallel()
 
+	tt.options.inherited = sets.New(tt.inherited...)
+	tt.options.ignoreInheritedCombinations()
+
 	err := tt.options.validate()
 	if !errors.Is(err, tt.wantErr) {
 		t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -90,7 +401,7 @@ type excludeGroupTest struct {
 func (tt excludeGroupTest) Test(t *testing.T) {
 	t.Parallel()
 
-	got := excludeGroup(tt.apiGroup, tt.options)
+	got := excludeGroup(tt.apiGroup, tt.options.filter(), tt.options)
 	if got != tt.want {
 		t.Errorf("excludeGroup() = %v, want %v", got, tt.want)
 	}
@@ -99,7 +410,7 @@ func (tt excludeGroupTest) Test(t *testing.T) {
 func TestExcludeGroupResource(t *testing.T) {
 	t.Parallel()
 
-	apiGroup := &metav1.APIGroup{
+	apiGroup := &resourceGroup{
 		Name: "apps",
 		Versions: []metav1.GroupVersionForDiscovery{
 			{GroupVersion: "apps/v1", Version: "v1"},
@@ -161,7 +472,7 @@ type excludeGroupResourceTest struct {
 func (tt excludeGroupResourceTest) Test(t *testing.T) {
 	t.Parallel()
 
-	got := excludeGroupResource(tt.resource, tt.options)
+	got := excludeGroupResource(tt.resource, tt.options.filter())
 	if got != tt.want {
 		t.Errorf("excludeGroupResource() = %v, want %v", got, tt.want)
 	}
@@ -277,7 +588,7 @@ func (tt unversionedResourceNameTest) Test(t *testing.T) {
 func TestFullname(t *testing.T) {
 	t.Parallel()
 
-	apiGroup := &metav1.APIGroup{
+	apiGroup := &resourceGroup{
 		Name: "apps",
 		Versions: []metav1.GroupVersionForDiscovery{
 			{GroupVersion: "apps/v1", Version: "v1"},
@@ -285,7 +596,7 @@ func TestFullname(t *testing.T) {
 		PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"},
 	}
 
-	coreAPIGroup := &metav1.APIGroup{
+	coreAPIGroup := &resourceGroup{
 		Name: "",
 		Versions: []metav1.GroupVersionForDiscovery{
 			{GroupVersion: "v1", Version: "v1"},
@@ -359,58 +670,6 @@ func (tt fullnameTest) Test(t *testing.T) {
 	}
 }
 
-func TestGetPrefererredResourceVersions(t *testing.T) {
-	t.Parallel()
-
-	t.Run("GetPreferredVersions", getPreferredResourceVersionsTest{
-		preferredVersions: []*metav1.APIResourceList{
-			{
-				GroupVersion: "apps/v1",
-				APIResources: []metav1.APIResource{
-					{Name: "deployments", Namespaced: true, Kind: "Deployment"},
-					{Name: "deployments/status", Namespaced: true},
-				},
-			},
-			{
-				GroupVersion: "autoscaling/v2",
-				APIResources: []metav1.APIResource{
-					{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"},
-				},
-			},
-		},
-		want: map[string]string{
-			"deployments.apps":                     "v1",
-			"horizontalpodautoscalers.autoscaling": "v2",
-		},
-		err: nil,
-	}.Test)
-}
-
-type getPreferredResourceVersionsTest struct {
-	preferredVersions []*metav1.APIResourceList
-	want              map[string]string
-	err               error
-}
-
-func (tt getPreferredResourceVersionsTest) Test(t *testing.T) {
-	t.Parallel()
-
-	builder := discoverytesting.NewFakeCachedDiscoveryClientBuilder()
-	builder.PreferredResources = tt.preferredVersions
-	options := NewTestOptionsBuilder().
-		WithDiscoveryClient(builder.CachedDiscoveryInterface()).
-		APIResourceVersionsOptions()
-
-	got, err := getPreferredResourceVersions(options)
-	if !errors.Is(err, tt.err) {
-		t.Fatalf("getPreferredResourceVersions() error = %v, wantErr %v", err, tt.err)
-	}
-
-	if !reflect.DeepEqual(got, tt.want) {
-		t.Errorf("getPreferredResourceVersions() = %v, want %v", got, tt.want)
-	}
-}
-
 // TestGetGroupResources tests resource discovery and processing.
 func TestGetGroupResources(t *testing.T) {
 	t.Parallel()
@@ -540,7 +799,7 @@ type getGroupResourcesCountTest struct {
 func (tt getGroupResourcesCountTest) Test(t *testing.T) {
 	t.Parallel()
 
-	got, err := getGroupResources(tt.options)
+	got, err := getGroupResources(t.Context(), tt.options)
 	if !errors.Is(err, tt.wantErr) {
 		t.Fatalf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
 	}
@@ -559,7 +818,7 @@ type getGroupResourcesNamesTest struct {
 func (tt getGroupResourcesNamesTest) Test(t *testing.T) {
 	t.Parallel()
 
-	got, err := getGroupResources(tt.options)
+	got, err := getGroupResources(t.Context(), tt.options)
 	if !errors.Is(err, tt.wantErr) {
 		t.Fatalf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
 	}
@@ -588,7 +847,7 @@ type getGroupResourcesTest struct {
 func (tt getGroupResourcesTest) Test(t *testing.T) {
 	t.Parallel()
 
-	got, err := getGroupResources(tt.options)
+	got, err := getGroupResources(t.Context(), tt.options)
 	if !errors.Is(err, tt.wantErr) {
 		t.Fatalf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
 	}
@@ -613,6 +872,30 @@ func (tt getGroupResourcesTest) Test(t *testing.T) {
 	}
 }
 
+// TestGetGroupResourcesConcurrency tests that the resources are discovered in a deterministic order regardless of the
+// discovery concurrency.
+func TestGetGroupResourcesConcurrency(t *testing.T) {
+	t.Parallel()
+
+	cached := discoverytesting.NewProcedural(20, 3, 5)
+
+	want, err := getGroupResources(t.Context(), NewTestOptionsBuilder().WithDiscoveryClient(cached).SetDiscoveryConcurrency(1).
+		APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	got, err := getGroupResources(t.Context(), NewTestOptionsBuilder().WithDiscoveryClient(cached).SetDiscoveryConcurrency(64).
+		APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	if !slices.EqualFunc(got, want, func(a, b groupResource) bool { return a.fullname() == b.fullname() }) {
+		t.Errorf("getGroupResources() with a concurrency of 64 = %v, want %v", got, want)
+	}
+}
+
 // TestPrintFunctions tests output formatting.
 func TestPrintFunctions(t *testing.T) {
 	t.Parallel()
@@ -625,7 +908,7 @@ func TestPrintFunctions(t *testing.T) {
 	}
 
 	sampleResource := groupResource{
-		APIGroup: &metav1.APIGroup{
+		APIGroup: &resourceGroup{
 			Name: "apps",
 			PreferredVersion: metav1.GroupVersionForDiscovery{
 				GroupVersion: "apps/v1",
@@ -647,7 +930,7 @@ func TestPrintFunctions(t *testing.T) {
 	}
 
 	sampleSubresource := groupResource{
-		APIGroup: &metav1.APIGroup{
+		APIGroup: &resourceGroup{
 			Name: "apps",
 			PreferredVersion: metav1.GroupVersionForDiscovery{
 				GroupVersion: "apps/v1",
@@ -715,20 +998,11 @@ func TestPrintFunctions(t *testing.T) {
 			buf := new(bytes.Buffer)
 			writer := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
 
-			var printFunc func(io.Writer, groupResource) error
+			options := NewTestOptionsBuilder().SetOutput(tt.output).APIResourceVersionsOptions()
 
-			switch tt.output {
-			case wideOutput:
-				printFunc = printGroupResourcesWide
-			case nameOutput:
-				printFunc = printGroupResourcesByName
-			default:
-				printFunc = printGroupResourcesDefault
-			}
-
-			err := printFunc(writer, tt.resource)
+			_, err := printGroupResource(writer, nil, tt.resource, options)
 			if err != nil {
-				t.Fatalf("print function failed: %v", err)
+				t.Fatalf("printGroupResource() error = %v", err)
 			}
 
 			mustFlushWriter(writer)
@@ -745,9 +1019,9 @@ func TestSorting(t *testing.T) {
 	t.Parallel()
 
 	resources := []groupResource{
-		{APIResource: &metav1.APIResource{Name: "b-kind", Kind: "BKind"}, APIGroup: &metav1.APIGroup{Name: "foo"}},
-		{APIResource: &metav1.APIResource{Name: "z-kind", Kind: "AKind"}, APIGroup: &metav1.APIGroup{Name: "foo"}},
-		{APIResource: &metav1.APIResource{Name: "m-kind", Kind: "CKind"}, APIGroup: &metav1.APIGroup{Name: "bar"}},
+		{APIResource: &metav1.APIResource{Name: "b-kind", Kind: "BKind"}, APIGroup: &resourceGroup{Name: "foo"}},
+		{APIResource: &metav1.APIResource{Name: "z-kind", Kind: "AKind"}, APIGroup: &resourceGroup{Name: "foo"}},
+		{APIResource: &metav1.APIResource{Name: "m-kind", Kind: "CKind"}, APIGroup: &resourceGroup{Name: "bar"}},
 	}
 
 	t.Run("sort by name", func(t *testing.T) {
@@ -802,18 +1076,18 @@ func BenchmarkGet3000GroupResources(b *testing.B) {
 	options := NewTestOptionsBuilder().WithDiscoveryClient(cached).APIResourceVersionsOptions()
 
 	for b.Loop() {
-		_, err := getGroupResources(options)
+		_, err := getGroupResources(b.Context(), options)
 		if err != nil {
 			b.Fatalf("getGroupResources failed: %v", err)
 		}
 	}
 }
 
-// BenchmarkPrintGroupResources benchmarks the performance of printing group resources using the default print function.
+// BenchmarkPrintGroupResources benchmarks the performance of printing group resources in the default format.
 func BenchmarkPrintGroupResources(b *testing.B) {
 	// Create a large fake discovery client
 	groupResource := groupResource{
-		APIGroup: &metav1.APIGroup{
+		APIGroup: &resourceGroup{
 			Name: "testgroup",
 			Versions: []metav1.GroupVersionForDiscovery{
 				{GroupVersion: "testgroup/v1", Version: "v1"},
@@ -833,11 +1107,16 @@ func BenchmarkPrintGroupResources(b *testing.B) {
 		},
 	}
 
+	options := NewTestOptionsBuilder().APIResourceVersionsOptions()
+	columns := make([]string, 0, maxRowColumns)
+
 	for b.Loop() {
-		// Print the resources using the default print function
-		err := printGroupResourcesDefault(io.Discard, groupResource)
+		// Print the resources in the default format, reusing the columns between the rows
+		var err error
+
+		columns, err = printGroupResource(io.Discard, columns, groupResource, options)
 		if err != nil {
-			b.Fatalf("printGroupResourcesDefault failed: %v", err)
+			b.Fatalf("printGroupResource failed: %v", err)
 		}
 	}
 }
@@ -846,7 +1125,7 @@ func BenchmarkSortableResource(b *testing.B) {
 	cached := discoverytesting.NewProcedural(100, 3, 30)
 	options := NewTestOptionsBuilder().WithDiscoveryClient(cached).APIResourceVersionsOptions()
 	// Create a large number of resources for sorting
-	groupResources, err := getGroupResources(options)
+	groupResources, err := getGroupResources(b.Context(), options)
 	if err != nil {
 		b.Fatalf("getGroupResources failed: %v", err)
 	}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// errAPIVersionsMode is returned when --output=api-versions is requested with multiple clusters, or with a mode
+// printing the resources as they are discovered.
+const errAPIVersionsMode = constError(
+	"output=api-versions is not supported with stream, watch, all-contexts, contexts, or clusters-file")
+
+const (
+	// apiVersionPreferred annotates the preferred version of a group in the api-versions output.
+	apiVersionPreferred = "preferred"
+	// apiVersionDeprecated annotates a deprecated group version in the api-versions output.
+	apiVersionDeprecated = "deprecated"
+)
+
+// apiVersionAnnotations are the annotations of a group version in the api-versions output.
+type apiVersionAnnotations struct {
+	preferred bool
+	// knownKinds are the number of kinds of the group version whose lifecycle is known, and deprecatedKinds are
+	// those of them which are deprecated in the release of the cluster.
+	knownKinds      int
+	deprecatedKinds int
+}
+
+// printAPIVersions prints the group versions of the resources sorted like kubectl api-versions, one per line, with
+// whether they are the preferred version of their group and whether they are deprecated, e.g.:
+//
+//	apps/v1                preferred
+//	autoscaling/v2         preferred
+//	autoscaling/v2beta2    deprecated
+//
+// The first column is the output of kubectl api-versions, limited to the group versions of the selected resources.
+// A group version is deprecated if every one of its kinds with a known lifecycle is deprecated in the release of the
+// cluster, or ever deprecated if the release can't be determined.
+func printAPIVersions(resources []groupResource, options *apiResourceVersionsOptions) error {
+	var release *utilversion.Version
+
+	serverVersion, err := options.discoveryClient.ServerVersion()
+	if err == nil {
+		release, _ = lifecycle.ParseRelease(serverVersion.GitVersion)
+	}
+
+	annotations := make(map[string]*apiVersionAnnotations)
+
+	for _, resource := range resources {
+		annotation, ok := annotations[resource.APIGroupVersion]
+		if !ok {
+			annotation = &apiVersionAnnotations{
+				preferred: resource.APIGroup.PreferredVersion.GroupVersion == resource.APIGroupVersion,
+			}
+			annotations[resource.APIGroupVersion] = annotation
+		}
+
+		if resource.Subresource {
+			continue
+		}
+
+		gvk := schema.FromAPIVersionAndKind(resource.APIGroupVersion, resource.APIResource.Kind)
+
+		api, known := lifecycle.Lookup(gvk)
+		if known {
+			annotation.knownKinds++
+
+			if api.DeprecatedIn(release) {
+				annotation.deprecatedKinds++
+			}
+		}
+	}
+
+	groupVersions := make([]string, 0, len(annotations))
+	for groupVersion := range annotations {
+		groupVersions = append(groupVersions, groupVersion)
+	}
+
+	slices.Sort(groupVersions)
+
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	for _, groupVersion := range groupVersions {
+		columns := []string{groupVersion}
+
+		annotation := annotations[groupVersion]
+		if annotation.preferred {
+			columns = append(columns, apiVersionPreferred)
+		}
+
+		if annotation.knownKinds > 0 && annotation.deprecatedKinds == annotation.knownKinds {
+			columns = append(columns, apiVersionDeprecated)
+		}
+
+		err := printRow(writer, columns)
+		if err != nil {
+			return fmt.Errorf("error printing api version %s: %w", groupVersion, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunAPIVersionsOutput tests printing the group versions like kubectl api-versions, with their annotations.
+func TestRunAPIVersionsOutput(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetOutput(apiVersionsOutput)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "autoscaling/v1\n" +
+		"autoscaling/v2        preferred\n" +
+		"autoscaling/v2beta2   deprecated\n" +
+		"v1                    preferred\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
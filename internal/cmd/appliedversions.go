@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apimachineryerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// lastAppliedAnnotation is the annotation in which kubectl apply records the last applied configuration.
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// appliedSourceLastApplied is the source of the API versions read from the [lastAppliedAnnotation].
+	appliedSourceLastApplied = "last-applied"
+	// appliedSourceManagerPrefix prefixes the manager of the API versions read from the managed fields.
+	appliedSourceManagerPrefix = "manager:"
+
+	defaultAppliedVersionsChunkSize = 500
+)
+
+var (
+	// appliedVersionsExample is the example text for the applied-versions command.
+	//
+	//nolint:gochecknoglobals
+	appliedVersionsExample = `
+		# Find the HorizontalPodAutoscalers last applied with an API version which isn't preferred
+		kubectl api-resource-versions applied-versions horizontalpodautoscalers.autoscaling
+
+		# Find the objects of every resource in the apps group last applied with an outdated API version
+		kubectl api-resource-versions --api-group=apps --preferred --verbs=list --output=name |
+			xargs kubectl api-resource-versions applied-versions`
+)
+
+// newCmdAppliedVersions returns a command that finds the objects of the selected resources which were last applied
+// with an API version which isn't preferred.
+func newCmdAppliedVersions(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newAppliedVersionsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "applied-versions RESOURCE [RESOURCE...]",
+		Short: "Find objects last applied with outdated API versions",
+		Long: "List the objects of the given resources and report those whose " + lastAppliedAnnotation +
+			" annotation or managed fields use an API version which is served but not preferred, deprecated, or " +
+			"absent in the cluster, pointing at the manifests and the field managers which need updating.\n" +
+			"Resources can be given as <resource>.<group> to use the preferred version, or as " +
+			"<resource>.<version>.<group> as printed by --output=name.",
+		Example:           templates.Examples(appliedVersionsExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runAppliedVersions(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().Int64Var(&options.ChunkSize, "chunk-size", options.ChunkSize,
+		"Return large lists in chunks rather than all at once. Pass 0 to disable.")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+
+	return cmd
+}
+
+// appliedVersionsOptions contains the options for the applied-versions command.
+type appliedVersionsOptions struct {
+	genericiooptions.IOStreams
+
+	ChunkSize int64
+	NoHeaders bool
+
+	resources       []schema.GroupVersionResource
+	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newAppliedVersionsOptions returns a new [appliedVersionsOptions] with default values.
+func newAppliedVersionsOptions(ioStreams genericiooptions.IOStreams) *appliedVersionsOptions {
+	return &appliedVersionsOptions{
+		IOStreams: ioStreams,
+		ChunkSize: defaultAppliedVersionsChunkSize,
+	}
+}
+
+// complete completes all the required options for the applied-versions command.
+func (o *appliedVersionsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) == 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "at least one resource is required")
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	o.resources = make([]schema.GroupVersionResource, 0, len(args))
+
+	for _, arg := range args {
+		gvr, err := resolveResourceArg(o.discoveryClient, arg)
+		if err != nil {
+			return err
+		}
+
+		o.resources = append(o.resources, gvr)
+	}
+
+	return nil
+}
+
+// validate checks that options are valid for the applied-versions command.
+func (o *appliedVersionsOptions) validate() error {
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("%w: %d", errChunkSize, o.ChunkSize)
+	}
+
+	return nil
+}
+
+// appliedVersionFinding is an API version, other than the preferred one, with which an object has been applied.
+type appliedVersionFinding struct {
+	// Name is the name of the object, prefixed by its namespace if it has one.
+	Name string
+	// Kind is the kind of the object.
+	Kind string
+	// Source is where the API version was read from: the last applied configuration, or the managed fields of a
+	// field manager.
+	Source string
+	// APIVersion is the API version with which the object has been applied.
+	APIVersion string
+	// Status is the status of the API version in the cluster.
+	Status manifestStatus
+	// PreferredVersion is the preferred group version for the kind in the cluster, or the replacement group version
+	// from the lifecycle database if the kind isn't served.
+	PreferredVersion string
+}
+
+// runAppliedVersions lists the objects of each of the selected resources and prints the API versions with which they
+// have been applied, other than the preferred ones.
+// The resources which can't be listed are reported once the others have been printed.
+func runAppliedVersions(ctx context.Context, options *appliedVersionsOptions) error {
+	kinds, err := getServedKinds(ctx, options.discoveryClient)
+	if err != nil {
+		return err
+	}
+
+	var (
+		findings []appliedVersionFinding
+		errs     []error
+	)
+
+	for _, gvr := range options.resources {
+		resourceFindings, err := findAppliedVersions(ctx, gvr, kinds, options)
+		findings = append(findings, resourceFindings...)
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err = printAppliedVersionFindings(findings, options)
+	if err != nil {
+		return err
+	}
+
+	return apimachineryerrors.NewAggregate(errs)
+}
+
+// findAppliedVersions lists the objects of the resource in chunks and returns the API versions with which they have
+// been applied, other than the preferred ones.
+// It returns the findings of the objects listed before an error occurred, if any.
+func findAppliedVersions(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	kinds *servedKinds,
+	options *appliedVersionsOptions,
+) ([]appliedVersionFinding, error) {
+	var findings []appliedVersionFinding
+
+	resourceClient := options.dynamicClient.Resource(gvr)
+	listOptions := metav1.ListOptions{Limit: options.ChunkSize}
+
+	for {
+		list, err := resourceClient.List(ctx, listOptions)
+		if err != nil {
+			return findings, fmt.Errorf("couldn't list %s: %w", gvr.String(), err)
+		}
+
+		for i := range list.Items {
+			findings = appendAppliedVersionFindings(findings, &list.Items[i], kinds, options)
+		}
+
+		listOptions.Continue = list.GetContinue()
+		if listOptions.Continue == "" {
+			return findings, nil
+		}
+	}
+}
+
+// appendAppliedVersionFindings appends the API versions with which the object has been applied, according to its
+// last applied configuration and its managed fields, unless they are preferred.
+// Each API version is reported once for each source.
+func appendAppliedVersionFindings(
+	findings []appliedVersionFinding,
+	obj *unstructured.Unstructured,
+	kinds *servedKinds,
+	options *appliedVersionsOptions,
+) []appliedVersionFinding {
+	type sourceVersion struct {
+		source     string
+		apiVersion string
+	}
+
+	var sourceVersions []sourceVersion
+
+	lastApplied, ok := obj.GetAnnotations()[lastAppliedAnnotation]
+	if ok {
+		var typeMeta metav1.TypeMeta
+
+		err := json.Unmarshal([]byte(lastApplied), &typeMeta)
+		if err != nil {
+			_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't read the %s annotation of %s %s: %v\n",
+				lastAppliedAnnotation, obj.GetKind(), appliedObjectName(obj), err)
+		} else if typeMeta.APIVersion != "" {
+			sourceVersions = append(sourceVersions, sourceVersion{appliedSourceLastApplied, typeMeta.APIVersion})
+		}
+	}
+
+	for _, managedFields := range obj.GetManagedFields() {
+		if managedFields.APIVersion != "" {
+			sourceVersions = append(sourceVersions,
+				sourceVersion{appliedSourceManagerPrefix + managedFields.Manager, managedFields.APIVersion})
+		}
+	}
+
+	seen := sets.New[sourceVersion]()
+
+	for _, sourceVersion := range sourceVersions {
+		if seen.Has(sourceVersion) {
+			continue
+		}
+
+		seen.Insert(sourceVersion)
+
+		applied := &unstructured.Unstructured{}
+		applied.SetAPIVersion(sourceVersion.apiVersion)
+		applied.SetKind(obj.GetKind())
+
+		finding := kinds.check(manifestLocation{}, applied)
+		if finding.Status == manifestStatusPreferred {
+			continue
+		}
+
+		findings = append(findings, appliedVersionFinding{
+			Name:             appliedObjectName(obj),
+			Kind:             obj.GetKind(),
+			Source:           sourceVersion.source,
+			APIVersion:       sourceVersion.apiVersion,
+			Status:           finding.Status,
+			PreferredVersion: finding.PreferredVersion,
+		})
+	}
+
+	return findings
+}
+
+// appliedObjectName returns the name of the object, prefixed by its namespace if it has one.
+func appliedObjectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// printAppliedVersionFindings prints the applied version findings as a table.
+func printAppliedVersionFindings(findings []appliedVersionFinding, options *appliedVersionsOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"NAME", "KIND", "SOURCE", "APIVERSION", "STATUS", "PREFERRED"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, finding := range findings {
+		err := printRow(writer, []string{
+			finding.Name,
+			finding.Kind,
+			finding.Source,
+			finding.APIVersion,
+			string(finding.Status),
+			finding.PreferredVersion,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
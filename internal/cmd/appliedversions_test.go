@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunAppliedVersions tests finding the objects applied with an API version which isn't preferred, from their last
+// applied configuration and their managed fields.
+func TestRunAppliedVersions(t *testing.T) {
+	t.Parallel()
+
+	outdated := newUnstructured("autoscaling/v2", "HorizontalPodAutoscaler", "default", "web")
+	outdated.SetAnnotations(map[string]string{
+		lastAppliedAnnotation: `{"apiVersion":"autoscaling/v2beta1","kind":"HorizontalPodAutoscaler"}`,
+	})
+	outdated.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "autoscaling/v1"},
+		{Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "autoscaling/v1"},
+		{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "autoscaling/v2"},
+	})
+
+	current := newUnstructured("autoscaling/v2", "HorizontalPodAutoscaler", "default", "api")
+	current.SetAnnotations(map[string]string{
+		lastAppliedAnnotation: `{"apiVersion":"autoscaling/v2","kind":"HorizontalPodAutoscaler"}`,
+	})
+
+	ioStreams, _, stdout, stderr := genericiooptions.NewTestIOStreams()
+	options := newAppliedVersionsOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = discoverytesting.NewDynamic(outdated, current)
+	options.resources = []schema.GroupVersionResource{
+		{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+	}
+
+	err := runAppliedVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runAppliedVersions() error = %v", err)
+	}
+
+	want := "NAME          KIND                      SOURCE                              APIVERSION            " +
+		"STATUS   PREFERRED\n" +
+		"default/web   HorizontalPodAutoscaler   last-applied                        autoscaling/v2beta1   " +
+		"absent   autoscaling/v2\n" +
+		"default/web   HorizontalPodAutoscaler   manager:kubectl-client-side-apply   autoscaling/v1        " +
+		"served   autoscaling/v2\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAppliedVersions() output = %q, want %q", got, want)
+	}
+
+	if got := stderr.String(); got != "" {
+		t.Errorf("runAppliedVersions() error output = %q, want none", got)
+	}
+}
+
+// TestAppliedVersionsMalformedAnnotation tests that a malformed last applied configuration is reported as a warning.
+func TestAppliedVersionsMalformedAnnotation(t *testing.T) {
+	t.Parallel()
+
+	obj := newUnstructured("v1", "Pod", "default", "pod-a")
+	obj.SetAnnotations(map[string]string{lastAppliedAnnotation: "{"})
+
+	ioStreams, _, _, stderr := genericiooptions.NewTestIOStreams()
+
+	findings := appendAppliedVersionFindings(nil, obj, &servedKinds{}, newAppliedVersionsOptions(ioStreams))
+	if len(findings) != 0 {
+		t.Errorf("appendAppliedVersionFindings() = %v, want none", findings)
+	}
+
+	if stderr.Len() == 0 {
+		t.Error("appendAppliedVersionFindings() printed no warning")
+	}
+}
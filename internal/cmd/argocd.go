@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// defaultArgoCDNamespace is the namespace in which Argo CD Applications are listed by default.
+	defaultArgoCDNamespace = "argocd"
+	// argoCDInClusterServer is the destination server of the Applications deployed to the cluster of Argo CD.
+	argoCDInClusterServer = "https://kubernetes.default.svc"
+	// argoCDInClusterName is the destination name of the Applications deployed to the cluster of Argo CD.
+	argoCDInClusterName = "in-cluster"
+)
+
+// argoCDApplicationsGVR is the group version resource of the Argo CD Application API.
+//
+//nolint:gochecknoglobals
+var argoCDApplicationsGVR = schema.GroupVersionResource{
+	Group:    "argoproj.io",
+	Version:  "v1alpha1",
+	Resource: "applications",
+}
+
+// argoCDOptions contains the options for checking the resources managed by Argo CD Applications.
+type argoCDOptions struct {
+	Enabled   bool
+	Namespace string
+	Selector  string
+
+	// server is the API server URL of the current context, whose Applications are checked along with the in-cluster
+	// ones.
+	server string
+	// contextName is the name of the current context, matched against the destination name of the Applications.
+	contextName string
+}
+
+// newArgoCDOptions returns a new [argoCDOptions] with default values.
+func newArgoCDOptions() argoCDOptions {
+	return argoCDOptions{
+		Namespace: defaultArgoCDNamespace,
+	}
+}
+
+// addFlags adds the flags for checking Argo CD Applications.
+func (o *argoCDOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Enabled, "argocd", o.Enabled,
+		"Check the resources managed by the Argo CD Applications, with the API versions listed in the "+
+			"status.resources of each Application as of its last reconciliation, rather than by re-rendering its "+
+			"target manifests.")
+	flags.StringVar(&o.Namespace, "argocd-namespace", o.Namespace,
+		"Namespace of the Argo CD Applications to check.")
+	flags.StringVar(&o.Selector, "argocd-selector", o.Selector,
+		"Label selector of the Argo CD Applications to check.")
+}
+
+// complete completes the API server URL and the name of the current context, to which the destinations of the
+// Applications are compared.
+func (o *argoCDOptions) complete(restClientGetter genericclioptions.RESTClientGetter) error {
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.server = restConfig.Host
+
+	rawConfig, err := restClientGetter.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't load kubeconfig: %w", err)
+	}
+
+	o.contextName = rawConfig.CurrentContext
+
+	if configFlags := configFlagsOf(restClientGetter); configFlags != nil && configFlags.Context != nil &&
+		*configFlags.Context != "" {
+		o.contextName = *configFlags.Context
+	}
+
+	return nil
+}
+
+// argoCDDestination is the destination cluster of an Argo CD Application, by server URL or by name.
+type argoCDDestination struct {
+	Server string `json:"server"`
+	Name   string `json:"name"`
+}
+
+// String returns the server of the destination, or its name if it has no server.
+func (d argoCDDestination) String() string {
+	if d.Server != "" {
+		return d.Server
+	}
+
+	return d.Name
+}
+
+// targets returns true if the destination is the cluster of Argo CD itself, or the cluster of the current context.
+func (d argoCDDestination) targets(options argoCDOptions) bool {
+	if d.Server != "" {
+		server := strings.TrimSuffix(d.Server, "/")
+
+		return server == argoCDInClusterServer ||
+			(options.server != "" && server == strings.TrimSuffix(options.server, "/"))
+	}
+
+	return d.Name == argoCDInClusterName || (options.contextName != "" && d.Name == options.contextName)
+}
+
+// argoCDResourceStatus is the subset of an Argo CD Application resource status used to check API versions.
+type argoCDResourceStatus struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// forEachArgoCDResource calls fn for each resource managed by the selected Argo CD Applications.
+// The location of each resource is "argocd:<namespace>/<application>", with the index of the resource in the
+// status of the Application as the document index.
+// The Applications deployed to another cluster than the one of Argo CD or of the current context are skipped, as
+// their resources aren't served by this API server, and reported to errOut.
+func forEachArgoCDResource(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	options argoCDOptions,
+	errOut io.Writer,
+	fn func(manifestLocation, *unstructured.Unstructured),
+) error {
+	applications, err := dynamicClient.Resource(argoCDApplicationsGVR).Namespace(options.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: options.Selector})
+	if err != nil {
+		return fmt.Errorf("couldn't list Argo CD Applications in namespace %s: %w", options.Namespace, err)
+	}
+
+	for _, application := range applications.Items {
+		destinationMap, _, err := unstructured.NestedMap(application.Object, "spec", "destination")
+		if err != nil {
+			return fmt.Errorf("couldn't read the destination of Argo CD Application %s: %w", application.GetName(), err)
+		}
+
+		destination := argoCDDestination{}
+
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(destinationMap, &destination)
+		if err != nil {
+			return fmt.Errorf("couldn't read the destination of Argo CD Application %s: %w", application.GetName(), err)
+		}
+
+		if !destination.targets(options) {
+			_, _ = fmt.Fprintf(errOut, "Skipped Argo CD Application %s/%s: its destination %q isn't the cluster of "+
+				"the current context\n", application.GetNamespace(), application.GetName(), destination.String())
+
+			continue
+		}
+
+		resources, _, err := unstructured.NestedSlice(application.Object, "status", "resources")
+		if err != nil {
+			return fmt.Errorf("couldn't read the resources of Argo CD Application %s: %w", application.GetName(), err)
+		}
+
+		filename := fmt.Sprintf("argocd:%s/%s", application.GetNamespace(), application.GetName())
+
+		for i, resource := range resources {
+			status := argoCDResourceStatus{}
+
+			resourceMap, _ := resource.(map[string]any)
+
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(resourceMap, &status)
+			if err != nil {
+				return fmt.Errorf("couldn't read resource %d of Argo CD Application %s: %w", i, application.GetName(), err)
+			}
+
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion(schema.GroupVersion{Group: status.Group, Version: status.Version}.String())
+			obj.SetKind(status.Kind)
+			obj.SetNamespace(status.Namespace)
+			obj.SetName(status.Name)
+
+			fn(manifestLocation{Filename: filename, Document: i, Item: -1}, obj)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// newArgoCDApplication returns a new Argo CD Application deployed to the cluster of Argo CD, managing the given
+// resources.
+func newArgoCDApplication(namespace, name string, labels map[string]string, resources ...any) *unstructured.Unstructured {
+	application := newUnstructured("argoproj.io/v1alpha1", "Application", namespace, name)
+	application.SetLabels(labels)
+	setArgoCDDestination(application, "server", argoCDInClusterServer)
+
+	err := unstructured.SetNestedSlice(application.Object, resources, "status", "resources")
+	if err != nil {
+		panic(err)
+	}
+
+	return application
+}
+
+// setArgoCDDestination sets the destination server or name of the Argo CD Application, replacing the previous one.
+func setArgoCDDestination(application *unstructured.Unstructured, field, value string) {
+	err := unstructured.SetNestedStringMap(application.Object, map[string]string{field: value}, "spec", "destination")
+	if err != nil {
+		panic(err)
+	}
+}
+
+// newArgoCDDynamicClient returns a fake dynamic client serving the Argo CD Applications.
+func newArgoCDDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{argoCDApplicationsGVR: "ApplicationList"},
+		objects...,
+	)
+}
+
+// TestRunCheckArgoCD tests checking the API versions of the resources managed by Argo CD Applications.
+func TestRunCheckArgoCD(t *testing.T) {
+	t.Parallel()
+
+	objects := []runtime.Object{
+		newArgoCDApplication("argocd", "web", map[string]string{"team": "web"},
+			map[string]any{"version": "v1", "kind": "Pod", "namespace": "default", "name": "web"},
+			map[string]any{
+				"group": "autoscaling", "version": "v2beta2", "kind": "HorizontalPodAutoscaler",
+				"namespace": "default", "name": "web",
+			},
+		),
+		newArgoCDApplication("argocd", "infra", map[string]string{"team": "infra"},
+			map[string]any{"version": "v1", "kind": "Namespace", "name": "infra"},
+		),
+		newArgoCDApplication("other", "ignored", nil,
+			map[string]any{"group": "apps", "version": "v1", "kind": "Deployment", "name": "ignored"},
+		),
+	}
+
+	t.Run("All", runCheckArgoCDTest{
+		objects: objects,
+		failOn:  failOnNone,
+		//nolint:lll
+		want: "LOCATION                 APIVERSION            KIND                      NAME          STATUS       PREFERRED\n" +
+			"argocd:argocd/infra[0]   v1                    Namespace                 infra         preferred    v1\n" +
+			"argocd:argocd/web[0]     v1                    Pod                       default/web   preferred    v1\n" +
+			"argocd:argocd/web[1]     autoscaling/v2beta2   HorizontalPodAutoscaler   default/web   deprecated   autoscaling/v2\n",
+	}.Test)
+	t.Run("Selector", runCheckArgoCDTest{
+		objects:  objects,
+		selector: "team=web",
+		failOn:   string(manifestStatusDeprecated),
+		//nolint:lll
+		want: "LOCATION               APIVERSION            KIND                      NAME          STATUS       PREFERRED\n" +
+			"argocd:argocd/web[0]   v1                    Pod                       default/web   preferred    v1\n" +
+			"argocd:argocd/web[1]   autoscaling/v2beta2   HorizontalPodAutoscaler   default/web   deprecated   autoscaling/v2\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	// autoscaling/v2beta2 is removed in 1.26, the release following the cluster.
+	t.Run("RemovedSoon", runCheckArgoCDTest{
+		objects:       objects,
+		selector:      "team=web",
+		serverVersion: "v1.25.3",
+		failOn:        string(manifestStatusRemovedSoon),
+		//nolint:lll
+		want: "LOCATION               APIVERSION            KIND                      NAME          STATUS         PREFERRED\n" +
+			"argocd:argocd/web[0]   v1                    Pod                       default/web   preferred      v1\n" +
+			"argocd:argocd/web[1]   autoscaling/v2beta2   HorizontalPodAutoscaler   default/web   removed-soon   autoscaling/v2\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	t.Run("Namespace", runCheckArgoCDTest{
+		objects:   objects,
+		namespace: "other",
+		failOn:    string(manifestStatusAbsent),
+		want: "LOCATION                  APIVERSION   KIND         NAME      STATUS   PREFERRED\n" +
+			"argocd:other/ignored[0]   apps/v1      Deployment   ignored   absent   \n",
+		wantErr: errCheckFailed,
+	}.Test)
+}
+
+// TestRunCheckArgoCDDestination tests that only the Applications deployed to the cluster of Argo CD or of the current
+// context are checked, and that the others are reported as skipped.
+func TestRunCheckArgoCDDestination(t *testing.T) {
+	t.Parallel()
+
+	resource := map[string]any{"version": "v1", "kind": "Namespace", "name": "web"}
+
+	inClusterName := newArgoCDApplication("argocd", "in-cluster-name", nil, resource)
+	setArgoCDDestination(inClusterName, "name", argoCDInClusterName)
+
+	server := newArgoCDApplication("argocd", "server", nil, resource)
+	setArgoCDDestination(server, "server", "https://api.example.com:6443/")
+
+	contextName := newArgoCDApplication("argocd", "context", nil, resource)
+	setArgoCDDestination(contextName, "name", "production")
+
+	otherServer := newArgoCDApplication("argocd", "other-server", nil, resource)
+	setArgoCDDestination(otherServer, "server", "https://other.example.com")
+
+	otherName := newArgoCDApplication("argocd", "other-name", nil, resource)
+	setArgoCDDestination(otherName, "name", "staging")
+
+	options, _, stdout := newCheckTestOptions(failOnNone)
+	options.dynamicClient = newArgoCDDynamicClient(inClusterName, server, contextName, otherServer, otherName)
+	options.ArgoCD.Enabled = true
+	options.ArgoCD.server = "https://api.example.com:6443"
+	options.ArgoCD.contextName = "production"
+
+	stderr := &bytes.Buffer{}
+	options.ErrOut = stderr
+
+	err := runCheck(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runCheck() error = %v", err)
+	}
+
+	//nolint:lll
+	want := "LOCATION                           APIVERSION   KIND        NAME   STATUS      PREFERRED\n" +
+		"argocd:argocd/context[0]           v1           Namespace   web    preferred   v1\n" +
+		"argocd:argocd/in-cluster-name[0]   v1           Namespace   web    preferred   v1\n" +
+		"argocd:argocd/server[0]            v1           Namespace   web    preferred   v1\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runCheck() output = %q, want %q", got, want)
+	}
+
+	wantErrOut := "Skipped Argo CD Application argocd/other-name: its destination \"staging\" isn't the cluster of the " +
+		"current context\n" +
+		"Skipped Argo CD Application argocd/other-server: its destination \"https://other.example.com\" isn't the " +
+		"cluster of the current context\n"
+	if got := stderr.String(); got != wantErrOut {
+		t.Errorf("runCheck() error output = %q, want %q", got, wantErrOut)
+	}
+}
+
+type runCheckArgoCDTest struct {
+	objects       []runtime.Object
+	namespace     string
+	selector      string
+	serverVersion string
+	failOn        string
+	want          string
+	wantErr       error
+}
+
+func (tt runCheckArgoCDTest) Test(t *testing.T) {
+	t.Parallel()
+
+	options, _, stdout := newCheckTestOptions(tt.failOn)
+	options.dynamicClient = newArgoCDDynamicClient(tt.objects...)
+	options.ArgoCD.Enabled = true
+	options.ArgoCD.Selector = tt.selector
+
+	if tt.serverVersion != "" {
+		discoveryClient := discoverytesting.New()
+		discoveryClient.DiscoveryInterface.(*fake.FakeDiscovery).FakedServerVersion = &version.Info{
+			GitVersion: tt.serverVersion,
+		}
+		options.discoveryClient = discoveryClient
+	}
+
+	if tt.namespace != "" {
+		options.ArgoCD.Namespace = tt.namespace
+	}
+
+	err := options.validate()
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	err = runCheck(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runCheck() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runCheck() output = %q, want %q", got, tt.want)
+	}
+}
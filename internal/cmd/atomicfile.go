@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// outputFilePermissions are the permissions of the files written with --output-file.
+const outputFilePermissions = 0o644
+
+// errOutputFileWatch is returned when --output-file is requested with --watch, which never finishes writing the
+// output.
+const errOutputFileWatch = constError(
+	"output-file is not supported with watch, which never finishes writing the output")
+
+// writeFileAtomically writes the file with write, through a temporary file in the same directory which is renamed to
+// the file once it has been completely written.
+// Readers of the file never see a partially written file, even if the command fails or is interrupted, in which case
+// the previous content of the file, if any, is kept.
+func writeFileAtomically(filename string, perm os.FileMode, write func(io.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("couldn't create temporary file for %s: %w", filename, err)
+	}
+
+	err = writeTemporaryFile(file, perm, write)
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+		if err != nil {
+			err = fmt.Errorf("couldn't rename temporary file to %s: %w", filename, err)
+		}
+	}
+
+	if err != nil {
+		return errors.Join(err, removeTemporaryFile(file.Name()))
+	}
+
+	return nil
+}
+
+// writeTemporaryFile writes the temporary file with write, then sets its permissions and flushes it to the disk,
+// see [writeFileAtomically].
+func writeTemporaryFile(file *os.File, perm os.FileMode, write func(io.Writer) error) error {
+	defer file.Close()
+
+	err := write(file)
+	if err != nil {
+		return err
+	}
+
+	err = file.Chmod(perm)
+	if err != nil {
+		return fmt.Errorf("couldn't set the permissions of %s: %w", file.Name(), err)
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return fmt.Errorf("couldn't flush %s: %w", file.Name(), err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't write %s: %w", file.Name(), err)
+	}
+
+	return nil
+}
+
+// removeTemporaryFile removes the temporary file of a failed write, see [writeFileAtomically].
+func removeTemporaryFile(name string) error {
+	err := os.Remove(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("couldn't remove temporary file %s: %w", name, err)
+	}
+
+	return nil
+}
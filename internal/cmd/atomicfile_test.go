@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteFileAtomically tests that the file is replaced once completely written, with the given permissions.
+func TestWriteFileAtomically(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "resources.txt")
+
+	err := os.WriteFile(filename, []byte("previous\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeFileAtomically(filename, outputFilePermissions, func(out io.Writer) error {
+		_, err := io.WriteString(out, "current\n")
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("writeFileAtomically() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(content), "current\n"; got != want {
+		t.Errorf("writeFileAtomically() content = %q, want %q", got, want)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := info.Mode().Perm(); got != outputFilePermissions {
+		t.Errorf("writeFileAtomically() permissions = %v, want %v", got, os.FileMode(outputFilePermissions))
+	}
+
+	assertNoTemporaryFiles(t, filepath.Dir(filename))
+}
+
+// TestWriteFileAtomicallyError tests that the previous content of the file is kept when the write fails.
+func TestWriteFileAtomicallyError(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "resources.txt")
+
+	err := os.WriteFile(filename, []byte("previous\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errWrite := errors.New("write failed")
+
+	err = writeFileAtomically(filename, outputFilePermissions, func(out io.Writer) error {
+		_, _ = io.WriteString(out, "partial")
+
+		return errWrite
+	})
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("writeFileAtomically() error = %v, want %v", err, errWrite)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(content), "previous\n"; got != want {
+		t.Errorf("writeFileAtomically() content = %q, want %q", got, want)
+	}
+
+	assertNoTemporaryFiles(t, filepath.Dir(filename))
+}
+
+// TestRunOutputFile tests writing the output of the command to the --output-file instead of stdout.
+func TestRunOutputFile(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "resources.txt")
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetPreferred(true).SetOutput(nameOutput).
+		SetOutputFile(filename)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersionsOutput(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersionsOutput() error = %v", err)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("runAPIResourceVersionsOutput() stdout = %q, want none", stdout.String())
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(content), "horizontalpodautoscalers.v2.autoscaling\n"; got != want {
+		t.Errorf("runAPIResourceVersionsOutput() file content = %q, want %q", got, want)
+	}
+}
+
+// assertNoTemporaryFiles checks that the directory contains no temporary file left by [writeFileAtomically].
+func assertNoTemporaryFiles(t *testing.T, directory string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(directory, ".*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
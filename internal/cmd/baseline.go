@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// baselineFilePermissions are the permissions of the baseline files written by --update-baseline.
+const baselineFilePermissions = 0o644
+
+// baselineFinding is an accepted finding recorded in a baseline file.
+// Findings are identified by their file, API version, kind, and name rather than by their document index, so that
+// baselines remain valid when documents are added to or removed from a file.
+type baselineFinding struct {
+	// Filename is the file, or other manifest source, of the finding.
+	Filename string `json:"filename"`
+	// APIVersion is the API version of the manifest.
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the manifest.
+	Kind string `json:"kind"`
+	// Name is the name of the manifest, prefixed by its namespace if it has one.
+	Name string `json:"name"`
+	// Status is the status of the manifest when the baseline was recorded.
+	Status manifestStatus `json:"status"`
+}
+
+// baseline is the content of a baseline file.
+type baseline struct {
+	// Findings are the accepted findings.
+	Findings []baselineFinding `json:"findings"`
+}
+
+// newBaselineFinding returns the baseline entry for the finding.
+func newBaselineFinding(finding manifestFinding) baselineFinding {
+	return baselineFinding{
+		Filename:   finding.Location.Filename,
+		APIVersion: finding.APIVersion,
+		Kind:       finding.Kind,
+		Name:       finding.Name,
+		Status:     finding.Status,
+	}
+}
+
+// suppress removes the findings accepted by the baseline, returning the remaining findings and the number of
+// suppressed findings.
+// A finding is accepted if the baseline has an entry for the same manifest with a status at least as severe, so
+// that a manifest getting worse, e.g. from deprecated to absent, is reported again.
+func (b *baseline) suppress(findings []manifestFinding) ([]manifestFinding, int) {
+	accepted := make(map[baselineFinding]manifestStatus, len(b.Findings))
+
+	for _, finding := range b.Findings {
+		status := finding.Status
+		finding.Status = ""
+		accepted[finding] = status
+	}
+
+	remaining := make([]manifestFinding, 0, len(findings))
+
+	for _, finding := range findings {
+		key := newBaselineFinding(finding)
+		key.Status = ""
+
+		if status, ok := accepted[key]; ok && finding.Status.severity() <= status.severity() {
+			continue
+		}
+
+		remaining = append(remaining, finding)
+	}
+
+	return remaining, len(findings) - len(remaining)
+}
+
+// readBaseline reads the baseline file.
+func readBaseline(filename string) (*baseline, error) {
+	content, err := os.ReadFile(filename) //nolint:gosec // Reading the user-provided baseline is intended.
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read baseline %s: %w", filename, err)
+	}
+
+	b := &baseline{}
+
+	err = k8syaml.UnmarshalStrict(content, b)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse baseline %s: %w", filename, err)
+	}
+
+	return b, nil
+}
+
+// writeBaseline writes every finding which isn't preferred to the baseline file.
+func writeBaseline(filename string, findings []manifestFinding) error {
+	b := &baseline{Findings: make([]baselineFinding, 0, len(findings))}
+
+	for _, finding := range findings {
+		if finding.Status != manifestStatusPreferred {
+			b.Findings = append(b.Findings, newBaselineFinding(finding))
+		}
+	}
+
+	content, err := k8syaml.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("couldn't encode baseline: %w", err)
+	}
+
+	//nolint:gosec // The baseline is meant to be committed alongside the manifests.
+	err = os.WriteFile(filename, content, baselineFilePermissions)
+	if err != nil {
+		return fmt.Errorf("couldn't write baseline %s: %w", filename, err)
+	}
+
+	return nil
+}
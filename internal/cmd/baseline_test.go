@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestBaseline tests recording findings in a baseline and suppressing them in subsequent checks.
+func TestBaseline(t *testing.T) {
+	t.Parallel()
+
+	baselineFilename := filepath.Join(t.TempDir(), "baseline.yaml")
+
+	options, _, _ := newCheckTestOptions(string(manifestStatusDeprecated), "testdata/manifests/workloads.yaml")
+	options.Baseline = baselineFilename
+	options.UpdateBaseline = true
+
+	err := runCheck(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runCheck() with --update-baseline error = %v", err)
+	}
+
+	b, err := readBaseline(baselineFilename)
+	if err != nil {
+		t.Fatalf("readBaseline() error = %v", err)
+	}
+
+	// The served, deprecated, and absent manifests are recorded, but not the preferred one.
+	if len(b.Findings) != 3 {
+		t.Errorf("readBaseline() findings = %v, want 3 findings", b.Findings)
+	}
+
+	options, _, stdout := newCheckTestOptions(string(manifestStatusDeprecated), "testdata/manifests/workloads.yaml")
+	options.Baseline = baselineFilename
+
+	err = runCheck(t.Context(), options)
+	if err != nil {
+		t.Errorf("runCheck() with --baseline error = %v, want nil", err)
+	}
+
+	want := "LOCATION                               APIVERSION   KIND   NAME          STATUS      PREFERRED\n" +
+		"testdata/manifests/workloads.yaml[0]   v1           Pod    default/web   preferred   v1\n"
+	if stdout.String() != want {
+		t.Errorf("runCheck() with --baseline output = %q, want %q", stdout.String(), want)
+	}
+}
+
+// TestBaselineSuppress tests that only findings at most as severe as their baseline are suppressed.
+func TestBaselineSuppress(t *testing.T) {
+	t.Parallel()
+
+	location := manifestLocation{Filename: "hpa.yaml", Item: -1}
+	deprecated := manifestFinding{
+		Location:   location,
+		APIVersion: "autoscaling/v2beta2",
+		Kind:       "HorizontalPodAutoscaler",
+		Name:       "default/web",
+		Status:     manifestStatusDeprecated,
+	}
+	absent := deprecated
+	absent.Status = manifestStatusAbsent
+	other := deprecated
+	other.Name = "default/worker"
+
+	b := &baseline{Findings: []baselineFinding{newBaselineFinding(deprecated)}}
+
+	remaining, suppressed := b.suppress([]manifestFinding{deprecated, absent, other})
+	if suppressed != 1 {
+		t.Errorf("suppress() suppressed = %d, want 1", suppressed)
+	}
+
+	if len(remaining) != 2 || remaining[0] != absent || remaining[1] != other {
+		t.Errorf("suppress() remaining = %v, want %v", remaining, []manifestFinding{absent, other})
+	}
+
+	err := checkFailures(remaining, string(manifestStatusAbsent))
+	if !errors.Is(err, errCheckFailed) {
+		t.Errorf("checkFailures() error = %v, want %v", err, errCheckFailed)
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// allCategory is the category of the resources listed by kubectl get all, always the first column of the category
+// matrix.
+const allCategory = "all"
+
+var (
+	// categoriesExample is the example text for the categories command.
+	//
+	//nolint:gochecknoglobals
+	categoriesExample = `
+		# Show the categories each resource belongs to
+		kubectl api-resource-versions categories
+
+		# Show the category matrix as a Markdown table
+		kubectl api-resource-versions categories --output=markdown`
+)
+
+// categoriesRow is a resource of the category matrix.
+type categoriesRow struct {
+	// Name is the group resource, e.g. "horizontalpodautoscalers.autoscaling".
+	Name string `json:"name"`
+	// Categories are the categories the resource belongs to, sorted.
+	Categories []string `json:"categories"`
+
+	group    string
+	resource string
+}
+
+// newCmdCategories returns a command that shows the categories each resource belongs to.
+func newCmdCategories(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newCategoriesOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "categories",
+		Short: "Show the categories each resource belongs to",
+		Long: "Show a matrix with a row for each resource and a column for each category, which tells whether the " +
+			"resource belongs to the category, making visible which resources are excluded from kubectl get all and " +
+			"which custom categories are defined, e.g. by the CustomResourceDefinitions of operators.\n" +
+			"The categories of a resource are the ones of its first version in the order of priority, usually its " +
+			"preferred version. The " + allCategory + " category is always the first column. Subresources are not " +
+			"included.",
+		Example: templates.Examples(categoriesExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runCategories(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+
+	return cmd
+}
+
+// categoriesOptions contains the options for the categories command.
+type categoriesOptions struct {
+	genericiooptions.IOStreams
+
+	Output    string
+	NoHeaders bool
+
+	contextName     string
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newCategoriesOptions returns a new [categoriesOptions] with default values.
+func newCategoriesOptions(ioStreams genericiooptions.IOStreams) *categoriesOptions {
+	return &categoriesOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the categories command.
+func (o *categoriesOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+	o.contextName = contextName(restClientGetter)
+
+	return nil
+}
+
+// validate checks that options are valid for the categories command.
+func (o *categoriesOptions) validate() error {
+	return validateReportOutput(o.Output)
+}
+
+// runCategories discovers the API resources and prints the category matrix.
+func runCategories(ctx context.Context, options *categoriesOptions) error {
+	snap, err := newSnapshot(ctx, options.discoveryClient, options.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	snap.Context = options.contextName
+
+	rows := newCategoriesRows(snap)
+
+	switch options.Output {
+	case jsonOutput:
+		return printCategoriesJSON(options.Out, newSnapshotsMetadata(snap), rows)
+	case markdownOutput:
+		return printCategoriesMarkdown(options.Out, rows)
+	default:
+		return printCategoriesTable(options.Out, rows, options.NoHeaders)
+	}
+}
+
+// newCategoriesRows returns a row for each resource of the snapshot with the categories of its first version in the
+// order of priority, sorted by group and resource.
+func newCategoriesRows(snap *snapshot) []categoriesRow {
+	var rows []categoriesRow
+
+	for _, group := range snap.Groups {
+		seen := sets.New[string]()
+
+		// The versions of the groups are already in the order of priority.
+		for _, groupVersionResources := range group.Versions {
+			for _, resource := range groupVersionResources.Resources {
+				if _, subName := splitResourceName(resource.Name); subName != nil || seen.Has(resource.Name) {
+					continue
+				}
+
+				seen.Insert(resource.Name)
+
+				categories := slices.Clone(resource.Categories)
+				slices.Sort(categories)
+
+				rows = append(rows, categoriesRow{
+					Name:       schema.GroupResource{Group: group.Name, Resource: resource.Name}.String(),
+					Categories: categories,
+					group:      group.Name,
+					resource:   resource.Name,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b categoriesRow) int {
+		return cmp.Or(cmp.Compare(a.group, b.group), cmp.Compare(a.resource, b.resource))
+	})
+
+	return rows
+}
+
+// matrixCategories returns the columns of the category matrix: the all category, then the other categories of the
+// rows, sorted.
+func matrixCategories(rows []categoriesRow) []string {
+	categories := sets.New[string]()
+	for _, row := range rows {
+		categories.Insert(row.Categories...)
+	}
+
+	categories.Delete(allCategory)
+
+	return append([]string{allCategory}, sets.List(categories)...)
+}
+
+// categoriesColumns returns the columns of the row in the table and Markdown output formats.
+func categoriesColumns(row categoriesRow, categories []string) []string {
+	columns := []string{row.Name}
+
+	for _, category := range categories {
+		cell := verbUnsupported
+		if slices.Contains(row.Categories, category) {
+			cell = verbSupported
+		}
+
+		columns = append(columns, cell)
+	}
+
+	return columns
+}
+
+// printCategoriesTable prints the category matrix as a table.
+func printCategoriesTable(out io.Writer, rows []categoriesRow, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	categories := matrixCategories(rows)
+
+	if !noHeaders {
+		headers := []string{"RESOURCE"}
+		for _, category := range categories {
+			headers = append(headers, strings.ToUpper(category))
+		}
+
+		err := printRow(writer, headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		err := printRow(writer, categoriesColumns(row, categories))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printCategoriesMarkdown prints the category matrix as a Markdown table.
+func printCategoriesMarkdown(out io.Writer, rows []categoriesRow) error {
+	categories := matrixCategories(rows)
+
+	lines := []string{
+		"| Resource | " + strings.Join(categories, " | ") + " |",
+		strings.Repeat("| --- ", len(categories)+1) + "|",
+	}
+	for _, row := range rows {
+		lines = append(lines, "| "+strings.Join(categoriesColumns(row, categories), " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("couldn't write category matrix: %w", err)
+	}
+
+	return nil
+}
+
+// printCategoriesJSON prints the category matrix as a JSON object with the "metadata", the "categories" of the
+// columns, and the "resources" list.
+func printCategoriesJSON(out io.Writer, metadata outputMetadata, rows []categoriesRow) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata   outputMetadata  `json:"metadata"`
+		Categories []string        `json:"categories"`
+		Resources  []categoriesRow `json:"resources"`
+	}{Metadata: metadata, Categories: matrixCategories(rows), Resources: rows})
+	if err != nil {
+		return fmt.Errorf("couldn't write category matrix: %w", err)
+	}
+
+	return nil
+}
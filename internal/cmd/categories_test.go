@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunCategories tests showing the categories each resource belongs to.
+func TestRunCategories(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newCategoriesOptions(ioStreams)
+	options.Output = markdownOutput
+	options.discoveryClient = discoverytesting.New()
+
+	err := runCategories(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runCategories() error = %v", err)
+	}
+
+	want := "| Resource | all |\n| --- | --- |\n"
+	if got := stdout.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("runCategories() output = %q, want it to start with %q", got, want)
+	}
+}
+
+// TestNewCategoriesRows tests the rows and the columns of the category matrix.
+func TestNewCategoriesRows(t *testing.T) {
+	t.Parallel()
+
+	snap := &snapshot{Groups: []snapshotGroup{
+		{
+			Name: "stable.example.com",
+			Versions: []snapshotVersion{
+				{Version: "v1", Resources: []snapshotResource{
+					{Name: "widgets", Categories: []string{"example", "all"}},
+					{Name: "widgets/status"},
+				}},
+				{Version: "v1beta1", Resources: []snapshotResource{
+					{Name: "widgets"},
+					{Name: "gadgets", Categories: []string{"example"}},
+				}},
+			},
+		},
+		{
+			Name: "",
+			Versions: []snapshotVersion{
+				{Version: "v1", Resources: []snapshotResource{
+					{Name: "pods", Categories: []string{"all"}},
+					{Name: "configmaps"},
+				}},
+			},
+		},
+	}}
+
+	rows := newCategoriesRows(snap)
+
+	var got [][]string
+	for _, row := range rows {
+		got = append(got, categoriesColumns(row, matrixCategories(rows)))
+	}
+
+	want := [][]string{
+		{"configmaps", "-", "-"},
+		{"pods", "✓", "-"},
+		{"gadgets.stable.example.com", "-", "✓"},
+		{"widgets.stable.example.com", "✓", "✓"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("categoriesColumns() = %v, want %v", got, want)
+	}
+
+	if categories := matrixCategories(rows); !reflect.DeepEqual(categories, []string{"all", "example"}) {
+		t.Errorf("matrixCategories() = %v, want [all example]", categories)
+	}
+}
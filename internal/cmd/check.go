@@ -0,0 +1,711 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// stdinFilename is the filename used to read manifests from stdin.
+const stdinFilename = "-"
+
+// manifestStatus is the status of the API version of a manifest in the cluster.
+type manifestStatus string
+
+const (
+	// manifestStatusPreferred is used when the API version is the preferred version of the kind.
+	manifestStatusPreferred manifestStatus = "preferred"
+	// manifestStatusServed is used when the API version is served, but is not the preferred version of the kind.
+	manifestStatusServed manifestStatus = "served"
+	// manifestStatusDeprecated is used when the API version is served, but is deprecated.
+	manifestStatusDeprecated manifestStatus = "deprecated"
+	// manifestStatusRemovedSoon is used when the API version is served, but is removed in the next minor release of
+	// the cluster.
+	manifestStatusRemovedSoon manifestStatus = "removed-soon"
+	// manifestStatusRejected is used when the API version is served, but the API server rejected the manifest in a
+	// server-side dry-run, with --validate=server.
+	manifestStatusRejected manifestStatus = "rejected"
+	// manifestStatusAbsent is used when the API version is not served for the kind.
+	manifestStatusAbsent manifestStatus = "absent"
+
+	// failOnNone disables failing the check on any manifest status.
+	failOnNone = "none"
+
+	// validateNone disables the validation of the manifests by the API server.
+	validateNone = "none"
+	// validateServer validates the manifests with a server-side dry-run.
+	validateServer = "server"
+)
+
+// manifestStatuses are all the manifest statuses, ordered by increasing severity.
+//
+//nolint:gochecknoglobals
+var manifestStatuses = []manifestStatus{
+	manifestStatusPreferred,
+	manifestStatusServed,
+	manifestStatusDeprecated,
+	manifestStatusRemovedSoon,
+	manifestStatusRejected,
+	manifestStatusAbsent,
+}
+
+// severity returns the severity of the status, higher is worse.
+func (s manifestStatus) severity() int {
+	return slices.Index(manifestStatuses, s)
+}
+
+// deprecated returns true if the API version is served but deprecated, including when it is removed soon.
+func (s manifestStatus) deprecated() bool {
+	return s == manifestStatusDeprecated || s == manifestStatusRemovedSoon
+}
+
+var (
+	// checkExample is the example text for the check command.
+	//
+	//nolint:gochecknoglobals
+	checkExample = `
+		# Check the API versions of all the manifests in a directory
+		kubectl api-resource-versions check -f manifests/
+
+		# Check the API versions of manifests read from stdin, failing only if an API version isn't served
+		helm template my-chart | kubectl api-resource-versions check -f - --fail-on=absent
+
+		# Check the API versions of all the manifests in a repository, except for the tests
+		kubectl api-resource-versions check -R -f . --exclude=test
+
+		# Check that the API server accepts the manifests, not only their API versions
+		kubectl api-resource-versions check -f manifests/ --validate=server
+
+		# Record the current findings as accepted, then fail only on new findings
+		kubectl api-resource-versions check -R -f . --baseline=baseline.yaml --update-baseline
+		kubectl api-resource-versions check -R -f . --baseline=baseline.yaml
+
+		# Check the API versions of a Helm chart before upgrading a release
+		kubectl api-resource-versions check --helm-chart=./charts/web --helm-values=values-prod.yaml
+
+		# Check the API versions of a deployed Helm release and a kustomization
+		kubectl api-resource-versions check --helm-release=web --kustomize=overlays/prod
+
+		# Check the API versions of the resources managed by the Argo CD Applications of a team
+		kubectl api-resource-versions check --argocd --argocd-selector=team=web`
+)
+
+// newCmdCheck returns a command that checks the API versions used by manifests against the cluster.
+func newCmdCheck(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newCheckOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "check -f FILENAME",
+		Short: "Check the API versions used by manifests",
+		Long: "Check whether the API version of each manifest is preferred, served, deprecated, removed-soon (removed " +
+			"in the next minor release of the cluster), or absent in the cluster.\n" +
+			"Manifests are read from YAML or JSON files, from every YAML or JSON file in a directory, or from stdin.\n" +
+			"Files and directories matching the patterns in " + manifestIgnoreFilename + " files are skipped when " +
+			"walking directories.\n" +
+			"Manifests can also be rendered from Helm charts with 'helm template', read from deployed Helm releases " +
+			"with 'helm get manifest', or built from kustomizations.\n" +
+			"With --argocd, the resources managed by Argo CD Applications are checked as well, with the API versions " +
+			"listed in the status.resources of each Application rather than re-rendered from its source; the " +
+			"Applications deployed to other clusters than the current context's are skipped.\n" +
+			"With --validate=server, each manifest whose API version is served is also submitted to the API server in " +
+			"a server-side dry-run create with strict field validation, and is " + string(manifestStatusRejected) +
+			" if the server rejects it, e.g. because of a field which doesn't exist or isn't valid in its version.\n" +
+			"The command fails if any manifest has a status at least as severe as --fail-on.",
+		Example: templates.Examples(checkExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runCheck(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames,
+		"Filename, directory, or - for stdin, of the manifests to check.")
+	cmd.Flags().StringVar(&options.FailOn, "fail-on", options.FailOn,
+		"Fail if any manifest has this status or a more severe one. One of: ("+failOnValues()+").")
+	cmd.Flags().StringVar(&options.Validate, "validate", options.Validate,
+		"Validate the manifests with the API server. One of: ("+validateNone+", "+validateServer+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+	cmd.Flags().StringVar(&options.Baseline, "baseline", options.Baseline,
+		"Baseline file of accepted findings, which are not printed and don't cause the check to fail.")
+	cmd.Flags().BoolVar(&options.UpdateBaseline, "update-baseline", options.UpdateBaseline,
+		"Write every finding which isn't "+string(manifestStatusPreferred)+" to the --baseline file instead of "+
+			"checking against it.")
+	options.Walk.addFlags(cmd.Flags())
+	options.Decode.addFlags(cmd.Flags())
+	options.Sources.addFlags(cmd.Flags())
+	options.ArgoCD.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// failOnValues returns the supported values of the --fail-on flag, separated by commas.
+func failOnValues() string {
+	values := []string{failOnNone}
+	for _, status := range manifestStatuses[1:] {
+		values = append(values, string(status))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// checkOptions contains the options for the check command.
+type checkOptions struct {
+	genericiooptions.IOStreams
+
+	Filenames      []string
+	FailOn         string
+	Validate       string
+	NoHeaders      bool
+	Baseline       string
+	UpdateBaseline bool
+	Walk           manifestWalkOptions
+	Decode         manifestDecodeOptions
+	Sources        manifestSourceOptions
+	ArgoCD         argoCDOptions
+
+	// namespace is the namespace in which the manifests without a namespace are validated, with --validate=server.
+	namespace       string
+	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newCheckOptions returns a new [checkOptions] with default values.
+func newCheckOptions(ioStreams genericiooptions.IOStreams) *checkOptions {
+	return &checkOptions{
+		IOStreams: ioStreams,
+		FailOn:    string(manifestStatusDeprecated),
+		Validate:  validateNone,
+		Decode:    newManifestDecodeOptions(),
+		Sources:   newManifestSourceOptions(),
+		ArgoCD:    newArgoCDOptions(),
+	}
+}
+
+// complete completes all the required options for the check command.
+func (o *checkOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	if o.Validate == validateServer {
+		o.namespace, _, err = restClientGetter.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return fmt.Errorf("couldn't get namespace: %w", err)
+		}
+	}
+
+	if o.ArgoCD.Enabled || o.Validate == validateServer {
+		restConfig, err := restClientGetter.ToRESTConfig()
+		if err != nil {
+			return fmt.Errorf("couldn't get REST config: %w", err)
+		}
+
+		o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("couldn't create dynamic client: %w", err)
+		}
+	}
+
+	if o.ArgoCD.Enabled {
+		err := o.ArgoCD.complete(restClientGetter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return o.Sources.complete(restClientGetter)
+}
+
+// errNoFilenames is returned when no manifests are given to the check command.
+const errNoFilenames = constError(
+	"at least one filename, Helm chart, Helm release, kustomization, or --argocd is required")
+
+// errFailOn is returned when the --fail-on value is not supported.
+const errFailOn = constError(
+	"fail-on must be one of: (" + failOnNone + ", served, deprecated, removed-soon, rejected, absent)")
+
+// errValidate is returned when the --validate value is not supported.
+const errValidate = constError("validate must be one of: (" + validateNone + ", " + validateServer + ")")
+
+// errUpdateBaseline is returned when --update-baseline is given without --baseline.
+const errUpdateBaseline = constError("update-baseline requires a baseline file")
+
+// validate checks that options are valid for the check command.
+func (o *checkOptions) validate() error {
+	if len(o.Filenames) == 0 && o.Sources.empty() && !o.ArgoCD.Enabled {
+		return errNoFilenames
+	}
+
+	if o.FailOn != failOnNone && manifestStatus(o.FailOn).severity() <= 0 {
+		return fmt.Errorf("%w: %s is not available", errFailOn, o.FailOn)
+	}
+
+	if o.Validate != validateNone && o.Validate != validateServer {
+		return fmt.Errorf("%w: %s is not available", errValidate, o.Validate)
+	}
+
+	if o.UpdateBaseline && o.Baseline == "" {
+		return errUpdateBaseline
+	}
+
+	err := o.Decode.validate()
+	if err != nil {
+		return err
+	}
+
+	return o.Walk.validate()
+}
+
+// manifestLocation is the location of a manifest in its source.
+type manifestLocation struct {
+	// Filename is the file from which the manifest was read, or "-" for stdin.
+	Filename string
+	// Document is the index of the YAML document, or of the JSON value, in the file.
+	Document int
+	// Item is the index of the manifest in the items of a list, or -1 if the manifest is not in a list.
+	Item int
+}
+
+// String returns the location in the format "<filename>[<document>]" or "<filename>[<document>].items[<item>]".
+func (l manifestLocation) String() string {
+	location := fmt.Sprintf("%s[%d]", l.Filename, l.Document)
+	if l.Item >= 0 {
+		location = fmt.Sprintf("%s.items[%d]", location, l.Item)
+	}
+
+	return location
+}
+
+// manifestFinding is the result of checking the API version of a single manifest.
+type manifestFinding struct {
+	// Location is where the manifest was read from.
+	Location manifestLocation
+	// APIVersion is the API version of the manifest.
+	APIVersion string
+	// Kind is the kind of the manifest.
+	Kind string
+	// Name is the name of the manifest, prefixed by its namespace if it has one.
+	Name string
+	// Status is the status of the API version of the manifest in the cluster.
+	Status manifestStatus
+	// PreferredVersion is the preferred group version for the kind in the cluster, or the replacement group version
+	// from the lifecycle database if the kind isn't served.
+	PreferredVersion string
+	// Rejection is the error of the API server which rejected the manifest, with --validate=server.
+	Rejection string
+}
+
+// errCheckFailed is returned when manifests have a status at least as severe as --fail-on.
+const errCheckFailed = constError("check failed")
+
+// runCheck checks the API versions of the manifests and prints the findings.
+func runCheck(ctx context.Context, options *checkOptions) error {
+	findings, err := collectManifestFindings(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	if options.Baseline != "" && options.UpdateBaseline {
+		return writeBaseline(options.Baseline, findings)
+	} else if options.Baseline != "" {
+		baseline, err := readBaseline(options.Baseline)
+		if err != nil {
+			return err
+		}
+
+		var suppressed int
+
+		findings, suppressed = baseline.suppress(findings)
+		if suppressed > 0 {
+			_, _ = fmt.Fprintf(options.ErrOut, "%d findings suppressed by the baseline %s\n", suppressed, options.Baseline)
+		}
+	}
+
+	err = printManifestFindings(findings, options)
+	if err != nil {
+		return err
+	}
+
+	return checkFailures(findings, options.FailOn)
+}
+
+// collectManifestFindings reads the manifests from every source and checks their API versions.
+func collectManifestFindings(ctx context.Context, options *checkOptions) ([]manifestFinding, error) {
+	kinds, err := getServedKinds(ctx, options.discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []manifestFinding
+
+	collect := func(location manifestLocation, obj *unstructured.Unstructured) {
+		finding := kinds.check(location, obj)
+		if options.Validate == validateServer {
+			finding = kinds.validate(ctx, options, finding, obj)
+		}
+
+		findings = append(findings, finding)
+	}
+
+	for _, filename := range options.Filenames {
+		err := forEachManifest(filename, options.In, &options.Walk, options.Decode.decodeOptions(), collect)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sources, err := options.Sources.render(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range sources {
+		err := forEachManifestInStream(source.Name, bytes.NewReader(source.Manifests), options.Decode.decodeOptions(),
+			collect)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.ArgoCD.Enabled {
+		// The resources of the Applications are only references to the objects, which can't be validated.
+		err := forEachArgoCDResource(ctx, options.dynamicClient, options.ArgoCD, options.ErrOut,
+			func(location manifestLocation, obj *unstructured.Unstructured) {
+				findings = append(findings, kinds.check(location, obj))
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return findings, nil
+}
+
+// checkFailures returns an error if any of the findings has a status at least as severe as failOn.
+func checkFailures(findings []manifestFinding, failOn string) error {
+	if failOn == failOnNone {
+		return nil
+	}
+
+	failOnStatus := manifestStatus(failOn)
+
+	var failed []manifestFinding
+
+	for _, finding := range findings {
+		if finding.Status.severity() >= failOnStatus.severity() {
+			failed = append(failed, finding)
+		}
+	}
+
+	if len(failed) > 0 {
+		return &checkFailedError{failOn: failOnStatus, findings: failed}
+	}
+
+	return nil
+}
+
+// servedKinds is an index of the kinds served by the cluster.
+type servedKinds struct {
+	// groupVersionKinds are the kinds served in each group version.
+	groupVersionKinds sets.Set[schema.GroupVersionKind]
+	// preferredVersions are the preferred group versions of each kind.
+	preferredVersions map[schema.GroupKind]string
+	// resources are the resources serving each kind in each group version, to validate the manifests.
+	resources map[schema.GroupVersionKind]kindResource
+	// serverVersion is the release of the cluster, or nil if it couldn't be determined.
+	serverVersion *utilversion.Version
+}
+
+// getServedKinds discovers the kinds served by the cluster.
+func getServedKinds(ctx context.Context, discoveryClient discovery.CachedDiscoveryInterface) (*servedKinds, error) {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = discoveryClient
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return newServedKinds(resources, discoveryClient), nil
+}
+
+// newServedKinds indexes the kinds of the discovered resources.
+func newServedKinds(resources []groupResource, discoveryClient discovery.DiscoveryInterface) *servedKinds {
+	kinds := &servedKinds{
+		groupVersionKinds: sets.New[schema.GroupVersionKind](),
+		preferredVersions: make(map[schema.GroupKind]string),
+		resources:         make(map[schema.GroupVersionKind]kindResource),
+	}
+
+	for _, resource := range resources {
+		gvk := resource.groupVersionResource().GroupVersion().WithKind(resource.APIResource.Kind)
+		kinds.groupVersionKinds.Insert(gvk)
+
+		if !resource.Subresource {
+			kinds.resources[gvk] = kindResource{
+				GroupVersionResource: resource.groupVersionResource(),
+				Namespaced:           resource.APIResource.Namespaced,
+			}
+		}
+
+		if resource.Preferred {
+			kinds.preferredVersions[gvk.GroupKind()] = resource.APIGroupVersion
+		}
+	}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err == nil {
+		kinds.serverVersion, _ = lifecycle.ParseRelease(serverVersion.GitVersion)
+	}
+
+	return kinds
+}
+
+// check returns the finding for the manifest.
+func (k *servedKinds) check(location manifestLocation, obj *unstructured.Unstructured) manifestFinding {
+	finding := manifestFinding{
+		Location:   location,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Status:     manifestStatusAbsent,
+	}
+
+	if obj.GetNamespace() != "" {
+		finding.Name = obj.GetNamespace() + "/" + obj.GetName()
+	}
+
+	gvk := obj.GroupVersionKind()
+	finding.PreferredVersion = k.preferredVersions[gvk.GroupKind()]
+
+	api, known := lifecycle.Lookup(gvk)
+	if finding.PreferredVersion == "" && known {
+		finding.PreferredVersion = api.Replacement
+	}
+
+	switch {
+	case !k.groupVersionKinds.Has(gvk):
+		finding.Status = manifestStatusAbsent
+	case known && k.serverVersion != nil && api.RemovedIn(k.serverVersion.WithMinor(k.serverVersion.Minor()+1)):
+		finding.Status = manifestStatusRemovedSoon
+	case known && api.DeprecatedIn(k.serverVersion):
+		finding.Status = manifestStatusDeprecated
+	case finding.PreferredVersion == finding.APIVersion:
+		finding.Status = manifestStatusPreferred
+	default:
+		finding.Status = manifestStatusServed
+	}
+
+	return finding
+}
+
+// validate submits the manifest to the API server in a server-side dry-run, and returns the finding with the
+// [manifestStatusRejected] status if the server rejects it.
+// The manifests whose API version isn't served aren't submitted, as they are already absent.
+func (k *servedKinds) validate(
+	ctx context.Context,
+	options *checkOptions,
+	finding manifestFinding,
+	obj *unstructured.Unstructured,
+) manifestFinding {
+	resource, ok := k.resources[obj.GroupVersionKind()]
+	if !ok || finding.Status == manifestStatusAbsent {
+		return finding
+	}
+
+	err := dryRunCreate(ctx, options.dynamicClient, resource, options.namespace, obj)
+	if err != nil {
+		_, _ = fmt.Fprintf(options.ErrOut, "Warning: %s: %v\n", finding.Location, err)
+
+		finding.Status = manifestStatusRejected
+		finding.Rejection = err.Error()
+	}
+
+	return finding
+}
+
+// forEachManifest calls fn for each manifest read from the filename, which may be a file, a directory, or "-" for
+// stdin.
+// Directories are walked according to the [manifestWalkOptions], and documents exceeding the limits of the
+// [yamlutil.DecodeOptions] are rejected.
+// Manifests without an API version or kind are skipped, and the items of lists are expanded.
+func forEachManifest(
+	filename string,
+	stdin io.Reader,
+	walkOptions *manifestWalkOptions,
+	decodeOptions yamlutil.DecodeOptions,
+	fn func(manifestLocation, *unstructured.Unstructured),
+) error {
+	if filename == stdinFilename {
+		return forEachManifestInStream(filename, stdin, decodeOptions, fn)
+	}
+
+	filenames, err := walkOptions.filenames(filename)
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range filenames {
+		err := forEachManifestInFile(filename, decodeOptions, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forEachManifestInFile calls fn for each manifest in the file, see [forEachManifest].
+func forEachManifestInFile(
+	filename string,
+	decodeOptions yamlutil.DecodeOptions,
+	fn func(manifestLocation, *unstructured.Unstructured),
+) error {
+	file, err := os.Open(filename) //nolint:gosec // Reading user-provided manifests is the purpose of the command.
+	if err != nil {
+		return fmt.Errorf("couldn't open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	return forEachManifestInStream(filename, file, decodeOptions, fn)
+}
+
+// forEachManifestInStream calls fn for each manifest in the stream of YAML or JSON documents, see [forEachManifest].
+func forEachManifestInStream(
+	filename string,
+	stream io.Reader,
+	decodeOptions yamlutil.DecodeOptions,
+	fn func(manifestLocation, *unstructured.Unstructured),
+) error {
+	document := 0
+
+	for result := range yamlutil.YAMLDocumentsToJSONWithOptions(stream, decodeOptions) {
+		location := manifestLocation{Filename: filename, Document: document, Item: -1}
+		document++
+
+		decoder, err := result.GetDecoder()
+		if err != nil {
+			return manifestDocumentError(location, err)
+		}
+
+		var content map[string]any
+
+		err = decoder.Decode(&content)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+
+			return fmt.Errorf("couldn't decode %s: %w", location, err)
+		}
+
+		if content == nil {
+			continue // Empty document.
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if !obj.IsList() {
+			if obj.GetAPIVersion() != "" && obj.GetKind() != "" {
+				fn(location, obj)
+			}
+
+			continue
+		}
+
+		location.Item = 0
+
+		err = obj.EachListItem(func(item runtime.Object) error {
+			itemObj, _ := item.(*unstructured.Unstructured)
+			if itemObj.GetAPIVersion() != "" && itemObj.GetKind() != "" {
+				fn(location, itemObj)
+			}
+
+			location.Item++
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't read the items of %s[%d]: %w", filename, location.Document, err)
+		}
+	}
+
+	return nil
+}
+
+// manifestDocumentError returns the error of the manifest document at the location, pointing at the line and column
+// at which it failed when they are known.
+func manifestDocumentError(location manifestLocation, err error) error {
+	var documentErr *yamlutil.DocumentError
+	if errors.As(err, &documentErr) && documentErr.Line > 0 {
+		return fmt.Errorf("couldn't read %s at %s: %w", location, documentErr.Position(), documentErr.Err)
+	}
+
+	return fmt.Errorf("couldn't read %s: %w", location, err)
+}
+
+// printManifestFindings prints the manifest findings as a table.
+func printManifestFindings(findings []manifestFinding, options *checkOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"LOCATION", "APIVERSION", "KIND", "NAME", "STATUS", "PREFERRED"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, finding := range findings {
+		err := printRow(writer, []string{
+			finding.Location.String(),
+			finding.APIVersion,
+			finding.Kind,
+			finding.Name,
+			string(finding.Status),
+			finding.PreferredVersion,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newCheckTestOptions returns [checkOptions] for the given filenames using the test discovery client.
+func newCheckTestOptions(failOn string, filenames ...string) (*checkOptions, *bytes.Buffer, *bytes.Buffer) {
+	ioStreams, stdin, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newCheckOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+	options.Filenames = filenames
+	options.FailOn = failOn
+
+	return options, stdin, stdout
+}
+
+// TestValidateCheckOptions tests validation of the check command options.
+func TestValidateCheckOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		failOn   string
+		validate string
+		files    []string
+		wantErr  error
+	}{
+		{name: "Default", failOn: string(manifestStatusDeprecated), files: []string{"-"}},
+		{name: "None", failOn: failOnNone, files: []string{"-"}},
+		{name: "NoFilenames", failOn: string(manifestStatusDeprecated), wantErr: errNoFilenames},
+		{name: "FailOnPreferred", failOn: string(manifestStatusPreferred), files: []string{"-"}, wantErr: errFailOn},
+		{name: "FailOnInvalid", failOn: "invalid", files: []string{"-"}, wantErr: errFailOn},
+		{name: "ValidateServer", failOn: failOnNone, validate: validateServer, files: []string{"-"}},
+		{name: "ValidateInvalid", failOn: failOnNone, validate: "client", files: []string{"-"}, wantErr: errValidate},
+	}
+
+	for _, tt := range tests {
+		options, _, _ := newCheckTestOptions(tt.failOn, tt.files...)
+		if tt.validate != "" {
+			options.Validate = tt.validate
+		}
+
+		err := options.validate()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestRunCheck tests checking the API versions of manifests.
+func TestRunCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Directory", runCheckTest{
+		failOn:    failOnNone,
+		filenames: []string{"testdata/manifests"},
+		//nolint:lll
+		want: "LOCATION                                   APIVERSION            KIND                      NAME               STATUS       PREFERRED\n" +
+			"testdata/manifests/list.json[0].items[0]   v1                    ConfigMap                 default/settings   preferred    v1\n" +
+			"testdata/manifests/list.json[0].items[1]   v1                    Namespace                 default            preferred    v1\n" +
+			"testdata/manifests/workloads.yaml[0]       v1                    Pod                       default/web        preferred    v1\n" +
+			"testdata/manifests/workloads.yaml[1]       autoscaling/v1        HorizontalPodAutoscaler   default/web        served       autoscaling/v2\n" +
+			"testdata/manifests/workloads.yaml[2]       autoscaling/v2beta2   HorizontalPodAutoscaler   default/worker     deprecated   autoscaling/v2\n" +
+			"testdata/manifests/workloads.yaml[3]       apps/v1               Deployment                default/web        absent       \n",
+	}.TestOutput)
+	t.Run("Statuses", runCheckTest{
+		failOn:    failOnNone,
+		filenames: []string{"testdata/manifests/workloads.yaml", "testdata/manifests/list.json"},
+		wantStatuses: []manifestStatus{
+			manifestStatusPreferred,
+			manifestStatusServed,
+			manifestStatusDeprecated,
+			manifestStatusAbsent,
+			manifestStatusPreferred,
+			manifestStatusPreferred,
+		},
+	}.TestStatuses)
+	t.Run("FailOnDeprecated", runCheckTest{
+		failOn:    string(manifestStatusDeprecated),
+		filenames: []string{"testdata/manifests/workloads.yaml"},
+		wantErr:   errCheckFailed,
+	}.TestStatuses)
+	t.Run("FailOnAbsentPasses", runCheckTest{
+		failOn:    string(manifestStatusAbsent),
+		filenames: []string{"-"},
+		stdin:     "apiVersion: autoscaling/v2beta2\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: web\n",
+		wantStatuses: []manifestStatus{
+			manifestStatusDeprecated,
+		},
+	}.TestStatuses)
+	t.Run("JSONStream", runCheckTest{
+		failOn:    failOnNone,
+		filenames: []string{"-"},
+		stdin: `{"apiVersion": "v1", "kind": "List", "items": [` +
+			`{"apiVersion": "autoscaling/v1", "kind": "HorizontalPodAutoscaler", "metadata": {"name": "web"}}]}` +
+			`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web"}}`,
+		wantStatuses: []manifestStatus{
+			manifestStatusServed,
+			manifestStatusPreferred,
+		},
+	}.TestStatuses)
+	t.Run("AliasExpansion", runCheckTest{
+		failOn:    failOnNone,
+		filenames: []string{"-"},
+		stdin: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: laughs\ndata:\n" +
+			"  a: &a [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n" +
+			"  b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]\n" +
+			"  c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]\n" +
+			"  d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]\n" +
+			"  e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]\n" +
+			"  f: [*e, *e, *e, *e, *e, *e, *e, *e, *e]\n",
+		wantErr: yamlutil.ErrAliasExpansion,
+	}.TestOutput)
+	t.Run("SyntaxError", runCheckTest{
+		failOn:    failOnNone,
+		filenames: []string{"-"},
+		stdin:     "apiVersion: v1\nkind: ConfigMap\n---\napiVersion: v1\nkind: ConfigMap\n  name: broken\n",
+		wantErr:   nil,
+		wantErrMessage: "couldn't read -[1] at line 6: failed to decode YAML document: mapping values are not allowed in " +
+			"this context",
+	}.TestOutput)
+}
+
+type runCheckTest struct {
+	failOn       string
+	filenames    []string
+	stdin        string
+	want         string
+	wantStatuses []manifestStatus
+	wantErr      error
+	// wantErrMessage is the message of the error, if any, when it isn't one of the sentinel errors.
+	wantErrMessage string
+}
+
+func (tt runCheckTest) run(t *testing.T) string {
+	t.Helper()
+
+	options, stdin, stdout := newCheckTestOptions(tt.failOn, tt.filenames...)
+	stdin.WriteString(tt.stdin)
+
+	err := runCheck(t.Context(), options)
+	if tt.wantErrMessage != "" {
+		if err == nil || err.Error() != tt.wantErrMessage {
+			t.Fatalf("runCheck() error = %v, want %q", err, tt.wantErrMessage)
+		}
+
+		return ""
+	}
+
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runCheck() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	return stdout.String()
+}
+
+func (tt runCheckTest) TestOutput(t *testing.T) {
+	t.Parallel()
+
+	got := tt.run(t)
+	if got != tt.want {
+		t.Errorf("runCheck() output = %q, want %q", got, tt.want)
+	}
+}
+
+func (tt runCheckTest) TestStatuses(t *testing.T) {
+	t.Parallel()
+
+	options, stdin, _ := newCheckTestOptions(tt.failOn, tt.filenames...)
+	stdin.WriteString(tt.stdin)
+
+	kinds, err := getServedKinds(t.Context(), options.discoveryClient)
+	if err != nil {
+		t.Fatalf("getServedKinds() error = %v", err)
+	}
+
+	var got []manifestStatus
+
+	for _, filename := range tt.filenames {
+		err := forEachManifest(filename, options.In, &options.Walk, options.Decode.decodeOptions(), func(location manifestLocation, obj *unstructured.Unstructured) {
+			got = append(got, kinds.check(location, obj).Status)
+		})
+		if err != nil {
+			t.Fatalf("forEachManifest() error = %v", err)
+		}
+	}
+
+	if tt.wantStatuses != nil && !reflect.DeepEqual(got, tt.wantStatuses) {
+		t.Errorf("check() statuses = %v, want %v", got, tt.wantStatuses)
+	}
+
+	if tt.wantErr != nil {
+		tt.run(t)
+	}
+}
+
+// TestRunCheckValidateServer tests validating the manifests whose API version is served with a server-side dry-run.
+func TestRunCheckValidateServer(t *testing.T) {
+	t.Parallel()
+
+	options, stdin, stdout := newCheckTestOptions(string(manifestStatusRejected), "-")
+	options.Validate = validateServer
+	options.namespace = "default"
+	stdin.WriteString("apiVersion: autoscaling/v1\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: web\n" +
+		"---\napiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: invalid\n" +
+		"---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+
+	errOut := &bytes.Buffer{}
+	options.ErrOut = errOut
+
+	dynamicClient := discoverytesting.NewDynamic()
+	dynamicClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, _ := action.(clienttesting.CreateAction).GetObject().(metav1.Object)
+		if obj.GetName() == "invalid" {
+			return true, nil, apierrors.NewBadRequest(`strict decoding error: unknown field "spec.foo"`)
+		}
+
+		return false, nil, nil
+	})
+	options.dynamicClient = dynamicClient
+
+	err := runCheck(t.Context(), options)
+
+	var checkFailed *checkFailedError
+	if !errors.As(err, &checkFailed) || len(checkFailed.findings) != 2 {
+		t.Fatalf("runCheck() error = %v, want the rejected and absent manifests", err)
+	}
+
+	if rejection := checkFailed.findings[0].Rejection; !strings.Contains(rejection, `unknown field "spec.foo"`) {
+		t.Errorf("runCheck() rejection = %q, want the error of the API server", rejection)
+	}
+
+	want := "LOCATION   APIVERSION       KIND                      NAME      STATUS     PREFERRED\n" +
+		"-[0]       autoscaling/v1   HorizontalPodAutoscaler   web       served     autoscaling/v2\n" +
+		"-[1]       autoscaling/v2   HorizontalPodAutoscaler   invalid   rejected   autoscaling/v2\n" +
+		"-[2]       apps/v1          Deployment                web       absent     \n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runCheck() output = %q, want %q", got, want)
+	}
+
+	wantErrOut := "Warning: -[1]: the API server rejected HorizontalPodAutoscaler invalid in autoscaling/v2: " +
+		"strict decoding error: unknown field \"spec.foo\"\n"
+	if got := errOut.String(); got != wantErrOut {
+		t.Errorf("runCheck() error output = %q, want %q", got, wantErrOut)
+	}
+
+	// The manifests whose API version isn't served aren't submitted.
+	creates := 0
+
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "create" {
+			creates++
+		}
+	}
+
+	if creates != 2 {
+		t.Errorf("runCheck() submitted %d manifests, want 2", creates)
+	}
+}
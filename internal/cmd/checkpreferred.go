@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// checkPreferredExample is the example text for the check-preferred command.
+	//
+	//nolint:gochecknoglobals
+	checkPreferredExample = `
+		# Check the preferred versions of every group against the APIServices and the CustomResourceDefinitions
+		kubectl api-resource-versions check-preferred
+
+		# Print only the inconsistencies
+		kubectl api-resource-versions check-preferred --inconsistent-only`
+)
+
+// Sources of the expected preferred versions of the check-preferred command.
+const (
+	// checkPreferredSourceAPIService is the source of the preferred version of a group implied by the version
+	// priorities of its APIServices.
+	checkPreferredSourceAPIService = "APIService"
+	// checkPreferredSourceCRD is the source of the storage version of a CustomResourceDefinition, compared with the
+	// preferred version of its resource.
+	checkPreferredSourceCRD = "CRD"
+)
+
+// newCmdCheckPreferred returns a command that verifies the preferred versions of the discovery against the APIServices
+// and the CustomResourceDefinitions.
+func newCmdCheckPreferred(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newCheckPreferredOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "check-preferred",
+		Short: "Verify the preferred versions against the APIServices and the CRDs",
+		Long: "Compare the preferred version of each group in the discovery with the version implied by its " +
+			"APIServices, the one with the highest versionPriority, ties being broken by the Kubernetes version " +
+			"ordering, e.g. v2 before v1 before v1beta1. Also compare the storage version of each " +
+			"CustomResourceDefinition with the preferred version of its resource.\n" +
+			"Mismatches usually indicate a misconfigured aggregated API server, or a CustomResourceDefinition whose " +
+			"storage version wasn't moved to a newly promoted version. The command fails if there are any.",
+		Example: templates.Examples(checkPreferredExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runCheckPreferred(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+	cmd.Flags().BoolVar(&options.InconsistentOnly, "inconsistent-only", options.InconsistentOnly,
+		"Limit to the preferred versions which are inconsistent.")
+
+	return cmd
+}
+
+// checkPreferredOptions contains the options for the check-preferred command.
+type checkPreferredOptions struct {
+	genericiooptions.IOStreams
+
+	NoHeaders        bool
+	InconsistentOnly bool
+
+	discoveryClient discovery.DiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newCheckPreferredOptions returns a new [checkPreferredOptions] with default values.
+func newCheckPreferredOptions(ioStreams genericiooptions.IOStreams) *checkPreferredOptions {
+	return &checkPreferredOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the check-preferred command.
+func (o *checkPreferredOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// preferredCheck is the comparison of a preferred version of the discovery with the version expected by a source.
+type preferredCheck struct {
+	// Source is where the expected version comes from, see [checkPreferredSourceAPIService].
+	Source string
+	// Name is the name of the group, "core" for the core group, or of the CustomResourceDefinition.
+	Name string
+	// Preferred is the preferred version of the discovery, empty if it isn't served.
+	Preferred string
+	// Expected is the version expected by the source.
+	Expected string
+}
+
+// Consistent returns true if the preferred version is the version expected by the source.
+func (c preferredCheck) Consistent() bool {
+	return c.Preferred == c.Expected
+}
+
+// apiServiceVersion is a version of a group registered by an APIService.
+type apiServiceVersion struct {
+	Group           string
+	Version         string
+	VersionPriority int64
+}
+
+// runCheckPreferred prints the checks of the preferred versions, and fails if any of them is inconsistent.
+func runCheckPreferred(ctx context.Context, options *checkPreferredOptions) error {
+	groupList, err := options.discoveryClient.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("couldn't get server groups: %w", err)
+	}
+
+	var checks []preferredCheck
+
+	apiServicesCtx, apiServicesSpan := startSpan(ctx, "read APIServices")
+	apiServiceVersions, err := listAPIServiceVersions(apiServicesCtx, options.dynamicClient)
+	endSpan(apiServicesSpan, err)
+
+	if err != nil {
+		_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't check the APIServices: %v\n", err)
+	} else {
+		checks = append(checks, apiServiceChecks(groupList, apiServiceVersions)...)
+	}
+
+	crdsCtx, crdsSpan := startSpan(ctx, "read custom resource definitions")
+	crdChecks, err := customResourceDefinitionChecks(crdsCtx, options)
+	endSpan(crdsSpan, err)
+
+	if err != nil {
+		_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't check the CustomResourceDefinitions: %v\n", err)
+	} else {
+		checks = append(checks, crdChecks...)
+	}
+
+	inconsistent := 0
+
+	for _, check := range checks {
+		if !check.Consistent() {
+			inconsistent++
+		}
+	}
+
+	if options.InconsistentOnly {
+		checks = slices.DeleteFunc(checks, preferredCheck.Consistent)
+	}
+
+	err = printPreferredChecks(checks, options)
+	if err != nil {
+		return err
+	}
+
+	if inconsistent > 0 {
+		return fmt.Errorf("%w: %d preferred versions are inconsistent", errCheckFailed, inconsistent)
+	}
+
+	return nil
+}
+
+// listAPIServiceVersions returns the versions of the groups registered by the APIServices.
+func listAPIServiceVersions(ctx context.Context, dynamicClient dynamic.Interface) ([]apiServiceVersion, error) {
+	list, err := dynamicClient.Resource(apiServicesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list %s: %w", apiServicesGVR.GroupResource(), err)
+	}
+
+	versions := make([]apiServiceVersion, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		group, _, _ := unstructured.NestedString(item.Object, "spec", "group")
+		apiVersion, _, _ := unstructured.NestedString(item.Object, "spec", "version")
+		priority, _, _ := unstructured.NestedInt64(item.Object, "spec", "versionPriority")
+
+		versions = append(versions, apiServiceVersion{Group: group, Version: apiVersion, VersionPriority: priority})
+	}
+
+	return versions, nil
+}
+
+// apiServiceChecks compares the preferred version of each group of the discovery registered by APIServices with the
+// version the APIServices imply: the highest versionPriority, then the highest Kubernetes version.
+func apiServiceChecks(groupList *metav1.APIGroupList, versions []apiServiceVersion) []preferredCheck {
+	expected := make(map[string]apiServiceVersion)
+
+	for _, version := range versions {
+		current, ok := expected[version.Group]
+		if !ok || compareAPIServiceVersions(version, current) > 0 {
+			expected[version.Group] = version
+		}
+	}
+
+	checks := make([]preferredCheck, 0, len(groupList.Groups))
+
+	for _, group := range groupList.Groups {
+		version, ok := expected[group.Name]
+		if !ok {
+			continue
+		}
+
+		name := group.Name
+		if name == "" {
+			name = "core"
+		}
+
+		checks = append(checks, preferredCheck{
+			Source:    checkPreferredSourceAPIService,
+			Name:      name,
+			Preferred: group.PreferredVersion.Version,
+			Expected:  version.Version,
+		})
+	}
+
+	return checks
+}
+
+// compareAPIServiceVersions compares the priorities of two versions of a group, like the aggregator orders them.
+func compareAPIServiceVersions(left, right apiServiceVersion) int {
+	if left.VersionPriority != right.VersionPriority {
+		if left.VersionPriority > right.VersionPriority {
+			return 1
+		}
+
+		return -1
+	}
+
+	return version.CompareKubeAwareVersionStrings(left.Version, right.Version)
+}
+
+// customResourceDefinitionChecks compares the storage version of each CustomResourceDefinition with the preferred
+// version of its resource in the discovery.
+// The CustomResourceDefinitions whose resource isn't served are skipped, e.g. when they aren't established yet.
+func customResourceDefinitionChecks(ctx context.Context, options *checkPreferredOptions) ([]preferredCheck, error) {
+	list, err := options.dynamicClient.Resource(customResourceDefinitionsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list %s: %w", customResourceDefinitionsGVR.GroupResource(), err)
+	}
+
+	preferredVersions, err := apiresourceversions.ServerPreferredVersions(options.discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]preferredCheck, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		group, _, _ := unstructured.NestedString(item.Object, "spec", "group")
+		resource, _, _ := unstructured.NestedString(item.Object, "spec", "names", "plural")
+
+		preferred, ok := preferredVersions[schema.GroupResource{Group: group, Resource: resource}]
+		if !ok {
+			continue
+		}
+
+		checks = append(checks, preferredCheck{
+			Source:    checkPreferredSourceCRD,
+			Name:      item.GetName(),
+			Preferred: preferred,
+			Expected:  customResourceStorage(&item).Version,
+		})
+	}
+
+	return checks, nil
+}
+
+// printPreferredChecks prints the checks of the preferred versions as a table.
+func printPreferredChecks(checks []preferredCheck, options *checkPreferredOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"SOURCE", "NAME", "PREFERRED", "EXPECTED", "CONSISTENT"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, check := range checks {
+		err := printRow(writer, []string{
+			check.Source,
+			check.Name,
+			check.Preferred,
+			check.Expected,
+			strconv.FormatBool(check.Consistent()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// TestRunCheckPreferred tests comparing the preferred versions with the APIServices and the CustomResourceDefinitions.
+func TestRunCheckPreferred(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Consistent", runCheckPreferredTest{
+		objects: []runtime.Object{
+			newAPIService("", "v1", 18000, 1),
+			newAPIService("autoscaling", "v1", 19100, 15),
+			newAPIService("autoscaling", "v2", 19100, 15),
+			newAPIService("autoscaling", "v2beta2", 19100, 9),
+		},
+		want: "SOURCE       NAME          PREFERRED   EXPECTED   CONSISTENT\n" +
+			"APIService   core          v1          v1         true\n" +
+			"APIService   autoscaling   v2          v2         true\n",
+		wantErr: nil,
+	}.Test)
+	// The version priority takes precedence over the Kubernetes version ordering.
+	t.Run("VersionPriority", runCheckPreferredTest{
+		objects: []runtime.Object{
+			newAPIService("autoscaling", "v1", 19100, 20),
+			newAPIService("autoscaling", "v2", 19100, 15),
+		},
+		want: "SOURCE       NAME          PREFERRED   EXPECTED   CONSISTENT\n" +
+			"APIService   autoscaling   v2          v1         false\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	t.Run("CRDStorage", runCheckPreferredTest{
+		objects: []runtime.Object{
+			newCustomResourceDefinition("autoscaling", "horizontalpodautoscalers", "v1"),
+		},
+		want: "SOURCE   NAME                                   PREFERRED   EXPECTED   CONSISTENT\n" +
+			"CRD      horizontalpodautoscalers.autoscaling   v2          v1         false\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	// The CustomResourceDefinitions whose resource isn't served are skipped.
+	t.Run("CRDNotServed", runCheckPreferredTest{
+		objects: []runtime.Object{
+			newCustomResourceDefinition("example.com", "widgets", "v1"),
+		},
+		want:    "SOURCE   NAME   PREFERRED   EXPECTED   CONSISTENT\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("InconsistentOnly", runCheckPreferredTest{
+		inconsistentOnly: true,
+		objects: []runtime.Object{
+			newAPIService("", "v1", 18000, 1),
+			newAPIService("autoscaling", "v1", 19100, 20),
+		},
+		want: "SOURCE       NAME          PREFERRED   EXPECTED   CONSISTENT\n" +
+			"APIService   autoscaling   v2          v1         false\n",
+		wantErr: errCheckFailed,
+	}.Test)
+}
+
+type runCheckPreferredTest struct {
+	inconsistentOnly bool
+	objects          []runtime.Object
+	want             string
+	wantErr          error
+}
+
+func (tt runCheckPreferredTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, stderr := genericiooptions.NewTestIOStreams()
+	options := newCheckPreferredOptions(ioStreams)
+	options.InconsistentOnly = tt.inconsistentOnly
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			apiServicesGVR:               "APIServiceList",
+			customResourceDefinitionsGVR: "CustomResourceDefinitionList",
+		},
+		tt.objects...,
+	)
+
+	err := runCheckPreferred(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runCheckPreferred() error = %v, want %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runCheckPreferred() output = %q, want %q", got, tt.want)
+	}
+
+	if got := stderr.String(); got != "" {
+		t.Errorf("runCheckPreferred() error output = %q, want none", got)
+	}
+}
+
+// newCustomResourceDefinition returns a CustomResourceDefinition of the resource of the group, stored in the version.
+func newCustomResourceDefinition(group, resource, storageVersion string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]any{"name": resource + "." + group},
+		"spec": map[string]any{
+			"group": group,
+			"names": map[string]any{"plural": resource},
+			"versions": []any{
+				map[string]any{"name": storageVersion, "served": true, "storage": true},
+			},
+		},
+	}}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// columnEllipsis ends the columns truncated to their maximum width.
+const columnEllipsis = "…"
+
+// errMaxColumnWidth is returned when a --max-column-width value isn't a positive width, optionally after a column.
+const errMaxColumnWidth = constError("invalid max-column-width, expected WIDTH or COLUMN=WIDTH with a positive width")
+
+// errMaxColumnWidthColumn is returned when --max-column-width limits a column which isn't printed.
+const errMaxColumnWidthColumn = constError("max-column-width limits a column which isn't printed")
+
+// errMaxColumnWidthOutput is returned when --max-column-width is requested with an output other than the default or
+// wide one, which don't print columns.
+const errMaxColumnWidthOutput = constError("max-column-width has no effect with outputs other than the default and " +
+	"wide ones: remove max-column-width or use the default or wide output")
+
+// validateMaxColumnWidth parses the --max-column-width values into the maximum width of each printed column.
+// A value without a column limits all the columns, and the values of a column, matched regardless of case, override it.
+func (o *apiResourceVersionsOptions) validateMaxColumnWidth() error {
+	if len(o.MaxColumnWidths) == 0 {
+		return nil
+	}
+
+	if o.Output != "" && o.Output != wideOutput {
+		return errMaxColumnWidthOutput
+	}
+
+	headers := tableHeaders(o)
+	widths := make([]int, len(headers))
+
+	// The values limiting all the columns are applied first, so that the values of a column override them.
+	for _, global := range []bool{true, false} {
+		for _, value := range o.MaxColumnWidths {
+			column, width, found := strings.Cut(value, "=")
+			if found == global {
+				continue
+			}
+
+			if !found {
+				width = column
+			}
+
+			maxWidth, err := strconv.Atoi(width)
+			if err != nil || maxWidth <= 0 {
+				return fmt.Errorf("%w: got %q", errMaxColumnWidth, value)
+			}
+
+			if global {
+				for i := range widths {
+					widths[i] = maxWidth
+				}
+
+				continue
+			}
+
+			i := slices.Index(headers, strings.ToUpper(column))
+			if i < 0 {
+				return fmt.Errorf("%w: got %s, the columns are %s", errMaxColumnWidthColumn, column,
+					strings.Join(headers, ", "))
+			}
+
+			widths[i] = maxWidth
+		}
+	}
+
+	o.maxColumnWidths = widths
+
+	return nil
+}
+
+// truncateColumns truncates each column to its maximum width in place, the columns without maximum width being left
+// unbounded, and returns them.
+func truncateColumns(columns []string, maxWidths []int) []string {
+	for i, maxWidth := range maxWidths {
+		if i < len(columns) && maxWidth > 0 {
+			columns[i] = truncateColumn(columns[i], maxWidth)
+		}
+	}
+
+	return columns
+}
+
+// truncateColumn returns the column truncated to the maximum width in runes, ending with an ellipsis if it was
+// truncated.
+func truncateColumn(column string, maxWidth int) string {
+	if utf8.RuneCountInString(column) <= maxWidth {
+		return column
+	}
+
+	runes := []rune(column)
+
+	return string(runes[:maxWidth-1]) + columnEllipsis
+}
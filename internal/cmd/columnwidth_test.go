@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestMaxColumnWidth(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetPreferred(true).SetOutput(wideOutput).
+		SetMaxColumnWidths("8", "NAME=12", "apiversion=20")
+	_, stdout, _ := builder.GetBuffers()
+	options := builder.APIResourceVersionsOptions()
+
+	err := options.validate()
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	err = runAPIResourceVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "NAME           SHORTNAMES   APIVERSION       NAMESPACED   KIND       PREFERRED   GROUPPREFERRED   VERBS      " +
+		"CATEGORIES\n" +
+		"horizontalp…   hpa          autoscaling/v2   true         Horizon…   true        true             create,…   " +
+		"all\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
+
+type truncateColumnTest struct {
+	column   string
+	maxWidth int
+	want     string
+}
+
+func (tt truncateColumnTest) Test(t *testing.T) {
+	t.Parallel()
+
+	if got := truncateColumn(tt.column, tt.maxWidth); got != tt.want {
+		t.Errorf("truncateColumn(%q, %d) = %q, want %q", tt.column, tt.maxWidth, got, tt.want)
+	}
+}
+
+func TestTruncateColumn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Short", truncateColumnTest{column: "pods", maxWidth: 4, want: "pods"}.Test)
+	t.Run("Long", truncateColumnTest{column: "get,list,watch", maxWidth: 8, want: "get,lis…"}.Test)
+	t.Run("Single", truncateColumnTest{column: "pods", maxWidth: 1, want: "…"}.Test)
+	t.Run("Multibyte", truncateColumnTest{column: "✓✓✓✓", maxWidth: 3, want: "✓✓…"}.Test)
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// releaseComparisonStatus is the difference between the cluster and a stock Kubernetes release for a kind.
+type releaseComparisonStatus string
+
+const (
+	// releaseExtension is used for the kinds served by the cluster which aren't built-in Kubernetes APIs, e.g. custom
+	// resources and aggregated APIs.
+	releaseExtension releaseComparisonStatus = "extension"
+	// releaseRemoved is used for the built-in kinds served by the cluster which aren't served by the release, and will
+	// disappear after upgrading to it.
+	releaseRemoved releaseComparisonStatus = "removed"
+	// releaseNewer is used for the built-in kinds served by the cluster which are introduced after the release.
+	releaseNewer releaseComparisonStatus = "newer"
+	// releaseMissing is used for the built-in kinds served by the release which aren't served by the cluster.
+	releaseMissing releaseComparisonStatus = "missing"
+)
+
+// releaseComparison is a kind which is served by either the cluster or a stock Kubernetes release, but not both.
+type releaseComparison struct {
+	// Status is the difference between the cluster and the release.
+	Status releaseComparisonStatus
+	// GroupVersionKind is the kind in its group version.
+	GroupVersionKind schema.GroupVersionKind
+	// Note explains when the kind was removed or introduced, if known.
+	Note string
+}
+
+// errCompareRelease is returned when the --compare-release value is not a Kubernetes release.
+const errCompareRelease = constError("invalid compare release")
+
+// errCompareReleaseMode is returned when --compare-release is requested with an incompatible option.
+const errCompareReleaseMode = constError(
+	"compare-release is not supported with watch, output, all-contexts, contexts, or clusters-file")
+
+// validateCompareRelease parses the release of --compare-release, if requested, and checks that it can be used with
+// the other options.
+func (o *apiResourceVersionsOptions) validateCompareRelease() error {
+	if o.CompareRelease == "" {
+		return nil
+	}
+
+	if o.Watch || o.Output != "" || o.AllContexts || len(o.Contexts) > 0 || o.ClustersFile != "" {
+		return errCompareReleaseMode
+	}
+
+	release, err := lifecycle.ParseRelease(o.CompareRelease)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCompareRelease, err)
+	}
+
+	o.compareRelease = release
+
+	return nil
+}
+
+// runCompareRelease prints the kinds which are served by either the cluster or the stock Kubernetes release, but not
+// both.
+func runCompareRelease(ctx context.Context, options *apiResourceVersionsOptions) error {
+	resources, err := listGroupResources(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	var serverRelease *utilversion.Version
+
+	serverVersion, err := options.discoveryClient.ServerVersion()
+	if err == nil {
+		serverRelease, _ = lifecycle.ParseRelease(serverVersion.GitVersion)
+	}
+
+	var releaseAPIs []lifecycle.API
+
+	for _, api := range lifecycle.All() {
+		if api.ServedIn(options.compareRelease) && !excludeReleaseAPI(api, options) {
+			releaseAPIs = append(releaseAPIs, api)
+		}
+	}
+
+	comparisons := compareRelease(resources, options.compareRelease, releaseAPIs, serverRelease)
+
+	return printReleaseComparisons(options.Out, comparisons, options.NoHeaders)
+}
+
+// excludeReleaseAPI checks if the API of the release should be excluded based on the options.
+// Only the API group can be filtered, the other filters depend on the discovery of the resources.
+func excludeReleaseAPI(api lifecycle.API, options *apiResourceVersionsOptions) bool {
+	return (options.groupChanged && options.APIGroup != api.Group) || excludeGroupOrigin(api.Group, options)
+}
+
+// compareRelease returns the kinds of the resources which aren't served by the APIs of the release, and the APIs of
+// the release which aren't served by the resources, sorted by group, version, and kind.
+// The server release is used to note the APIs introduced after the release of the cluster, and may be nil.
+func compareRelease(
+	resources []groupResource,
+	release *utilversion.Version,
+	releaseAPIs []lifecycle.API,
+	serverRelease *utilversion.Version,
+) []releaseComparison {
+	served := sets.New[schema.GroupVersionKind]()
+
+	for _, resource := range resources {
+		if !resource.Subresource {
+			served.Insert(resource.groupVersionResource().GroupVersion().WithKind(resource.APIResource.Kind))
+		}
+	}
+
+	released := sets.New[schema.GroupVersionKind]()
+	for _, api := range releaseAPIs {
+		released.Insert(api.GroupVersionKind())
+	}
+
+	comparisons := make([]releaseComparison, 0)
+
+	for _, gvk := range served.Difference(released).UnsortedList() {
+		api, known := lifecycle.Lookup(gvk)
+		if !known {
+			comparisons = append(comparisons, releaseComparison{Status: releaseExtension, GroupVersionKind: gvk})
+
+			continue
+		}
+
+		if !api.RemovedIn(release) {
+			comparisons = append(comparisons, releaseComparison{
+				Status: releaseNewer, GroupVersionKind: gvk, Note: "introduced in " + api.Introduced,
+			})
+
+			continue
+		}
+
+		comparison := releaseComparison{Status: releaseRemoved, GroupVersionKind: gvk, Note: "removed in " + api.Removed}
+		if api.Replacement != "" {
+			comparison.Note += ", use " + api.Replacement
+		}
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	for _, api := range releaseAPIs {
+		gvk := api.GroupVersionKind()
+		if served.Has(gvk) {
+			continue
+		}
+
+		comparison := releaseComparison{Status: releaseMissing, GroupVersionKind: gvk}
+		if serverRelease != nil && api.Introduced != "" && !api.ServedIn(serverRelease) {
+			comparison.Note = "introduced in " + api.Introduced
+		}
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	slices.SortFunc(comparisons, func(a, b releaseComparison) int {
+		return cmp.Or(
+			cmp.Compare(a.GroupVersionKind.Group, b.GroupVersionKind.Group),
+			cmp.Compare(a.GroupVersionKind.Version, b.GroupVersionKind.Version),
+			cmp.Compare(a.GroupVersionKind.Kind, b.GroupVersionKind.Kind),
+		)
+	})
+
+	return comparisons
+}
+
+// printReleaseComparisons prints the comparisons as a table.
+func printReleaseComparisons(out io.Writer, comparisons []releaseComparison, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		err := printRow(writer, []string{"STATUS", "APIVERSION", "KIND", "NOTE"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, comparison := range comparisons {
+		err := printRow(writer, []string{
+			string(comparison.Status),
+			comparison.GroupVersionKind.GroupVersion().String(),
+			comparison.GroupVersionKind.Kind,
+			comparison.Note,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunCompareRelease tests comparing the kinds served by the cluster with a stock Kubernetes release.
+func TestRunCompareRelease(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Upgrade", runCompareReleaseTest{
+		release: "v1.33",
+		want: "STATUS    APIVERSION            KIND                      NOTE\n" +
+			"removed   autoscaling/v2beta2   HorizontalPodAutoscaler   removed in 1.26, use autoscaling/v2\n",
+	}.Test)
+	t.Run("Older", runCompareReleaseTest{
+		release: "1.22",
+		want: "STATUS    APIVERSION            KIND                      NOTE\n" +
+			"newer     autoscaling/v2        HorizontalPodAutoscaler   introduced in 1.23\n" +
+			"missing   autoscaling/v2beta1   HorizontalPodAutoscaler   \n",
+	}.Test)
+}
+
+type runCompareReleaseTest struct {
+	release string
+	want    string
+}
+
+func (tt runCompareReleaseTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetCompareRelease(tt.release)
+	_, stdout, _ := builder.GetBuffers()
+	options := builder.APIResourceVersionsOptions()
+
+	err := options.validate()
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	err = runCompareRelease(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runCompareRelease() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runCompareRelease() output = %q, want %q", got, tt.want)
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+)
+
+// The shell completion scripts are generated by the completion command which cobra adds to the root command, e.g.
+// `kubectl api-resource-versions completion bash`.
+// The completions below are dynamic: they are read from the discovery cache of the cluster selected by the flags
+// already given, e.g. --context, which is only refreshed once it is expired like for kubectl.
+
+// completeAPIGroups returns the completion function of the API groups served by the cluster.
+func completeAPIGroups(restClientGetter genericclioptions.RESTClientGetter) cobra.CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		discoveryClient, err := restClientGetter.ToDiscoveryClient()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		groupList, err := discoveryClient.ServerGroups()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]cobra.Completion, 0, len(groupList.Groups))
+
+		for _, group := range groupList.Groups {
+			// The core group has no name, and can't be selected with --api-group.
+			if group.Name == "" || !strings.HasPrefix(group.Name, toComplete) {
+				continue
+			}
+
+			completions = append(completions,
+				cobra.CompletionWithDesc(group.Name, "preferred version "+group.PreferredVersion.Version))
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeResourceValues returns the completion function of a comma-separated list of the values of the resources
+// served by the cluster, e.g. their verbs for --verbs.
+func completeResourceValues(
+	restClientGetter genericclioptions.RESTClientGetter,
+	values func(resource *metav1.APIResource) []string,
+) cobra.CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		resourceLists, err := completionResourceLists(restClientGetter)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		allValues := sets.New[string]()
+
+		for _, resourceList := range resourceLists {
+			for i := range resourceList.APIResources {
+				allValues.Insert(values(&resourceList.APIResources[i])...)
+			}
+		}
+
+		return completeList(sets.List(allValues), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeResources returns the completion function of the resources served by the cluster in the
+// <resource>.<group> format, e.g. for the arguments of migrate-storage.
+func completeResources(restClientGetter genericclioptions.RESTClientGetter) cobra.CompletionFunc {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		resourceLists, err := completionResourceLists(restClientGetter)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := sets.New[string]()
+
+		for _, resourceList := range resourceLists {
+			for _, resource := range resourceList.APIResources {
+				// Subresources can't be given as arguments.
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+
+				name := resource.Name
+				if resource.Group != "" {
+					name += "." + resource.Group
+				}
+
+				if strings.HasPrefix(name, toComplete) && !slices.Contains(args, name) {
+					completions.Insert(name)
+				}
+			}
+		}
+
+		return sets.List(completions), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionResourceLists returns the preferred resources served by the cluster, with their group set.
+// The group versions which couldn't be discovered are ignored, rather than failing the completion.
+func completionResourceLists(
+	restClientGetter genericclioptions.RESTClientGetter,
+) ([]*metav1.APIResourceList, error) {
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	resourceLists, err := discoveryClient.ServerPreferredResources()
+
+	failedGroups := &discovery.ErrGroupDiscoveryFailed{}
+	if err != nil && !errors.As(err, &failedGroups) {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse group version %s: %w", resourceList.GroupVersion, err)
+		}
+
+		for i := range resourceList.APIResources {
+			resourceList.APIResources[i].Group = groupVersion.Group
+		}
+	}
+
+	return resourceLists, nil
+}
+
+// completeList returns the completions of the last value of a comma-separated list, prefixed by the values already
+// given, e.g. "get,list" and "get,watch" for "get,".
+func completeList(values []string, toComplete string) []cobra.Completion {
+	// given are the values already given, along with their trailing comma.
+	given := toComplete[:strings.LastIndex(toComplete, ",")+1]
+	last := toComplete[len(given):]
+
+	completions := make([]cobra.Completion, 0, len(values))
+
+	for _, value := range values {
+		if strings.HasPrefix(value, last) && !slices.Contains(strings.Split(given, ","), value) {
+			completions = append(completions, given+value)
+		}
+	}
+
+	return completions
+}
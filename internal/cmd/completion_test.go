@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// TestCompletions tests completing the flags and arguments from the discovery documents of the cluster.
+func TestCompletions(t *testing.T) {
+	t.Parallel()
+
+	restClientGetter := newFromDumpFlags(genericclioptions.NewConfigFlags(false))
+	restClientGetter.Directory = filepath.Join("testdata", "discovery")
+
+	verbs := completeResourceValues(restClientGetter, func(resource *metav1.APIResource) []string {
+		return resource.Verbs
+	})
+
+	t.Run("APIGroups", completionTest{
+		completion: completeAPIGroups(restClientGetter),
+		want:       []cobra.Completion{"apps\tpreferred version v1"},
+	}.Test)
+	t.Run("Verbs", completionTest{
+		completion: verbs,
+		toComplete: "get,l",
+		want:       []cobra.Completion{"get,list"},
+	}.Test)
+	t.Run("Resources", completionTest{
+		completion: completeResources(restClientGetter),
+		args:       []string{"pods"},
+		want:       []cobra.Completion{"configmaps", "deployments.apps"},
+	}.Test)
+	t.Run("ResourcesPrefix", completionTest{
+		completion: completeResources(restClientGetter),
+		toComplete: "dep",
+		want:       []cobra.Completion{"deployments.apps"},
+	}.Test)
+}
+
+type completionTest struct {
+	completion cobra.CompletionFunc
+	args       []string
+	toComplete string
+	want       []cobra.Completion
+}
+
+func (tt completionTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got, directive := tt.completion(&cobra.Command{}, tt.args, tt.toComplete)
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("completion() directive = %v, want %v", directive, cobra.ShellCompDirectiveNoFileComp)
+	}
+
+	if !slices.Equal(got, tt.want) {
+		t.Errorf("completion() = %q, want %q", got, tt.want)
+	}
+}
+
+// TestCompleteList tests completing the last value of a comma-separated list.
+func TestCompleteList(t *testing.T) {
+	t.Parallel()
+
+	values := []string{"get", "list", "watch"}
+
+	for toComplete, want := range map[string][]cobra.Completion{
+		"":          {"get", "list", "watch"},
+		"w":         {"watch"},
+		"get,":      {"get,list", "get,watch"},
+		"get,list,": {"get,list,watch"},
+		"get,x":     {},
+	} {
+		got := completeList(values, toComplete)
+		if !slices.Equal(got, want) {
+			t.Errorf("completeList(%q) = %q, want %q", toComplete, got, want)
+		}
+	}
+}
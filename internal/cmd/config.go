@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// configFlag is the name of the flag selecting the config file.
+const configFlag = "config"
+
+// configDirectory is the directory of the default config file, in the XDG config directory.
+const configDirectory = "kubectl-api-resource-versions"
+
+// inheritedAnnotation annotates the flags set from their KUBECTL_ARV_* environment variables or the config file
+// rather than on the command line.
+const inheritedAnnotation = "kubectl-api-resource-versions/inherited"
+
+// errConfigFlag is returned when the config file sets a flag which the command doesn't have.
+const errConfigFlag = constError("unknown flag")
+
+// errConfigCommand is returned when the config file sets the flags of a command which doesn't exist.
+const errConfigCommand = constError("unknown command")
+
+// configFile is the config file providing the default values of the flags, so that teams can standardize the
+// behavior of the plugin without long command lines, e.g.:
+//
+//	flags:
+//	  sort-by: kind
+//	commands:
+//	  check:
+//	    fail-on: absent
+//
+// The flags given on the command line and the KUBECTL_ARV_* environment variables take precedence over the config
+// file.
+type configFile struct {
+	// Flags are the values of the flags of the api-resource-versions command, including its persistent flags which
+	// apply to every command, e.g. context.
+	Flags map[string]any `json:"flags,omitempty"`
+	// Commands are the values of the flags of the commands by their path, e.g. "snapshot diff", taking precedence
+	// over the persistent flags of Flags.
+	Commands map[string]map[string]any `json:"commands,omitempty"`
+}
+
+// addConfigFlag adds the --config flag to the flag set.
+func addConfigFlag(flags *pflag.FlagSet) {
+	flags.String(configFlag, "",
+		"Path to the config file providing the default values of the flags. "+
+			"Defaults to $XDG_CONFIG_HOME/"+configDirectory+"/config.yaml, or ~/.config if XDG_CONFIG_HOME is unset.")
+}
+
+// defaultConfigFilename returns the path of the default config file.
+func defaultConfigFilename() (string, error) {
+	directory := os.Getenv("XDG_CONFIG_HOME")
+	if directory == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("couldn't get home directory: %w", err)
+		}
+
+		directory = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(directory, configDirectory, "config.yaml"), nil
+}
+
+// readConfigFile reads the config file of the --config flag of the command, or the default config file if it
+// exists.
+// It returns nil if the default config file doesn't exist.
+func readConfigFile(cmd *cobra.Command) (*configFile, error) {
+	filename := cmd.Flag(configFlag).Value.String()
+	explicit := filename != ""
+
+	if !explicit {
+		var err error
+
+		filename, err = defaultConfigFilename()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := os.ReadFile(filename) //nolint:gosec // Reading the user-provided config file is intended.
+	if !explicit && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil //nolint:nilnil
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read config file %s: %w", filename, err)
+	}
+
+	config := &configFile{}
+
+	err = k8syaml.UnmarshalStrict(content, config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse config file %s: %w", filename, err)
+	}
+
+	for path := range config.Commands {
+		found, args, err := cmd.Root().Find(strings.Fields(path))
+		if err != nil || len(args) != 0 || found == cmd.Root() {
+			return nil, fmt.Errorf("%w in config file %s: %s", errConfigCommand, filename, path)
+		}
+	}
+
+	return config, nil
+}
+
+// applyFlagDefaults sets the flags of the command which weren't given on the command line to the values of their
+// environment variables, or else to their values from the config file.
+func applyFlagDefaults(cmd *cobra.Command) error {
+	given := sets.New[string]()
+	cmd.Flags().Visit(func(flag *pflag.Flag) { given.Insert(flag.Name) })
+
+	err := applyEnvironment(cmd.Flags(), given)
+	if err != nil {
+		return err
+	}
+
+	return applyConfigFile(cmd, given)
+}
+
+// applyConfigFile sets the flags of the command which weren't given to their values from the config file, see
+// [configFile].
+func applyConfigFile(cmd *cobra.Command, given sets.Set[string]) error {
+	config, err := readConfigFile(cmd)
+	if err != nil || config == nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	for name, value := range config.Flags {
+		flag := flags.Lookup(name)
+		if flag == nil && cmd.HasParent() {
+			// The local flags of the api-resource-versions command don't apply to the other commands.
+			continue
+		}
+
+		err := setConfigFlag(flag, name, value, given)
+		if err != nil {
+			return err
+		}
+	}
+
+	commandPath := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().CommandPath()))
+
+	for name, value := range config.Commands[commandPath] {
+		err := setConfigFlag(flags.Lookup(name), name, value, given)
+		if err != nil {
+			return fmt.Errorf("%s: %w", commandPath, err)
+		}
+	}
+
+	return nil
+}
+
+// setConfigFlag sets the flag to its value from the config file, unless it was given on the command line.
+// Lists set every value of the list flags, e.g. verbs, replacing their default values.
+func setConfigFlag(flag *pflag.Flag, name string, value any, given sets.Set[string]) error {
+	if flag == nil {
+		return fmt.Errorf("%w in config file: %s", errConfigFlag, name)
+	}
+
+	if given.Has(flag.Name) {
+		return nil
+	}
+
+	var err error
+
+	if list, ok := value.([]any); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+
+		sliceValue, ok := flag.Value.(pflag.SliceValue)
+		if ok {
+			err = sliceValue.Replace(values)
+		} else {
+			err = flag.Value.Set(strings.Join(values, ","))
+		}
+	} else {
+		err = flag.Value.Set(fmt.Sprint(value))
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value %v for flag %s in config file: %w", value, name, err)
+	}
+
+	setInherited(flag)
+
+	return nil
+}
+
+// setInherited marks the flag as changed by its default from the environment or the config file, see
+// [inheritedFlags].
+func setInherited(flag *pflag.Flag) {
+	flag.Changed = true
+
+	if flag.Annotations == nil {
+		flag.Annotations = map[string][]string{}
+	}
+
+	flag.Annotations[inheritedAnnotation] = []string{"true"}
+}
+
+// inheritedFlags returns the names of the changed flags which weren't given on the command line, but set from their
+// environment variables or the config file.
+func inheritedFlags(flags *pflag.FlagSet) sets.Set[string] {
+	inherited := sets.New[string]()
+
+	// The defaults don't set the flags through the flag set, so that Visit doesn't visit them.
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if _, ok := flag.Annotations[inheritedAnnotation]; ok && flag.Changed {
+			inherited.Insert(flag.Name)
+		}
+	})
+
+	return inherited
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const testConfigFile = `flags:
+  sort-by: kind
+  verbs: [get, list]
+  request-timeout: 10s
+commands:
+  check:
+    fail-on: absent
+`
+
+// TestApplyConfigFile tests setting the default values of the flags from the config file.
+func TestApplyConfigFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Defaults", applyConfigFileTest{
+		config: testConfigFile,
+		want:   map[string]string{"sort-by": "kind", "verbs": "[get,list]", "request-timeout": "10s"},
+	}.Test)
+	t.Run("CommandLine", applyConfigFileTest{
+		config: testConfigFile,
+		args:   []string{"--sort-by=name", "--verbs=watch"},
+		want:   map[string]string{"sort-by": "name", "verbs": "[watch]", "request-timeout": "10s"},
+	}.Test)
+	t.Run("Command", applyConfigFileTest{
+		config: testConfigFile,
+		args:   []string{"check", "--fail-on=deprecated"},
+		want:   map[string]string{"fail-on": "deprecated", "request-timeout": "10s"},
+	}.Test)
+	t.Run("CommandDefaults", applyConfigFileTest{
+		config: testConfigFile,
+		args:   []string{"check"},
+		want:   map[string]string{"fail-on": "absent"},
+	}.Test)
+	t.Run("UnknownFlag", applyConfigFileTest{
+		config:  "flags:\n  bogus: true\n",
+		wantErr: errConfigFlag,
+	}.Test)
+	t.Run("UnknownCommandFlag", applyConfigFileTest{
+		config:  "commands:\n  check:\n    sort-by: kind\n",
+		args:    []string{"check"},
+		wantErr: errConfigFlag,
+	}.Test)
+	t.Run("UnknownCommand", applyConfigFileTest{
+		config:  "commands:\n  bogus: {}\n",
+		wantErr: errConfigCommand,
+	}.Test)
+}
+
+type applyConfigFileTest struct {
+	config  string
+	args    []string
+	want    map[string]string
+	wantErr error
+}
+
+func (tt applyConfigFileTest) Test(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(filename, []byte(tt.config), 0o600)
+	if err != nil {
+		t.Fatalf("couldn't write config file: %v", err)
+	}
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	root := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), ioStreams)
+
+	cmd, args, err := root.Find(tt.args)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	err = cmd.ParseFlags(append(args, "--config="+filename))
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+
+	err = applyFlagDefaults(cmd)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("applyFlagDefaults() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	for name, want := range tt.want {
+		got := cmd.Flag(name).Value.String()
+		if got != want {
+			t.Errorf("applyFlagDefaults() flag %s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// TestReadConfigFileDefault tests that a missing default config file is ignored.
+func TestReadConfigFileDefault(t *testing.T) { //nolint:paralleltest // Setting XDG_CONFIG_HOME.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	root := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), ioStreams)
+
+	config, err := readConfigFile(root)
+	if err != nil || config != nil {
+		t.Errorf("readConfigFile() = %v, %v, want nil, nil", config, err)
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// withContext returns new config flags which select the kubeconfig context, keeping the kubeconfig file, cache
+// directory, timeout, and impersonation of the config flags.
+// The other flags, e.g. --cluster or --server, would override the context and are not kept.
+func withContext(configFlags *genericclioptions.ConfigFlags, contextName string) *genericclioptions.ConfigFlags {
+	contextFlags := genericclioptions.NewConfigFlags(true)
+	contextFlags.KubeConfig = configFlags.KubeConfig
+	contextFlags.CacheDir = configFlags.CacheDir
+	contextFlags.Timeout = configFlags.Timeout
+	contextFlags.Impersonate = configFlags.Impersonate
+	contextFlags.ImpersonateUID = configFlags.ImpersonateUID
+	contextFlags.ImpersonateGroup = configFlags.ImpersonateGroup
+	contextFlags.WrapConfigFn = configFlags.WrapConfigFn
+	contextFlags.Context = &contextName
+
+	return contextFlags
+}
+
+// contextDiscoveryClients returns a discovery client for each of the kubeconfig contexts, with a discovery cache of
+// their own.
+func contextDiscoveryClients(
+	configFlags *genericclioptions.ConfigFlags,
+	contexts []string,
+) ([]discovery.CachedDiscoveryInterface, error) {
+	discoveryClients := make([]discovery.CachedDiscoveryInterface, 0, len(contexts))
+
+	for _, contextName := range contexts {
+		discoveryClient, err := newCachedDiscoveryClient(
+			withContext(configFlags, contextName), contextName, defaultDiscoveryCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create discovery client for context %s: %w", contextName, err)
+		}
+
+		discoveryClients = append(discoveryClients, discoveryClient)
+	}
+
+	return discoveryClients, nil
+}
+
+// contextSnapshots discovers the API resources of the contexts concurrently and returns their snapshots, in the order
+// of the contexts.
+// The warnings of each context are written to errOut once all of them are discovered, prefixed with the context.
+func contextSnapshots(
+	ctx context.Context,
+	contexts []string,
+	discoveryClients []discovery.CachedDiscoveryInterface,
+	errOut io.Writer,
+) ([]*snapshot, error) {
+	snapshots := make([]*snapshot, len(discoveryClients))
+	warnings := make([]bytes.Buffer, len(discoveryClients))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for i, discoveryClient := range discoveryClients {
+		group.Go(func() error {
+			snap, err := newSnapshot(groupCtx, discoveryClient, &warnings[i])
+			if err != nil {
+				return fmt.Errorf("couldn't discover context %s: %w", contexts[i], err)
+			}
+
+			snap.Context = contexts[i]
+			snapshots[i] = snap
+
+			return nil
+		})
+	}
+
+	err := group.Wait()
+
+	for i := range warnings {
+		for line := range strings.Lines(warnings[i].String()) {
+			_, _ = fmt.Fprintf(errOut, "context %s: %s", contexts[i], line)
+		}
+	}
+
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// errNoContexts is returned when the kubeconfig has no contexts.
+const errNoContexts = constError("no contexts found in the kubeconfig")
+
+// kubeconfigContexts returns the names of all the contexts of the kubeconfig, sorted by name.
+func kubeconfigContexts(configFlags *genericclioptions.ConfigFlags) ([]string, error) {
+	rawConfig, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load kubeconfig: %w", err)
+	}
+
+	if len(rawConfig.Contexts) == 0 {
+		return nil, errNoContexts
+	}
+
+	return slices.Sorted(maps.Keys(rawConfig.Contexts)), nil
+}
+
+// clusterClients are the clients of a kubeconfig context.
+type clusterClients struct {
+	// context is the name of the context, empty for the current context.
+	context         string
+	discoveryClient discovery.CachedDiscoveryInterface
+	// dynamicClient is only created when the objects are counted or the priorities of the groups are shown.
+	dynamicClient dynamic.Interface
+	// staleDiscoveryClient is only created with --stale-ok.
+	staleDiscoveryClient *directoryDiscoveryClient
+}
+
+// newClusterClients creates the clients of the cluster selected by the config flags, reading the discovery directory
+// with --from-dump, the discovery files with --from-file, or the kubectl discovery cache with --offline, and with a
+// dynamic client if the objects are counted.
+// The discovery cache is used with --cache-ttl if it is newer than the TTL, and when the discovery fails with
+// --stale-ok; the named clusters of a fleet have a discovery cache of their own, see [clusterDiscoveryCacheDirectory].
+func (o *apiResourceVersionsOptions) newClusterClients(
+	configFlags *genericclioptions.ConfigFlags,
+	contextName string,
+) (clusterClients, error) {
+	cluster := clusterClients{context: contextName}
+
+	if o.FromDump != "" {
+		cluster.discoveryClient = newDirectoryDiscoveryClient(o.FromDump)
+
+		return cluster, nil
+	} else if len(o.FromFiles) > 0 {
+		discoveryClient, err := newFileDiscoveryClient(o.FromFiles)
+		if err != nil {
+			return cluster, err
+		}
+
+		cluster.discoveryClient = discoveryClient
+
+		return cluster, nil
+	} else if o.Offline {
+		cacheDirectory, err := discoveryCacheDirectory(configFlags)
+		if err != nil {
+			return cluster, err
+		}
+
+		cluster.discoveryClient = newDirectoryDiscoveryClient(cacheDirectory)
+
+		return cluster, nil
+	}
+
+	var (
+		discoveryClient discovery.CachedDiscoveryInterface
+		err             error
+	)
+
+	if o.CacheTTL > 0 || contextName != "" {
+		ttl := o.CacheTTL
+		if ttl <= 0 {
+			// The discovery is refreshed anyway, see getGroupResources.
+			ttl = defaultDiscoveryCacheTTL
+		}
+
+		discoveryClient, err = newCachedDiscoveryClient(configFlags, contextName, ttl)
+		if err != nil {
+			return cluster, err
+		}
+	} else {
+		discoveryClient, err = configFlags.ToDiscoveryClient()
+		if err != nil {
+			return cluster, fmt.Errorf("couldn't create discovery client: %w", err)
+		}
+	}
+
+	cluster.discoveryClient = discoveryClient
+
+	if o.StaleOK {
+		cacheDirectory, err := clusterDiscoveryCacheDirectory(configFlags, contextName)
+		if err != nil {
+			return cluster, err
+		}
+
+		cluster.staleDiscoveryClient = newDirectoryDiscoveryClient(cacheDirectory)
+	}
+
+	if o.countsRequired() || o.ShowGroupPriority {
+		restConfig, err := configFlags.ToRESTConfig()
+		if err != nil {
+			return cluster, fmt.Errorf("couldn't get REST config: %w", err)
+		}
+
+		cluster.dynamicClient, err = dynamic.NewForConfig(restConfig)
+		if err != nil {
+			return cluster, fmt.Errorf("couldn't create dynamic client: %w", err)
+		}
+	}
+
+	return cluster, nil
+}
+
+// listClusterGroupResources lists the API resources of the current context, or of each of the clusters with bounded
+// concurrency, see [listGroupResources].
+func listClusterGroupResources(ctx context.Context, options *apiResourceVersionsOptions) ([]groupResource, error) {
+	if len(options.clusters) == 0 {
+		return listGroupResources(ctx, options)
+	}
+
+	clusterResources := make([][]groupResource, len(options.clusters))
+	clusterGroupVersions := make([][]clusterGroupVersion, len(options.clusters))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(options.ClusterConcurrency)
+
+	for i, cluster := range options.clusters {
+		group.Go(func() error {
+			clusterOptions := *options
+			clusterOptions.discoveryClient = cluster.discoveryClient
+			clusterOptions.dynamicClient = cluster.dynamicClient
+			clusterOptions.staleDiscoveryClient = cluster.staleDiscoveryClient
+
+			clusterCtx, clusterSpan := startSpan(groupCtx, "list cluster",
+				attribute.String("k8s.context", cluster.context))
+			resources, err := listGroupResources(clusterCtx, &clusterOptions)
+			endSpan(clusterSpan, err)
+
+			if err != nil {
+				return fmt.Errorf("couldn't list resources of context %s: %w", cluster.context, err)
+			}
+
+			serverVersion := ""
+			if options.ShowServerVersion {
+				serverVersion = clusterServerVersion(cluster.discoveryClient)
+			}
+
+			for j := range resources {
+				resources[j].Cluster = cluster.context
+				resources[j].ServerVersion = serverVersion
+			}
+
+			clusterResources[i] = resources
+
+			for _, groupVersion := range clusterOptions.matchedGroupVersions {
+				groupVersion.Cluster = cluster.context
+				clusterGroupVersions[i] = append(clusterGroupVersions[i], groupVersion)
+			}
+
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	options.matchedGroupVersions = slices.Concat(clusterGroupVersions...)
+
+	return slices.Concat(clusterResources...), nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery/fake"
+)
+
+// TestWithContext tests that the config flags select the context and keep the kubeconfig file.
+func TestWithContext(t *testing.T) {
+	t.Parallel()
+
+	configFlags := genericclioptions.NewConfigFlags(true)
+	kubeConfig := "/tmp/kubeconfig"
+	configFlags.KubeConfig = &kubeConfig
+
+	contextFlags := withContext(configFlags, "staging")
+	if got := *contextFlags.Context; got != "staging" {
+		t.Errorf("withContext() context = %q, want %q", got, "staging")
+	}
+
+	if got := *contextFlags.KubeConfig; got != kubeConfig {
+		t.Errorf("withContext() kubeconfig = %q, want %q", got, kubeConfig)
+	}
+
+	if got := *configFlags.Context; got != "" {
+		t.Errorf("withContext() changed the context of the config flags to %q", got)
+	}
+}
+
+// TestRunAPIResourceVersionsContexts tests listing the resources of multiple contexts with a CLUSTER column.
+func TestRunAPIResourceVersionsContexts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", runAPIResourceVersionsContextsTest{
+		output:            "",
+		showServerVersion: false,
+		want: "CLUSTER   NAME                       SHORTNAMES   APIVERSION            NAMESPACED   KIND                      PREFERRED\n" +
+			"prod      horizontalpodautoscalers   hpa          autoscaling/v2        true         HorizontalPodAutoscaler   true\n" +
+			"prod      horizontalpodautoscalers   hpa          autoscaling/v1        true         HorizontalPodAutoscaler   false\n" +
+			"prod      horizontalpodautoscalers   hpa          autoscaling/v2beta2   true         HorizontalPodAutoscaler   false\n" +
+			"staging   horizontalpodautoscalers   hpa          autoscaling/v2        true         HorizontalPodAutoscaler   true\n" +
+			"staging   horizontalpodautoscalers   hpa          autoscaling/v1        true         HorizontalPodAutoscaler   false\n" +
+			"staging   horizontalpodautoscalers   hpa          autoscaling/v2beta2   true         HorizontalPodAutoscaler   false\n",
+	}.Test)
+	t.Run("ServerVersion", runAPIResourceVersionsContextsTest{
+		output:            "",
+		showServerVersion: true,
+		want: "CLUSTER   SERVER-VERSION   NAME                       SHORTNAMES   APIVERSION            NAMESPACED   " +
+			"KIND                      PREFERRED\n" +
+			"prod      <unknown>        horizontalpodautoscalers   hpa          autoscaling/v2        true         " +
+			"HorizontalPodAutoscaler   true\n" +
+			"prod      <unknown>        horizontalpodautoscalers   hpa          autoscaling/v1        true         " +
+			"HorizontalPodAutoscaler   false\n" +
+			"prod      <unknown>        horizontalpodautoscalers   hpa          autoscaling/v2beta2   true         " +
+			"HorizontalPodAutoscaler   false\n" +
+			"staging   v1.33.1          horizontalpodautoscalers   hpa          autoscaling/v2        true         " +
+			"HorizontalPodAutoscaler   true\n" +
+			"staging   v1.33.1          horizontalpodautoscalers   hpa          autoscaling/v1        true         " +
+			"HorizontalPodAutoscaler   false\n" +
+			"staging   v1.33.1          horizontalpodautoscalers   hpa          autoscaling/v2beta2   true         " +
+			"HorizontalPodAutoscaler   false\n",
+	}.Test)
+	t.Run("Name", runAPIResourceVersionsContextsTest{
+		output:            nameOutput,
+		showServerVersion: false,
+		want: "prod      horizontalpodautoscalers.v2.autoscaling\n" +
+			"prod      horizontalpodautoscalers.v1.autoscaling\n" +
+			"prod      horizontalpodautoscalers.v2beta2.autoscaling\n" +
+			"staging   horizontalpodautoscalers.v2.autoscaling\n" +
+			"staging   horizontalpodautoscalers.v1.autoscaling\n" +
+			"staging   horizontalpodautoscalers.v2beta2.autoscaling\n",
+	}.Test)
+}
+
+type runAPIResourceVersionsContextsTest struct {
+	output            string
+	showServerVersion bool
+	want              string
+}
+
+func (tt runAPIResourceVersionsContextsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	// Only the staging cluster reports its version, the version of the prod cluster is unknown.
+	staging := discoverytesting.New()
+	staging.DiscoveryInterface.(*fake.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.33.1"}
+
+	builder := NewTestOptionsBuilder().
+		SetOutput(tt.output).
+		SetShowServerVersion(tt.showServerVersion).
+		SetAPIGroup("autoscaling").
+		WithCluster("staging", staging).
+		WithCluster("prod", discoverytesting.New())
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+}
@@ -0,0 +1,485 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// defaultReportConfigMapName is the name of the ConfigMap to which the controller writes the report by default.
+	defaultReportConfigMapName = "api-resource-versions"
+	// defaultControllerInterval is the interval at which the controller writes the report by default.
+	defaultControllerInterval = 5 * time.Minute
+	// defaultControllerHealthAddress is the address on which the controller serves its health endpoints by default.
+	defaultControllerHealthAddress = ":8081"
+	// reportConfigMapKey is the key of the report in the data of the ConfigMap.
+	reportConfigMapKey = "report.json"
+	// managedByLabel is the well-known label identifying the tool managing an object.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	// managedByValue is the value of the [managedByLabel] for the objects managed by the controller.
+	managedByValue = "kubectl-api-resource-versions"
+)
+
+var (
+	// controllerExample is the example text for the controller command.
+	//
+	//nolint:gochecknoglobals
+	controllerExample = `
+		# Write the API resource versions of the cluster to a ConfigMap in the monitoring namespace every 5 minutes
+		kubectl api-resource-versions controller --namespace=monitoring
+
+		# Write the report once, e.g. from a CronJob
+		kubectl api-resource-versions controller --namespace=monitoring --configmap-name=api-inventory --once`
+)
+
+// newCmdController returns a command that periodically writes the API resource versions to a ConfigMap.
+func newCmdController(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newControllerOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "controller",
+		Short: "Periodically write the API resource versions to a ConfigMap",
+		Long: "Run a controller, usually in the cluster, which discovers the API resources and their group versions " +
+			"every --refresh-interval, and writes them as a JSON report to the " + reportConfigMapKey + " key of a ConfigMap " +
+			"in the namespace given with --namespace.\n" +
+			"The report has the same format as the /resources endpoint of the serve command, so GitOps tools and " +
+			"dashboards can consume a continuously updated inventory of the API.\n" +
+			"Unless --emit-events=false, Events are emitted on the ConfigMap when group versions appear or " +
+			"disappear, or when a deprecated version starts being served, compared with the previous report.\n" +
+			"Unless --once is set, the health endpoints are served at /healthz and /readyz on --health-address, " +
+			"the latter failing until the report is written and whenever the last write failed.\n" +
+			"On SIGTERM or an interrupt, the controller stops writing the report and shuts the health endpoints " +
+			"down gracefully.",
+		Example: templates.Examples(controllerExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			checkErr(cmd, runController(ctx, options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.ConfigMapName, "configmap-name", options.ConfigMapName,
+		"Name of the ConfigMap to which the report is written.")
+	cmd.Flags().DurationVar(&options.RefreshInterval, "refresh-interval", options.RefreshInterval,
+		"Interval at which the report is written.")
+	cmd.Flags().Float64Var(&options.RefreshJitter, "refresh-jitter", options.RefreshJitter,
+		"Maximum factor of --refresh-interval by which each write of the report is randomly delayed, between 0 and 1.")
+	cmd.Flags().StringVar(&options.HealthAddress, "health-address", options.HealthAddress,
+		"Address on which to serve the /healthz and /readyz health endpoints. Pass an empty address to disable them.")
+	cmd.Flags().BoolVar(&options.Once, "once", options.Once,
+		"Write the report once and exit.")
+	cmd.Flags().BoolVar(&options.EmitEvents, "emit-events", options.EmitEvents,
+		"Emit Events on the ConfigMap when group versions appear or disappear, or when a deprecated version starts "+
+			"being served.")
+	options.Notify.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// controllerOptions contains the options for the controller command.
+type controllerOptions struct {
+	genericiooptions.IOStreams
+
+	ConfigMapName   string
+	RefreshInterval time.Duration
+	RefreshJitter   float64
+	HealthAddress   string
+	Once            bool
+	EmitEvents      bool
+	Notify          notifyOptions
+
+	contextName     string
+	namespace       string
+	discoveryClient discovery.CachedDiscoveryInterface
+	client          kubernetes.Interface
+}
+
+// newControllerOptions returns a new [controllerOptions] with default values.
+func newControllerOptions(ioStreams genericiooptions.IOStreams) *controllerOptions {
+	return &controllerOptions{
+		IOStreams:       ioStreams,
+		ConfigMapName:   defaultReportConfigMapName,
+		RefreshInterval: defaultControllerInterval,
+		RefreshJitter:   defaultRefreshJitter,
+		HealthAddress:   defaultControllerHealthAddress,
+		EmitEvents:      true,
+		Notify:          newNotifyOptions(),
+	}
+}
+
+// complete completes all the required options for the controller command.
+func (o *controllerOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	namespace, _, err := restClientGetter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("couldn't get namespace: %w", err)
+	}
+
+	o.namespace = namespace
+	o.contextName = contextName(restClientGetter)
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.client, err = kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create client: %w", err)
+	}
+
+	return nil
+}
+
+// errConfigMapName is returned when the --configmap-name value is empty.
+const errConfigMapName = constError("configmap-name must not be empty")
+
+// validate checks that options are valid for the controller command.
+func (o *controllerOptions) validate() error {
+	if o.ConfigMapName == "" {
+		return errConfigMapName
+	}
+
+	if o.RefreshInterval <= 0 {
+		return fmt.Errorf("%w: got %s", errRefreshInterval, o.RefreshInterval)
+	}
+
+	err := validateJitter(o.RefreshJitter)
+	if err != nil {
+		return err
+	}
+
+	return o.Notify.validate()
+}
+
+// runController writes the report every interval until the context is done, or once if --once is set.
+// The health endpoints are served alongside, unless --once is set or the health address is empty, and the controller
+// only stops early if they can't be served: the failed writes are retried at the next refresh.
+func runController(ctx context.Context, options *controllerOptions) error {
+	if options.Once {
+		return writeReportConfigMap(ctx, options)
+	}
+
+	health := &controllerHealth{err: errReportNotWritten}
+	errGroup, ctx := errgroup.WithContext(ctx)
+
+	if options.HealthAddress != "" {
+		server := newHTTPServer(options.HealthAddress, health.mux())
+
+		errGroup.Go(func() error {
+			return runHTTPServer(ctx, server, server.ListenAndServe)
+		})
+	}
+
+	errGroup.Go(func() error {
+		// A failed first write, e.g. before the RBAC of the controller is propagated, is retried at the next refresh
+		// like the later ones, with /readyz failing until then.
+		err := health.record(writeReportConfigMap(ctx, options))
+		if err != nil {
+			_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't write the report: %v\n", err)
+		}
+
+		refreshEvery(ctx, options.RefreshInterval, options.RefreshJitter, options.ErrOut, func(ctx context.Context) error {
+			return health.record(writeReportConfigMap(ctx, options))
+		})
+
+		return nil
+	})
+
+	//nolint:wrapcheck
+	return errGroup.Wait()
+}
+
+// errReportNotWritten is reported by the readiness endpoint of the controller until the report is written.
+const errReportNotWritten = constError("report not written")
+
+// controllerHealth is the health of the controller, reported by its health endpoints.
+type controllerHealth struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// record records the error of the last write of the report, nil if it succeeded, and returns it.
+func (h *controllerHealth) record(err error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err = err
+
+	return err
+}
+
+// mux returns the HTTP handler serving the health endpoints of the controller.
+func (h *controllerHealth) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, nil)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		h.mu.RLock()
+		err := h.err
+		h.mu.RUnlock()
+
+		writeHealth(w, err)
+	})
+
+	return mux
+}
+
+// newReport discovers the API resources and returns the report of the controller, and the kinds served by the
+// cluster.
+func newReport(
+	ctx context.Context,
+	discoveryClient discovery.CachedDiscoveryInterface,
+) (*inventory, *servedKinds, error) {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = discoveryClient
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &inventory{RefreshTime: time.Now().UTC(), Resources: make([]inventoryResource, 0, len(resources))}
+	for _, resource := range resources {
+		report.Resources = append(report.Resources, newInventoryResource(resource))
+	}
+
+	return report, newServedKinds(resources, discoveryClient), nil
+}
+
+// writeReportConfigMap discovers the API resources, and creates or updates the ConfigMap with the report.
+func writeReportConfigMap(ctx context.Context, options *controllerOptions) error {
+	report, kinds, err := newReport(ctx, options.discoveryClient)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("couldn't encode the report: %w", err)
+	}
+
+	configMaps := options.client.CoreV1().ConfigMaps(options.namespace)
+
+	configMap, err := configMaps.Get(ctx, options.ConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      options.ConfigMapName,
+				Namespace: options.namespace,
+				Labels:    map[string]string{managedByLabel: managedByValue},
+			},
+			Data: map[string]string{reportConfigMapKey: string(content)},
+		}
+
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("couldn't create ConfigMap %s/%s: %w", options.namespace, options.ConfigMapName, err)
+		}
+
+		// There is no previous report to compare with.
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("couldn't get ConfigMap %s/%s: %w", options.namespace, options.ConfigMapName, err)
+	}
+
+	previous := &inventory{}
+
+	previousErr := json.Unmarshal([]byte(configMap.Data[reportConfigMapKey]), previous)
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string, 1)
+	}
+
+	configMap.Data[reportConfigMapKey] = string(content)
+
+	configMap, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("couldn't update ConfigMap %s/%s: %w", options.namespace, options.ConfigMapName, err)
+	}
+
+	if previousErr != nil {
+		// The previous report is missing or can't be read, e.g. it was written by another version.
+		return nil
+	}
+
+	changes := diffReports(previous, report, kinds)
+
+	if options.EmitEvents {
+		err = emitReportEvents(ctx, options.client, configMap, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return options.Notify.notify(ctx, options.contextName, changes)
+}
+
+const (
+	// reasonGroupVersionAdded is the reason of the Event emitted when a group version appears.
+	reasonGroupVersionAdded = "GroupVersionAdded"
+	// reasonGroupVersionRemoved is the reason of the Event emitted when a group version disappears.
+	reasonGroupVersionRemoved = "GroupVersionRemoved"
+	// reasonDeprecatedVersionServed is the reason of the Event emitted when a deprecated version starts being served.
+	reasonDeprecatedVersionServed = "DeprecatedVersionServed"
+)
+
+// reportChange is a change of the API between two reports, emitted as an Event.
+type reportChange struct {
+	// Type is the type of the Event, either Normal or Warning.
+	Type string `json:"type"`
+	// Reason is the reason of the Event.
+	Reason string `json:"reason"`
+	// Message is the message of the Event.
+	Message string `json:"message"`
+}
+
+// diffReports returns the group versions which appear or disappear between the previous and current reports, and the
+// deprecated versions of the kinds which are served in the current report but not in the previous one.
+func diffReports(previous, current *inventory, kinds *servedKinds) []reportChange {
+	previousGroupVersions, previousKinds := reportGroupVersionKinds(previous)
+	currentGroupVersions, currentKinds := reportGroupVersionKinds(current)
+
+	var changes []reportChange
+
+	for _, groupVersion := range sets.List(currentGroupVersions.Difference(previousGroupVersions)) {
+		changes = append(changes, reportChange{
+			Type:    corev1.EventTypeNormal,
+			Reason:  reasonGroupVersionAdded,
+			Message: fmt.Sprintf("Group version %s is now served", groupVersion),
+		})
+	}
+
+	for _, groupVersion := range sets.List(previousGroupVersions.Difference(currentGroupVersions)) {
+		changes = append(changes, reportChange{
+			Type:    corev1.EventTypeWarning,
+			Reason:  reasonGroupVersionRemoved,
+			Message: fmt.Sprintf("Group version %s is no longer served", groupVersion),
+		})
+	}
+
+	servedKinds := currentKinds.Difference(previousKinds).UnsortedList()
+	slices.SortFunc(servedKinds, func(a, b schema.GroupVersionKind) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, gvk := range servedKinds {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+
+		if !kinds.check(manifestLocation{}, obj).Status.deprecated() {
+			continue
+		}
+
+		changes = append(changes, reportChange{
+			Type:    corev1.EventTypeWarning,
+			Reason:  reasonDeprecatedVersionServed,
+			Message: fmt.Sprintf("Deprecated version %s of %s is now served", gvk.GroupVersion(), gvk.Kind),
+		})
+	}
+
+	return changes
+}
+
+// reportGroupVersionKinds returns the group versions and the kinds of the resources in the report.
+func reportGroupVersionKinds(report *inventory) (sets.Set[string], sets.Set[schema.GroupVersionKind]) {
+	groupVersions := sets.New[string]()
+	kinds := sets.New[schema.GroupVersionKind]()
+
+	for _, resource := range report.Resources {
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		groupVersions.Insert(gvk.GroupVersion().String())
+		kinds.Insert(gvk)
+	}
+
+	return groupVersions, kinds
+}
+
+// emitReportEvents emits an Event on the ConfigMap for each change.
+func emitReportEvents(
+	ctx context.Context,
+	client kubernetes.Interface,
+	configMap *corev1.ConfigMap,
+	changes []reportChange,
+) error {
+	now := metav1.Now()
+
+	for i, change := range changes {
+		event := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				// Events are named after the involved object and a timestamp, like the event recorders do.
+				Name:      fmt.Sprintf("%s.%x", configMap.Name, now.UnixNano()+int64(i)),
+				Namespace: configMap.Namespace,
+			},
+			InvolvedObject: corev1.ObjectReference{
+				APIVersion:      "v1",
+				Kind:            "ConfigMap",
+				Namespace:       configMap.Namespace,
+				Name:            configMap.Name,
+				UID:             configMap.UID,
+				ResourceVersion: configMap.ResourceVersion,
+			},
+			Type:           change.Type,
+			Reason:         change.Reason,
+			Message:        change.Message,
+			Source:         corev1.EventSource{Component: managedByValue},
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+		}
+
+		_, err := client.CoreV1().Events(configMap.Namespace).Create(ctx, event, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("couldn't emit %s Event: %w", change.Reason, err)
+		}
+	}
+
+	return nil
+}
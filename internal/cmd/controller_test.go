@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newControllerTestOptions returns [controllerOptions] using the test discovery client and a fake client.
+func newControllerTestOptions(objects ...runtime.Object) *controllerOptions {
+	options := newControllerOptions(genericiooptions.NewTestIOStreamsDiscard())
+	options.namespace = "monitoring"
+	options.discoveryClient = discoverytesting.New()
+	options.client = kubernetesfake.NewClientset(objects...)
+
+	return options
+}
+
+// TestValidateControllerOptions tests validation of the controller command options.
+func TestValidateControllerOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		configMapName string
+		interval      time.Duration
+		jitter        float64
+		wantErr       error
+	}{
+		{name: "Default", configMapName: defaultReportConfigMapName, interval: defaultControllerInterval,
+			jitter: defaultRefreshJitter},
+		{name: "NoConfigMapName", interval: defaultControllerInterval, wantErr: errConfigMapName},
+		{name: "ZeroInterval", configMapName: defaultReportConfigMapName, wantErr: errRefreshInterval},
+		{name: "NoJitter", configMapName: defaultReportConfigMapName, interval: defaultControllerInterval},
+		{name: "NegativeJitter", configMapName: defaultReportConfigMapName, interval: defaultControllerInterval,
+			jitter: -0.1, wantErr: errRefreshJitter},
+		{name: "LargeJitter", configMapName: defaultReportConfigMapName, interval: defaultControllerInterval,
+			jitter: 1.5, wantErr: errRefreshJitter},
+	}
+
+	for _, tt := range tests {
+		options := newControllerOptions(genericiooptions.NewTestIOStreamsDiscard())
+		options.ConfigMapName = tt.configMapName
+		options.RefreshInterval = tt.interval
+		options.RefreshJitter = tt.jitter
+
+		err := options.validate()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestWriteReportConfigMap tests writing the report to a ConfigMap.
+func TestWriteReportConfigMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create", writeReportConfigMapTest{}.Test)
+	t.Run("Update", writeReportConfigMapTest{
+		existing: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultReportConfigMapName, Namespace: "monitoring"},
+			Data:       map[string]string{"other": "kept", reportConfigMapKey: "{}"},
+		},
+		wantOther: "kept",
+	}.Test)
+}
+
+// TestControllerEvents tests emitting Events for the changes between the previous and current reports.
+func TestControllerEvents(t *testing.T) {
+	t.Parallel()
+
+	current, _, err := newReport(t.Context(), discoverytesting.New())
+	if err != nil {
+		t.Fatalf("newReport() error = %v", err)
+	}
+
+	previous := inventory{Resources: []inventoryResource{
+		{Name: "cronjobs", Group: "batch", Version: "v1beta1", Kind: "CronJob", Namespaced: true},
+	}}
+	for _, resource := range current.Resources {
+		if resource.Group != "autoscaling" {
+			previous.Resources = append(previous.Resources, resource)
+		}
+	}
+
+	previousContent, err := json.Marshal(previous)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	t.Run("Changes", controllerEventsTest{
+		previous:   string(previousContent),
+		emitEvents: true,
+		want: []string{
+			"Normal GroupVersionAdded Group version autoscaling/v1 is now served",
+			"Normal GroupVersionAdded Group version autoscaling/v2 is now served",
+			"Normal GroupVersionAdded Group version autoscaling/v2beta2 is now served",
+			"Warning GroupVersionRemoved Group version batch/v1beta1 is no longer served",
+			"Warning DeprecatedVersionServed Deprecated version autoscaling/v2beta2 of HorizontalPodAutoscaler is now " +
+				"served",
+		},
+	}.Test)
+	t.Run("Disabled", controllerEventsTest{
+		previous: string(previousContent),
+	}.Test)
+	t.Run("InvalidPreviousReport", controllerEventsTest{
+		previous:   "not json",
+		emitEvents: true,
+	}.Test)
+}
+
+type controllerEventsTest struct {
+	previous   string
+	emitEvents bool
+	want       []string
+}
+
+func (tt controllerEventsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	options := newControllerTestOptions(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultReportConfigMapName, Namespace: "monitoring"},
+		Data:       map[string]string{reportConfigMapKey: tt.previous},
+	})
+	options.Once = true
+	options.EmitEvents = tt.emitEvents
+
+	err := runController(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runController() error = %v", err)
+	}
+
+	events, err := options.client.CoreV1().Events("monitoring").List(t.Context(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var got []string
+
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name != defaultReportConfigMapName {
+			t.Errorf("event involved object = %s, want %s", event.InvolvedObject.Name, defaultReportConfigMapName)
+		}
+
+		got = append(got, event.Type+" "+event.Reason+" "+event.Message)
+	}
+
+	if !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("events = %q, want %q", got, tt.want)
+	}
+}
+
+type writeReportConfigMapTest struct {
+	existing  *corev1.ConfigMap
+	wantOther string
+}
+
+func (tt writeReportConfigMapTest) Test(t *testing.T) {
+	t.Parallel()
+
+	var objects []runtime.Object
+	if tt.existing != nil {
+		objects = append(objects, tt.existing)
+	}
+
+	options := newControllerTestOptions(objects...)
+	options.Once = true
+
+	err := runController(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runController() error = %v", err)
+	}
+
+	configMap, err := options.client.CoreV1().ConfigMaps("monitoring").
+		Get(t.Context(), defaultReportConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	report := inventory{}
+
+	err = json.Unmarshal([]byte(configMap.Data[reportConfigMapKey]), &report)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if report.RefreshTime.IsZero() {
+		t.Errorf("report refreshTime is zero")
+	}
+
+	// The test discovery serves 13 resource versions, excluding subresources.
+	if got, want := len(report.Resources), 13; got != want {
+		t.Errorf("len(report.Resources) = %d, want %d", got, want)
+	}
+
+	if got := configMap.Data["other"]; got != tt.wantOther {
+		t.Errorf("ConfigMap data other = %q, want %q", got, tt.wantOther)
+	}
+}
+
+// TestControllerHealth tests the readiness endpoint of the controller before and after the report is written.
+func TestControllerHealth(t *testing.T) {
+	t.Parallel()
+
+	health := &controllerHealth{err: errReportNotWritten}
+
+	server := httptest.NewServer(health.mux())
+	defer server.Close()
+
+	getStatus := func(path string) int {
+		resp, err := http.Get(server.URL + path) //nolint:noctx
+		if err != nil {
+			t.Fatalf("http.Get() error = %v", err)
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	if got := getStatus("/healthz"); got != http.StatusOK {
+		t.Errorf("healthz status = %d, want %d", got, http.StatusOK)
+	}
+
+	if got := getStatus("/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("readyz status before the report = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	_ = health.record(nil)
+
+	if got := getStatus("/readyz"); got != http.StatusOK {
+		t.Errorf("readyz status after the report = %d, want %d", got, http.StatusOK)
+	}
+
+	err := health.record(errConfigMapName)
+	if !errors.Is(err, errConfigMapName) {
+		t.Errorf("record() error = %v, want %v", err, errConfigMapName)
+	}
+
+	if got := getStatus("/readyz"); got != http.StatusServiceUnavailable {
+		t.Errorf("readyz status after a failed report = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
+
+// TestRunControllerFirstWriteFailed tests that the controller keeps running, not ready, when the first write of the
+// report fails, e.g. before its RBAC is propagated.
+func TestRunControllerFirstWriteFailed(t *testing.T) {
+	t.Parallel()
+
+	options := newControllerTestOptions()
+	options.HealthAddress = ""
+	options.RefreshInterval = time.Hour
+
+	stderr := &bytes.Buffer{}
+	options.ErrOut = stderr
+
+	client := kubernetesfake.NewClientset()
+	client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("configmaps"), defaultReportConfigMapName,
+			errors.New("RBAC not propagated"))
+	})
+	options.client = client
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	err := runController(ctx, options)
+	if err != nil {
+		t.Fatalf("runController() error = %v, want nil once the context is done", err)
+	}
+
+	if !strings.Contains(stderr.String(), "Warning: couldn't write the report") {
+		t.Errorf("runController() stderr = %q, want a warning about the failed write", stderr.String())
+	}
+}
+
+// TestRunControllerHealthAddress tests that the controller stops when its health endpoints can't be served.
+func TestRunControllerHealthAddress(t *testing.T) {
+	t.Parallel()
+
+	options := newControllerTestOptions()
+	options.HealthAddress = "invalid address"
+
+	err := runController(t.Context(), options)
+	if err == nil {
+		t.Error("runController() error = nil, want an error")
+	}
+}
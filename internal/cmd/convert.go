@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// convertExample is the example text for the convert command.
+	//
+	//nolint:gochecknoglobals
+	convertExample = `
+		# Convert the manifests of a directory to the preferred API versions of the cluster
+		kubectl api-resource-versions convert -f manifests/ > converted.yaml
+
+		# Convert the manifests rendered by Helm
+		helm template my-chart | kubectl api-resource-versions convert -f -`
+)
+
+// errNoConvertFilenames is returned when no manifests are given to the convert command.
+const errNoConvertFilenames = constError("at least one filename is required")
+
+// errNoServedVersion is returned when neither the kind of a manifest nor its replacement are served by the cluster.
+const errNoServedVersion = constError("no served version for")
+
+// errNotConverted is returned when some of the manifests couldn't be converted to the preferred version.
+const errNotConverted = constError("some manifests couldn't be converted, and were written unchanged")
+
+// newCmdConvert returns a command that rewrites manifests in the preferred API versions of the cluster.
+func newCmdConvert(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newConvertOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "convert -f FILENAME",
+		Short: "Rewrite manifests in the preferred API versions",
+		Long: "Rewrite the manifests whose API version isn't the preferred version of their kind in the cluster, or " +
+			"which use a removed API version with a known replacement, in the preferred version, filling the gap " +
+			"left by the removal of kubectl convert.\n" +
+			"The API server only converts the objects which it stores, so each rewritten manifest is sent to the " +
+			"server in a dry-run create with strict field validation, which rejects the fields which don't exist or " +
+			"aren't valid in the preferred version. The manifests rejected by the server are written unchanged and " +
+			"listed in the error, to be converted by hand.\n" +
+			"The manifests are written to stdout as a stream of YAML documents, with the items of lists expanded. " +
+			"The comments and the order of the fields of the manifests aren't kept.",
+		Example: templates.Examples(convertExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runConvert(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&options.Filenames, "filename", "f", options.Filenames,
+		"Filename, directory, or - for stdin, of the manifests to convert.")
+	options.Walk.addFlags(cmd.Flags())
+	options.Decode.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// convertOptions contains the options for the convert command.
+type convertOptions struct {
+	genericiooptions.IOStreams
+
+	Filenames []string
+	Walk      manifestWalkOptions
+	Decode    manifestDecodeOptions
+
+	// namespace is the namespace in which the manifests without a namespace are validated.
+	namespace       string
+	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newConvertOptions returns a new [convertOptions] with default values.
+func newConvertOptions(ioStreams genericiooptions.IOStreams) *convertOptions {
+	return &convertOptions{
+		IOStreams: ioStreams,
+		Decode:    newManifestDecodeOptions(),
+	}
+}
+
+// complete completes all the required options for the convert command.
+func (o *convertOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	namespace, _, err := restClientGetter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("couldn't get namespace: %w", err)
+	}
+
+	o.namespace = namespace
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// validate checks that options are valid for the convert command.
+func (o *convertOptions) validate() error {
+	if len(o.Filenames) == 0 {
+		return errNoConvertFilenames
+	}
+
+	err := o.Decode.validate()
+	if err != nil {
+		return err
+	}
+
+	return o.Walk.validate()
+}
+
+// convertTargets indexes the preferred version of each kind served by the cluster.
+func convertTargets(resources []groupResource) map[schema.GroupKind]kindResource {
+	targets := make(map[schema.GroupKind]kindResource)
+
+	for _, resource := range resources {
+		if !resource.Preferred || resource.Subresource {
+			continue
+		}
+
+		gvr := resource.groupVersionResource()
+		targets[schema.GroupKind{Group: gvr.Group, Kind: resource.APIResource.Kind}] = kindResource{
+			GroupVersionResource: gvr,
+			Namespaced:           resource.APIResource.Namespaced,
+		}
+	}
+
+	return targets
+}
+
+// findConvertTarget returns the preferred version of the kind of the manifest, or of the replacement of its API
+// version from the lifecycle database if the kind isn't served anymore, e.g. apps/v1 for the Deployments of
+// extensions/v1beta1.
+func findConvertTarget(
+	targets map[schema.GroupKind]kindResource,
+	gvk schema.GroupVersionKind,
+) (kindResource, error) {
+	target, ok := targets[gvk.GroupKind()]
+	if ok {
+		return target, nil
+	}
+
+	api, known := lifecycle.Lookup(gvk)
+	if known && api.Replacement != "" {
+		replacement, err := schema.ParseGroupVersion(api.Replacement)
+		if err == nil {
+			target, ok = targets[replacement.WithKind(gvk.Kind).GroupKind()]
+			if ok {
+				return target, nil
+			}
+		}
+	}
+
+	return kindResource{}, fmt.Errorf("%w %s", errNoServedVersion, gvk.GroupKind())
+}
+
+// runConvert writes the manifests in the preferred versions of their kinds.
+func runConvert(ctx context.Context, options *convertOptions) error {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = options.discoveryClient
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	targets := convertTargets(resources)
+
+	var (
+		manifests []*unstructured.Unstructured
+		errs      []error
+	)
+
+	collect := func(location manifestLocation, obj *unstructured.Unstructured) {
+		converted, err := convertManifest(ctx, options, targets, obj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", location, err))
+			converted = obj
+		}
+
+		manifests = append(manifests, converted)
+	}
+
+	for _, filename := range options.Filenames {
+		err := forEachManifest(filename, options.In, &options.Walk, options.Decode.decodeOptions(), collect)
+		if err != nil {
+			return err
+		}
+	}
+
+	encoder := yamlutil.NewDocumentEncoder(options.Out)
+
+	for _, manifest := range manifests {
+		err := encoder.Encode(manifest.Object)
+		if err != nil {
+			return fmt.Errorf("couldn't write manifest: %w", err)
+		}
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't write manifests: %w", err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w:\n%w", errNotConverted, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// convertManifest returns the manifest in the preferred version of its kind, once the API server accepted it in a
+// dry-run create.
+// Manifests already in the preferred version are returned unchanged, without being sent to the API server.
+func convertManifest(
+	ctx context.Context,
+	options *convertOptions,
+	targets map[schema.GroupKind]kindResource,
+	obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	target, err := findConvertTarget(targets, obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersion := target.GroupVersionResource.GroupVersion().String()
+	if obj.GetAPIVersion() == apiVersion {
+		return obj, nil
+	}
+
+	converted := obj.DeepCopy()
+	converted.SetAPIVersion(apiVersion)
+
+	err = dryRunCreate(ctx, options.dynamicClient, target, options.namespace, converted)
+	if err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// TestRunConvert tests rewriting manifests in the preferred versions of their kinds.
+func TestRunConvert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Served", runConvertTest{
+		manifests: "apiVersion: autoscaling/v1\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: web\n" +
+			"spec:\n" +
+			"  maxReplicas: 3\n" +
+			"---\n" +
+			"apiVersion: v1\n" +
+			"kind: Pod\n" +
+			"metadata:\n" +
+			"  name: web\n",
+		want: "apiVersion: autoscaling/v2\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: web\n" +
+			"spec:\n" +
+			"  maxReplicas: 3\n" +
+			"---\n" +
+			"apiVersion: v1\n" +
+			"kind: Pod\n" +
+			"metadata:\n" +
+			"  name: web\n",
+		wantCreates: []string{"default/web"},
+	}.Test)
+	// The name of the object is only found to be taken once it has been validated.
+	t.Run("AlreadyExists", runConvertTest{
+		manifests: "apiVersion: autoscaling/v2beta2\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: existing\n" +
+			"  namespace: web\n",
+		want: "apiVersion: autoscaling/v2\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: existing\n" +
+			"  namespace: web\n",
+		wantCreates: []string{"web/existing"},
+	}.Test)
+	t.Run("Rejected", runConvertTest{
+		manifests: "apiVersion: autoscaling/v1\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: invalid\n" +
+			"spec:\n" +
+			"  targetCPUUtilizationPercentage: 80\n",
+		want: "apiVersion: autoscaling/v1\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: invalid\n" +
+			"spec:\n" +
+			"  targetCPUUtilizationPercentage: 80\n",
+		wantCreates: []string{"default/invalid"},
+		wantErr:     errNotConverted,
+	}.Test)
+	t.Run("NotServed", runConvertTest{
+		manifests: "apiVersion: apps/v1\n" +
+			"kind: Deployment\n" +
+			"metadata:\n" +
+			"  name: web\n",
+		want: "apiVersion: apps/v1\n" +
+			"kind: Deployment\n" +
+			"metadata:\n" +
+			"  name: web\n",
+		wantErr: errNoServedVersion,
+	}.Test)
+}
+
+type runConvertTest struct {
+	manifests   string
+	want        string
+	wantCreates []string
+	wantErr     error
+}
+
+func (tt runConvertTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, stdin, stdout, _ := genericiooptions.NewTestIOStreams()
+	stdin.WriteString(tt.manifests)
+
+	dynamicClient := discoverytesting.NewDynamic()
+	dynamicClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, _ := action.(clienttesting.CreateAction).GetObject().(metav1.Object)
+
+		switch obj.GetName() {
+		case "existing":
+			return true, nil, apierrors.NewAlreadyExists(schema.GroupResource{}, obj.GetName())
+		case "invalid":
+			return true, nil, apierrors.NewBadRequest(
+				`strict decoding error: unknown field "spec.targetCPUUtilizationPercentage"`)
+		default:
+			return false, nil, nil
+		}
+	})
+
+	options := newConvertOptions(ioStreams)
+	options.Filenames = []string{stdinFilename}
+	options.namespace = "default"
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = dynamicClient
+
+	err := runConvert(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Errorf("runConvert() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runConvert() output = %q, want %q", got, tt.want)
+	}
+
+	var creates []string
+
+	for _, action := range dynamicClient.Actions() {
+		create, ok := action.(clienttesting.CreateActionImpl)
+		if !ok {
+			continue
+		}
+
+		if len(create.CreateOptions.DryRun) != 1 || create.CreateOptions.DryRun[0] != metav1.DryRunAll ||
+			create.CreateOptions.FieldValidation != metav1.FieldValidationStrict {
+			t.Errorf("runConvert() create options = %+v, want a strict dry-run", create.CreateOptions)
+		}
+
+		obj, _ := create.GetObject().(metav1.Object)
+		creates = append(creates, create.GetNamespace()+"/"+obj.GetName())
+	}
+
+	if !slices.Equal(creates, tt.wantCreates) {
+		t.Errorf("runConvert() created %v, want %v", creates, tt.wantCreates)
+	}
+}
+
+// TestFindConvertTarget tests converting the manifests of removed API versions to the preferred version of their
+// replacement.
+func TestFindConvertTarget(t *testing.T) {
+	t.Parallel()
+
+	appsV1 := kindResource{
+		GroupVersionResource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		Namespaced:           true,
+	}
+	targets := map[schema.GroupKind]kindResource{{Group: "apps", Kind: "Deployment"}: appsV1}
+
+	target, err := findConvertTarget(targets, schema.GroupVersionKind{
+		Group: "extensions", Version: "v1beta1", Kind: "Deployment",
+	})
+	if err != nil || target != appsV1 {
+		t.Errorf("findConvertTarget() = %v, %v, want %v", target, err, appsV1)
+	}
+
+	_, err = findConvertTarget(targets, schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+	if !errors.Is(err, errNoServedVersion) {
+		t.Errorf("findConvertTarget() error = %v, want %v", err, errNoServedVersion)
+	}
+}
+
+// TestValidateConvertOptions tests validation of the convert command options.
+func TestValidateConvertOptions(t *testing.T) {
+	t.Parallel()
+
+	options := newConvertOptions(genericiooptions.NewTestIOStreamsDiscard())
+
+	err := options.validate()
+	if !errors.Is(err, errNoConvertFilenames) {
+		t.Errorf("validate() error = %v, want %v", err, errNoConvertFilenames)
+	}
+
+	options.Filenames = []string{stdinFilename}
+
+	err = options.validate()
+	if err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errCoreGroupName is returned when the name of the core group isn't a single path segment.
+const errCoreGroupName = constError("core-group-name must not contain '/' or '.'")
+
+// errCoreGroupNameOutput is returned when --core-group-name is requested with --output=name, name0, api-versions,
+// script, or gvk, which stay compatible with kubectl.
+const errCoreGroupNameOutput = constError("core-group-name has no effect with output=name, name0, api-versions, " +
+	"script, or gvk, which print the core group as kubectl expects: remove core-group-name")
+
+// validateCoreGroupName checks that the name of the core group is a valid group name, and that it's requested with
+// an output displaying it.
+func (o *apiResourceVersionsOptions) validateCoreGroupName() error {
+	if o.CoreGroupName == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(o.CoreGroupName, "/.") {
+		return fmt.Errorf("%w: got %s", errCoreGroupName, o.CoreGroupName)
+	}
+
+	if o.namesOnly() || o.Output == apiVersionsOutput || o.Output == scriptOutput || o.Output == gvkOutput {
+		return errCoreGroupNameOutput
+	}
+
+	return nil
+}
+
+// displayGroup returns the name of the group as displayed, with --core-group-name for the core group.
+func (o *apiResourceVersionsOptions) displayGroup(group string) string {
+	if group == "" {
+		return o.CoreGroupName
+	}
+
+	return group
+}
+
+// displayGroupVersion returns the group version as displayed, e.g. "core/v1" for "v1" with --core-group-name=core.
+func (o *apiResourceVersionsOptions) displayGroupVersion(groupVersion string) string {
+	if o.CoreGroupName == "" || strings.Contains(groupVersion, "/") {
+		return groupVersion
+	}
+
+	return o.CoreGroupName + "/" + groupVersion
+}
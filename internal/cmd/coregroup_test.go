@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunCoreGroupName tests displaying the core group under a name in the tables and the structured outputs.
+func TestRunCoreGroupName(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", runCoreGroupNameTest{
+		output: "",
+		want: "NAME         SHORTNAMES   APIVERSION   NAMESPACED   KIND        PREFERRED\n" +
+			"namespaces   ns           core/v1      false        Namespace   true\n",
+	}.Test)
+	t.Run("Mapping", runCoreGroupNameTest{
+		output: mappingOutput,
+		want: "APIVERSION   PLURAL       SINGULAR    KIND        SHORTNAMES\n" +
+			"core/v1      namespaces   namespace   Namespace   ns\n",
+	}.Test)
+}
+
+type runCoreGroupNameTest struct {
+	output string
+	want   string
+}
+
+func (tt runCoreGroupNameTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().
+		SetOutput(tt.output).
+		SetAPIGroup("").
+		SetVerbs([]string{"list"}).
+		SetCoreGroupName("core")
+	builder.APIResourceVersionsOptions().query = "namespace"
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+}
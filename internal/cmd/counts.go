@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// unknownCount is printed in place of the count when the objects for a resource could not be counted.
+const unknownCount = "<unknown>"
+
+const (
+	// defaultCountConcurrency is the default number of resources whose objects are counted concurrently.
+	defaultCountConcurrency = 8
+	// defaultCountChunkSize is the default number of objects listed per request when the API server doesn't report
+	// the remaining item count.
+	defaultCountChunkSize = 500
+)
+
+// errCountConcurrency is returned when the count concurrency is not positive.
+const errCountConcurrency = constError("count-concurrency must be positive")
+
+// errCountChunkSize is returned when the count chunk size is negative.
+const errCountChunkSize = constError("count-chunk-size must not be negative")
+
+// errCountTimeout is returned when the count timeout is negative.
+const errCountTimeout = constError("count-timeout must not be negative")
+
+// countString returns the count of objects for the resource as a string suitable for printing.
+func (gr groupResource) countString() string {
+	if gr.Count == nil {
+		return unknownCount
+	}
+
+	return strconv.FormatInt(*gr.Count, 10)
+}
+
+// countable returns true if the objects of the resource can be counted with a list request.
+func (gr groupResource) countable() bool {
+	return !gr.Subresource && sets.New(gr.APIResource.Verbs...).Has("list")
+}
+
+// countsRequired returns true if the objects of each resource must be counted, either to print or filter them.
+func (o *apiResourceVersionsOptions) countsRequired() bool {
+	return o.ShowCounts || o.EmptyOnly || o.NonEmptyOnly || o.Output == countsOutput
+}
+
+// excludeCountedResource checks if the resource should be excluded based on its count of objects and the options.
+func excludeCountedResource(resource groupResource, options *apiResourceVersionsOptions) bool {
+	if !options.EmptyOnly && !options.NonEmptyOnly {
+		return false
+	}
+
+	if resource.Count == nil {
+		// If the resource couldn't be counted, we can't tell whether it's empty.
+		return true
+	}
+
+	return options.EmptyOnly != (*resource.Count == 0)
+}
+
+// countGroupResources sets the approximate count of objects for each of the countable resources, counting up to
+// --count-concurrency resources concurrently.
+// Resources which cannot be counted, either because they don't support the list verb or because the list requests
+// failed, are left without a count and a warning is printed for failed requests, in the order of the resources.
+// With --output=counts, a single version of each resource is counted, and its count is shared with the other
+// versions.
+func countGroupResources(ctx context.Context, resources []groupResource, options *apiResourceVersionsOptions) {
+	var representatives map[countsSummaryKey]int
+	if options.Output == countsOutput {
+		representatives = countsSummaryRepresentatives(resources)
+	}
+
+	errs := make([]error, len(resources))
+
+	var errGroup errgroup.Group
+	errGroup.SetLimit(options.CountConcurrency)
+
+	for i := range resources {
+		resource := &resources[i]
+		if !resource.countable() {
+			continue
+		}
+
+		if j, ok := representatives[countsSummaryKeyOf(*resource)]; ok && j != i {
+			continue
+		}
+
+		errGroup.Go(func() error {
+			count, err := countObjects(ctx, *resource, options)
+			if err != nil {
+				errs[i] = err
+
+				return nil
+			}
+
+			resource.Count = &count
+
+			return nil
+		})
+	}
+
+	_ = errGroup.Wait()
+
+	if representatives != nil {
+		shareCounts(resources, representatives)
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			_, _ = fmt.Fprintf(options.ErrOut, "Warning: %v\n", err)
+		}
+	}
+}
+
+// countObjects returns the approximate count of objects for the resource across all namespaces, within
+// --count-timeout.
+// Only a single object is requested, the remaining item count reported by the API server is used to estimate the
+// total.
+// When the API server doesn't report it, e.g. some aggregated API servers, the remaining objects are listed in chunks
+// of --count-chunk-size, or all at once if it is 0, and counted.
+func countObjects(ctx context.Context, resource groupResource, options *apiResourceVersionsOptions) (int64, error) {
+	ctx, cancel := withCountTimeout(ctx, options.CountTimeout)
+	defer cancel()
+
+	resourceClient := options.dynamicClient.Resource(resource.groupVersionResource())
+	listOptions := metav1.ListOptions{Limit: 1}
+	count := int64(0)
+
+	for {
+		list, err := resourceClient.List(ctx, listOptions)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't count objects for %s: %w", resource.fullname(), err)
+		}
+
+		count += int64(len(list.Items))
+		if remaining := list.GetRemainingItemCount(); remaining != nil {
+			return count + *remaining, nil
+		}
+
+		listOptions.Continue = list.GetContinue()
+		if listOptions.Continue == "" {
+			return count, nil
+		}
+
+		listOptions.Limit = options.CountChunkSize
+	}
+}
+
+// withCountTimeout returns a context which is canceled after the timeout of counting the objects of a resource, or
+// the context itself if the timeout is 0.
+func withCountTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w: count-timeout %s", errTimeout, timeout))
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	restclient "k8s.io/client-go/rest"
+)
+
+// newUnstructured returns a new object with the given API version, kind, namespace, and name.
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+// TestCountGroupResources tests counting the objects of each resource.
+func TestCountGroupResources(t *testing.T) {
+	t.Parallel()
+
+	objects := []runtime.Object{
+		newUnstructured("v1", "Pod", "default", "pod-a"),
+		newUnstructured("v1", "Pod", "kube-system", "pod-b"),
+		newUnstructured("v1", "ConfigMap", "default", "config"),
+	}
+
+	t.Run("CoreCounts", countGroupResourcesTest{
+		objects:  objects,
+		apiGroup: "",
+		want: map[string]string{
+			"configmaps.v1.": "1",
+			"pods.v1.":       "2",
+			"namespaces.v1.": "0",
+			"secrets.v1.":    "0",
+			"nodes.v1.":      "0",
+			"services.v1.":   "0",
+			"events.v1.":     "0",
+		},
+	}.Test)
+	t.Run("SubresourcesNotCounted", countGroupResourcesTest{
+		objects:             objects,
+		apiGroup:            "",
+		includeSubresources: true,
+		want: map[string]string{
+			"pods.v1.":        "2",
+			"pods.v1. status": unknownCount,
+			"pods.v1. log":    unknownCount,
+		},
+	}.Test)
+}
+
+type countGroupResourcesTest struct {
+	objects             []runtime.Object
+	apiGroup            string
+	includeSubresources bool
+	want                map[string]string
+}
+
+func (tt countGroupResourcesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	options := NewTestOptionsBuilder().
+		WithDynamicClient(discoverytesting.NewDynamic(tt.objects...)).
+		SetAPIGroup(tt.apiGroup).
+		SetIncludeSubresources(tt.includeSubresources).
+		SetShowCounts(true).
+		APIResourceVersionsOptions()
+
+	resources, err := getGroupResources(t.Context(), options)
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	countGroupResources(t.Context(), resources, options)
+
+	got := make(map[string]string, len(resources))
+	for _, resource := range resources {
+		got[resource.fullname()] = resource.countString()
+	}
+
+	for name, want := range tt.want {
+		if got[name] != want {
+			t.Errorf("countGroupResources() %s = %q, want %q", name, got[name], want)
+		}
+	}
+}
+
+// TestCountObjectsChunks tests counting the objects in chunks when the API server doesn't report the remaining item
+// count.
+func TestCountObjectsChunks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Chunks", countObjectsChunksTest{chunkSize: 2, wantLimits: []int64{1, 2, 2}}.Test)
+	t.Run("AllAtOnce", countObjectsChunksTest{chunkSize: 0, wantLimits: []int64{1, 0}}.Test)
+}
+
+type countObjectsChunksTest struct {
+	chunkSize  int64
+	wantLimits []int64
+}
+
+func (tt countObjectsChunksTest) Test(t *testing.T) {
+	t.Parallel()
+
+	const total = 5
+
+	var (
+		mutex  sync.Mutex
+		limits []int64
+	)
+
+	// The pods are served in pages of the requested size, with a continue token but without a remaining item count.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
+		start, _ := strconv.Atoi(r.URL.Query().Get("continue"))
+
+		mutex.Lock()
+		limits = append(limits, limit)
+		mutex.Unlock()
+
+		end := total
+		if limit > 0 {
+			end = min(start+int(limit), total)
+		}
+
+		list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "v1", "kind": "PodList"}}
+		for i := start; i < end; i++ {
+			list.Items = append(list.Items, *newUnstructured("v1", "Pod", "default", "pod-"+strconv.Itoa(i)))
+		}
+
+		if end < total {
+			list.SetContinue(strconv.Itoa(end))
+		}
+
+		content, err := list.MarshalJSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	dynamicClient, err := dynamic.NewForConfig(&restclient.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := NewTestOptionsBuilder().
+		WithDynamicClient(dynamicClient).
+		SetCountChunkSize(tt.chunkSize).
+		APIResourceVersionsOptions()
+
+	pods := groupResource{
+		APIGroup:        &resourceGroup{},
+		APIGroupVersion: "v1",
+		APIResource:     &metav1.APIResource{Name: "pods", Verbs: []string{"list"}},
+	}
+
+	count, err := countObjects(t.Context(), pods, options)
+	if err != nil {
+		t.Fatalf("countObjects() error = %v", err)
+	}
+
+	if count != total {
+		t.Errorf("countObjects() = %d, want %d", count, total)
+	}
+
+	if !reflect.DeepEqual(limits, tt.wantLimits) {
+		t.Errorf("countObjects() limits = %v, want %v", limits, tt.wantLimits)
+	}
+}
+
+// TestRunWithCounts tests the COUNT column in the tabular output.
+func TestRunWithCounts(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().
+		WithDynamicClient(discoverytesting.NewDynamic(newUnstructured("v1", "Pod", "default", "pod-a"))).
+		SetAPIGroup("").
+		SetShowCounts(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	lines := strings.Split(stdout.String(), "\n")
+	if fields := strings.Fields(lines[0]); fields[len(fields)-1] != "COUNT" {
+		t.Errorf("runAPIResourceVersions() headers = %q, want COUNT as the last column", lines[0])
+	}
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "pods" && fields[len(fields)-1] != "1" {
+			t.Errorf("runAPIResourceVersions() pods row = %q, want a count of 1", line)
+		}
+	}
+}
+
+// TestRunWithCountFilters tests filtering resources by whether they have objects.
+func TestRunWithCountFilters(t *testing.T) {
+	t.Parallel()
+
+	objects := []runtime.Object{
+		newUnstructured("v1", "Pod", "default", "pod-a"),
+		newUnstructured("v1", "ConfigMap", "default", "config"),
+	}
+
+	t.Run("NonEmptyOnly", runWithCountFiltersTest{
+		builder: NewTestOptionsBuilder().
+			WithDynamicClient(discoverytesting.NewDynamic(objects...)).
+			SetAPIGroup("").
+			SetNonEmptyOnly(true),
+		want: "configmaps.v1.\npods.v1.\n",
+	}.Test)
+	t.Run("EmptyOnly", runWithCountFiltersTest{
+		builder: NewTestOptionsBuilder().
+			WithDynamicClient(discoverytesting.NewDynamic(objects...)).
+			SetAPIGroup("autoscaling").
+			SetEmptyOnly(true),
+		want: "horizontalpodautoscalers.v2.autoscaling\n" +
+			"horizontalpodautoscalers.v1.autoscaling\n" +
+			"horizontalpodautoscalers.v2beta2.autoscaling\n",
+	}.Test)
+	t.Run("NoneEmpty", runWithCountFiltersTest{
+		builder: NewTestOptionsBuilder().
+			// The fake dynamic client doesn't convert between versions, so each version is populated separately.
+			WithDynamicClient(discoverytesting.NewDynamic(
+				newUnstructured("autoscaling/v1", "HorizontalPodAutoscaler", "default", "hpa"),
+				newUnstructured("autoscaling/v2", "HorizontalPodAutoscaler", "default", "hpa"),
+				newUnstructured("autoscaling/v2beta2", "HorizontalPodAutoscaler", "default", "hpa"),
+			)).
+			SetAPIGroup("autoscaling").
+			SetEmptyOnly(true),
+		want: "",
+	}.Test)
+}
+
+type runWithCountFiltersTest struct {
+	builder *APIResourceVersionsOptionsBuilder
+	want    string
+}
+
+func (tt runWithCountFiltersTest) Test(t *testing.T) {
+	t.Parallel()
+
+	_, stdout, _ := tt.builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), tt.builder.SetOutput(nameOutput).APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if stdout.String() != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", stdout.String(), tt.want)
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// noPreferredVersion is printed in the PREFERRED column of the counts output when the preferred version of the
+// resource has been filtered out.
+const noPreferredVersion = "<none>"
+
+// errCountsMode is returned when --output=counts is requested with --watch, which prints the changes of the resources.
+// The --stream mode is rejected by [apiResourceVersionsOptions.validateStream], as it doesn't count the objects.
+const errCountsMode = constError("output=counts is not supported with watch")
+
+// errCountsColumns is returned when --output=counts is requested with flags adding columns.
+const errCountsColumns = constError("output=counts prints only the counts and the versions of the resources: " +
+	"remove show-counts, show-commands, show-min-k8s, show-server-version, show-versions-served, " +
+	"show-group-priority, and show-usage")
+
+// validateCounts checks that --output=counts isn't requested with flags which have no effect on it.
+func (o *apiResourceVersionsOptions) validateCounts() error {
+	if o.Output != countsOutput {
+		return nil
+	}
+
+	if o.Watch {
+		return errCountsMode
+	}
+
+	if o.ShowCounts || o.ShowCommands || o.ShowMinK8s || o.ShowServerVersion || o.ShowVersionsServed ||
+		o.ShowGroupPriority || o.ShowUsage {
+		return errCountsColumns
+	}
+
+	return nil
+}
+
+// countsSummaryKey identifies a resource across its versions in the counts output.
+type countsSummaryKey struct {
+	cluster  string
+	group    string
+	resource string
+}
+
+// countsSummaryKeyOf returns the key of the resource in the counts output.
+func countsSummaryKeyOf(resource groupResource) countsSummaryKey {
+	return countsSummaryKey{cluster: resource.Cluster, group: resource.APIGroup.Name, resource: resource.APIResource.Name}
+}
+
+// countsSummaryRepresentatives returns the index of the version counted for each countable resource with
+// --output=counts: the preferred version, or the first countable version if the preferred one has been filtered out.
+// The objects of a resource are the same in all of its versions, so a single version is counted.
+func countsSummaryRepresentatives(resources []groupResource) map[countsSummaryKey]int {
+	representatives := make(map[countsSummaryKey]int)
+
+	for i, resource := range resources {
+		if !resource.countable() {
+			continue
+		}
+
+		key := countsSummaryKeyOf(resource)
+		if j, ok := representatives[key]; !ok || (resource.Preferred && !resources[j].Preferred) {
+			representatives[key] = i
+		}
+	}
+
+	return representatives
+}
+
+// shareCounts sets the count of each version of the resources to the count of their counted version, see
+// [countsSummaryRepresentatives].
+func shareCounts(resources []groupResource, representatives map[countsSummaryKey]int) {
+	for i := range resources {
+		j, ok := representatives[countsSummaryKeyOf(resources[i])]
+		if ok && j != i {
+			resources[i].Count = resources[j].Count
+		}
+	}
+}
+
+// countsSummary is a row of the counts output, a resource with the count of its objects and its versions.
+type countsSummary struct {
+	cluster  string
+	name     string
+	count    string
+	versions []string
+	// preferred is the preferred version of the resource, empty if it has been filtered out.
+	preferred string
+}
+
+// groupResourceCountsSummaries returns the counts summaries of the resources, once for each resource of each cluster,
+// in the order of the resources.
+// Subresources are skipped, as their objects are those of their parent resource.
+func groupResourceCountsSummaries(resources []groupResource, options *apiResourceVersionsOptions) []*countsSummary {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	index := make(map[countsSummaryKey]*countsSummary, len(resources))
+	summaries := make([]*countsSummary, 0, len(resources))
+
+	for _, resource := range resources {
+		if resource.Subresource {
+			continue
+		}
+
+		version := resource.resource().GroupVersion.Version
+
+		key := countsSummaryKeyOf(resource)
+
+		summary, ok := index[key]
+		if !ok {
+			name := resource.APIResource.Name
+			if group := options.displayGroup(resource.APIGroup.Name); group != "" {
+				name += "." + group
+			}
+
+			summary = &countsSummary{cluster: resource.Cluster, name: name, count: resource.countString()}
+			index[key] = summary
+			summaries = append(summaries, summary)
+		}
+
+		summary.versions = append(summary.versions, version)
+
+		if resource.Preferred {
+			summary.preferred = version
+		}
+	}
+
+	return summaries
+}
+
+// printCountsSummaries prints a table of the resources with the count of their objects, the versions in which they
+// are served, and their preferred version, prefixed by their cluster when listing multiple contexts.
+func printCountsSummaries(resources []groupResource, options *apiResourceVersionsOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		headers := []string{"RESOURCE", "COUNT", "VERSIONS", "PREFERRED"}
+		if len(options.clusters) > 0 {
+			headers = append([]string{"CLUSTER"}, headers...)
+		}
+
+		err := printRow(writer, headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, summary := range groupResourceCountsSummaries(resources, options) {
+		preferred := summary.preferred
+		if preferred == "" {
+			preferred = noPreferredVersion
+		}
+
+		columns := []string{summary.name, summary.count, strings.Join(summary.versions, ","), preferred}
+		if len(options.clusters) > 0 {
+			columns = append([]string{summary.cluster}, columns...)
+		}
+
+		err := printRow(writer, columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
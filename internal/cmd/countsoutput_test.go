@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// TestPrintCountsSummaries tests printing the counts and the versions of each resource with --output=counts.
+func TestPrintCountsSummaries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", printCountsSummariesTest{
+		apiVersion: "autoscaling/v2",
+		want: "RESOURCE                               COUNT   VERSIONS        PREFERRED\n" +
+			"horizontalpodautoscalers.autoscaling   2       v2,v1,v2beta2   v2\n",
+	}.Test)
+	t.Run("NoHeaders", printCountsSummariesTest{
+		apiVersion: "autoscaling/v2",
+		noHeaders:  true,
+		want:       "horizontalpodautoscalers.autoscaling   2     v2,v1,v2beta2   v2\n",
+	}.Test)
+	// The first version is counted when the preferred version is filtered out.
+	t.Run("NonPreferred", printCountsSummariesTest{
+		apiVersion:   "autoscaling/v1",
+		nonPreferred: true,
+		want: "RESOURCE                               COUNT   VERSIONS     PREFERRED\n" +
+			"horizontalpodautoscalers.autoscaling   2       v1,v2beta2   <none>\n",
+	}.Test)
+}
+
+type printCountsSummariesTest struct {
+	// apiVersion is the version in which the fake dynamic client serves the objects, as it doesn't convert them.
+	apiVersion   string
+	noHeaders    bool
+	nonPreferred bool
+	want         string
+}
+
+func (tt printCountsSummariesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	dynamicClient := discoverytesting.NewDynamic(
+		newUnstructured(tt.apiVersion, "HorizontalPodAutoscaler", "default", "hpa-a"),
+		newUnstructured(tt.apiVersion, "HorizontalPodAutoscaler", "kube-system", "hpa-b"),
+	)
+
+	builder := NewTestOptionsBuilder().
+		WithDynamicClient(dynamicClient).
+		SetAPIGroup("autoscaling").
+		SetOutput(countsOutput).
+		SetNoHeaders(tt.noHeaders)
+	if tt.nonPreferred {
+		builder = builder.SetPreferred(false)
+	}
+
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() = %q, want %q", got, tt.want)
+	}
+
+	// A single version of the resource is counted.
+	lists := 0
+
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "list" && action.(clienttesting.ListActionImpl).GetResource().Resource ==
+			"horizontalpodautoscalers" {
+			lists++
+		}
+	}
+
+	if lists != 1 {
+		t.Errorf("runAPIResourceVersions() listed the objects %d times, want 1", lists)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// diffContexts is the number of contexts compared by the diff command.
+const diffContexts = 2
+
+var (
+	// diffExample is the example text for the diff command.
+	//
+	//nolint:gochecknoglobals
+	diffExample = `
+		# Compare the API resource versions served by the production and staging clusters
+		kubectl api-resource-versions diff --context=prod --context=staging
+
+		# Compare the clusters as a Markdown table for a pull request comment
+		kubectl api-resource-versions diff --context=prod --context=staging --output=markdown`
+)
+
+// newCmdDiff returns a command that compares the API resource versions served by two kubeconfig contexts.
+func newCmdDiff(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newDiffOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "diff --context=OLD --context=NEW",
+		Short: "Compare the API resource versions served by two contexts",
+		Long: "Discover the API resources of two kubeconfig contexts concurrently, and report the group versions and " +
+			"resources which are only served by one of them, the preferred versions which differ, and the verbs and " +
+			"categories which differ.",
+		Example: templates.Examples(diffExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(configFlags, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runDiff(cmd.Context(), options))
+		},
+	}
+
+	// The local flag shadows the persistent --context flag of the parent command.
+	cmd.Flags().StringArrayVar(&options.Contexts, "context", options.Contexts,
+		"The name of a kubeconfig context to compare, given exactly twice: the old context, then the new context.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+
+	return cmd
+}
+
+// diffOptions contains the options for the diff command.
+type diffOptions struct {
+	genericiooptions.IOStreams
+
+	Contexts  []string
+	Output    string
+	NoHeaders bool
+
+	discoveryClients []discovery.CachedDiscoveryInterface
+}
+
+// newDiffOptions returns a new [diffOptions] with default values.
+func newDiffOptions(ioStreams genericiooptions.IOStreams) *diffOptions {
+	return &diffOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the diff command.
+func (o *diffOptions) complete(
+	configFlags *genericclioptions.ConfigFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	if len(o.Contexts) != diffContexts {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "expected --context exactly twice, got %d contexts", len(o.Contexts))
+	}
+
+	discoveryClients, err := contextDiscoveryClients(configFlags, o.Contexts)
+	if err != nil {
+		return err
+	}
+
+	o.discoveryClients = discoveryClients
+
+	return nil
+}
+
+// validate checks that options are valid for the diff command.
+func (o *diffOptions) validate() error {
+	return validateReportOutput(o.Output)
+}
+
+// runDiff discovers the API resources of both contexts concurrently and prints the changes.
+func runDiff(ctx context.Context, options *diffOptions) error {
+	snapshots, err := contextSnapshots(ctx, options.Contexts, options.discoveryClients, options.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	changes := diffSnapshots(snapshots[0], snapshots[1])
+
+	return printSnapshotChanges(options.Out, options.Output, options.NoHeaders, newSnapshotsMetadata(snapshots...),
+		changes)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+)
+
+// TestRunDiff tests comparing the API resource versions of two contexts.
+func TestRunDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Identical", runDiffTest{
+		discoveryClients: []discovery.CachedDiscoveryInterface{discoverytesting.New(), discoverytesting.New()},
+		wantChanges:      []snapshotChange{},
+	}.Test)
+	t.Run("Different", runDiffTest{
+		discoveryClients: []discovery.CachedDiscoveryInterface{
+			discoverytesting.New(),
+			discoverytesting.NewProcedural(1, 1, 1),
+		},
+		wantChanges: []snapshotChange{
+			{Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: "autoscaling/v1"},
+			{Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: "autoscaling/v2"},
+			{Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: "autoscaling/v2beta2"},
+			{Change: snapshotAdded, Type: snapshotChangeGroupVersion, Name: "group0/v1"},
+			{Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: "v1"},
+		},
+	}.Test)
+}
+
+type runDiffTest struct {
+	discoveryClients []discovery.CachedDiscoveryInterface
+	wantChanges      []snapshotChange
+}
+
+func (tt runDiffTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newDiffOptions(ioStreams)
+	options.Output = jsonOutput
+	options.Contexts = []string{"prod", "staging"}
+	options.discoveryClients = tt.discoveryClients
+
+	err := runDiff(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runDiff() error = %v", err)
+	}
+
+	result := struct {
+		Metadata outputMetadata   `json:"metadata"`
+		Changes  []snapshotChange `json:"changes"`
+	}{}
+
+	err = json.Unmarshal(stdout.Bytes(), &result)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	var contexts []string
+	for _, cluster := range result.Metadata.Clusters {
+		contexts = append(contexts, cluster.Context)
+	}
+
+	if !reflect.DeepEqual(contexts, options.Contexts) || result.Metadata.Timestamp.IsZero() {
+		t.Errorf("runDiff() metadata = %+v, want the contexts %v", result.Metadata, options.Contexts)
+	}
+
+	// Only the group version changes are compared, the resource changes are covered by the snapshot diff tests.
+	got := []snapshotChange{}
+
+	for _, change := range result.Changes {
+		if change.Type == snapshotChangeGroupVersion {
+			got = append(got, change)
+		}
+	}
+
+	if len(got) != len(tt.wantChanges) {
+		t.Fatalf("runDiff() group version changes = %v, want %v", got, tt.wantChanges)
+	}
+
+	for i := range got {
+		if got[i] != tt.wantChanges[i] {
+			t.Errorf("runDiff() change %d = %v, want %v", i, got[i], tt.wantChanges[i])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+)
+
+// disallowedVersionsOptions contains the options selecting the versions disallowed by the generated policies and the
+// admission webhook.
+type disallowedVersionsOptions struct {
+	DeprecatedOnly bool
+	TargetVersion  string
+
+	targetRelease *utilversion.Version
+}
+
+// addFlags adds the flags selecting the disallowed versions.
+func (o *disallowedVersionsOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.DeprecatedOnly, "deprecated-only", o.DeprecatedOnly,
+		"Only disallow deprecated versions, and allow the served versions which are not preferred.")
+	flags.StringVar(&o.TargetVersion, "target-version", o.TargetVersion,
+		"Kubernetes release, e.g. 1.32, whose removed versions are disallowed as well.")
+}
+
+// errInvalidTargetVersion is returned when the --target-version value is not a Kubernetes release.
+const errInvalidTargetVersion = constError("invalid target version")
+
+// validate checks that the target version is a Kubernetes release.
+func (o *disallowedVersionsOptions) validate() error {
+	if o.TargetVersion == "" {
+		return nil
+	}
+
+	release, err := lifecycle.ParseRelease(o.TargetVersion)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidTargetVersion, err)
+	}
+
+	o.targetRelease = release
+
+	return nil
+}
+
+// disallowedVersion is a version of a resource which the generated policies and the admission webhook disallow.
+type disallowedVersion struct {
+	// GroupVersionKind is the group version kind of the resource.
+	GroupVersionKind schema.GroupVersionKind
+	// Resource is the plural name of the resource.
+	Resource string
+	// Status is the status of the version, either served or deprecated.
+	Status manifestStatus
+	// RemovedIn is the release in which the version is removed, if it is removed in the target release.
+	RemovedIn string
+	// Replacement is the preferred group version of the kind, or the replacement group version from the lifecycle
+	// database.
+	Replacement string
+}
+
+// message returns the message of the policy violation or the admission warning for the version.
+func (v disallowedVersion) message() string {
+	reason := "is deprecated"
+	if v.RemovedIn != "" {
+		reason = "is removed in Kubernetes " + v.RemovedIn
+	} else if v.Status == manifestStatusServed {
+		reason = "is not the preferred version"
+	}
+
+	message := fmt.Sprintf("%s %s %s", v.GroupVersionKind.GroupVersion(), v.GroupVersionKind.Kind, reason)
+	if v.Replacement != "" {
+		message += ", use " + v.Replacement + " instead"
+	}
+
+	return message + "."
+}
+
+// getDisallowedVersions discovers the deprecated, removed in the target release, and unless --deprecated-only is set
+// the non-preferred, versions of the resources served by the cluster.
+func getDisallowedVersions(
+	ctx context.Context,
+	discoveryClient discovery.CachedDiscoveryInterface,
+	options *disallowedVersionsOptions,
+) ([]disallowedVersion, error) {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = discoveryClient
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := newServedKinds(resources, discoveryClient)
+
+	var versions []disallowedVersion
+
+	for _, resource := range resources {
+		if resource.Subresource {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(resource.APIGroupVersion)
+		obj.SetKind(resource.APIResource.Kind)
+
+		finding := kinds.check(manifestLocation{}, obj)
+		version := disallowedVersion{
+			GroupVersionKind: obj.GroupVersionKind(),
+			Resource:         resource.APIResource.Name,
+			Status:           finding.Status,
+			Replacement:      finding.PreferredVersion,
+		}
+
+		api, known := lifecycle.Lookup(version.GroupVersionKind)
+		if known && options.targetRelease != nil && api.RemovedIn(options.targetRelease) {
+			version.Status = manifestStatusDeprecated
+			version.RemovedIn = api.Removed
+		}
+
+		if version.Status != manifestStatusDeprecated &&
+			(options.DeprecatedOnly || version.Status != manifestStatusServed) {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	slices.SortFunc(versions, func(a, b disallowedVersion) int {
+		return cmp.Or(
+			cmp.Compare(a.GroupVersionKind.Group, b.GroupVersionKind.Group),
+			cmp.Compare(a.GroupVersionKind.Version, b.GroupVersionKind.Version),
+			cmp.Compare(a.GroupVersionKind.Kind, b.GroupVersionKind.Kind),
+		)
+	})
+
+	return versions, nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	diskcached "k8s.io/client-go/discovery/cached/disk"
+	restclient "k8s.io/client-go/rest"
+)
+
+const (
+	// discoveryBurst is the burst of the discovery client, the same as kubectl to discover large clusters quickly.
+	discoveryBurst = 300
+	// discoveryQPS is the QPS of the discovery client, the same as kubectl.
+	discoveryQPS = 50.0
+	// defaultDiscoveryCacheTTL is the TTL of the discovery cache used by kubectl.
+	defaultDiscoveryCacheTTL = 6 * time.Hour
+	// clusterCacheDirectory is the directory of the discovery cache of the clusters of a fleet, in the kubectl cache
+	// directory.
+	clusterCacheDirectory = "api-resource-versions"
+)
+
+// errCacheTTL is returned when the cache TTL is negative.
+const errCacheTTL = constError("cache-ttl must not be negative")
+
+// illegalClusterCacheKeyCharacters matches the characters of a cluster name replaced in the name of its discovery
+// cache directory, notably path separators and dots.
+//
+//nolint:gochecknoglobals
+var illegalClusterCacheKeyCharacters = regexp.MustCompile(`[^\w-]`)
+
+// clusterCacheKey returns the name of the discovery cache directory of the cluster.
+// The name is suffixed with a hash of the cluster name, so that names differing only by replaced characters, e.g.
+// "a.b" and "a_b", don't share a directory.
+func clusterCacheKey(clusterName string) string {
+	hash := sha256.Sum256([]byte(clusterName))
+
+	//nolint:mnd
+	return illegalClusterCacheKeyCharacters.ReplaceAllString(clusterName, "_") + "-" + hex.EncodeToString(hash[:4])
+}
+
+// clusterDiscoveryCacheDirectory returns the discovery cache directory of the cluster selected by the config flags.
+// The clusters of a fleet, which have a name, have a cache directory of their own keyed by both their name and host:
+// kubectl only keys its cache by host, so contexts sharing a host, e.g. through a proxy or a local port-forward,
+// would otherwise pollute the cache of one another.
+// The cluster without a name shares the kubectl discovery cache directory.
+func clusterDiscoveryCacheDirectory(configFlags *genericclioptions.ConfigFlags, clusterName string) (string, error) {
+	if clusterName == "" {
+		return discoveryCacheDirectory(configFlags)
+	}
+
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return "", fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	return filepath.Join(
+		kubectlCacheDirectory(configFlags), clusterCacheDirectory, "discovery",
+		clusterCacheKey(clusterName), hostCacheKey(restConfig.Host),
+	), nil
+}
+
+// newCachedDiscoveryClient creates a discovery client of the cluster selected by the config flags which uses its
+// discovery cache if it is newer than the TTL, and otherwise refreshes it from the API server.
+// The discovery client of the config flags always uses a TTL of 6 hours and the kubectl cache directory, so it can't
+// be used with another TTL or for the clusters of a fleet, see [clusterDiscoveryCacheDirectory].
+func newCachedDiscoveryClient(
+	configFlags *genericclioptions.ConfigFlags,
+	clusterName string,
+	ttl time.Duration,
+) (discovery.CachedDiscoveryInterface, error) {
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	restConfig = restclient.CopyConfig(restConfig)
+	restConfig.Burst = discoveryBurst
+	restConfig.QPS = discoveryQPS
+
+	discoveryCacheDir, err := clusterDiscoveryCacheDirectory(configFlags, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	httpCacheDir := filepath.Join(kubectlCacheDirectory(configFlags), "http")
+
+	discoveryClient, err := diskcached.NewCachedDiscoveryClientForConfig(
+		restConfig, discoveryCacheDir, httpCacheDir, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	return discoveryClient, nil
+}
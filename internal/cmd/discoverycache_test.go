@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// TestCacheTTLDiscoveryClient tests using the kubectl discovery cache only if it is newer than the TTL.
+func TestCacheTTLDiscoveryClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Fresh", cacheTTLDiscoveryClientTest{age: time.Minute, ttl: 10 * time.Minute, wantErr: false}.Test)
+	t.Run("Expired", cacheTTLDiscoveryClientTest{age: time.Hour, ttl: 10 * time.Minute, wantErr: true}.Test)
+}
+
+type cacheTTLDiscoveryClientTest struct {
+	age     time.Duration
+	ttl     time.Duration
+	wantErr bool
+}
+
+func (tt cacheTTLDiscoveryClientTest) Test(t *testing.T) {
+	t.Parallel()
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	cacheDir := t.TempDir()
+	// Nothing listens on this port, so the discovery only succeeds from the cache.
+	server := "https://127.0.0.1:1"
+	configFlags.CacheDir = &cacheDir
+	configFlags.APIServer = &server
+
+	groupsFile := filepath.Join(cacheDir, "discovery", "127.0.0.1_1", serverGroupsFilename)
+
+	groups, err := os.ReadFile(filepath.Join("testdata", "discovery", serverGroupsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(groupsFile), 0o750)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(groupsFile, groups, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := time.Now().Add(-tt.age)
+
+	err = os.Chtimes(groupsFile, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	discoveryClient, err := newCachedDiscoveryClient(configFlags, "", tt.ttl)
+	if err != nil {
+		t.Fatalf("newCachedDiscoveryClient() error = %v", err)
+	}
+
+	groupList, err := discoveryClient.ServerGroups()
+	if (err != nil) != tt.wantErr {
+		t.Fatalf("ServerGroups() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if !tt.wantErr && len(groupList.Groups) != 2 {
+		t.Errorf("ServerGroups() = %d groups, want 2", len(groupList.Groups))
+	}
+}
+
+// TestClusterDiscoveryCacheDirectory tests isolating the discovery cache of the clusters of a fleet.
+func TestClusterDiscoveryCacheDirectory(t *testing.T) {
+	t.Parallel()
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	cacheDir := "/tmp/cache"
+	server := "https://192.168.49.2:8443"
+	configFlags.CacheDir = &cacheDir
+	configFlags.APIServer = &server
+
+	directories := map[string]string{}
+
+	for _, clusterName := range []string{"", "prod", "staging", "arn:aws:eks:eu-west-1:1234:cluster/prod", "a.b", "a_b"} {
+		directory, err := clusterDiscoveryCacheDirectory(configFlags, clusterName)
+		if err != nil {
+			t.Fatalf("clusterDiscoveryCacheDirectory(%q) error = %v", clusterName, err)
+		}
+
+		if other, ok := directories[directory]; ok {
+			t.Errorf("clusterDiscoveryCacheDirectory(%q) = %q, shared with %q", clusterName, directory, other)
+		}
+
+		directories[directory] = clusterName
+
+		wantDir := filepath.Join(cacheDir, clusterCacheDirectory, "discovery")
+		if clusterName == "" {
+			wantDir = filepath.Join(cacheDir, "discovery")
+		} else {
+			key := clusterCacheKey(clusterName)
+			if filepath.Base(key) != key {
+				t.Errorf("clusterCacheKey(%q) = %q, want a single path element", clusterName, key)
+			}
+
+			wantDir = filepath.Join(wantDir, key)
+		}
+
+		if want := filepath.Join(wantDir, "192.168.49.2_8443"); directory != want {
+			t.Errorf("clusterDiscoveryCacheDirectory(%q) = %q, want %q", clusterName, directory, want)
+		}
+	}
+}
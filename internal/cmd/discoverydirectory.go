@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	openapi_v2 "github.com/google/gnostic-models/openapiv2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/openapi"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/util/homedir"
+)
+
+const (
+	// serverGroupsFilename is the file of the API groups in a discovery directory.
+	serverGroupsFilename = "servergroups.json"
+	// serverResourcesFilename is the file of the API resources of a group version in a discovery directory.
+	serverResourcesFilename = "serverresources.json"
+	// serverVersionFilename is the file of the server version in a discovery directory.
+	// It is not written by kubectl, so the server version is unknown when reading the kubectl discovery cache.
+	serverVersionFilename = "version.json"
+)
+
+// errDiscoveryDirectory is returned when a discovery directory doesn't contain a discovery document.
+const errDiscoveryDirectory = constError("not available in the discovery directory")
+
+// directoryDiscoveryClient is a [discovery.CachedDiscoveryInterface] reading the discovery documents of a directory
+// in the layout of the kubectl discovery cache, without ever contacting the API server.
+type directoryDiscoveryClient struct {
+	// directory contains the servergroups.json file, and the <group>/<version>/serverresources.json files.
+	directory string
+}
+
+var _ discovery.CachedDiscoveryInterface = &directoryDiscoveryClient{}
+
+// newDirectoryDiscoveryClient returns a discovery client reading the discovery documents of the directory.
+func newDirectoryDiscoveryClient(directory string) *directoryDiscoveryClient {
+	return &directoryDiscoveryClient{directory: directory}
+}
+
+// readDocument reads the JSON discovery document of the file, relative to the directory.
+func (d *directoryDiscoveryClient) readDocument(filename string, document any) error {
+	path := filepath.Join(d.directory, filename)
+
+	content, err := os.ReadFile(path) //nolint:gosec // Reading the user-provided discovery directory is intended.
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", errDiscoveryDirectory, path)
+	} else if err != nil {
+		return fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(content, document)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// RESTClient implements [discovery.DiscoveryInterface.RESTClient], there is no REST client.
+func (d *directoryDiscoveryClient) RESTClient() restclient.Interface {
+	return nil
+}
+
+// ServerGroups implements [discovery.ServerGroupsInterface.ServerGroups].
+func (d *directoryDiscoveryClient) ServerGroups() (*metav1.APIGroupList, error) {
+	groupList := &metav1.APIGroupList{}
+
+	err := d.readDocument(serverGroupsFilename, groupList)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupList, nil
+}
+
+// ServerResourcesForGroupVersion implements [discovery.ServerResourcesInterface.ServerResourcesForGroupVersion].
+func (d *directoryDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	resourceList := &metav1.APIResourceList{}
+
+	err := d.readDocument(filepath.Join(groupVersion, serverResourcesFilename), resourceList)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceList, nil
+}
+
+// ServerGroupsAndResources implements [discovery.ServerResourcesInterface.ServerGroupsAndResources].
+func (d *directoryDiscoveryClient) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerGroupsAndResources(d)
+}
+
+// ServerPreferredResources implements [discovery.ServerResourcesInterface.ServerPreferredResources].
+func (d *directoryDiscoveryClient) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerPreferredResources(d)
+}
+
+// ServerPreferredNamespacedResources implements
+// [discovery.ServerResourcesInterface.ServerPreferredNamespacedResources].
+func (d *directoryDiscoveryClient) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerPreferredNamespacedResources(d)
+}
+
+// ServerVersion implements [discovery.ServerVersionInterface.ServerVersion], reading the version.json file if any.
+func (d *directoryDiscoveryClient) ServerVersion() (*version.Info, error) {
+	info := &version.Info{}
+
+	err := d.readDocument(serverVersionFilename, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// OpenAPISchema implements [discovery.OpenAPISchemaInterface.OpenAPISchema], the schema is never available.
+func (d *directoryDiscoveryClient) OpenAPISchema() (*openapi_v2.Document, error) {
+	return nil, fmt.Errorf("%w: OpenAPI schema", errDiscoveryDirectory)
+}
+
+// OpenAPIV3 implements [discovery.OpenAPIV3SchemaInterface.OpenAPIV3], the schema is never available.
+func (d *directoryDiscoveryClient) OpenAPIV3() openapi.Client {
+	return nil
+}
+
+// WithLegacy implements [discovery.DiscoveryInterface.WithLegacy], the documents are always in the legacy format.
+func (d *directoryDiscoveryClient) WithLegacy() discovery.DiscoveryInterface {
+	return d
+}
+
+// Fresh implements [discovery.CachedDiscoveryInterface.Fresh], the documents can't be refreshed.
+func (d *directoryDiscoveryClient) Fresh() bool {
+	return true
+}
+
+// Invalidate implements [discovery.CachedDiscoveryInterface.Invalidate], the documents can't be refreshed.
+func (d *directoryDiscoveryClient) Invalidate() {}
+
+// illegalCacheDirectoryCharacters matches the characters of the host replaced by kubectl in the name of the
+// discovery cache directory.
+//
+//nolint:gochecknoglobals
+var illegalCacheDirectoryCharacters = regexp.MustCompile(`[^(\w/.)]`)
+
+// discoveryCacheDirectory returns the kubectl discovery cache directory of the cluster selected by the config flags,
+// computed the same way as kubectl does.
+func discoveryCacheDirectory(configFlags *genericclioptions.ConfigFlags) (string, error) {
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return "", fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	return filepath.Join(kubectlCacheDirectory(configFlags), "discovery", hostCacheKey(restConfig.Host)), nil
+}
+
+// hostCacheKey returns the name of the discovery cache directory of the API server host, the same as kubectl.
+func hostCacheKey(host string) string {
+	host = strings.Replace(strings.Replace(host, "https://", "", 1), "http://", "", 1)
+
+	return illegalCacheDirectoryCharacters.ReplaceAllString(host, "_")
+}
+
+// kubectlCacheDirectory returns the kubectl cache directory: --cache-dir, $KUBECACHEDIR, or ~/.kube/cache.
+func kubectlCacheDirectory(configFlags *genericclioptions.ConfigFlags) string {
+	if configFlags.CacheDir != nil && *configFlags.CacheDir != "" {
+		return *configFlags.CacheDir
+	} else if cacheDir := os.Getenv("KUBECACHEDIR"); cacheDir != "" {
+		return cacheDir
+	}
+
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache")
+}
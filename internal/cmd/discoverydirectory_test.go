@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// TestDirectoryDiscoveryClient tests listing the resources of a discovery directory.
+func TestDirectoryDiscoveryClient(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().WithDiscoveryClient(newDirectoryDiscoveryClient("testdata/discovery"))
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "NAME          SHORTNAMES   APIVERSION   NAMESPACED   KIND         PREFERRED\n" +
+		"configmaps    cm           v1           true         ConfigMap    true\n" +
+		"pods          po           v1           true         Pod          true\n" +
+		"deployments   deploy       apps/v1      true         Deployment   true\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+
+	_, err = newDirectoryDiscoveryClient("testdata/discovery").ServerVersion()
+	if !errors.Is(err, errDiscoveryDirectory) {
+		t.Errorf("ServerVersion() error = %v, want %v", err, errDiscoveryDirectory)
+	}
+}
+
+// TestDiscoveryCacheDirectory tests computing the kubectl discovery cache directory of the cluster.
+func TestDiscoveryCacheDirectory(t *testing.T) {
+	t.Parallel()
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	cacheDir := "/tmp/cache"
+	server := "https://192.168.49.2:8443"
+	configFlags.CacheDir = &cacheDir
+	configFlags.APIServer = &server
+
+	got, err := discoveryCacheDirectory(configFlags)
+	if err != nil {
+		t.Fatalf("discoveryCacheDirectory() error = %v", err)
+	}
+
+	if want := filepath.Join(cacheDir, "discovery", "192.168.49.2_8443"); got != want {
+		t.Errorf("discoveryCacheDirectory() = %q, want %q", got, want)
+	}
+}
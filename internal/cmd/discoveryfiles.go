@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	openapi_v2 "github.com/google/gnostic-models/openapiv2"
+	apidiscoveryv2 "k8s.io/api/apidiscovery/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/openapi"
+	restclient "k8s.io/client-go/rest"
+)
+
+// errDiscoveryFiles is returned when the discovery files don't contain a discovery document.
+const errDiscoveryFiles = constError("not available in the discovery files")
+
+// errDiscoveryFileKind is returned when a discovery file isn't one of the documents of the discovery endpoints.
+const errDiscoveryFileKind = constError("unsupported discovery document, expected the output of kubectl get --raw " +
+	"for /api, /apis, /api/v1, /apis/<group>/<version>, or /version")
+
+// fileDiscoveryClient is a [discovery.CachedDiscoveryInterface] serving the discovery documents of a set of files,
+// e.g. collected with kubectl get --raw by someone with access to the cluster, without ever contacting the API server.
+type fileDiscoveryClient struct {
+	// groupList contains the API groups of the files, the core group first.
+	groupList metav1.APIGroupList
+	// resourceLists are the resources of the group versions of the files, keyed by group version.
+	resourceLists map[string]*metav1.APIResourceList
+	// serverVersion is the server version of the /version document, nil if none of the files is one.
+	serverVersion *version.Info
+}
+
+var _ discovery.CachedDiscoveryInterface = &fileDiscoveryClient{}
+
+// discoveryFileDocument is the part of a discovery document identifying it.
+type discoveryFileDocument struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// GitVersion is only set in the /version document, which has no kind.
+	GitVersion string `json:"gitVersion"`
+}
+
+// newFileDiscoveryClient returns a discovery client serving the discovery documents of the files.
+// Each file is the output of kubectl get --raw for one of the discovery endpoints: the API groups of /apis, the core
+// versions of /api, the resources of a group version, e.g. /apis/apps/v1, or the server version of /version. A single
+// file may also contain the resources of all the groups, as served by the aggregated discovery of /apis with
+// `Accept: application/json;g=apidiscovery.k8s.io;v=v2;as=APIGroupDiscoveryList`.
+// The core group is added from /api, or else from the resources of /api/v1, as /apis doesn't list it.
+func newFileDiscoveryClient(filenames []string) (*fileDiscoveryClient, error) {
+	d := &fileDiscoveryClient{resourceLists: make(map[string]*metav1.APIResourceList)}
+
+	for _, filename := range filenames {
+		content, err := os.ReadFile(filename) //nolint:gosec // Reading the user-provided discovery files is intended.
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %s: %w", filename, err)
+		}
+
+		err = d.addDocument(content)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %w", filename, err)
+		}
+	}
+
+	if !d.hasGroup("") && d.resourceLists["v1"] != nil {
+		d.addGroups([]metav1.APIGroup{*coreGroupOf(&metav1.APIVersions{Versions: []string{"v1"}})})
+	}
+
+	return d, nil
+}
+
+// addDocument adds the discovery document to the client.
+func (d *fileDiscoveryClient) addDocument(content []byte) error {
+	document := discoveryFileDocument{}
+
+	err := json.Unmarshal(content, &document)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	switch {
+	case document.Kind == "APIGroupList":
+		groupList := &metav1.APIGroupList{}
+
+		err = json.Unmarshal(content, groupList)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		d.addGroups(groupList.Groups)
+	case document.Kind == "APIGroup":
+		group := metav1.APIGroup{}
+
+		err = json.Unmarshal(content, &group)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		d.addGroups([]metav1.APIGroup{group})
+	case document.Kind == "APIVersions":
+		versions := &metav1.APIVersions{}
+
+		err = json.Unmarshal(content, versions)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		d.addGroups([]metav1.APIGroup{*coreGroupOf(versions)})
+	case document.Kind == "APIResourceList":
+		resourceList := &metav1.APIResourceList{}
+
+		err = json.Unmarshal(content, resourceList)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		d.resourceLists[resourceList.GroupVersion] = resourceList
+	case document.Kind == "APIGroupDiscoveryList":
+		return d.addAggregatedDocument(content)
+	case document.Kind == "" && document.GitVersion != "":
+		d.serverVersion = &version.Info{}
+
+		err = json.Unmarshal(content, d.serverVersion)
+		if err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: got kind %q", errDiscoveryFileKind, document.Kind)
+	}
+
+	return nil
+}
+
+// addAggregatedDocument adds the groups and the resources of the aggregated discovery document to the client.
+func (d *fileDiscoveryClient) addAggregatedDocument(content []byte) error {
+	aggregated := apidiscoveryv2.APIGroupDiscoveryList{}
+
+	err := json.Unmarshal(content, &aggregated)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	groupList, resourceLists, _ := discovery.SplitGroupsAndResources(aggregated)
+	d.addGroups(groupList.Groups)
+
+	for groupVersion, resourceList := range resourceLists {
+		d.resourceLists[groupVersion.String()] = resourceList
+	}
+
+	return nil
+}
+
+// addGroups adds the API groups to the client, replacing the groups of the same name.
+// The core group is added first, as the API server lists it before the other groups.
+func (d *fileDiscoveryClient) addGroups(groups []metav1.APIGroup) {
+	for _, group := range groups {
+		i := slices.IndexFunc(d.groupList.Groups, func(existing metav1.APIGroup) bool {
+			return existing.Name == group.Name
+		})
+
+		switch {
+		case i >= 0:
+			d.groupList.Groups[i] = group
+		case group.Name == "":
+			d.groupList.Groups = slices.Insert(d.groupList.Groups, 0, group)
+		default:
+			d.groupList.Groups = append(d.groupList.Groups, group)
+		}
+	}
+}
+
+// hasGroup returns true if the client has the API group.
+func (d *fileDiscoveryClient) hasGroup(name string) bool {
+	return slices.ContainsFunc(d.groupList.Groups, func(group metav1.APIGroup) bool { return group.Name == name })
+}
+
+// coreGroupOf returns the core group of the versions of the /api document, preferring the first version like the
+// API server does.
+func coreGroupOf(versions *metav1.APIVersions) *metav1.APIGroup {
+	group := &metav1.APIGroup{}
+
+	for _, version := range versions.Versions {
+		group.Versions = append(group.Versions, metav1.GroupVersionForDiscovery{GroupVersion: version, Version: version})
+	}
+
+	if len(group.Versions) > 0 {
+		group.PreferredVersion = group.Versions[0]
+	}
+
+	return group
+}
+
+// RESTClient implements [discovery.DiscoveryInterface.RESTClient], there is no REST client.
+func (d *fileDiscoveryClient) RESTClient() restclient.Interface {
+	return nil
+}
+
+// ServerGroups implements [discovery.ServerGroupsInterface.ServerGroups].
+func (d *fileDiscoveryClient) ServerGroups() (*metav1.APIGroupList, error) {
+	if len(d.groupList.Groups) == 0 {
+		return nil, fmt.Errorf("%w: API groups of /api or /apis", errDiscoveryFiles)
+	}
+
+	return d.groupList.DeepCopy(), nil
+}
+
+// ServerResourcesForGroupVersion implements [discovery.ServerResourcesInterface.ServerResourcesForGroupVersion].
+func (d *fileDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	resourceList, ok := d.resourceLists[groupVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w: resources of %s", errDiscoveryFiles, groupVersion)
+	}
+
+	return resourceList.DeepCopy(), nil
+}
+
+// ServerGroupsAndResources implements [discovery.ServerResourcesInterface.ServerGroupsAndResources].
+func (d *fileDiscoveryClient) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerGroupsAndResources(d)
+}
+
+// ServerPreferredResources implements [discovery.ServerResourcesInterface.ServerPreferredResources].
+func (d *fileDiscoveryClient) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerPreferredResources(d)
+}
+
+// ServerPreferredNamespacedResources implements
+// [discovery.ServerResourcesInterface.ServerPreferredNamespacedResources].
+func (d *fileDiscoveryClient) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	//nolint:wrapcheck
+	return discovery.ServerPreferredNamespacedResources(d)
+}
+
+// ServerVersion implements [discovery.ServerVersionInterface.ServerVersion], from the /version document if any.
+func (d *fileDiscoveryClient) ServerVersion() (*version.Info, error) {
+	if d.serverVersion == nil {
+		return nil, fmt.Errorf("%w: server version of /version", errDiscoveryFiles)
+	}
+
+	info := *d.serverVersion
+
+	return &info, nil
+}
+
+// OpenAPISchema implements [discovery.OpenAPISchemaInterface.OpenAPISchema], the schema is never available.
+func (d *fileDiscoveryClient) OpenAPISchema() (*openapi_v2.Document, error) {
+	return nil, fmt.Errorf("%w: OpenAPI schema", errDiscoveryFiles)
+}
+
+// OpenAPIV3 implements [discovery.OpenAPIV3SchemaInterface.OpenAPIV3], the schema is never available.
+func (d *fileDiscoveryClient) OpenAPIV3() openapi.Client {
+	return nil
+}
+
+// WithLegacy implements [discovery.DiscoveryInterface.WithLegacy], the documents are always served in the legacy
+// format.
+func (d *fileDiscoveryClient) WithLegacy() discovery.DiscoveryInterface {
+	return d
+}
+
+// Fresh implements [discovery.CachedDiscoveryInterface.Fresh], the documents can't be refreshed.
+func (d *fileDiscoveryClient) Fresh() bool {
+	return true
+}
+
+// Invalidate implements [discovery.CachedDiscoveryInterface.Invalidate], the documents can't be refreshed.
+func (d *fileDiscoveryClient) Invalidate() {}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestFromFile tests listing the resources of discovery files with --from-file, without a cluster.
+func TestFromFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Raw", fromDumpTest{
+		args: []string{"--from-file=testdata/discoveryfiles/apis.json", "--from-file=testdata/discoveryfiles/api.json",
+			"--from-file=testdata/discoveryfiles/api_v1.json,testdata/discoveryfiles/apps_v1.json", "--output=name"},
+		want: "configmaps.v1.\npods.v1.\ndeployments.v1.apps\n",
+	}.Test)
+	// The core group is added from the resources of /api/v1 without /api.
+	t.Run("CoreResourcesOnly", fromDumpTest{
+		args: []string{"--from-file=testdata/discoveryfiles/api_v1.json", "--output=name"},
+		want: "configmaps.v1.\npods.v1.\n",
+	}.Test)
+	t.Run("Aggregated", fromDumpTest{
+		args: []string{"--from-file=testdata/discoveryfiles/aggregated.json", "--output=name"},
+		want: "deployments.v1.apps\n",
+	}.Test)
+	t.Run("Versions", fromDumpTest{
+		args: []string{"--from-file=testdata/discoveryfiles/aggregated.json", "versions", "deployments"},
+		want: "NAME               APIVERSION   KIND         PREFERRED   STORAGE     DEPRECATED\n" +
+			"deployments.apps   apps/v1      Deployment   true        <unknown>   false\n",
+	}.Test)
+}
+
+// TestNewFileDiscoveryClient tests reading the discovery files.
+func TestNewFileDiscoveryClient(t *testing.T) {
+	t.Parallel()
+
+	// The server version is read from /version, without the API groups.
+	t.Run("VersionOnly", newFileDiscoveryClientTest{
+		filenames:       []string{"testdata/discoveryfiles/version.json"},
+		wantVersion:     "v1.33.1",
+		wantGroupsError: errDiscoveryFiles,
+	}.Test)
+	t.Run("UnsupportedKind", newFileDiscoveryClientTest{
+		filenames: []string{"testdata/discoveryfiles/pod.json"},
+		wantErr:   errDiscoveryFileKind,
+	}.Test)
+}
+
+type newFileDiscoveryClientTest struct {
+	filenames       []string
+	wantVersion     string
+	wantErr         error
+	wantGroupsError error
+}
+
+func (tt newFileDiscoveryClientTest) Test(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient, err := newFileDiscoveryClient(tt.filenames)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("newFileDiscoveryClient() error = %v, want %v", err, tt.wantErr)
+	} else if err != nil {
+		return
+	}
+
+	info, err := discoveryClient.ServerVersion()
+	if err != nil {
+		t.Fatalf("ServerVersion() error = %v", err)
+	}
+
+	if info.GitVersion != tt.wantVersion {
+		t.Errorf("ServerVersion() = %s, want %s", info.GitVersion, tt.wantVersion)
+	}
+
+	_, err = discoveryClient.ServerGroups()
+	if !errors.Is(err, tt.wantGroupsError) {
+		t.Errorf("ServerGroups() error = %v, want %v", err, tt.wantGroupsError)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateDiscovery returns the warnings about the duplicate entries of the discovery documents, which are the
+// symptoms of broken aggregated APIs: the groups listed more than once, the versions listed more than once in a group,
+// and the resources listed more than once in a group version, with conflicting attributes or not.
+// The resource lists are those of the versions of each of the groups.
+func validateDiscovery(groups []metav1.APIGroup, groupResourceLists [][]*metav1.APIResourceList) []string {
+	var warnings []string
+
+	groupCounts := make(map[string]int, len(groups))
+	for _, group := range groups {
+		groupCounts[group.Name]++
+	}
+
+	for _, group := range groups {
+		count := groupCounts[group.Name]
+		if count > 1 {
+			warnings = append(warnings, fmt.Sprintf("discovery lists the API group %q %d times", group.Name, count))
+			// The duplicates are only reported once.
+			groupCounts[group.Name] = 0
+		}
+
+		versionCounts := make(map[string]int, len(group.Versions))
+		for _, version := range group.Versions {
+			versionCounts[version.GroupVersion]++
+		}
+
+		for _, version := range group.Versions {
+			count := versionCounts[version.GroupVersion]
+			if count > 1 {
+				warnings = append(warnings, fmt.Sprintf("discovery lists the group version %s %d times",
+					version.GroupVersion, count))
+				versionCounts[version.GroupVersion] = 0
+			}
+		}
+	}
+
+	for _, resourceLists := range groupResourceLists {
+		for _, resourceList := range resourceLists {
+			if resourceList != nil {
+				warnings = append(warnings, duplicateResourceWarnings(resourceList)...)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// duplicateResourceWarnings returns the warnings about the resources listed more than once in the group version.
+func duplicateResourceWarnings(resourceList *metav1.APIResourceList) []string {
+	var (
+		warnings []string
+		names    []string
+	)
+
+	resources := make(map[string][]metav1.APIResource, len(resourceList.APIResources))
+
+	for _, resource := range resourceList.APIResources {
+		if _, ok := resources[resource.Name]; !ok {
+			names = append(names, resource.Name)
+		}
+
+		resources[resource.Name] = append(resources[resource.Name], resource)
+	}
+
+	for _, name := range names {
+		duplicates := resources[name]
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		conflicting := slices.ContainsFunc(duplicates[1:], func(resource metav1.APIResource) bool {
+			return !reflect.DeepEqual(resource, duplicates[0])
+		})
+		if conflicting {
+			warnings = append(warnings, fmt.Sprintf(
+				"discovery lists the resource %s of %s %d times with conflicting attributes",
+				name, resourceList.GroupVersion, len(duplicates)))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("discovery lists the resource %s of %s %d times",
+				name, resourceList.GroupVersion, len(duplicates)))
+		}
+	}
+
+	return warnings
+}
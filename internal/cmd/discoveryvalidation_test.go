@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestValidateDiscovery tests the warnings about the duplicate entries of the discovery documents.
+func TestValidateDiscovery(t *testing.T) {
+	t.Parallel()
+
+	appsV1 := metav1.GroupVersionForDiscovery{GroupVersion: "apps/v1", Version: "v1"}
+	deployments := metav1.APIResource{Name: "deployments", Namespaced: true, Kind: "Deployment"}
+	clusterDeployments := metav1.APIResource{Name: "deployments", Namespaced: false, Kind: "Deployment"}
+
+	t.Run("Valid", validateDiscoveryTest{
+		groups: []metav1.APIGroup{{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1}}},
+		groupResourceLists: [][]*metav1.APIResourceList{{
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{deployments}},
+		}},
+		want: nil,
+	}.Test)
+	t.Run("DuplicateGroup", validateDiscoveryTest{
+		groups: []metav1.APIGroup{
+			{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1}},
+			{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1}},
+		},
+		groupResourceLists: nil,
+		want:               []string{`discovery lists the API group "apps" 2 times`},
+	}.Test)
+	t.Run("DuplicateGroupVersion", validateDiscoveryTest{
+		groups: []metav1.APIGroup{
+			{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1, appsV1}},
+		},
+		groupResourceLists: nil,
+		want:               []string{"discovery lists the group version apps/v1 2 times"},
+	}.Test)
+	t.Run("DuplicateResource", validateDiscoveryTest{
+		groups: []metav1.APIGroup{{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1}}},
+		groupResourceLists: [][]*metav1.APIResourceList{{
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{deployments, deployments}},
+		}},
+		want: []string{"discovery lists the resource deployments of apps/v1 2 times"},
+	}.Test)
+	t.Run("ConflictingResource", validateDiscoveryTest{
+		groups: []metav1.APIGroup{{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{appsV1}}},
+		groupResourceLists: [][]*metav1.APIResourceList{{
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{deployments, clusterDeployments}},
+		}},
+		want: []string{"discovery lists the resource deployments of apps/v1 2 times with conflicting attributes"},
+	}.Test)
+}
+
+type validateDiscoveryTest struct {
+	groups             []metav1.APIGroup
+	groupResourceLists [][]*metav1.APIResourceList
+	want               []string
+}
+
+func (tt validateDiscoveryTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got := validateDiscovery(tt.groups, tt.groupResourceLists)
+	if !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("validateDiscovery() = %q, want %q", got, tt.want)
+	}
+}
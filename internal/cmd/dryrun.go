@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// kindResource is the resource serving a kind in a group version.
+type kindResource struct {
+	GroupVersionResource schema.GroupVersionResource
+	Namespaced           bool
+}
+
+// dryRunCreate submits the object to the API server in a server-side dry-run create with strict field validation, so
+// that the fields which don't exist or aren't valid in its version are rejected, and returns the error of the API
+// server if it rejects the object.
+// Namespaced objects without a namespace are created in the namespace.
+func dryRunCreate(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	resource kindResource,
+	namespace string,
+	obj *unstructured.Unstructured,
+) error {
+	namespaceableClient := dynamicClient.Resource(resource.GroupVersionResource)
+
+	var resourceClient dynamic.ResourceInterface = namespaceableClient
+	if resource.Namespaced {
+		if obj.GetNamespace() != "" {
+			namespace = obj.GetNamespace()
+		}
+
+		resourceClient = namespaceableClient.Namespace(namespace)
+	}
+
+	_, err := resourceClient.Create(ctx, obj, metav1.CreateOptions{
+		DryRun:          []string{metav1.DryRunAll},
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+	// The object is validated before its name is found to be taken.
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("the API server rejected %s %s in %s: %w", obj.GetKind(), obj.GetName(),
+			obj.GetAPIVersion(), err)
+	}
+
+	return nil
+}
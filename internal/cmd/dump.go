@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// dumpFilePermissions are the permissions of the files written by the dump command.
+	dumpFilePermissions = 0o644
+	// dumpDirectoryPermissions are the permissions of the directories created by the dump command.
+	dumpDirectoryPermissions = 0o755
+)
+
+var (
+	// dumpExample is the example text for the dump command.
+	//
+	//nolint:gochecknoglobals
+	dumpExample = `
+		# Dump the discovery documents of the cluster to the out directory
+		kubectl api-resource-versions dump --dir=out/
+
+		# Dump the discovery documents of the production cluster, then archive them
+		kubectl api-resource-versions --context=prod dump --dir=prod && tar -czf prod.tar.gz prod`
+)
+
+// newCmdDump returns a command that writes the raw discovery documents of the cluster to a directory.
+func newCmdDump(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newDumpOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "dump --dir=DIRECTORY",
+		Short: "Write the discovery documents of the cluster to a directory",
+		Long: "Write the raw discovery documents of the cluster to a directory, in the layout of the kubectl discovery " +
+			"cache: servergroups.json, and <group>/<version>/serverresources.json for each group version.\n" +
+			"The version of the server is written to version.json.",
+		Example: templates.Examples(dumpExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runDump(options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Directory, "dir", options.Directory,
+		"Directory to which the discovery documents are written, created if it doesn't exist.")
+
+	return cmd
+}
+
+// dumpOptions contains the options for the dump command.
+type dumpOptions struct {
+	genericiooptions.IOStreams
+
+	Directory string
+
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newDumpOptions returns a new [dumpOptions] with default values.
+func newDumpOptions(ioStreams genericiooptions.IOStreams) *dumpOptions {
+	return &dumpOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the dump command.
+func (o *dumpOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	return nil
+}
+
+// errDumpDirectory is returned when the directory of the dump command is not set.
+const errDumpDirectory = constError("dir is required")
+
+// validate checks that options are valid for the dump command.
+func (o *dumpOptions) validate() error {
+	if o.Directory == "" {
+		return errDumpDirectory
+	}
+
+	return nil
+}
+
+// runDump discovers the API resources of the cluster and writes the discovery documents to the directory.
+func runDump(options *dumpOptions) error {
+	options.discoveryClient.Invalidate()
+
+	groupList, err := options.discoveryClient.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("couldn't get server groups: %w", err)
+	}
+
+	groupList.Kind = "APIGroupList"
+	groupList.APIVersion = "v1"
+
+	err = writeDumpDocument(options.Directory, serverGroupsFilename, groupList)
+	if err != nil {
+		return err
+	}
+
+	groupVersions := 0
+
+	for _, group := range groupList.Groups {
+		for _, version := range group.Versions {
+			resourceList, err := options.discoveryClient.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return fmt.Errorf("couldn't get server resources for group version %s: %w", version.GroupVersion, err)
+			}
+
+			resourceList.Kind = "APIResourceList"
+			resourceList.APIVersion = "v1"
+
+			err = writeDumpDocument(
+				options.Directory, filepath.Join(version.GroupVersion, serverResourcesFilename), resourceList)
+			if err != nil {
+				return err
+			}
+
+			groupVersions++
+		}
+	}
+
+	serverVersion, err := options.discoveryClient.ServerVersion()
+	if err == nil {
+		err = writeDumpDocument(options.Directory, serverVersionFilename, serverVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(options.Out, "Wrote %d group versions to %s\n", groupVersions, options.Directory)
+	if err != nil {
+		return fmt.Errorf("couldn't write summary: %w", err)
+	}
+
+	return nil
+}
+
+// writeDumpDocument writes the discovery document as JSON to the file, relative to the directory.
+func writeDumpDocument(directory, filename string, document any) error {
+	path := filepath.Join(directory, filename)
+
+	content, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal %s: %w", path, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), dumpDirectoryPermissions)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory for %s: %w", path, err)
+	}
+
+	err = writeFileAtomically(path, dumpFilePermissions, func(out io.Writer) error {
+		_, err := out.Write(content)
+
+		//nolint:wrapcheck
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't write %s: %w", path, err)
+	}
+
+	return nil
+}
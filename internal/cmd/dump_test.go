@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunDump tests that the dumped discovery documents are read back as the same resources.
+func TestRunDump(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newDumpOptions(ioStreams)
+	options.Directory = t.TempDir()
+	options.discoveryClient = discoverytesting.New()
+
+	err := runDump(options)
+	if err != nil {
+		t.Fatalf("runDump() error = %v", err)
+	}
+
+	if got, want := stdout.String(), "Wrote 4 group versions to "+options.Directory+"\n"; got != want {
+		t.Errorf("runDump() output = %q, want %q", got, want)
+	}
+
+	wantOptions := NewTestOptionsBuilder().SetIncludeSubresources(true).APIResourceVersionsOptions()
+
+	want, err := getGroupResources(t.Context(), wantOptions)
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	gotOptions := NewTestOptionsBuilder().
+		SetIncludeSubresources(true).
+		WithDiscoveryClient(newDirectoryDiscoveryClient(options.Directory)).
+		APIResourceVersionsOptions()
+
+	got, err := getGroupResources(t.Context(), gotOptions)
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("getGroupResources() returned %d resources from the dump, want %d", len(got), len(want))
+	}
+
+	for i := range got {
+		if got[i].fullname() != want[i].fullname() || got[i].Preferred != want[i].Preferred ||
+			!reflect.DeepEqual(got[i].APIResource.Verbs, want[i].APIResource.Verbs) {
+			t.Errorf("resource %d = %s, want %s", i, got[i].fullname(), want[i].fullname())
+		}
+	}
+}
+
+// TestDumpOptionsValidate tests the validation of the dump options.
+func TestDumpOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	options := newDumpOptions(genericiooptions.NewTestIOStreamsDiscard())
+
+	err := options.validate()
+	if !errors.Is(err, errDumpDirectory) {
+		t.Errorf("validate() error = %v, wantErr %v", err, errDumpDirectory)
+	}
+}
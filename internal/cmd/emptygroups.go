@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"cmp"
+	"io"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// emptyGroupVersionName is printed in the NAME column of the group versions which have no resources with
+// --show-empty-groups.
+const emptyGroupVersionName = "<none>"
+
+// errEmptyGroupsOutput is returned when --show-empty-groups is requested with an output other than the default and
+// wide tables.
+const errEmptyGroupsOutput = constError("show-empty-groups is only supported with the default and wide outputs, " +
+	"without stream, watch, exec, or fzf")
+
+// clusterGroupVersion is a group version of a cluster.
+type clusterGroupVersion struct {
+	// Cluster is the kubeconfig context the group version was discovered in, when listing multiple contexts.
+	Cluster string
+	// GroupVersion is the group version, e.g. "apps/v1".
+	GroupVersion string
+}
+
+// validateEmptyGroups checks that --show-empty-groups is requested with an output printing the group versions as
+// rows of a table.
+func (o *apiResourceVersionsOptions) validateEmptyGroups() error {
+	if !o.ShowEmptyGroups {
+		return nil
+	}
+
+	if (o.Output != "" && o.Output != wideOutput) || o.Stream || o.Watch || o.Exec != "" || o.Fzf {
+		return errEmptyGroupsOutput
+	}
+
+	return nil
+}
+
+// matchedGroupVersions returns the group versions of the groups which matched the group filters.
+func matchedGroupVersions(groups []*metav1.APIGroup) []clusterGroupVersion {
+	var groupVersions []clusterGroupVersion
+
+	for _, group := range groups {
+		for _, version := range group.Versions {
+			groupVersions = append(groupVersions, clusterGroupVersion{Cluster: "", GroupVersion: version.GroupVersion})
+		}
+	}
+
+	return groupVersions
+}
+
+// emptyGroupVersions returns the group versions which matched the group filters but have no resources left after the
+// resources were filtered, sorted by cluster and group version.
+func emptyGroupVersions(resources []groupResource, options *apiResourceVersionsOptions) []clusterGroupVersion {
+	listed := sets.New[clusterGroupVersion]()
+	for _, resource := range resources {
+		listed.Insert(clusterGroupVersion{Cluster: resource.Cluster, GroupVersion: resource.APIGroupVersion})
+	}
+
+	var empty []clusterGroupVersion
+
+	for _, groupVersion := range options.matchedGroupVersions {
+		if !listed.Has(groupVersion) {
+			empty = append(empty, groupVersion)
+		}
+	}
+
+	slices.SortFunc(empty, func(left, right clusterGroupVersion) int {
+		return cmp.Or(cmp.Compare(left.Cluster, right.Cluster), cmp.Compare(left.GroupVersion, right.GroupVersion))
+	})
+
+	return slices.Compact(empty)
+}
+
+// printEmptyGroupVersions prints a row for each group version which has no resources, with [emptyGroupVersionName]
+// as its name, after the rows of the resources.
+func printEmptyGroupVersions(writer io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	for _, groupVersion := range emptyGroupVersions(resources, options) {
+		var columns []string
+		if len(options.clusters) > 0 {
+			columns = append(columns, groupVersion.Cluster)
+		}
+
+		if options.ShowServerVersion {
+			columns = append(columns, "")
+		}
+
+		columns = append(columns, emptyGroupVersionName, "", options.displayGroupVersion(groupVersion.GroupVersion))
+
+		err := printRow(writer, truncateColumns(columns, options.maxColumnWidths))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
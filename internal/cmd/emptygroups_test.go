@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunEmptyGroups tests listing the group versions which have no resources left after filtering.
+func TestRunEmptyGroups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Preferred", runEmptyGroupsTest{
+		builder: NewTestOptionsBuilder().SetPreferred(true),
+		want: "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true\n" +
+			"<none>                                  autoscaling/v1\n" +
+			"<none>                                  autoscaling/v2beta2\n",
+	}.Test)
+	t.Run("NoResources", runEmptyGroupsTest{
+		builder: NewTestOptionsBuilder().SetVerbs([]string{"proxy"}),
+		want: "NAME     SHORTNAMES   APIVERSION   NAMESPACED   KIND   PREFERRED\n" +
+			"<none>                autoscaling/v1\n" +
+			"<none>                autoscaling/v2\n" +
+			"<none>                autoscaling/v2beta2\n",
+	}.Test)
+}
+
+type runEmptyGroupsTest struct {
+	builder *APIResourceVersionsOptionsBuilder
+	want    string
+}
+
+func (tt runEmptyGroupsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := tt.builder.SetAPIGroup("autoscaling").SetShowEmptyGroups(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// environmentPrefix is the prefix of the environment variables setting the flags, e.g. KUBECTL_ARV_SORT_BY.
+const environmentPrefix = "KUBECTL_ARV_"
+
+// flagEnvironmentVariable returns the name of the environment variable setting the flag.
+func flagEnvironmentVariable(name string) string {
+	return environmentPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnvironment sets the flags which weren't given on the command line to the values of their KUBECTL_ARV_*
+// environment variables, adding them to the given flags.
+// Lists are comma separated, like on the command line.
+func applyEnvironment(flags *pflag.FlagSet, given sets.Set[string]) error {
+	var err error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || given.Has(flag.Name) {
+			return
+		}
+
+		variable := flagEnvironmentVariable(flag.Name)
+
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			return
+		}
+
+		setErr := flag.Value.Set(value)
+		if setErr != nil {
+			err = fmt.Errorf("invalid value %q for flag %s in %s: %w", value, flag.Name, variable, setErr)
+
+			return
+		}
+
+		setInherited(flag)
+
+		given.Insert(flag.Name)
+	})
+
+	return err
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestApplyFlagDefaultsEnvironment tests the precedence of the flags, the environment variables, and the config file.
+func TestApplyFlagDefaultsEnvironment(t *testing.T) { //nolint:paralleltest // Setting the environment variables.
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(filename, []byte(testConfigFile), 0o600)
+	if err != nil {
+		t.Fatalf("couldn't write config file: %v", err)
+	}
+
+	t.Setenv("KUBECTL_ARV_CONFIG", filename)
+	t.Setenv("KUBECTL_ARV_SORT_BY", "name")
+	t.Setenv("KUBECTL_ARV_VERBS", "watch,list")
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), ioStreams)
+
+	err = cmd.ParseFlags([]string{"--verbs=get"})
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+
+	err = applyFlagDefaults(cmd)
+	if err != nil {
+		t.Fatalf("applyFlagDefaults() error = %v", err)
+	}
+
+	want := map[string]string{"sort-by": "name", "verbs": "[get]", "request-timeout": "10s"}
+	for name, want := range want {
+		got := cmd.Flag(name).Value.String()
+		if got != want {
+			t.Errorf("applyFlagDefaults() flag %s = %s, want %s", name, got, want)
+		}
+	}
+
+	inherited := sets.List(inheritedFlags(cmd.Flags()))
+	if !slices.Equal(inherited, []string{"config", "request-timeout", "sort-by"}) {
+		t.Errorf("inheritedFlags() = %v, want [config request-timeout sort-by]", inherited)
+	}
+
+	t.Setenv("KUBECTL_ARV_QUIET", "maybe")
+
+	err = applyFlagDefaults(cmd)
+	if err == nil {
+		t.Errorf("applyFlagDefaults() error = nil, want an error for KUBECTL_ARV_QUIET")
+	}
+}
+
+// TestFlagEnvironmentVariable tests the names of the environment variables of the flags.
+func TestFlagEnvironmentVariable(t *testing.T) {
+	t.Parallel()
+
+	got := flagEnvironmentVariable("discovery-concurrency")
+	if got != "KUBECTL_ARV_DISCOVERY_CONCURRENCY" {
+		t.Errorf("flagEnvironmentVariable() = %s, want KUBECTL_ARV_DISCOVERY_CONCURRENCY", got)
+	}
+}
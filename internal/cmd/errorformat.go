@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// errorFormatFlag is the name of the flag selecting the format of the errors printed on stderr.
+const errorFormatFlag = "error-format"
+
+// Error formats of --error-format.
+const (
+	// textErrorFormat prints the errors like kubectl, e.g. "error: check failed".
+	textErrorFormat = "text"
+	// jsonErrorFormat prints the errors as a single line JSON object with an error code, see [jsonError].
+	jsonErrorFormat = "json"
+)
+
+// errErrorFormat is returned when the error format is not supported.
+const errErrorFormat = constError("error-format must be one of: (" + textErrorFormat + ", " + jsonErrorFormat + ")")
+
+// Error codes of the errors printed with --error-format=json.
+// The errors returned by the API server have the reason of their status as code instead, e.g. Forbidden.
+const (
+	// errorCodeInvalidArgument is the code of the errors of the validation of the flags and arguments.
+	errorCodeInvalidArgument = "InvalidArgument"
+	// errorCodeCheckFailed is the code of the error of the check command when manifests are as severe as --fail-on.
+	errorCodeCheckFailed = "CheckFailed"
+	// errorCodeTimeout is the code of the errors of the operations which exceeded their --timeout.
+	errorCodeTimeout = "Timeout"
+	// errorCodeDiscoveryFailed is the code of the errors of the group versions which couldn't be discovered.
+	errorCodeDiscoveryFailed = "DiscoveryFailed"
+	// errorCodeConnectionFailed is the code of the errors of the connection to the API server.
+	errorCodeConnectionFailed = "ConnectionFailed"
+	// errorCodeUnknown is the code of every other error.
+	errorCodeUnknown = "Unknown"
+)
+
+// errorFormatValue is the value of --error-format, validated when the flag is parsed so that an invalid format
+// isn't only reported along with another error.
+type errorFormatValue string
+
+// String implements [pflag.Value].
+func (v *errorFormatValue) String() string {
+	return string(*v)
+}
+
+// Set implements [pflag.Value].
+func (v *errorFormatValue) Set(value string) error {
+	if value != textErrorFormat && value != jsonErrorFormat {
+		return fmt.Errorf("%w: got %s", errErrorFormat, value)
+	}
+
+	*v = errorFormatValue(value)
+
+	return nil
+}
+
+// Type implements [pflag.Value].
+func (v *errorFormatValue) Type() string {
+	return "string"
+}
+
+// addErrorFormatFlag adds the --error-format flag to the flag set.
+func addErrorFormatFlag(flags *pflag.FlagSet) {
+	value := errorFormatValue(textErrorFormat)
+
+	flags.Var(&value, errorFormatFlag,
+		"Format of the errors printed on stderr. One of: ("+textErrorFormat+", "+jsonErrorFormat+").")
+}
+
+// invalidArgumentError is an error of the validation of the flags and arguments.
+type invalidArgumentError struct {
+	err error
+}
+
+// invalidArgument marks the error of the validation of the flags and arguments, or returns nil if it is nil.
+func invalidArgument(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &invalidArgumentError{err: err}
+}
+
+// Error implements the error interface.
+func (e *invalidArgumentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the validation error.
+func (e *invalidArgumentError) Unwrap() error {
+	return e.err
+}
+
+// checkFailedError is returned by the check command when manifests have a status at least as severe as --fail-on.
+type checkFailedError struct {
+	failOn manifestStatus
+	// findings are the findings at least as severe as failOn.
+	findings []manifestFinding
+}
+
+// Error implements the error interface.
+func (e *checkFailedError) Error() string {
+	return fmt.Sprintf("%s: %d manifests are %s or worse", errCheckFailed, len(e.findings), e.failOn)
+}
+
+// Unwrap returns [errCheckFailed].
+func (e *checkFailedError) Unwrap() error {
+	return errCheckFailed
+}
+
+// jsonError is an error printed with --error-format=json.
+type jsonError struct {
+	// Code identifies the kind of the error, e.g. InvalidArgument or Forbidden.
+	Code string `json:"code"`
+	// Message is the message of the error, as printed with --error-format=text.
+	Message string `json:"message"`
+	// Findings are the findings which failed the check, with the CheckFailed code.
+	Findings []jsonErrorFinding `json:"findings,omitempty"`
+}
+
+// jsonErrorFinding is a finding which failed the check, printed with --error-format=json.
+type jsonErrorFinding struct {
+	Location         string         `json:"location"`
+	APIVersion       string         `json:"apiVersion"`
+	Kind             string         `json:"kind"`
+	Name             string         `json:"name"`
+	Status           manifestStatus `json:"status"`
+	PreferredVersion string         `json:"preferredVersion,omitempty"`
+	Rejection        string         `json:"rejection,omitempty"`
+}
+
+// errorCode returns the code of the error printed with --error-format=json.
+func errorCode(err error) string {
+	var (
+		invalid              *invalidArgumentError
+		groupDiscoveryFailed *discovery.ErrGroupDiscoveryFailed
+		netErr               net.Error
+	)
+
+	switch {
+	case errors.As(err, &invalid):
+		return errorCodeInvalidArgument
+	case errors.Is(err, errCheckFailed):
+		return errorCodeCheckFailed
+	case errors.Is(err, errTimeout), errors.Is(err, context.DeadlineExceeded):
+		return errorCodeTimeout
+	case apierrors.ReasonForError(err) != metav1.StatusReasonUnknown:
+		return string(apierrors.ReasonForError(err))
+	case errors.As(err, &groupDiscoveryFailed), errors.Is(err, errDiscoveryDirectory),
+		errors.Is(err, errDiscoveryFiles):
+		return errorCodeDiscoveryFailed
+	case errors.As(err, &netErr):
+		return errorCodeConnectionFailed
+	default:
+		return errorCodeUnknown
+	}
+}
+
+// newJSONError returns the error printed with --error-format=json.
+func newJSONError(err error) jsonError {
+	jsonErr := jsonError{Code: errorCode(err), Message: err.Error()}
+
+	var checkFailed *checkFailedError
+	if errors.As(err, &checkFailed) {
+		jsonErr.Findings = make([]jsonErrorFinding, 0, len(checkFailed.findings))
+
+		for _, finding := range checkFailed.findings {
+			jsonErr.Findings = append(jsonErr.Findings, jsonErrorFinding{
+				Location:         finding.Location.String(),
+				APIVersion:       finding.APIVersion,
+				Kind:             finding.Kind,
+				Name:             finding.Name,
+				Status:           finding.Status,
+				PreferredVersion: finding.PreferredVersion,
+				Rejection:        finding.Rejection,
+			})
+		}
+	}
+
+	return jsonErr
+}
+
+// writeJSONError writes the error as a single line JSON object.
+func writeJSONError(writer io.Writer, err error) error {
+	//nolint:wrapcheck
+	return json.NewEncoder(writer).Encode(newJSONError(err))
+}
+
+// checkErr prints the error in the --error-format of the command and exits if it isn't nil, like [cmdutil.CheckErr].
+func checkErr(cmd *cobra.Command, err error) {
+	if err == nil {
+		return
+	}
+
+	// The spans are exported before exiting, as the failed commands are the most interesting to trace.
+	finishTracing(cmd, err)
+
+	flag := cmd.Flag(errorFormatFlag)
+	if flag == nil || flag.Value.String() != jsonErrorFormat {
+		cmdutil.CheckErr(err)
+
+		return
+	}
+
+	writeErr := writeJSONError(cmd.ErrOrStderr(), err)
+	if writeErr != nil {
+		panic(fmt.Errorf("error encountered while writing %w: %w", err, writeErr))
+	}
+
+	os.Exit(cmdutil.DefaultErrorExitCode)
+}
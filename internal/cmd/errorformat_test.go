@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// TestErrorCode tests the codes of the errors printed with --error-format=json.
+func TestErrorCode(t *testing.T) {
+	t.Parallel()
+
+	connectionRefused := &url.Error{Op: "Get", URL: "https://127.0.0.1:1/api", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: errors.New("connection refused"),
+	}}
+
+	for name, tt := range map[string]struct {
+		err  error
+		want string
+	}{
+		"InvalidArgument": {err: invalidArgument(fmt.Errorf("%w: got -1", errRetries)), want: errorCodeInvalidArgument},
+		"CheckFailed": {
+			err:  &checkFailedError{failOn: manifestStatusDeprecated, findings: []manifestFinding{{}}},
+			want: errorCodeCheckFailed,
+		},
+		"Timeout":          {err: fmt.Errorf("couldn't get server groups: %w", errTimeout), want: errorCodeTimeout},
+		"DeadlineExceeded": {err: context.DeadlineExceeded, want: errorCodeTimeout},
+		"Forbidden": {
+			err:  apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("forbidden")),
+			want: "Forbidden",
+		},
+		"DiscoveryFailed": {
+			err: &discovery.ErrGroupDiscoveryFailed{
+				Groups: map[schema.GroupVersion]error{{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("503")},
+			},
+			want: errorCodeDiscoveryFailed,
+		},
+		"ConnectionFailed": {
+			err:  fmt.Errorf("couldn't get server groups: %w", connectionRefused),
+			want: errorCodeConnectionFailed,
+		},
+		"Unknown": {err: errors.New("unknown"), want: errorCodeUnknown},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteJSONError tests printing the error as a single line JSON object, with the findings which failed the check.
+func TestWriteJSONError(t *testing.T) {
+	t.Parallel()
+
+	findings := []manifestFinding{{
+		Location:         manifestLocation{Filename: "deploy.yaml", Document: 1, Item: -1},
+		APIVersion:       "autoscaling/v2beta2",
+		Kind:             "HorizontalPodAutoscaler",
+		Name:             "default/web",
+		Status:           manifestStatusAbsent,
+		PreferredVersion: "autoscaling/v2",
+	}}
+
+	var buffer bytes.Buffer
+
+	err := writeJSONError(&buffer, checkFailures(findings, string(manifestStatusDeprecated)))
+	if err != nil {
+		t.Fatalf("writeJSONError() error = %v", err)
+	}
+
+	if lines := bytes.Count(buffer.Bytes(), []byte("\n")); lines != 1 {
+		t.Errorf("writeJSONError() wrote %d lines, want 1", lines)
+	}
+
+	var got jsonError
+
+	err = json.Unmarshal(buffer.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := jsonErrorFinding{
+		Location:         "deploy.yaml[1]",
+		APIVersion:       "autoscaling/v2beta2",
+		Kind:             "HorizontalPodAutoscaler",
+		Name:             "default/web",
+		Status:           manifestStatusAbsent,
+		PreferredVersion: "autoscaling/v2",
+	}
+
+	if got.Code != errorCodeCheckFailed || len(got.Findings) != 1 || got.Findings[0] != want {
+		t.Errorf("writeJSONError() = %s, want code %s and finding %+v", buffer.String(), errorCodeCheckFailed, want)
+	}
+
+	if got.Message != "check failed: 1 manifests are deprecated or worse" {
+		t.Errorf("writeJSONError() message = %q", got.Message)
+	}
+}
+
+// TestErrorFormatValue tests rejecting the unsupported error formats when the flag is parsed.
+func TestErrorFormatValue(t *testing.T) {
+	t.Parallel()
+
+	value := errorFormatValue(textErrorFormat)
+
+	err := value.Set(jsonErrorFormat)
+	if err != nil || value.String() != jsonErrorFormat {
+		t.Errorf("Set(%s) = %v, value %s", jsonErrorFormat, err, value.String())
+	}
+
+	err = value.Set("xml")
+	if !errors.Is(err, errErrorFormat) {
+		t.Errorf("Set(xml) error = %v, want %v", err, errErrorFormat)
+	}
+}
+
+// TestCheckErrText tests printing the errors in the text format with [cmdutil.CheckErr], the default.
+func TestCheckErrText(t *testing.T) { //nolint:paralleltest // Setting the behavior on fatal errors.
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	var (
+		gotMsg  string
+		gotCode int
+	)
+
+	cmdutil.BehaviorOnFatal(func(msg string, code int) {
+		gotMsg, gotCode = msg, code
+	})
+
+	cmd := &cobra.Command{Use: "test"}
+	addErrorFormatFlag(cmd.Flags())
+
+	checkErr(cmd, errors.New("unknown"))
+
+	if gotMsg != "error: unknown" || gotCode != cmdutil.DefaultErrorExitCode {
+		t.Errorf("checkErr() exited with %q and code %d, want %q and code %d",
+			gotMsg, gotCode, "error: unknown", cmdutil.DefaultErrorExitCode)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	apimachineryerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// defaultExecConcurrency is the default number of --exec commands which run concurrently.
+const defaultExecConcurrency = 4
+
+// errExecMode is returned when --exec is requested with an option which prints the resources instead, or with
+// multiple clusters, as the commands run against the current context.
+const errExecMode = constError("exec is not supported with output, show-commands, stream, watch, compare-release, " +
+	"all-contexts, contexts, or clusters-file")
+
+// errExecConcurrency is returned when the concurrency of the --exec commands is not positive.
+const errExecConcurrency = constError("exec-concurrency must be positive")
+
+// validateExec checks that --exec isn't requested with an option which prints the resources, and that its
+// concurrency is valid.
+func (o *apiResourceVersionsOptions) validateExec() error {
+	if o.Exec == "" {
+		return nil
+	}
+
+	if o.Output != "" || o.ShowCommands || o.Stream || o.Watch || o.CompareRelease != "" || o.AllContexts ||
+		len(o.Contexts) > 0 || o.ClustersFile != "" {
+		return errExecMode
+	}
+
+	if o.ExecConcurrency <= 0 {
+		return fmt.Errorf("%w: got %d", errExecConcurrency, o.ExecConcurrency)
+	}
+
+	return nil
+}
+
+// execCommand returns the --exec command of the resource, with the {group}, {version}, {resource}, and {fullname}
+// placeholders replaced by those of the resource, each quoted as a single shell word so that the names served by
+// the API server, e.g. by an aggregated API server, can't inject shell commands.
+// The core group is an empty word.
+func (gr groupResource) execCommand(template string) string {
+	groupVersionResource := gr.groupVersionResource()
+
+	return strings.NewReplacer(
+		"{group}", shellQuote(groupVersionResource.Group),
+		"{version}", shellQuote(groupVersionResource.Version),
+		"{resource}", shellQuote(gr.APIResource.Name),
+		"{fullname}", shellQuote(gr.fullname()),
+	).Replace(template)
+}
+
+// shellQuote returns the string single-quoted for sh, with its single quotes escaped.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// execResult is the outcome of the --exec command of a resource.
+type execResult struct {
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	err    error
+	// done is closed once the command has exited.
+	done chan struct{}
+}
+
+// runExec runs the --exec command of each resource with sh, at most --exec-concurrency at once.
+// The outputs of the commands are written in the order of the resources as soon as their command exits, and the
+// failed commands are reported once all of them have run.
+// If the outputs can't be written, the remaining commands are canceled before returning.
+func runExec(ctx context.Context, resources []groupResource, options *apiResourceVersionsOptions) error {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	results := make([]*execResult, len(resources))
+	for i := range results {
+		results[i] = &execResult{done: make(chan struct{})}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var group errgroup.Group
+	group.SetLimit(options.ExecConcurrency)
+
+	// started is closed once no more commands are started, so that the group can be waited for.
+	started := make(chan struct{})
+
+	go func() {
+		defer close(started)
+
+		for i, resource := range resources {
+			if context.Cause(ctx) != nil {
+				return
+			}
+
+			group.Go(func() error {
+				defer close(results[i].done)
+
+				command := resource.execCommand(options.Exec)
+
+				cmd := exec.CommandContext(ctx, "sh", "-c", command)
+				cmd.Stdout = &results[i].stdout
+				cmd.Stderr = &results[i].stderr
+
+				err := cmd.Run()
+				if err != nil {
+					results[i].err = fmt.Errorf("command %q of %s failed: %w", command, resource.fullname(), err)
+				}
+
+				return nil
+			})
+		}
+	}()
+
+	errs, err := writeExecResults(results, options)
+
+	cancel()
+	<-started
+	// The commands never fail the group, their errors are in their results.
+	_ = group.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return apimachineryerrors.NewAggregate(errs)
+}
+
+// writeExecResults writes the outputs of the commands in the order of the resources as soon as their command exits,
+// and returns the errors of the failed commands, or the error writing their outputs.
+func writeExecResults(results []*execResult, options *apiResourceVersionsOptions) ([]error, error) {
+	var errs []error
+
+	for _, result := range results {
+		<-result.done
+
+		_, err := result.stdout.WriteTo(options.Out)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't write command output: %w", err)
+		}
+
+		_, err = result.stderr.WriteTo(options.ErrOut)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't write command error output: %w", err)
+		}
+
+		if result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRunExec tests running the --exec command of each resource, with its output in the order of the resources.
+func TestRunExec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Placeholders", runExecTest{
+		exec: "echo {group} {version} {resource} {fullname}",
+		want: "autoscaling v2 horizontalpodautoscalers horizontalpodautoscalers.v2.autoscaling\n" +
+			"autoscaling v1 horizontalpodautoscalers horizontalpodautoscalers.v1.autoscaling\n" +
+			"autoscaling v2beta2 horizontalpodautoscalers horizontalpodautoscalers.v2beta2.autoscaling\n",
+	}.Test)
+	t.Run("Failed", runExecTest{
+		exec:    "echo {version}; test {version} = v1",
+		want:    "v2\nv1\nv2beta2\n",
+		wantErr: true,
+	}.Test)
+}
+
+type runExecTest struct {
+	exec    string
+	want    string
+	wantErr bool
+}
+
+func (tt runExecTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetExec(tt.exec)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if (err != nil) != tt.wantErr {
+		t.Fatalf("runAPIResourceVersions() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+}
+
+// TestRunExecWriteFailed tests that the remaining commands are canceled and waited for when their output can't be
+// written.
+func TestRunExecWriteFailed(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	resources := make([]groupResource, 8)
+	for i := range resources {
+		resources[i] = groupResource{
+			APIGroup:        &resourceGroup{Name: "example.com"},
+			APIGroupVersion: "example.com/v1",
+			APIResource:     &metav1.APIResource{Name: fmt.Sprintf("widgets%d", i)},
+		}
+	}
+
+	options := NewTestOptionsBuilder().SetExec("echo {resource} && touch " + directory + "/{resource}").
+		APIResourceVersionsOptions()
+	options.ExecConcurrency = 1
+	options.Out = failingWriter{}
+
+	err := runExec(t.Context(), resources, options)
+	if err == nil {
+		t.Fatalf("runExec() error = nil, want an error writing the output")
+	}
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatalf("couldn't read directory: %v", err)
+	}
+
+	// The command writing the output and the one started concurrently at most.
+	if len(entries) > 2 {
+		t.Errorf("runExec() ran %d commands, want at most 2", len(entries))
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+// TestExecCommand tests that the placeholders of the --exec command are replaced by single shell words, whatever the
+// names served by the API server.
+func TestExecCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Core", execCommandTest{
+		group:    "",
+		resource: "pods",
+		want:     "[] [v1] [pods] [pods.v1.]",
+	}.Test)
+	t.Run("Injection", execCommandTest{
+		group:    "example.com",
+		resource: "widgets;echo injected",
+		want:     "[example.com] [v1] [widgets;echo injected] [widgets;echo injected.v1.example.com]",
+	}.Test)
+	t.Run("SingleQuote", execCommandTest{
+		group:    "example.com",
+		resource: "it's$(echo injected)",
+		want:     "[example.com] [v1] [it's$(echo injected)] [it's$(echo injected).v1.example.com]",
+	}.Test)
+}
+
+type execCommandTest struct {
+	group    string
+	resource string
+	want     string
+}
+
+func (tt execCommandTest) Test(t *testing.T) {
+	t.Parallel()
+
+	groupVersion := metav1.GroupVersion{Group: tt.group, Version: "v1"}.String()
+	resource := groupResource{
+		APIGroup:        &resourceGroup{Name: tt.group},
+		APIGroupVersion: groupVersion,
+		APIResource:     &metav1.APIResource{Name: tt.resource},
+	}
+
+	command := resource.execCommand(`printf '[%s] ' {group} {version} {resource} {fullname}`)
+
+	output, err := exec.CommandContext(t.Context(), "sh", "-c", command).Output()
+	if err != nil {
+		t.Fatalf("sh -c %q error = %v", command, err)
+	}
+
+	if got := string(output); got != tt.want+" " {
+		t.Errorf("sh -c %q output = %q, want %q", command, got, tt.want+" ")
+	}
+}
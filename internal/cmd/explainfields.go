@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// schemaRefPrefix is the prefix of the references to the schemas of the components of an OpenAPI v3 document.
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	// explainFieldsExample is the example text for the explain-fields command.
+	//
+	//nolint:gochecknoglobals
+	explainFieldsExample = `
+		# Print the fields of the HorizontalPodAutoscalers in a version which isn't preferred
+		kubectl api-resource-versions explain-fields hpa --api-version=autoscaling/v2beta2
+
+		# Compare the fields of two versions of a resource
+		diff <(kubectl api-resource-versions explain-fields hpa --api-version=autoscaling/v1) \
+			<(kubectl api-resource-versions explain-fields hpa --api-version=autoscaling/v2)`
+)
+
+// newCmdExplainFields returns a command that prints the field tree of a resource in a group version.
+func newCmdExplainFields(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newExplainFieldsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "explain-fields RESOURCE",
+		Short: "Print the field tree of a resource in any of its served versions",
+		Long: "Print the tree of the fields of the resource from the OpenAPI v3 schema of the group version, like " +
+			"kubectl explain --recursive, but in any served version of the resource rather than its preferred " +
+			"version.\n" +
+			"The resource is resolved like the versions command. The preferred version is used without --api-version.",
+		Example:           templates.Examples(explainFieldsExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runExplainFields(options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.APIVersion, "api-version", options.APIVersion,
+		"The group version of the resource to explain, e.g. autoscaling/v2beta2. Defaults to the preferred version.")
+
+	return cmd
+}
+
+// explainFieldsOptions contains the options for the explain-fields command.
+type explainFieldsOptions struct {
+	genericiooptions.IOStreams
+
+	APIVersion string
+
+	resource        string
+	discoveryClient discovery.DiscoveryInterface
+	openAPIClient   openapi.Client
+}
+
+// newExplainFieldsOptions returns a new [explainFieldsOptions] with default values.
+func newExplainFieldsOptions(ioStreams genericiooptions.IOStreams) *explainFieldsOptions {
+	return &explainFieldsOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// errOpenAPIUnavailable is returned when the OpenAPI v3 schema can't be fetched, e.g. with --from-dump.
+const errOpenAPIUnavailable = constError("the OpenAPI v3 schema isn't available")
+
+// complete completes all the required options for the explain-fields command.
+func (o *explainFieldsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 1 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "exactly one resource is required, got: %v", args)
+	}
+
+	o.resource = args[0]
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	o.openAPIClient = discoveryClient.OpenAPIV3()
+	if o.openAPIClient == nil {
+		return fmt.Errorf("%w, e.g. with --from-dump", errOpenAPIUnavailable)
+	}
+
+	return nil
+}
+
+// errAPIVersionNotServed is returned when the resource isn't served in the --api-version.
+const errAPIVersionNotServed = constError("the resource isn't served in the api-version")
+
+// runExplainFields prints the field tree of the resource in the selected group version.
+func runExplainFields(options *explainFieldsOptions) error {
+	kind, err := getKindSchema(options.discoveryClient, options.openAPIClient, options.resource, options.APIVersion)
+	if err != nil {
+		return err
+	}
+
+	return printFieldTree(options.Out, kind)
+}
+
+// kindSchema is the OpenAPI v3 schema of a kind, along with the schemas of the components which it references.
+type kindSchema struct {
+	GroupVersionKind schema.GroupVersionKind
+	Schema           *spec.Schema
+	Components       map[string]*spec.Schema
+}
+
+// getKindSchema resolves the resource in the group version, or in its preferred version if apiVersion is empty, and
+// returns the OpenAPI v3 schema of its kind.
+func getKindSchema(
+	discoveryClient discovery.DiscoveryInterface,
+	openAPIClient openapi.Client,
+	resource string,
+	apiVersion string,
+) (*kindSchema, error) {
+	versions, err := getServedVersions(discoveryClient, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(versions, func(version servedVersion) bool {
+		if apiVersion == "" {
+			return version.Preferred
+		}
+
+		return version.GroupVersionResource.GroupVersion().String() == apiVersion
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s isn't served in %s", errAPIVersionNotServed, resource, apiVersion)
+	}
+
+	return findKindSchema(openAPIClient, versions[i].GroupVersionResource.GroupVersion().WithKind(versions[i].Kind))
+}
+
+// findKindSchema returns the OpenAPI v3 schema of the kind from the document of its group version.
+func findKindSchema(openAPIClient openapi.Client, gvk schema.GroupVersionKind) (*kindSchema, error) {
+	document, err := getOpenAPIDocument(openAPIClient, gvk.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	if document.Components == nil {
+		return nil, fmt.Errorf("%w %s", errKindSchemaNotFound, gvk)
+	}
+
+	for _, componentSchema := range document.Components.Schemas {
+		if slices.Contains(schemaGroupVersionKinds(componentSchema), gvk) {
+			return &kindSchema{GroupVersionKind: gvk, Schema: componentSchema, Components: document.Components.Schemas}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %s", errKindSchemaNotFound, gvk)
+}
+
+// getOpenAPIDocument fetches the OpenAPI v3 document of the group version.
+func getOpenAPIDocument(client openapi.Client, groupVersion schema.GroupVersion) (*spec3.OpenAPI, error) {
+	paths, err := client.Paths()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get OpenAPI v3 paths: %w", err)
+	}
+
+	path := "apis/" + groupVersion.String()
+	if groupVersion.Group == "" {
+		path = "api/" + groupVersion.Version
+	}
+
+	openAPIGroupVersion, ok := paths[path]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", errOpenAPIUnavailable, groupVersion)
+	}
+
+	content, err := openAPIGroupVersion.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get OpenAPI v3 schema of %s: %w", groupVersion, err)
+	}
+
+	document := &spec3.OpenAPI{}
+
+	err = json.Unmarshal(content, document)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse OpenAPI v3 schema of %s: %w", groupVersion, err)
+	}
+
+	return document, nil
+}
+
+// errKindSchemaNotFound is returned when the OpenAPI v3 document has no schema for the kind.
+const errKindSchemaNotFound = constError("no OpenAPI v3 schema found for")
+
+// printFieldTree prints the fields of the schema of the kind, e.g.:
+//
+//	KIND:       Deployment
+//	VERSION:    apps/v1
+//
+//	FIELDS:
+//	  apiVersion	<string>
+//	  spec	<DeploymentSpec>
+//	    replicas	<integer>
+//	    selector	<LabelSelector> -required-
+func printFieldTree(out io.Writer, kind *kindSchema) error {
+	var builder strings.Builder
+
+	gvk := kind.GroupVersionKind
+	fmt.Fprintf(&builder, "KIND:       %s\nVERSION:    %s\n\nFIELDS:\n", gvk.Kind, gvk.GroupVersion())
+	writeFields(&builder, kind.Components, kind.Schema, 1, sets.New[string]())
+
+	_, err := io.WriteString(out, builder.String())
+	if err != nil {
+		return fmt.Errorf("couldn't write fields: %w", err)
+	}
+
+	return nil
+}
+
+// schemaGroupVersionKinds returns the kinds of the x-kubernetes-group-version-kind extension of the schema.
+func schemaGroupVersionKinds(componentSchema *spec.Schema) []schema.GroupVersionKind {
+	extension, _ := componentSchema.Extensions[gvkExtension].([]any)
+
+	gvks := make([]schema.GroupVersionKind, 0, len(extension))
+
+	for _, item := range extension {
+		fields, _ := item.(map[string]any)
+		group, _ := fields["group"].(string)
+		version, _ := fields["version"].(string)
+		kind, _ := fields["kind"].(string)
+
+		gvks = append(gvks, schema.GroupVersionKind{Group: group, Version: version, Kind: kind})
+	}
+
+	return gvks
+}
+
+// writeFields writes the properties of the object schema sorted by name, then their own fields indented below them.
+// The references being expanded are skipped to stop at recursive schemas, e.g. JSONSchemaProps.
+func writeFields(
+	builder *strings.Builder,
+	schemas map[string]*spec.Schema,
+	objectSchema *spec.Schema,
+	depth int,
+	expanding sets.Set[string],
+) {
+	names := make([]string, 0, len(objectSchema.Properties))
+	for name := range objectSchema.Properties {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		property := objectSchema.Properties[name]
+
+		builder.WriteString(strings.Repeat("  ", depth) + name + "\t<" + schemaTypeName(&property) + ">")
+
+		if slices.Contains(objectSchema.Required, name) {
+			builder.WriteString(" -required-")
+		}
+
+		builder.WriteString("\n")
+
+		ref, fieldSchema := resolveFieldSchema(schemas, &property)
+		if fieldSchema == nil || expanding.Has(ref) {
+			continue
+		}
+
+		if ref != "" {
+			expanding.Insert(ref)
+		}
+
+		writeFields(builder, schemas, fieldSchema, depth+1, expanding)
+		expanding.Delete(ref)
+	}
+}
+
+// resolveFieldSchema returns the schema of the object whose fields are below the field, following the items of the
+// arrays and the values of the maps, along with its reference if any.
+func resolveFieldSchema(schemas map[string]*spec.Schema, fieldSchema *spec.Schema) (string, *spec.Schema) {
+	fieldSchema = elementSchema(fieldSchema)
+
+	ref := schemaRef(fieldSchema)
+	if ref != "" {
+		fieldSchema = schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+	}
+
+	if fieldSchema == nil || len(fieldSchema.Properties) == 0 {
+		return "", nil
+	}
+
+	return ref, fieldSchema
+}
+
+// elementSchema returns the schema of the items of an array, or of the values of a map, or else the schema itself.
+func elementSchema(fieldSchema *spec.Schema) *spec.Schema {
+	switch {
+	case fieldSchema.Items != nil && fieldSchema.Items.Schema != nil:
+		return elementSchema(fieldSchema.Items.Schema)
+	case fieldSchema.AdditionalProperties != nil && fieldSchema.AdditionalProperties.Schema != nil:
+		return elementSchema(fieldSchema.AdditionalProperties.Schema)
+	default:
+		return fieldSchema
+	}
+}
+
+// schemaRef returns the reference of the schema, which the Kubernetes OpenAPI v3 documents wrap in allOf to set a
+// default value.
+func schemaRef(fieldSchema *spec.Schema) string {
+	if ref := fieldSchema.Ref.String(); ref != "" {
+		return ref
+	}
+
+	for _, allOf := range fieldSchema.AllOf {
+		if ref := allOf.Ref.String(); ref != "" {
+			return ref
+		}
+	}
+
+	return ""
+}
+
+// schemaTypeName returns the type of the field like kubectl explain, e.g. "string", "[]Container", or
+// "map[string]string".
+func schemaTypeName(fieldSchema *spec.Schema) string {
+	switch {
+	case fieldSchema.Items != nil && fieldSchema.Items.Schema != nil:
+		return "[]" + schemaTypeName(fieldSchema.Items.Schema)
+	case fieldSchema.AdditionalProperties != nil && fieldSchema.AdditionalProperties.Schema != nil:
+		return "map[string]" + schemaTypeName(fieldSchema.AdditionalProperties.Schema)
+	}
+
+	if ref := schemaRef(fieldSchema); ref != "" {
+		name := strings.TrimPrefix(ref, schemaRefPrefix)
+
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+
+	if len(fieldSchema.Type) > 0 {
+		return fieldSchema.Type[0]
+	}
+
+	return "Object"
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+// TestRunExplainFields tests printing the field tree of a resource from the OpenAPI v3 schema of its version.
+func TestRunExplainFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Preferred", runExplainFieldsTest{
+		resource: "hpa",
+		want: "KIND:       HorizontalPodAutoscaler\n" +
+			"VERSION:    autoscaling/v2\n" +
+			"\n" +
+			"FIELDS:\n" +
+			"  apiVersion\t<string>\n" +
+			"  kind\t<string>\n" +
+			"  metadata\t<ObjectMeta>\n" +
+			"    labels\t<map[string]string>\n" +
+			"    name\t<string>\n" +
+			"  spec\t<HorizontalPodAutoscalerSpec>\n" +
+			"    maxReplicas\t<integer> -required-\n" +
+			"    metrics\t<[]MetricSpec>\n" +
+			"      nested\t<MetricSpec>\n" +
+			"      type\t<string>\n" +
+			"    scaleTargetRef\t<CrossVersionObjectReference> -required-\n" +
+			"      kind\t<string> -required-\n" +
+			"      name\t<string> -required-\n",
+	}.Test)
+	t.Run("NotServed", runExplainFieldsTest{
+		resource:   "hpa",
+		apiVersion: "autoscaling/v3",
+		wantErr:    errAPIVersionNotServed,
+	}.Test)
+	t.Run("NoSchema", runExplainFieldsTest{
+		resource: "pods",
+		wantErr:  errOpenAPIUnavailable,
+	}.Test)
+}
+
+type runExplainFieldsTest struct {
+	resource   string
+	apiVersion string
+	want       string
+	wantErr    error
+}
+
+func (tt runExplainFieldsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newExplainFieldsOptions(ioStreams)
+	options.resource = tt.resource
+	options.APIVersion = tt.apiVersion
+	options.discoveryClient = discoverytesting.New()
+	options.openAPIClient = openapitest.NewFileClient("testdata/openapi")
+
+	err := runExplainFields(options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runExplainFields() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runExplainFields() output = %q, want %q", got, tt.want)
+	}
+}
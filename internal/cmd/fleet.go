@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// defaultClusterConcurrency is the default number of clusters which are discovered concurrently.
+const defaultClusterConcurrency = 8
+
+// fleetCluster is a cluster listed in a clusters file, or selected with --all-contexts or --contexts.
+type fleetCluster struct {
+	// Name is the name of the cluster in the CLUSTER column, defaulting to the context, or else the kubeconfig file.
+	Name string `json:"name,omitempty"`
+	// Kubeconfig is the kubeconfig file of the cluster, relative to the clusters file, defaulting to the kubeconfig
+	// of the config flags.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context is the kubeconfig context of the cluster, defaulting to the current context of the kubeconfig.
+	Context string `json:"context,omitempty"`
+	// Labels are used to select the clusters with --cluster-selector.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// fleet is the content of a clusters file.
+type fleet struct {
+	// Clusters are the clusters of the fleet.
+	Clusters []fleetCluster `json:"clusters"`
+}
+
+// errClusterName is returned when a cluster of a clusters file has no name, or the same name as another cluster.
+const errClusterName = constError("clusters must have a unique name, context, or kubeconfig")
+
+// readFleet reads the clusters file, resolving the kubeconfig files relative to it and defaulting the cluster names.
+func readFleet(filename string) (*fleet, error) {
+	content, err := os.ReadFile(filename) //nolint:gosec // Reading the user-provided clusters file is intended.
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read clusters file %s: %w", filename, err)
+	}
+
+	f := &fleet{}
+
+	err = k8syaml.UnmarshalStrict(content, f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse clusters file %s: %w", filename, err)
+	}
+
+	names := sets.New[string]()
+
+	for i := range f.Clusters {
+		cluster := &f.Clusters[i]
+
+		if cluster.Name == "" {
+			cluster.Name = cluster.Context
+		}
+
+		if cluster.Name == "" {
+			cluster.Name = cluster.Kubeconfig
+		}
+
+		if cluster.Name == "" || names.Has(cluster.Name) {
+			return nil, fmt.Errorf("%w: cluster %d of %s", errClusterName, i, filename)
+		}
+
+		names.Insert(cluster.Name)
+
+		if cluster.Kubeconfig != "" && !filepath.IsAbs(cluster.Kubeconfig) {
+			cluster.Kubeconfig = filepath.Join(filepath.Dir(filename), cluster.Kubeconfig)
+		}
+	}
+
+	return f, nil
+}
+
+// selectClusters returns the clusters of the fleet matching the label selector.
+func (f *fleet) selectClusters(selector labels.Selector) []fleetCluster {
+	clusters := make([]fleetCluster, 0, len(f.Clusters))
+
+	for _, cluster := range f.Clusters {
+		if selector.Matches(labels.Set(cluster.Labels)) {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters
+}
+
+// configFlags returns new config flags which select the kubeconfig and context of the cluster, see [withContext].
+func (c fleetCluster) configFlags(configFlags *genericclioptions.ConfigFlags) *genericclioptions.ConfigFlags {
+	clusterFlags := withContext(configFlags, c.Context)
+	if c.Kubeconfig != "" {
+		clusterFlags.KubeConfig = &c.Kubeconfig
+	}
+
+	return clusterFlags
+}
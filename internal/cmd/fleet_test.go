@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TestReadFleet tests reading and selecting the clusters of a clusters file.
+func TestReadFleet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("All", readFleetTest{
+		filename: "testdata/clusters/fleet.yaml",
+		selector: "",
+		want: []fleetCluster{
+			{
+				Name:       "prod-eu",
+				Kubeconfig: "testdata/clusters/kubeconfigs/prod.yaml",
+				Context:    "eu-west-1",
+				Labels:     map[string]string{"env": "prod"},
+			},
+			{
+				Name:       "us-east-1",
+				Kubeconfig: "/etc/kubernetes/prod-us.yaml",
+				Context:    "us-east-1",
+				Labels:     map[string]string{"env": "prod"},
+			},
+			{
+				Name:       "kubeconfigs/staging.yaml",
+				Kubeconfig: "testdata/clusters/kubeconfigs/staging.yaml",
+				Labels:     map[string]string{"env": "staging"},
+			},
+		},
+	}.Test)
+	t.Run("Selector", readFleetTest{
+		filename: "testdata/clusters/fleet.yaml",
+		selector: "env=staging",
+		want: []fleetCluster{
+			{
+				Name:       "kubeconfigs/staging.yaml",
+				Kubeconfig: "testdata/clusters/kubeconfigs/staging.yaml",
+				Labels:     map[string]string{"env": "staging"},
+			},
+		},
+	}.Test)
+	t.Run("DuplicateName", readFleetTest{
+		filename: "testdata/clusters/duplicate.yaml",
+		wantErr:  errClusterName,
+	}.Test)
+}
+
+type readFleetTest struct {
+	filename string
+	selector string
+	want     []fleetCluster
+	wantErr  error
+}
+
+func (tt readFleetTest) Test(t *testing.T) {
+	t.Parallel()
+
+	f, err := readFleet(tt.filename)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("readFleet() error = %v, wantErr %v", err, tt.wantErr)
+	} else if err != nil {
+		return
+	}
+
+	selector, err := labels.Parse(tt.selector)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := f.selectClusters(selector); !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("selectClusters() = %v, want %v", got, tt.want)
+	}
+}
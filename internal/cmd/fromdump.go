@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+)
+
+// fromDumpFlags are the config flags, with the --from-dump and --from-file flags replacing the discovery client of the
+// cluster by the discovery documents written by the dump command or collected with kubectl get --raw.
+type fromDumpFlags struct {
+	*genericclioptions.ConfigFlags
+
+	// Directory is the discovery directory written by the dump command, empty to discover the cluster.
+	Directory string
+	// Files are the discovery documents collected with kubectl get --raw, empty to discover the cluster.
+	Files []string
+}
+
+var _ genericclioptions.RESTClientGetter = &fromDumpFlags{}
+
+// newFromDumpFlags returns new [fromDumpFlags] wrapping the config flags.
+func newFromDumpFlags(configFlags *genericclioptions.ConfigFlags) *fromDumpFlags {
+	return &fromDumpFlags{ConfigFlags: configFlags}
+}
+
+// AddFlags adds the --from-dump and --from-file flags to the flag set.
+func (f *fromDumpFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Directory, "from-dump", f.Directory,
+		"Read the API resources from a directory written by the dump command, instead of discovering the cluster.")
+	flags.StringSliceVar(&f.Files, "from-file", f.Files,
+		"Read the API resources from discovery documents saved with kubectl get --raw, e.g. /apis and /apis/apps/v1, "+
+			"or the aggregated discovery of /apis, instead of discovering the cluster. May be repeated.")
+}
+
+// fromDocuments returns true if the API resources are read from discovery documents, with --from-dump or --from-file,
+// instead of discovering the cluster.
+func (f *fromDumpFlags) fromDocuments() bool {
+	return f.Directory != "" || len(f.Files) > 0
+}
+
+// ToDiscoveryClient implements [genericclioptions.RESTClientGetter.ToDiscoveryClient], reading the discovery
+// directory with --from-dump, or the discovery files with --from-file.
+func (f *fromDumpFlags) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	if f.Directory != "" {
+		return newDirectoryDiscoveryClient(f.Directory), nil
+	}
+
+	if len(f.Files) > 0 {
+		discoveryClient, err := newFileDiscoveryClient(f.Files)
+		if err != nil {
+			return nil, err
+		}
+
+		return discoveryClient, nil
+	}
+
+	//nolint:wrapcheck
+	return f.ConfigFlags.ToDiscoveryClient()
+}
+
+// contextName returns the name of the kubeconfig context selected by the config flags: the --context flag, or else the
+// current context of the kubeconfig.
+// It is empty when reading discovery documents with --from-dump or --from-file, or if the kubeconfig can't be loaded.
+func (f *fromDumpFlags) contextName() string {
+	if f.fromDocuments() {
+		return ""
+	}
+
+	if f.Context != nil && *f.Context != "" {
+		return *f.Context
+	}
+
+	rawConfig, err := f.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return ""
+	}
+
+	return rawConfig.CurrentContext
+}
+
+// fromDocuments returns true if the API resources are read from discovery documents, with --from-dump or --from-file,
+// instead of discovering the cluster.
+func (o *apiResourceVersionsOptions) fromDocuments() bool {
+	return o.FromDump != "" || len(o.FromFiles) > 0
+}
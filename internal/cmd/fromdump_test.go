@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestFromDump tests listing the resources of a discovery directory with --from-dump, without a cluster.
+func TestFromDump(t *testing.T) {
+	t.Parallel()
+
+	t.Run("List", fromDumpTest{
+		args: []string{"--from-dump=testdata/discovery", "--output=name"},
+		want: "configmaps.v1.\npods.v1.\ndeployments.v1.apps\n",
+	}.Test)
+	t.Run("ListCommand", fromDumpTest{
+		args: []string{"--from-dump=testdata/discovery", "list", "--output=name"},
+		want: "configmaps.v1.\npods.v1.\ndeployments.v1.apps\n",
+	}.Test)
+	t.Run("SnapshotDiff", fromDumpTest{
+		args: []string{"--from-dump=testdata/discovery", "snapshot", "diff", "testdata/snapshots/before.json",
+			"--output=markdown"},
+		want: "| Change | Type | Name | Old | New |\n| --- | --- | --- | --- | --- |\n" +
+			"| added | group-version | apps/v1 |  |  |\n" +
+			"| removed | group-version | autoscaling/v1 |  |  |\n" +
+			"| removed | group-version | autoscaling/v2beta1 |  |  |\n" +
+			"| added | resource | configmaps.v1. |  |  |\n" +
+			"| added | resource | deployments.v1.apps |  |  |\n" +
+			"| added | resource | deployments.v1.apps status |  |  |\n" +
+			"| removed | resource | horizontalpodautoscalers.v1.autoscaling |  |  |\n" +
+			"| removed | resource | horizontalpodautoscalers.v2beta1.autoscaling |  |  |\n" +
+			"| categories | resource | pods.v1. |  | all |\n" +
+			"| verbs | resource | pods.v1. | get,list | create,delete,deletecollection,get,list,patch,update,watch |\n" +
+			"| added | resource | pods.v1. status |  |  |\n",
+	}.Test)
+}
+
+type fromDumpTest struct {
+	args []string
+	want string
+}
+
+func (tt fromDumpTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), ioStreams)
+	cmd.SetArgs(tt.args)
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("Execute() output = %q, want %q", got, tt.want)
+	}
+}
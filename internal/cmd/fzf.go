@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+)
+
+// errName0Mode is returned when --output=name0 is requested with a mode which doesn't print the names, or prints
+// them along with their cluster.
+const errName0Mode = constError("output=name0 is not supported with watch, all-contexts, contexts, or clusters-file")
+
+// errFzfMode is returned when --fzf is requested with an option which prints the resources instead.
+const errFzfMode = constError("fzf is not supported with output, exec, show-commands, stream, watch, " +
+	"compare-release, all-contexts, contexts, or clusters-file")
+
+// defaultFzfCommand is the fuzzy-finder which the resources are piped to with --fzf, previewing the fields of the
+// highlighted resource with kubectl explain.
+//
+//nolint:gochecknoglobals
+var defaultFzfCommand = []string{"fzf", "--read0", "--multi", "--preview", "kubectl explain {}"}
+
+// validateName0 checks that --output=name0 and --fzf aren't requested with an option which prints the resources
+// differently.
+func (o *apiResourceVersionsOptions) validateName0() error {
+	clusters := o.AllContexts || len(o.Contexts) > 0 || o.ClustersFile != ""
+
+	if o.Output == name0Output && (o.Watch || clusters) {
+		return errName0Mode
+	}
+
+	if o.Fzf && (o.Output != "" || o.Exec != "" || o.ShowCommands || o.Stream || o.Watch || o.CompareRelease != "" ||
+		clusters) {
+		return errFzfMode
+	}
+
+	return nil
+}
+
+// namesOnly returns true if the output only prints the fully qualified names of the resources, without headers.
+func (o *apiResourceVersionsOptions) namesOnly() bool {
+	return o.Output == nameOutput || o.Output == name0Output
+}
+
+// printGroupResourceName0 prints the API resource name in the format expected by kubectl, terminated by a NUL
+// character rather than a newline, so that the names of the subresources can be read with xargs -0.
+func printGroupResourceName0(writer io.Writer, resource groupResource) error {
+	_, err := fmt.Fprintf(writer, "%s\x00", resource.fullname())
+	if err != nil {
+		return fmt.Errorf("error printing resource name: %w", err)
+	}
+
+	return nil
+}
+
+// runFzf pipes the NUL-separated names of the resources to the fuzzy-finder, which prints the selected ones on
+// stdout, one per line.
+func runFzf(ctx context.Context, resources []groupResource, options *apiResourceVersionsOptions) error {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	var names bytes.Buffer
+
+	for _, resource := range resources {
+		err := printGroupResourceName0(&names, resource)
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, options.fzfCommand[0], options.fzfCommand[1:]...)
+	cmd.Stdin = &names
+	cmd.Stdout = options.Out
+	cmd.Stderr = options.ErrOut
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s: %w", options.fzfCommand[0], err)
+	}
+
+	return nil
+}
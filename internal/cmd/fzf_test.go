@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunName0 tests printing the names of the resources terminated by NUL characters.
+func TestRunName0(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(name0Output)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "horizontalpodautoscalers.v2.autoscaling\x00" +
+		"horizontalpodautoscalers.v1.autoscaling\x00" +
+		"horizontalpodautoscalers.v2beta2.autoscaling\x00"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
+
+// TestRunFzf tests piping the names of the resources to the fuzzy-finder, replaced by tr selecting every name.
+func TestRunFzf(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling")
+	_, stdout, _ := builder.GetBuffers()
+
+	options := builder.APIResourceVersionsOptions()
+	options.Fzf = true
+	options.fzfCommand = []string{"tr", `\000`, `\n`}
+
+	err := runAPIResourceVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "horizontalpodautoscalers.v2.autoscaling\n" +
+		"horizontalpodautoscalers.v1.autoscaling\n" +
+		"horizontalpodautoscalers.v2beta2.autoscaling\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
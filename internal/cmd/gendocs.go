@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// Formats of the documentation generated by the gen-docs command.
+const (
+	// markdownDocsFormat generates a Markdown reference page for each command.
+	markdownDocsFormat = "markdown"
+	// manDocsFormat generates a man page for each command, in section 1.
+	manDocsFormat = "man"
+)
+
+// docsCommandPrefix is the prefix of the name of the root command in the documentation, as the plugin is run by
+// kubectl.
+const docsCommandPrefix = "kubectl "
+
+var (
+	// genDocsExample is the example text for the gen-docs command.
+	//
+	//nolint:gochecknoglobals
+	genDocsExample = `
+		# Generate the man pages of every command, e.g. when packaging the plugin
+		kubectl api-resource-versions gen-docs --format=man --dir=share/man/man1
+
+		# Generate the Markdown reference of every command
+		kubectl api-resource-versions gen-docs --format=markdown --dir=docs/reference`
+)
+
+// newCmdGenDocs returns a hidden command that generates the documentation of every command of the plugin.
+func newCmdGenDocs(ioStreams genericiooptions.IOStreams) *cobra.Command {
+	options := newGenDocsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "gen-docs --dir=DIRECTORY",
+		Short: "Generate the documentation of every command",
+		Long: "Generate the man pages or the Markdown reference of every command and flag of the plugin, one file " +
+			"per command, e.g. for packaging.",
+		Example: templates.Examples(genDocsExample),
+		Hidden:  true,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runGenDocs(cmd.Root(), options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Directory, "dir", options.Directory,
+		"Directory to which the documentation is written, created if it doesn't exist.")
+	cmd.Flags().StringVar(&options.Format, "format", options.Format,
+		"Format of the documentation. One of: ("+markdownDocsFormat+", "+manDocsFormat+").")
+
+	return cmd
+}
+
+// genDocsOptions contains the options for the gen-docs command.
+type genDocsOptions struct {
+	genericiooptions.IOStreams
+
+	Directory string
+	Format    string
+}
+
+// newGenDocsOptions returns a new [genDocsOptions] with default values.
+func newGenDocsOptions(ioStreams genericiooptions.IOStreams) *genDocsOptions {
+	return &genDocsOptions{
+		IOStreams: ioStreams,
+		Format:    markdownDocsFormat,
+	}
+}
+
+// complete completes all the required options for the gen-docs command.
+func (o *genDocsOptions) complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	return nil
+}
+
+// errDocsDirectory is returned when the directory of the gen-docs command is not set.
+const errDocsDirectory = constError("dir is required")
+
+// errDocsFormat is returned when the format of the documentation is not supported.
+const errDocsFormat = constError("format must be one of: (" + markdownDocsFormat + ", " + manDocsFormat + ")")
+
+// validate checks that options are valid for the gen-docs command.
+func (o *genDocsOptions) validate() error {
+	if o.Directory == "" {
+		return errDocsDirectory
+	}
+
+	if o.Format != markdownDocsFormat && o.Format != manDocsFormat {
+		return fmt.Errorf("%w: got %s", errDocsFormat, o.Format)
+	}
+
+	return nil
+}
+
+// runGenDocs writes the documentation of the command and of all its available sub-commands to the directory with
+// cobra/doc. The commands are documented as run through kubectl, e.g. "kubectl api-resource-versions check".
+func runGenDocs(root *cobra.Command, options *genDocsOptions) error {
+	err := os.MkdirAll(options.Directory, dumpDirectoryPermissions)
+	if err != nil {
+		return fmt.Errorf("couldn't create directory %s: %w", options.Directory, err)
+	}
+
+	if root.Annotations == nil {
+		root.Annotations = map[string]string{}
+	}
+
+	root.Annotations[cobra.CommandDisplayNameAnnotation] = docsCommandPrefix + root.Name()
+	// The date is left out of the pages so that they are reproducible.
+	root.DisableAutoGenTag = true
+
+	if options.Format == manDocsFormat {
+		err = doc.GenManTree(root, &doc.GenManHeader{Section: "1"}, options.Directory)
+	} else {
+		err = doc.GenMarkdownTree(root, options.Directory)
+	}
+
+	if err != nil {
+		return fmt.Errorf("couldn't write the %s documentation: %w", options.Format, err)
+	}
+
+	_, _ = fmt.Fprintf(options.ErrOut, "Wrote the %s documentation to %s\n", options.Format, options.Directory)
+
+	return nil
+}
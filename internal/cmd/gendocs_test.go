@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunGenDocs tests generating the documentation of every command, except the hidden ones.
+func TestRunGenDocs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Markdown", runGenDocsTest{
+		format:      markdownDocsFormat,
+		filename:    "kubectl_api-resource-versions_snapshot_save.md",
+		hiddenFile:  "kubectl_api-resource-versions_gen-docs.md",
+		wantContent: "* [kubectl api-resource-versions snapshot](kubectl_api-resource-versions_snapshot.md)",
+	}.Test)
+	t.Run("Man", runGenDocsTest{
+		format:      manDocsFormat,
+		filename:    "kubectl-api-resource-versions-snapshot-save.1",
+		hiddenFile:  "kubectl-api-resource-versions-gen-docs.1",
+		wantContent: `.SH SEE ALSO` + "\n" + `\fBkubectl-api-resource-versions-snapshot(1)\fP`,
+	}.Test)
+}
+
+type runGenDocsTest struct {
+	format      string
+	filename    string
+	hiddenFile  string
+	wantContent string
+}
+
+func (tt runGenDocsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	root := NewCmdAPIResourceVersions(genericclioptions.NewConfigFlags(true), ioStreams)
+
+	options := newGenDocsOptions(ioStreams)
+	options.Directory = t.TempDir()
+	options.Format = tt.format
+
+	err := options.validate()
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	err = runGenDocs(root, options)
+	if err != nil {
+		t.Fatalf("runGenDocs() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(options.Directory, tt.filename))
+	if err != nil {
+		t.Fatalf("runGenDocs() didn't write %s: %v", tt.filename, err)
+	}
+
+	if !strings.Contains(string(content), tt.wantContent) {
+		t.Errorf("runGenDocs() %s = %s, want it to contain %q", tt.filename, content, tt.wantContent)
+	}
+
+	_, err = os.Stat(filepath.Join(options.Directory, tt.hiddenFile))
+	if !os.IsNotExist(err) {
+		t.Errorf("runGenDocs() wrote %s for the hidden command, error = %v", tt.hiddenFile, err)
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// defaultPolicyName is the name of the generated policies.
+const defaultPolicyName = "deny-deprecated-api-versions"
+
+var (
+	// generatePolicyExample is the example text for the generate-policy command.
+	//
+	//nolint:gochecknoglobals
+	generatePolicyExample = `
+		# Generate a Kyverno ClusterPolicy denying the deprecated and non-preferred API versions of the cluster
+		kubectl api-resource-versions generate-policy --engine=kyverno | kubectl apply -f -
+
+		# Generate a Kyverno ClusterPolicy which only audits the use of deprecated API versions
+		kubectl api-resource-versions generate-policy --engine=kyverno --deprecated-only --audit
+
+		# Generate a ValidatingAdmissionPolicy and its binding, also denying the versions removed in Kubernetes 1.32
+		kubectl api-resource-versions generate-policy --engine=vap --target-version=1.32
+
+		# Generate a Gatekeeper ConstraintTemplate and a constraint which warns about the disallowed versions
+		kubectl api-resource-versions generate-policy --engine=gatekeeper --audit`
+)
+
+// policyEngine generates the policy manifests disallowing the versions for an admission policy engine.
+type policyEngine func(versions []disallowedVersion, options *generatePolicyOptions) []any
+
+// policyEngines are the supported admission policy engines by name.
+//
+//nolint:gochecknoglobals
+var policyEngines = map[string]policyEngine{
+	"gatekeeper": generateGatekeeperPolicy,
+	"kyverno":    generateKyvernoPolicy,
+	"vap":        generateValidatingAdmissionPolicy,
+}
+
+// policyEngineNames returns the names of the supported policy engines, separated by commas.
+func policyEngineNames() string {
+	return strings.Join(slices.Sorted(maps.Keys(policyEngines)), ", ")
+}
+
+// newCmdGeneratePolicy returns a command that generates admission policies disallowing deprecated API versions.
+func newCmdGeneratePolicy(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newGeneratePolicyOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "generate-policy --engine=ENGINE",
+		Short: "Generate admission policies disallowing deprecated API versions",
+		Long: "Generate the manifests of admission policies which deny the creation and update of objects using the " +
+			"deprecated or non-preferred versions of the resources discovered in the cluster.\n" +
+			"Deprecated versions are identified from the embedded database of the built-in Kubernetes API " +
+			"lifecycles.\n" +
+			"With --target-version, the versions removed in the target release are denied as well, so that the " +
+			"cluster can be upgraded without breaking the objects applied in the meantime.\n" +
+			"The manifests are printed as YAML documents, to be reviewed and applied to the cluster.",
+		Example: templates.Examples(generatePolicyExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runGeneratePolicy(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Engine, "engine", options.Engine,
+		"Admission policy engine to generate policies for. One of: ("+policyEngineNames()+").")
+	cmd.Flags().StringVar(&options.Name, "name", options.Name,
+		"Name of the generated policies.")
+	cmd.Flags().BoolVar(&options.Audit, "audit", options.Audit,
+		"Generate policies which only audit the use of the disallowed versions instead of denying it.")
+	options.disallowedVersionsOptions.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// generatePolicyOptions contains the options for the generate-policy command.
+type generatePolicyOptions struct {
+	genericiooptions.IOStreams
+
+	disallowedVersionsOptions
+
+	Engine string
+	Name   string
+	Audit  bool
+
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newGeneratePolicyOptions returns a new [generatePolicyOptions] with default values.
+func newGeneratePolicyOptions(ioStreams genericiooptions.IOStreams) *generatePolicyOptions {
+	return &generatePolicyOptions{
+		IOStreams: ioStreams,
+		Name:      defaultPolicyName,
+	}
+}
+
+// complete completes all the required options for the generate-policy command.
+func (o *generatePolicyOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	return nil
+}
+
+// errEngine is returned when the --engine value is not supported.
+const errEngine = constError("engine must be one of the supported policy engines")
+
+// errPolicyName is returned when the --name value is empty.
+const errPolicyName = constError("name must not be empty")
+
+// validate checks that options are valid for the generate-policy command.
+func (o *generatePolicyOptions) validate() error {
+	if _, ok := policyEngines[o.Engine]; !ok {
+		return fmt.Errorf("%w: %q is not one of (%s)", errEngine, o.Engine, policyEngineNames())
+	}
+
+	if o.Name == "" {
+		return errPolicyName
+	}
+
+	return o.disallowedVersionsOptions.validate()
+}
+
+// errNoDisallowedVersions is returned when the cluster serves no version which the policies would disallow.
+const errNoDisallowedVersions = constError("no deprecated or non-preferred versions found")
+
+// runGeneratePolicy generates the policies for the engine and prints them as YAML documents.
+func runGeneratePolicy(ctx context.Context, options *generatePolicyOptions) error {
+	versions, err := getDisallowedVersions(ctx, options.discoveryClient, &options.disallowedVersionsOptions)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		return errNoDisallowedVersions
+	}
+
+	return printYAMLDocuments(options.Out, policyEngines[options.Engine](versions, options))
+}
+
+// policyRuleName returns a DNS label for the rule disallowing the version, e.g.
+// "autoscaling-v2beta2-horizontalpodautoscaler".
+func policyRuleName(gvk schema.GroupVersionKind) string {
+	name := strings.Join([]string{gvk.Group, gvk.Version, gvk.Kind}, "-")
+	name = strings.ToLower(strings.Trim(strings.ReplaceAll(name, ".", "-"), "-"))
+
+	const maxLabelLength = 63
+	if len(name) > maxLabelLength {
+		name = strings.TrimRight(name[:maxLabelLength], "-")
+	}
+
+	return name
+}
+
+// printYAMLDocuments prints the objects as a stream of YAML documents.
+func printYAMLDocuments(out io.Writer, objects []any) error {
+	for i, obj := range objects {
+		content, err := k8syaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("couldn't encode YAML document: %w", err)
+		}
+
+		if i > 0 {
+			_, err = fmt.Fprintln(out, "---")
+			if err != nil {
+				return fmt.Errorf("couldn't write YAML document: %w", err)
+			}
+		}
+
+		_, err = out.Write(content)
+		if err != nil {
+			return fmt.Errorf("couldn't write YAML document: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestValidateGeneratePolicyOptions tests validation of the generate-policy command options.
+func TestValidateGeneratePolicyOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		engine        string
+		policyName    string
+		targetVersion string
+		wantErr       error
+	}{
+		{name: "Kyverno", engine: "kyverno", policyName: defaultPolicyName},
+		{name: "VAP", engine: "vap", policyName: defaultPolicyName, targetVersion: "1.32"},
+		{name: "Gatekeeper", engine: "gatekeeper", policyName: defaultPolicyName},
+		{name: "NoEngine", policyName: defaultPolicyName, wantErr: errEngine},
+		{name: "UnknownEngine", engine: "unknown", policyName: defaultPolicyName, wantErr: errEngine},
+		{name: "NoName", engine: "kyverno", wantErr: errPolicyName},
+		{name: "InvalidTargetVersion", engine: "vap", policyName: defaultPolicyName, targetVersion: "latest",
+			wantErr: errInvalidTargetVersion},
+	}
+
+	for _, tt := range tests {
+		options := newGeneratePolicyOptions(genericiooptions.NewTestIOStreamsDiscard())
+		options.Engine = tt.engine
+		options.Name = tt.policyName
+		options.TargetVersion = tt.targetVersion
+
+		err := options.validate()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestRunGeneratePolicy tests generating admission policies.
+func TestRunGeneratePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Kyverno", runGeneratePolicyTest{
+		engine: "kyverno",
+		want: `apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  annotations:
+    policies.kyverno.io/description: Deny the creation and update of objects using
+      deprecated or non-preferred API versions.
+    policies.kyverno.io/title: Deny deprecated API versions
+  name: deny-deprecated-api-versions
+spec:
+  background: false
+  rules:
+  - match:
+      any:
+      - resources:
+          kinds:
+          - autoscaling/v1/HorizontalPodAutoscaler
+          operations:
+          - CREATE
+          - UPDATE
+    name: autoscaling-v1-horizontalpodautoscaler
+    validate:
+      deny: {}
+      message: autoscaling/v1 HorizontalPodAutoscaler is not the preferred version,
+        use autoscaling/v2 instead.
+  - match:
+      any:
+      - resources:
+          kinds:
+          - autoscaling/v2beta2/HorizontalPodAutoscaler
+          operations:
+          - CREATE
+          - UPDATE
+    name: autoscaling-v2beta2-horizontalpodautoscaler
+    validate:
+      deny: {}
+      message: autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated, use autoscaling/v2
+        instead.
+  validationFailureAction: Enforce
+`,
+	}.Test)
+	t.Run("KyvernoAuditDeprecatedOnly", runGeneratePolicyTest{
+		engine:         "kyverno",
+		deprecatedOnly: true,
+		audit:          true,
+		want: `apiVersion: kyverno.io/v1
+kind: ClusterPolicy
+metadata:
+  annotations:
+    policies.kyverno.io/description: Deny the creation and update of objects using
+      deprecated or non-preferred API versions.
+    policies.kyverno.io/title: Deny deprecated API versions
+  name: deny-deprecated-api-versions
+spec:
+  background: false
+  rules:
+  - match:
+      any:
+      - resources:
+          kinds:
+          - autoscaling/v2beta2/HorizontalPodAutoscaler
+          operations:
+          - CREATE
+          - UPDATE
+    name: autoscaling-v2beta2-horizontalpodautoscaler
+    validate:
+      deny: {}
+      message: autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated, use autoscaling/v2
+        instead.
+  validationFailureAction: Audit
+`,
+	}.Test)
+	t.Run("VAPTargetVersion", runGeneratePolicyTest{
+		engine:         "vap",
+		deprecatedOnly: true,
+		targetVersion:  "1.26",
+		want: `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: deny-deprecated-api-versions
+spec:
+  failurePolicy: Fail
+  matchConstraints:
+    matchPolicy: Exact
+    resourceRules:
+    - apiGroups:
+      - autoscaling
+      apiVersions:
+      - v2beta2
+      operations:
+      - CREATE
+      - UPDATE
+      resources:
+      - horizontalpodautoscalers
+  validations:
+  - expression: '!(request.resource.group == "autoscaling" && request.resource.version
+      == "v2beta2" && request.resource.resource == "horizontalpodautoscalers")'
+    message: autoscaling/v2beta2 HorizontalPodAutoscaler is removed in Kubernetes
+      1.26, use autoscaling/v2 instead.
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: deny-deprecated-api-versions
+spec:
+  policyName: deny-deprecated-api-versions
+  validationActions:
+  - Deny
+`,
+	}.Test)
+	t.Run("GatekeeperAudit", runGeneratePolicyTest{
+		engine: "gatekeeper",
+		audit:  true,
+		want: `apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8sdisallowedapiversions
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sDisallowedAPIVersions
+      validation:
+        openAPIV3Schema:
+          properties:
+            apiVersions:
+              items:
+                properties:
+                  group:
+                    type: string
+                  kind:
+                    type: string
+                  message:
+                    type: string
+                  version:
+                    type: string
+                type: object
+              type: array
+          type: object
+  targets:
+  - rego: |
+      package k8sdisallowedapiversions
+
+      violation[{"msg": msg}] {
+        disallowed := input.parameters.apiVersions[_]
+        input.review.kind.group == disallowed.group
+        input.review.kind.version == disallowed.version
+        input.review.kind.kind == disallowed.kind
+        msg := disallowed.message
+      }
+    target: admission.k8s.gatekeeper.sh
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDisallowedAPIVersions
+metadata:
+  name: deny-deprecated-api-versions
+spec:
+  enforcementAction: warn
+  match:
+    kinds:
+    - apiGroups:
+      - autoscaling
+      kinds:
+      - HorizontalPodAutoscaler
+  parameters:
+    apiVersions:
+    - group: autoscaling
+      kind: HorizontalPodAutoscaler
+      message: autoscaling/v1 HorizontalPodAutoscaler is not the preferred version,
+        use autoscaling/v2 instead.
+      version: v1
+    - group: autoscaling
+      kind: HorizontalPodAutoscaler
+      message: autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated, use autoscaling/v2
+        instead.
+      version: v2beta2
+`,
+	}.Test)
+}
+
+type runGeneratePolicyTest struct {
+	engine         string
+	deprecatedOnly bool
+	audit          bool
+	targetVersion  string
+	want           string
+	wantErr        error
+}
+
+func (tt runGeneratePolicyTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newGeneratePolicyOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+	options.Engine = tt.engine
+	options.DeprecatedOnly = tt.deprecatedOnly
+	options.Audit = tt.audit
+	options.TargetVersion = tt.targetVersion
+
+	err := options.validate()
+	if err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	err = runGeneratePolicy(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runGeneratePolicy() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runGeneratePolicy() output = %s, want %s", got, tt.want)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// apiServicesGVR is the group version resource of the APIService API, which registers the group versions served by
+// the API server and their priorities.
+//
+//nolint:gochecknoglobals
+var apiServicesGVR = schema.GroupVersionResource{
+	Group:    "apiregistration.k8s.io",
+	Version:  "v1",
+	Resource: "apiservices",
+}
+
+// unknownGroupPriority is printed in the GROUP-PRIORITY column when the APIServices couldn't be listed, or none of
+// them registers the group.
+const unknownGroupPriority = "<unknown>"
+
+// errOfflineGroupPriority is returned when --show-group-priority is requested with --offline, --from-dump, or
+// --from-file.
+const errOfflineGroupPriority = constError(
+	"show-group-priority is not supported with offline, from-dump, or from-file")
+
+// errNameGroupPriority is returned when --show-group-priority is requested with --output=name, name0, api-versions,
+// or script, which don't print the priorities of the groups.
+const errNameGroupPriority = constError("show-group-priority has no effect with output=name, name0, api-versions, " +
+	"or script, which don't print the priorities of the groups: remove show-group-priority or use the default or " +
+	"wide output")
+
+// errNoAPIServicesClient is returned when there is no dynamic client to list the APIServices with.
+const errNoAPIServicesClient = constError("no dynamic client to list the APIServices with")
+
+// groupPriorityString returns the priority of the group of the resource, as printed in the GROUP-PRIORITY column.
+func (gr groupResource) groupPriorityString() string {
+	if gr.GroupPriority == nil {
+		return unknownGroupPriority
+	}
+
+	return strconv.FormatInt(*gr.GroupPriority, 10)
+}
+
+// annotateGroupPriorities sets the priority of the group of each resource, according to the APIServices registering
+// its versions. If the APIServices can't be listed, e.g. as listing them is forbidden, a warning is printed and the
+// resources are left without priority.
+func annotateGroupPriorities(ctx context.Context, resources []groupResource, options *apiResourceVersionsOptions) {
+	priorities, err := getGroupPriorities(ctx, options)
+	if err != nil {
+		_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't read the priorities of the groups: %v\n", err)
+
+		return
+	}
+
+	for i := range resources {
+		priority, ok := priorities[resources[i].APIGroup.Name]
+		if ok {
+			resources[i].GroupPriority = &priority
+		}
+	}
+}
+
+// getGroupPriorities returns the priority of each group, keyed by the name of the group, "" for the core group.
+// The priority of a group is the highest groupPriorityMinimum of the APIServices of its versions, which is how the
+// API server orders the groups of the discovery, and so which group is preferred when several serve the same kind.
+func getGroupPriorities(ctx context.Context, options *apiResourceVersionsOptions) (map[string]int64, error) {
+	if options.dynamicClient == nil {
+		return nil, errNoAPIServicesClient
+	}
+
+	list, err := options.dynamicClient.Resource(apiServicesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list %s: %w", apiServicesGVR.GroupResource(), err)
+	}
+
+	priorities := make(map[string]int64)
+
+	for _, item := range list.Items {
+		group, _, err := unstructured.NestedString(item.Object, "spec", "group")
+		if err != nil {
+			return nil, fmt.Errorf("malformed APIService %s: %w", item.GetName(), err)
+		}
+
+		priority, found, err := unstructured.NestedInt64(item.Object, "spec", "groupPriorityMinimum")
+		if err != nil {
+			return nil, fmt.Errorf("malformed APIService %s: %w", item.GetName(), err)
+		}
+
+		if !found {
+			continue
+		}
+
+		if current, ok := priorities[group]; !ok || priority > current {
+			priorities[group] = priority
+		}
+	}
+
+	return priorities, nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// TestRunShowGroupPriority tests the GROUP-PRIORITY column, the highest groupPriorityMinimum of the APIServices of
+// the group.
+func TestRunShowGroupPriority(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", showGroupPriorityTest{
+		apiServices: []*unstructured.Unstructured{
+			newAPIService("", "v1", 18000, 1),
+			newAPIService("autoscaling", "v1", 19100, 15),
+			newAPIService("autoscaling", "v2", 19200, 15),
+		},
+		want: "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED   " +
+			"GROUP-PRIORITY\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true        " +
+			"19200\n",
+		wantWarning: "",
+	}.Test)
+	t.Run("Unregistered", showGroupPriorityTest{
+		apiServices: []*unstructured.Unstructured{
+			newAPIService("", "v1", 18000, 1),
+		},
+		want: "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED   " +
+			"GROUP-PRIORITY\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true        " +
+			"<unknown>\n",
+		wantWarning: "",
+	}.Test)
+	t.Run("NoDynamicClient", showGroupPriorityTest{
+		noDynamicClient: true,
+		want: "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED   " +
+			"GROUP-PRIORITY\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true        " +
+			"<unknown>\n",
+		wantWarning: "Warning: couldn't read the priorities of the groups: no dynamic client to list the APIServices " +
+			"with\n",
+	}.Test)
+}
+
+type showGroupPriorityTest struct {
+	apiServices     []*unstructured.Unstructured
+	noDynamicClient bool
+	want            string
+	wantWarning     string
+}
+
+func (tt showGroupPriorityTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetPreferred(true).SetShowGroupPriority(true)
+	if tt.noDynamicClient {
+		builder = builder.WithDynamicClient(nil)
+	} else {
+		objects := make([]runtime.Object, 0, len(tt.apiServices))
+		for _, apiService := range tt.apiServices {
+			objects = append(objects, apiService)
+		}
+
+		builder = builder.WithDynamicClient(dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{apiServicesGVR: "APIServiceList"}, objects...))
+	}
+
+	_, stdout, stderr := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.want)
+	}
+
+	if got := stderr.String(); got != tt.wantWarning {
+		t.Errorf("runAPIResourceVersions() error output = %q, want %q", got, tt.wantWarning)
+	}
+}
+
+// newAPIService returns an APIService registering the version of the group with the minimum priority of the group and
+// the priority of the version.
+func newAPIService(group, version string, groupPriorityMinimum, versionPriority int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]any{"name": version + "." + group},
+		"spec": map[string]any{
+			"group":                group,
+			"version":              version,
+			"groupPriorityMinimum": groupPriorityMinimum,
+			"versionPriority":      versionPriority,
+		},
+	}}
+}
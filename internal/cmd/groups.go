@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// groupsExample is the example text for the groups command.
+	//
+	//nolint:gochecknoglobals
+	groupsExample = `
+		# Print the API groups with their served and preferred versions
+		kubectl api-resource-versions groups
+
+		# Find the groups which serve more than one version
+		kubectl api-resource-versions groups --no-headers | awk '$2 ~ /,/'`
+)
+
+// newCmdGroups returns a command that lists the API groups with their versions, one row per group.
+func newCmdGroups(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newGroupsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "groups",
+		Short: "List the API groups with their served and preferred versions",
+		Long: "List the API groups served by the cluster, one row per group, with their served versions and their " +
+			"preferred version.\n" +
+			"Only the groups are discovered, which is much cheaper than discovering the resources of every group " +
+			"version.",
+		Example: templates.Examples(groupsExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runGroups(options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+
+	return cmd
+}
+
+// groupsOptions contains the options for the groups command.
+type groupsOptions struct {
+	genericiooptions.IOStreams
+
+	NoHeaders bool
+
+	discoveryClient discovery.DiscoveryInterface
+}
+
+// newGroupsOptions returns a new [groupsOptions] with default values.
+func newGroupsOptions(ioStreams genericiooptions.IOStreams) *groupsOptions {
+	return &groupsOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the groups command.
+func (o *groupsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	return nil
+}
+
+// runGroups prints the API groups sorted by name, the core group first.
+func runGroups(options *groupsOptions) error {
+	groupList, err := options.discoveryClient.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("couldn't get API groups: %w", err)
+	}
+
+	groups := groupList.Groups
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	return printGroups(groups, options)
+}
+
+// printGroups prints the API groups as a table.
+func printGroups(groups []metav1.APIGroup, options *groupsOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"NAME", "VERSIONS", "PREFERRED"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, group := range groups {
+		versions := make([]string, 0, len(group.Versions))
+		for _, version := range group.Versions {
+			versions = append(versions, version.Version)
+		}
+
+		err := printRow(writer, []string{group.Name, strings.Join(versions, ","), group.PreferredVersion.Version})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunGroups tests listing the API groups with their versions, one row per group.
+func TestRunGroups(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newGroupsOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+
+	err := runGroups(options)
+	if err != nil {
+		t.Fatalf("runGroups() error = %v", err)
+	}
+
+	want := "NAME          VERSIONS        PREFERRED\n" +
+		"              v1              v1\n" +
+		"autoscaling   v2,v1,v2beta2   v2\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runGroups() output = %q, want %q", got, want)
+	}
+}
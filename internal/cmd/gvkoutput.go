@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// errGVKMode is returned when --output=gvk or gvk-json is requested with a mode printing the resources as they are
+// discovered.
+const errGVKMode = constError("output=gvk and gvk-json are not supported with stream or watch")
+
+// errGVKColumns is returned when --output=gvk or gvk-json is requested with flags adding headers or columns.
+const errGVKColumns = constError("output=gvk and gvk-json print only the kinds: remove no-headers, show-counts, " +
+	"show-commands, show-min-k8s, show-server-version, show-versions-served, show-group-priority, and show-usage")
+
+// kindDocument is a kind printed with --output=gvk-json.
+type kindDocument struct {
+	// Cluster is the kubeconfig context the kind was discovered in, when listing multiple contexts.
+	Cluster string `json:"cluster,omitempty"`
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// gvkOutput returns true if the output prints the group version kinds rather than the resources.
+func (o *apiResourceVersionsOptions) gvkOutput() bool {
+	return o.Output == gvkOutput || o.Output == gvkJSONOutput
+}
+
+// validateGVK checks that --output=gvk and gvk-json aren't requested with flags which have no effect on them.
+func (o *apiResourceVersionsOptions) validateGVK() error {
+	if !o.gvkOutput() {
+		return nil
+	}
+
+	if o.Stream || o.Watch {
+		return errGVKMode
+	}
+
+	if o.NoHeaders || o.ShowCounts || o.ShowCommands || o.ShowMinK8s || o.ShowServerVersion || o.ShowVersionsServed ||
+		o.ShowGroupPriority || o.ShowUsage {
+		return errGVKColumns
+	}
+
+	return nil
+}
+
+// groupResourceKinds returns the kind documents of the resources, once for each kind of each cluster, in the order
+// of the resources.
+// Subresources are skipped, as their kind, e.g. Scale, isn't served by the group version of their parent resource.
+func groupResourceKinds(resources []groupResource, options *apiResourceVersionsOptions) []kindDocument {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	type clusterKind struct {
+		cluster string
+		gvk     schema.GroupVersionKind
+	}
+
+	seen := make(map[clusterKind]struct{}, len(resources))
+	kinds := make([]kindDocument, 0, len(resources))
+
+	for _, resource := range resources {
+		if resource.Subresource {
+			continue
+		}
+
+		gvk := schema.FromAPIVersionAndKind(resource.APIGroupVersion, resource.APIResource.Kind)
+
+		key := clusterKind{cluster: resource.Cluster, gvk: gvk}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		kinds = append(kinds, kindDocument{
+			Cluster: resource.Cluster,
+			Group:   options.displayGroup(gvk.Group),
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+		})
+	}
+
+	return kinds
+}
+
+// printGVK prints the kinds of the resources as Kind.version.group, e.g. Deployment.v1.apps or Pod.v1. for the core
+// group, the format of the kinds accepted by kubectl, prefixed by their cluster when listing multiple contexts.
+func printGVK(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	for _, kind := range groupResourceKinds(resources, options) {
+		columns := []string{fmt.Sprintf("%s.%s.%s", kind.Kind, kind.Version, kind.Group)}
+		if len(options.clusters) > 0 {
+			columns = append([]string{kind.Cluster}, columns...)
+		}
+
+		err := printRow(writer, columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printGVKJSON prints the kinds of the resources as a JSON object with the "metadata" and the "kinds" list of group
+// version kinds.
+func printGVKJSON(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata outputMetadata `json:"metadata"`
+		Kinds    []kindDocument `json:"kinds"`
+	}{Metadata: options.outputMetadata(), Kinds: groupResourceKinds(resources, options)})
+	if err != nil {
+		return fmt.Errorf("couldn't print kinds: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestRunGVK tests printing the kinds of the resources as Kind.version.group, once for each kind even when their
+// subresources are included.
+func TestRunGVK(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(gvkOutput).SetIncludeSubresources(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "HorizontalPodAutoscaler.v2.autoscaling\n" +
+		"HorizontalPodAutoscaler.v1.autoscaling\n" +
+		"HorizontalPodAutoscaler.v2beta2.autoscaling\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
+
+// TestRunGVKJSON tests printing the kinds of the resources as a JSON list of group version kinds.
+func TestRunGVKJSON(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(gvkJSONOutput)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	var got struct {
+		Metadata outputMetadata `json:"metadata"`
+		Kinds    []kindDocument `json:"kinds"`
+	}
+
+	err = json.Unmarshal(stdout.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(got.Metadata.Clusters) != 1 {
+		t.Errorf("metadata clusters = %v, want the current context", got.Metadata.Clusters)
+	}
+
+	want := []kindDocument{
+		{Cluster: "", Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+		{Cluster: "", Group: "autoscaling", Version: "v1", Kind: "HorizontalPodAutoscaler"},
+		{Cluster: "", Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"},
+	}
+	if !reflect.DeepEqual(got.Kinds, want) {
+		t.Errorf("kinds = %+v, want %+v", got.Kinds, want)
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	// httpShutdownTimeout is the time given to the in-flight requests to complete when a server is stopped.
+	httpShutdownTimeout = 10 * time.Second
+	// httpReadHeaderTimeout is the time allowed to read the headers of a request.
+	httpReadHeaderTimeout = 10 * time.Second
+	// defaultRefreshJitter is the jitter factor of the refreshes by default, so that the replicas started together
+	// don't query the API server at the same time.
+	defaultRefreshJitter = 0.1
+)
+
+// errRefreshInterval is returned when the refresh interval is not positive.
+const errRefreshInterval = constError("refresh-interval must be positive")
+
+// errRefreshJitter is returned when the jitter factor of the refreshes is negative or greater than 1.
+const errRefreshJitter = constError("jitter must be between 0 and 1")
+
+// validateJitter checks that the jitter factor of the refreshes is between 0 and 1.
+func validateJitter(jitter float64) error {
+	if jitter < 0 || jitter > 1 {
+		return fmt.Errorf("%w: got %v", errRefreshJitter, jitter)
+	}
+
+	return nil
+}
+
+// newHTTPServer returns a new HTTP server for the handler.
+func newHTTPServer(address string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+}
+
+// runHTTPServer runs listen, which serves with the server, until the context is done, then gracefully shuts the
+// server down.
+func runHTTPServer(ctx context.Context, server *http.Server, listen func() error) error {
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), httpShutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	err := listen()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("couldn't serve on %s: %w", server.Addr, err)
+	}
+
+	return nil
+}
+
+// refreshEvery calls refresh every interval, extended by up to jitter times the interval, until the context is done.
+// The interval is counted from the end of the previous refresh, so that slow refreshes don't pile up.
+// Errors are written as warnings to errOut, and don't stop the refreshes.
+func refreshEvery(
+	ctx context.Context,
+	interval time.Duration,
+	jitter float64,
+	errOut io.Writer,
+	refresh func(context.Context) error,
+) {
+	timer := time.NewTimer(jitterInterval(interval, jitter))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			err := refresh(ctx)
+			if err != nil {
+				_, _ = fmt.Fprintf(errOut, "Warning: couldn't refresh: %v\n", err)
+			}
+
+			timer.Reset(jitterInterval(interval, jitter))
+		}
+	}
+}
+
+// jitterInterval returns the interval extended by a random duration of up to jitter times the interval.
+func jitterInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+
+	//nolint:gosec // The jitter spreads the refreshes, it doesn't need a cryptographically secure random source.
+	return interval + time.Duration(rand.Float64()*jitter*float64(interval))
+}
+
+// writeHealth writes the response of a health endpoint, which is healthy unless err is not nil.
+func writeHealth(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	_, _ = w.Write([]byte("ok"))
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// TestJitterInterval tests extending the refresh interval by a random jitter.
+func TestJitterInterval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoJitter", jitterIntervalTest{jitter: 0}.Test)
+	t.Run("DefaultJitter", jitterIntervalTest{jitter: defaultRefreshJitter}.Test)
+	t.Run("FullJitter", jitterIntervalTest{jitter: 1}.Test)
+}
+
+type jitterIntervalTest struct {
+	jitter float64
+}
+
+func (tt jitterIntervalTest) Test(t *testing.T) {
+	t.Parallel()
+
+	const interval = time.Minute
+
+	maxInterval := interval + time.Duration(tt.jitter*float64(interval))
+
+	for range 100 {
+		got := jitterInterval(interval, tt.jitter)
+		if got < interval || got > maxInterval {
+			t.Fatalf("jitterInterval(%s, %v) = %s, want between %s and %s", interval, tt.jitter, got, interval,
+				maxInterval)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Values of the IDENTICAL-TO column of the versions command.
+const (
+	// noIdenticalSchema is printed when no other served version has the same schema.
+	noIdenticalSchema = "<none>"
+	// unknownSchema is printed when the OpenAPI v3 schema of the version isn't published.
+	unknownSchema = "<unknown>"
+)
+
+// gvkExtension is the extension of the OpenAPI schemas of the kinds which lists their group, version, and kind.
+const gvkExtension = "x-kubernetes-group-version-kind"
+
+// annotateSchemaHashes sets the hash of the structural schema of the served versions, left empty for the versions
+// whose OpenAPI v3 schema isn't published.
+func annotateSchemaHashes(openAPIClient openapi.Client, versions []servedVersion) error {
+	for i := range versions {
+		version := &versions[i]
+
+		kind, err := findKindSchema(openAPIClient, version.GroupVersionResource.GroupVersion().WithKind(version.Kind))
+		if errors.Is(err, errOpenAPIUnavailable) || errors.Is(err, errKindSchemaNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		version.SchemaHash, err = structuralSchemaHash(kind)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structuralSchemaHash returns the SHA-256 hash of the structure of the schema of the kind, with its references
+// inlined, and without its descriptions or its group version kind, so that the hashes of identical versions match.
+func structuralSchemaHash(kind *kindSchema) (string, error) {
+	content, err := json.Marshal(structuralSchema(kind.Components, kind.Schema, sets.New[string]()))
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal schema of %s: %w", kind.GroupVersionKind, err)
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// structuralSchema returns the fields of the schema which constrain the objects, with its references inlined.
+// The references being expanded are replaced by their short name to stop at recursive schemas.
+func structuralSchema(schemas map[string]*spec.Schema, fieldSchema *spec.Schema, expanding sets.Set[string]) any {
+	ref := schemaRef(fieldSchema)
+	if ref != "" {
+		name := strings.TrimPrefix(ref, schemaRefPrefix)
+		if expanding.Has(ref) {
+			return map[string]any{"recursive": name[strings.LastIndex(name, ".")+1:]}
+		}
+
+		referenced, ok := schemas[name]
+		if !ok {
+			return map[string]any{"unresolved": name[strings.LastIndex(name, ".")+1:]}
+		}
+
+		expanding.Insert(ref)
+		defer expanding.Delete(ref)
+
+		fieldSchema = referenced
+	}
+
+	structure := map[string]any{
+		"type":     fieldSchema.Type,
+		"format":   fieldSchema.Format,
+		"enum":     fieldSchema.Enum,
+		"nullable": fieldSchema.Nullable,
+		"default":  fieldSchema.Default,
+		"required": slices.Sorted(slices.Values(fieldSchema.Required)),
+	}
+
+	properties := make(map[string]any, len(fieldSchema.Properties))
+	for name, property := range fieldSchema.Properties {
+		properties[name] = structuralSchema(schemas, &property, expanding)
+	}
+
+	structure["properties"] = properties
+
+	if fieldSchema.Items != nil && fieldSchema.Items.Schema != nil {
+		structure["items"] = structuralSchema(schemas, fieldSchema.Items.Schema, expanding)
+	}
+
+	if fieldSchema.AdditionalProperties != nil && fieldSchema.AdditionalProperties.Schema != nil {
+		structure["additionalProperties"] = structuralSchema(schemas, fieldSchema.AdditionalProperties.Schema,
+			expanding)
+	}
+
+	for name, value := range fieldSchema.Extensions {
+		if strings.HasPrefix(name, "x-kubernetes-") && name != gvkExtension {
+			structure[name] = value
+		}
+	}
+
+	return structure
+}
+
+// identicalVersions returns the other group versions of the same resource whose schema has the same hash as the
+// version, or the placeholder of the IDENTICAL-TO column if there are none or the schema is unknown.
+func identicalVersions(versions []servedVersion, version servedVersion) string {
+	if version.SchemaHash == "" {
+		return unknownSchema
+	}
+
+	var identical []string
+
+	for _, other := range versions {
+		if other.GroupVersionResource != version.GroupVersionResource &&
+			other.GroupVersionResource.GroupResource() == version.GroupVersionResource.GroupResource() &&
+			other.SchemaHash == version.SchemaHash {
+			identical = append(identical, other.GroupVersionResource.GroupVersion().String())
+		}
+	}
+
+	if len(identical) == 0 {
+		return noIdenticalSchema
+	}
+
+	return strings.Join(identical, ",")
+}
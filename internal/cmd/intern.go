@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resourceGroup is the API group of the listed resources, shared by all the resources of the group.
+// Unlike the discovered [metav1.APIGroup], it doesn't retain the discovery response, e.g. the server addresses by
+// client CIDR, and its strings are interned so that the clusters of a fleet share them.
+type resourceGroup struct {
+	// Name is the name of the group, empty for the core group.
+	Name string
+	// PreferredVersion is the preferred version of the group.
+	PreferredVersion metav1.GroupVersionForDiscovery
+	// Versions are the versions of the group, in the order of the discovery.
+	Versions []metav1.GroupVersionForDiscovery
+}
+
+// newResourceGroup returns the [resourceGroup] of the discovered group.
+func newResourceGroup(group *metav1.APIGroup) *resourceGroup {
+	versions := make([]metav1.GroupVersionForDiscovery, len(group.Versions))
+	for i, version := range group.Versions {
+		versions[i] = internGroupVersion(version)
+	}
+
+	return &resourceGroup{
+		Name:             intern(group.Name),
+		PreferredVersion: internGroupVersion(group.PreferredVersion),
+		Versions:         versions,
+	}
+}
+
+// internedStrings are the canonical copies of the interned strings, see [intern].
+// The strings are never released, which is bounded by the distinct names of the API surface of the clusters.
+// Unlike with the unique package, the canonical copies remain interned while only the strings are retained, and not
+// their handles.
+//
+//nolint:gochecknoglobals
+var internedStrings sync.Map
+
+// intern returns the canonical copy of the string, so that the equal strings of the resources of every cluster share
+// their memory rather than retaining the discovery responses they were decoded from.
+func intern(s string) string {
+	if s == "" {
+		return s
+	}
+
+	canonical, ok := internedStrings.Load(s)
+	if !ok {
+		clone := strings.Clone(s)
+		canonical, _ = internedStrings.LoadOrStore(clone, clone)
+	}
+
+	//nolint:forcetypeassert
+	return canonical.(string)
+}
+
+// internGroupVersion returns the group version with its strings interned, see [intern].
+func internGroupVersion(version metav1.GroupVersionForDiscovery) metav1.GroupVersionForDiscovery {
+	return metav1.GroupVersionForDiscovery{GroupVersion: intern(version.GroupVersion), Version: intern(version.Version)}
+}
+
+// internAPIResource interns the strings identifying the API resource, see [intern].
+// The slices of the resource, e.g. its verbs, are left as-is as they are shared with the discovery cache.
+func internAPIResource(resource *metav1.APIResource) {
+	resource.Name = intern(resource.Name)
+	resource.SingularName = intern(resource.SingularName)
+	resource.Version = intern(resource.Version)
+	resource.Kind = intern(resource.Kind)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+)
+
+// TestGetGroupResourcesInterned tests that the resources of distinct clusters share the memory of their strings.
+func TestGetGroupResourcesInterned(t *testing.T) {
+	t.Parallel()
+
+	var clusters [2][]groupResource
+
+	for i := range clusters {
+		options := NewTestOptionsBuilder().WithDiscoveryClient(discoverytesting.NewProcedural(2, 2, 2)).
+			APIResourceVersionsOptions()
+
+		resources, err := getGroupResources(t.Context(), options)
+		if err != nil {
+			t.Fatalf("getGroupResources() error = %v", err)
+		}
+
+		clusters[i] = resources
+	}
+
+	if len(clusters[0]) == 0 || len(clusters[0]) != len(clusters[1]) {
+		t.Fatalf("getGroupResources() = %d and %d resources, want the same", len(clusters[0]), len(clusters[1]))
+	}
+
+	for i, resource := range clusters[0] {
+		other := clusters[1][i]
+
+		if unsafe.StringData(resource.APIResource.Name) != unsafe.StringData(other.APIResource.Name) {
+			t.Errorf("resource %s isn't interned", resource.APIResource.Name)
+		}
+
+		if unsafe.StringData(resource.APIGroupVersion) != unsafe.StringData(other.APIGroupVersion) {
+			t.Errorf("group version %s isn't interned", resource.APIGroupVersion)
+		}
+
+		if resource.APIGroup == other.APIGroup {
+			t.Errorf("group %s is shared by the clusters, want a group per cluster", resource.APIGroup.Name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// listExample is the example text for the list command.
+	//
+	//nolint:gochecknoglobals
+	listExample = `
+		# Print all API resources with their group versions, like kubectl api-resource-versions
+		kubectl api-resource-versions list
+
+		# Print the resources of the apps group sorted by kind
+		kubectl api-resource-versions list --api-group=apps --sort-by=kind
+
+		# Search the resources whose name, short names, or kind fuzzily match a query
+		kubectl api-resource-versions list deploy`
+)
+
+// newCmdList returns a command that lists all API resources and their versions, like the api-resource-versions
+// command itself, which keeps listing them when no command is given.
+func newCmdList(restClientGetter *fromDumpFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	options := newAPIResourceVersionsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "list [QUERY]",
+		Short: "List all API resources and versions (the default command)",
+		Long: "List all API resources and their API group versions along with whether the version is preferred.\n" +
+			"This is the default command of kubectl api-resource-versions, which takes the same flags.",
+		Example: templates.Examples(listExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			runAPIResourceVersionsCommand(cmd, options, restClientGetter, args)
+		},
+	}
+
+	addAPIResourceVersionsFlags(cmd, options, restClientGetter)
+
+	return cmd
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// klogFlags are the klog flags added to the command, the other klog flags (e.g. --log_dir) aren't useful to a kubectl
+// plugin.
+//
+//nolint:gochecknoglobals
+var klogFlags = []string{"v", "vmodule"}
+
+// Verbosities of the logs of the discovery.
+// At 6 and higher, client-go logs each HTTP request along with its status and timing too.
+const (
+	// discoveryLogLevel logs the timing of the discovery of the groups and of each group version.
+	discoveryLogLevel klog.Level = 4
+	// retryLogLevel logs the failed discoveries of group versions which are retried.
+	retryLogLevel klog.Level = 2
+)
+
+// addKlogFlags adds the klog verbosity flags to the flag set, i.e. -v and --vmodule like kubectl.
+func addKlogFlags(flags *pflag.FlagSet) {
+	goFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(goFlags)
+
+	for _, name := range klogFlags {
+		// -v has a shorthand, as its name is a single character.
+		flags.AddGoFlag(goFlags.Lookup(name))
+	}
+}
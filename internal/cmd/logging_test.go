@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// TestAddKlogFlags tests adding the klog verbosity flags, with -v as a shorthand like kubectl.
+func TestAddKlogFlags(t *testing.T) {
+	t.Parallel()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	addKlogFlags(flags)
+
+	for _, name := range klogFlags {
+		if flags.Lookup(name) == nil {
+			t.Errorf("addKlogFlags() didn't add --%s", name)
+		}
+	}
+
+	if flags.ShorthandLookup("v") == nil {
+		t.Error("addKlogFlags() didn't add -v")
+	}
+
+	if flags.Lookup("log_dir") != nil {
+		t.Error("addKlogFlags() added --log_dir")
+	}
+}
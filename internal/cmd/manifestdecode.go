@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// defaultMaxDocumentSize is the default maximum size of a manifest document, with its aliases expanded.
+	defaultMaxDocumentSize = 16 << 20
+	// defaultMaxDepth is the default maximum nesting depth of a manifest document.
+	defaultMaxDepth = 512
+	// defaultMaxAliasExpansion is the default maximum number of nodes of a manifest document reached through aliases.
+	defaultMaxAliasExpansion = 100_000
+	// defaultDecodeConcurrency is the default number of manifest documents of a stream transcoded concurrently.
+	defaultDecodeConcurrency = 4
+)
+
+// manifestDecodeOptions contains the options for decoding the manifest documents: their limits, so that untrusted
+// YAML doesn't blow up, and the number of documents transcoded concurrently, for large rendered charts.
+type manifestDecodeOptions struct {
+	MaxDocumentSize   int
+	MaxDepth          int
+	MaxAliasExpansion int
+	Concurrency       int
+}
+
+// newManifestDecodeOptions returns a new [manifestDecodeOptions] with default values.
+func newManifestDecodeOptions() manifestDecodeOptions {
+	return manifestDecodeOptions{
+		MaxDocumentSize:   defaultMaxDocumentSize,
+		MaxDepth:          defaultMaxDepth,
+		MaxAliasExpansion: defaultMaxAliasExpansion,
+		Concurrency:       defaultDecodeConcurrency,
+	}
+}
+
+// addFlags adds the flags for decoding the manifest documents.
+func (o *manifestDecodeOptions) addFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&o.MaxDocumentSize, "max-document-size", o.MaxDocumentSize,
+		"Maximum size in bytes of the values of a manifest document once its YAML aliases are expanded, or 0 for no "+
+			"limit.")
+	flags.IntVar(&o.MaxDepth, "max-depth", o.MaxDepth,
+		"Maximum nesting depth of a manifest document, or 0 for no limit.")
+	flags.IntVar(&o.MaxAliasExpansion, "max-alias-expansion", o.MaxAliasExpansion,
+		"Maximum number of values of a manifest document reached through its YAML aliases, or 0 for no limit.")
+	flags.IntVar(&o.Concurrency, "decode-concurrency", o.Concurrency,
+		"Number of documents of a manifest file transcoded from YAML concurrently.")
+}
+
+// errManifestLimits is returned when a limit of the manifest documents is negative.
+const errManifestLimits = constError("max-document-size, max-depth, and max-alias-expansion must not be negative")
+
+// errDecodeConcurrency is returned when the decode concurrency is not positive.
+const errDecodeConcurrency = constError("decode-concurrency must be positive")
+
+// validate checks that the limits aren't negative, and that the concurrency is positive.
+func (o *manifestDecodeOptions) validate() error {
+	if o.MaxDocumentSize < 0 || o.MaxDepth < 0 || o.MaxAliasExpansion < 0 {
+		return fmt.Errorf("%w: got %d, %d, and %d", errManifestLimits, o.MaxDocumentSize, o.MaxDepth,
+			o.MaxAliasExpansion)
+	}
+
+	if o.Concurrency <= 0 {
+		return fmt.Errorf("%w: got %d", errDecodeConcurrency, o.Concurrency)
+	}
+
+	return nil
+}
+
+// decodeOptions returns the options decoding the manifest documents.
+func (o *manifestDecodeOptions) decodeOptions() yamlutil.DecodeOptions {
+	return yamlutil.DecodeOptions{
+		MaxDocumentSize:   o.MaxDocumentSize,
+		MaxDepth:          o.MaxDepth,
+		MaxAliasExpansion: o.MaxAliasExpansion,
+		Concurrency:       o.Concurrency,
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// defaultHelmBinary is the Helm binary used to render charts and read releases, looked up in the PATH.
+	defaultHelmBinary = "helm"
+	// defaultHelmReleaseName is the name of the release used when rendering Helm charts.
+	defaultHelmReleaseName = "release"
+)
+
+// manifestSourceOptions contains the options for rendering manifests from Helm and kustomize, in addition to plain
+// files.
+type manifestSourceOptions struct {
+	HelmCharts      []string
+	HelmValues      []string
+	HelmReleaseName string
+	HelmReleases    []string
+	HelmBinary      string
+	Kustomizations  []string
+
+	namespace   string
+	kubeContext string
+	kubeConfig  string
+	// kubeVersion and apiVersions are the capabilities of the cluster with which the charts are rendered, so that
+	// the charts branching on .Capabilities render the manifests of the cluster being checked.
+	kubeVersion string
+	apiVersions []string
+}
+
+// newManifestSourceOptions returns a new [manifestSourceOptions] with default values.
+func newManifestSourceOptions() manifestSourceOptions {
+	return manifestSourceOptions{
+		HelmReleaseName: defaultHelmReleaseName,
+		HelmBinary:      defaultHelmBinary,
+	}
+}
+
+// addFlags adds the flags for the manifest sources.
+func (o *manifestSourceOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.HelmCharts, "helm-chart", o.HelmCharts,
+		"Helm chart, as a path or a repository reference, to render with 'helm template' and check. "+
+			"The API versions of the cluster are added to the default capabilities of Helm, so charts checking "+
+			".Capabilities.APIVersions still see the versions built into Helm even if the cluster doesn't serve them.")
+	flags.StringSliceVar(&o.HelmValues, "helm-values", o.HelmValues,
+		"Values files used when rendering the charts given with --helm-chart.")
+	flags.StringVar(&o.HelmReleaseName, "helm-release-name", o.HelmReleaseName,
+		"Release name used when rendering the charts given with --helm-chart.")
+	flags.StringSliceVar(&o.HelmReleases, "helm-release", o.HelmReleases,
+		"Name of a deployed Helm release, in the current namespace, whose manifests are read with "+
+			"'helm get manifest' and checked.")
+	flags.StringVar(&o.HelmBinary, "helm-binary", o.HelmBinary,
+		"Path to the Helm binary used for --helm-chart and --helm-release.")
+	flags.StringSliceVarP(&o.Kustomizations, "kustomize", "k", o.Kustomizations,
+		"Kustomization directory to build and check.")
+}
+
+// empty returns true if no manifest sources are configured.
+func (o *manifestSourceOptions) empty() bool {
+	return len(o.HelmCharts) == 0 && len(o.HelmReleases) == 0 && len(o.Kustomizations) == 0
+}
+
+// complete completes the namespace, context, and kubeconfig passed to Helm from the kubeconfig flags, and the
+// capabilities of the cluster with which the charts are rendered from its discovery.
+func (o *manifestSourceOptions) complete(restClientGetter genericclioptions.RESTClientGetter) error {
+	namespace, _, err := restClientGetter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("couldn't get namespace: %w", err)
+	}
+
+	o.namespace = namespace
+
+	configFlags := configFlagsOf(restClientGetter)
+	if configFlags != nil && configFlags.Context != nil {
+		o.kubeContext = *configFlags.Context
+	}
+
+	if configFlags != nil && configFlags.KubeConfig != nil {
+		o.kubeConfig = *configFlags.KubeConfig
+	}
+
+	if len(o.HelmCharts) == 0 {
+		return nil
+	}
+
+	return o.completeCapabilities(restClientGetter)
+}
+
+// configFlagsOf returns the kubeconfig flags of the REST client getter, or nil if it isn't backed by them.
+func configFlagsOf(restClientGetter genericclioptions.RESTClientGetter) *genericclioptions.ConfigFlags {
+	switch getter := restClientGetter.(type) {
+	case *genericclioptions.ConfigFlags:
+		return getter
+	case *fromDumpFlags:
+		return getter.ConfigFlags
+	default:
+		return nil
+	}
+}
+
+// completeCapabilities completes the Kubernetes version and the API versions of the cluster passed to
+// 'helm template', which would otherwise render the charts with its default capabilities.
+// The Kubernetes version is left to Helm if it isn't known, e.g. with --from-dump.
+func (o *manifestSourceOptions) completeCapabilities(restClientGetter genericclioptions.RESTClientGetter) error {
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	groupList, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("couldn't get server groups: %w", err)
+	}
+
+	o.apiVersions = metav1.ExtractGroupVersions(groupList)
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err == nil {
+		o.kubeVersion = serverVersion.GitVersion
+	}
+
+	return nil
+}
+
+// manifestSource is a named stream of rendered manifests.
+type manifestSource struct {
+	// Name identifies the source in manifest locations, e.g. "helm-chart:./charts/web".
+	Name string
+	// Manifests are the rendered YAML documents.
+	Manifests []byte
+}
+
+// render renders the manifests from every configured source.
+func (o *manifestSourceOptions) render(ctx context.Context) ([]manifestSource, error) {
+	sources := make([]manifestSource, 0, len(o.HelmCharts)+len(o.HelmReleases)+len(o.Kustomizations))
+
+	for _, chart := range o.HelmCharts {
+		args := []string{"template", o.HelmReleaseName, chart, "--namespace", o.namespace}
+		for _, values := range o.HelmValues {
+			args = append(args, "--values", values)
+		}
+
+		if o.kubeVersion != "" {
+			args = append(args, "--kube-version", o.kubeVersion)
+		}
+
+		// Helm adds the --api-versions to its default capability set rather than replacing it.
+		for _, apiVersion := range o.apiVersions {
+			args = append(args, "--api-versions", apiVersion)
+		}
+
+		manifests, err := o.runHelm(ctx, args...)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't render Helm chart %s: %w", chart, err)
+		}
+
+		sources = append(sources, manifestSource{Name: "helm-chart:" + chart, Manifests: manifests})
+	}
+
+	for _, release := range o.HelmReleases {
+		args := []string{"get", "manifest", release, "--namespace", o.namespace}
+		if o.kubeContext != "" {
+			args = append(args, "--kube-context", o.kubeContext)
+		}
+
+		if o.kubeConfig != "" {
+			args = append(args, "--kubeconfig", o.kubeConfig)
+		}
+
+		manifests, err := o.runHelm(ctx, args...)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get the manifests of Helm release %s: %w", release, err)
+		}
+
+		sources = append(sources, manifestSource{Name: "helm-release:" + release, Manifests: manifests})
+	}
+
+	for _, kustomization := range o.Kustomizations {
+		manifests, err := buildKustomization(kustomization)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, manifestSource{Name: "kustomize:" + kustomization, Manifests: manifests})
+	}
+
+	return sources, nil
+}
+
+// runHelm runs the Helm binary with the given arguments and returns its standard output.
+func (o *manifestSourceOptions) runHelm(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, o.HelmBinary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("%s %v: %w: %s", o.HelmBinary, args, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// buildKustomization builds the kustomization in the directory and returns the resulting YAML documents.
+func buildKustomization(dir string) ([]byte, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	resources, err := kustomizer.Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build kustomization %s: %w", dir, err)
+	}
+
+	manifests, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't encode kustomization %s: %w", dir, err)
+	}
+
+	return manifests, nil
+}
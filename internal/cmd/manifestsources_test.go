@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/utils/ptr"
+)
+
+// TestRenderManifestSources tests rendering manifests from Helm and kustomize.
+func TestRenderManifestSources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HelmChart", renderManifestSourcesTest{
+		options: manifestSourceOptions{
+			HelmCharts:      []string{"./charts/web"},
+			HelmValues:      []string{"values.yaml"},
+			HelmReleaseName: defaultHelmReleaseName,
+			HelmBinary:      "testdata/fake-helm.sh",
+			namespace:       "default",
+			kubeVersion:     "v1.33.1",
+			apiVersions:     []string{"v1", "autoscaling/v2"},
+		},
+		wantNames: []string{"helm-chart:./charts/web"},
+		wantManifests: []string{
+			"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: helm-args\n  annotations:\n" +
+				"    args: \"template release ./charts/web --namespace default --values values.yaml " +
+				"--kube-version v1.33.1 --api-versions v1 --api-versions autoscaling/v2\"\n",
+		},
+	}.Test)
+	t.Run("HelmRelease", renderManifestSourcesTest{
+		options: manifestSourceOptions{
+			HelmReleases: []string{"web"},
+			HelmBinary:   "testdata/fake-helm.sh",
+			namespace:    "production",
+			kubeContext:  "prod",
+			kubeConfig:   "/etc/kubeconfig",
+		},
+		wantNames: []string{"helm-release:web"},
+		wantManifests: []string{
+			"---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: helm-args\n  annotations:\n" +
+				"    args: \"get manifest web --namespace production --kube-context prod " +
+				"--kubeconfig /etc/kubeconfig\"\n",
+		},
+	}.Test)
+	t.Run("Kustomize", renderManifestSourcesTest{
+		options: manifestSourceOptions{
+			Kustomizations: []string{"testdata/kustomization"},
+		},
+		wantNames: []string{"kustomize:testdata/kustomization"},
+		wantManifests: []string{
+			"apiVersion: autoscaling/v2beta2\nkind: HorizontalPodAutoscaler\nmetadata:\n  name: web\n" +
+				"  namespace: production\n",
+		},
+	}.Test)
+}
+
+type renderManifestSourcesTest struct {
+	options       manifestSourceOptions
+	wantNames     []string
+	wantManifests []string
+}
+
+func (tt renderManifestSourcesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	sources, err := tt.options.render(t.Context())
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+
+	gotNames := make([]string, len(sources))
+	gotManifests := make([]string, len(sources))
+
+	for i, source := range sources {
+		gotNames[i] = source.Name
+		gotManifests[i] = string(source.Manifests)
+	}
+
+	if !reflect.DeepEqual(gotNames, tt.wantNames) {
+		t.Errorf("render() names = %v, want %v", gotNames, tt.wantNames)
+	}
+
+	if !reflect.DeepEqual(gotManifests, tt.wantManifests) {
+		t.Errorf("render() manifests = %q, want %q", gotManifests, tt.wantManifests)
+	}
+}
+
+// TestCompleteManifestSources tests passing the kubeconfig flags and the capabilities of the cluster to Helm.
+func TestCompleteManifestSources(t *testing.T) {
+	t.Parallel()
+
+	kubeConfig := filepath.Join(t.TempDir(), "kubeconfig")
+
+	err := os.WriteFile(kubeConfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster: {server: "https://prod.example.com"}
+contexts:
+- name: prod
+  context: {cluster: prod, namespace: production}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	configFlags.KubeConfig = &kubeConfig
+	configFlags.Context = ptr.To("prod")
+
+	restClientGetter := newFromDumpFlags(configFlags)
+	restClientGetter.Directory = filepath.Join("testdata", "discovery")
+
+	options := newManifestSourceOptions()
+	options.HelmCharts = []string{"./charts/web"}
+
+	err = options.complete(restClientGetter)
+	if err != nil {
+		t.Fatalf("complete() error = %v", err)
+	}
+
+	if options.namespace != "production" || options.kubeContext != "prod" || options.kubeConfig != kubeConfig {
+		t.Errorf("complete() namespace = %s, context = %s, kubeconfig = %s, want production, prod, %s",
+			options.namespace, options.kubeContext, options.kubeConfig, kubeConfig)
+	}
+
+	if !slices.Contains(options.apiVersions, "apps/v1") || !slices.Contains(options.apiVersions, "v1") {
+		t.Errorf("complete() API versions = %v, want the group versions of the discovery", options.apiVersions)
+	}
+}
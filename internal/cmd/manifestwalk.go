@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/monochromegane/go-gitignore"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// manifestIgnoreFilename is the name of the files containing gitignore-style patterns of the files and directories to
+// skip when walking directories.
+// Patterns are relative to the directory containing the file, and apply to all of its subdirectories.
+const manifestIgnoreFilename = ".arvignore"
+
+// manifestExtensions are the extensions of the files read from directories when no include patterns are given.
+//
+//nolint:gochecknoglobals
+var manifestExtensions = sets.New(".yaml", ".yml", ".json")
+
+// manifestWalkOptions contains the options for finding the manifest files in directories.
+type manifestWalkOptions struct {
+	Recursive bool
+	Include   []string
+	Exclude   []string
+}
+
+// addFlags adds the flags for walking directories.
+func (o *manifestWalkOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&o.Recursive, "recursive", "R", o.Recursive,
+		"Process the directories given with -f recursively.")
+	flags.StringSliceVar(&o.Include, "include", o.Include,
+		"Glob patterns of the files to read from directories, matched against the path relative to the directory "+
+			"or the file name (default all YAML and JSON files).")
+	flags.StringSliceVar(&o.Exclude, "exclude", o.Exclude,
+		"Glob patterns of the files and directories to skip when walking directories, matched against the path "+
+			"relative to the directory or the file name.")
+}
+
+// validate checks that the include and exclude patterns are well-formed.
+func (o *manifestWalkOptions) validate() error {
+	for _, pattern := range append(append([]string{}, o.Include...), o.Exclude...) {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("invalid include or exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// filenames returns the files to read for the filename given with -f.
+// Files are returned as is, while directories are walked, recursively if requested, skipping ignored and excluded
+// files and directories.
+// The returned files are sorted lexically within each directory.
+func (o *manifestWalkOptions) filenames(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", root, err)
+	}
+
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var (
+		filenames []string
+		ignores   = make(map[string]gitignore.IgnoreMatcher)
+	)
+
+	err = filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if relative == "." {
+			return loadManifestIgnore(filename, ignores)
+		}
+
+		if o.skip(filename, filepath.ToSlash(relative), entry.IsDir(), ignores) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			if !o.Recursive {
+				return filepath.SkipDir
+			}
+
+			return loadManifestIgnore(filename, ignores)
+		}
+
+		filenames = append(filenames, filename)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't walk directory %s: %w", root, err)
+	}
+
+	return filenames, nil
+}
+
+// skip returns true if the file or directory is ignored, excluded, or, for files, not included.
+func (o *manifestWalkOptions) skip(
+	filename, relative string,
+	isDir bool,
+	ignores map[string]gitignore.IgnoreMatcher,
+) bool {
+	for dir, ignore := range ignores {
+		ignoreRelative, err := filepath.Rel(dir, filename)
+		if err == nil && !strings.HasPrefix(ignoreRelative, "..") && ignore.Match(filename, isDir) {
+			return true
+		}
+	}
+
+	if matchAny(o.Exclude, relative) {
+		return true
+	}
+
+	if isDir {
+		return false
+	}
+
+	if len(o.Include) == 0 {
+		return !manifestExtensions.Has(path.Ext(relative))
+	}
+
+	return !matchAny(o.Include, relative)
+}
+
+// matchAny returns true if any of the glob patterns match the relative path or its base name.
+func matchAny(patterns []string, relative string) bool {
+	for _, pattern := range patterns {
+		for _, name := range []string{relative, path.Base(relative)} {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// loadManifestIgnore loads the ignore file in the directory, if any.
+func loadManifestIgnore(dir string, ignores map[string]gitignore.IgnoreMatcher) error {
+	ignore, err := gitignore.NewGitIgnore(filepath.Join(dir, manifestIgnoreFilename), dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("couldn't read %s in %s: %w", manifestIgnoreFilename, dir, err)
+	}
+
+	ignores[dir] = ignore
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+// TestManifestWalkFilenames tests finding the manifest files in directories.
+func TestManifestWalkFilenames(t *testing.T) {
+	t.Parallel()
+
+	t.Run("File", manifestWalkFilenamesTest{
+		root: "testdata/tree/notes.txt",
+		want: []string{"testdata/tree/notes.txt"},
+	}.Test)
+	t.Run("Directory", manifestWalkFilenamesTest{
+		root: "testdata/tree",
+		want: []string{"testdata/tree/a.yaml"},
+	}.Test)
+	t.Run("Recursive", manifestWalkFilenamesTest{
+		root:    "testdata/tree",
+		options: manifestWalkOptions{Recursive: true},
+		want: []string{
+			"testdata/tree/a.yaml",
+			"testdata/tree/nested/b.yml",
+			"testdata/tree/test/e.yaml",
+		},
+	}.Test)
+	t.Run("Exclude", manifestWalkFilenamesTest{
+		root:    "testdata/tree",
+		options: manifestWalkOptions{Recursive: true, Exclude: []string{"test", "*.yml"}},
+		want:    []string{"testdata/tree/a.yaml"},
+	}.Test)
+	t.Run("Include", manifestWalkFilenamesTest{
+		root:    "testdata/tree",
+		options: manifestWalkOptions{Recursive: true, Include: []string{"*.txt", "nested/*"}},
+		want: []string{
+			"testdata/tree/nested/b.yml",
+			"testdata/tree/notes.txt",
+		},
+	}.Test)
+	t.Run("Missing", manifestWalkFilenamesTest{
+		root:    "testdata/tree/missing",
+		wantErr: true,
+	}.Test)
+}
+
+type manifestWalkFilenamesTest struct {
+	root    string
+	options manifestWalkOptions
+	want    []string
+	wantErr bool
+}
+
+func (tt manifestWalkFilenamesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got, err := tt.options.filenames(tt.root)
+	if (err != nil) != tt.wantErr {
+		t.Fatalf("filenames() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("filenames() = %v, want %v", got, tt.want)
+	}
+}
+
+// TestManifestWalkValidate tests validation of the include and exclude patterns.
+func TestManifestWalkValidate(t *testing.T) {
+	t.Parallel()
+
+	options := manifestWalkOptions{Exclude: []string{"[invalid"}}
+
+	err := options.validate()
+	if !errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("validate() error = %v, want %v", err, path.ErrBadPattern)
+	}
+}
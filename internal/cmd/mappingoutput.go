@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// errMappingMode is returned when --output=mapping or mapping-json is requested with a mode printing the resources as
+// they are discovered.
+const errMappingMode = constError("output=mapping and mapping-json are not supported with stream or watch")
+
+// errMappingColumns is returned when --output=mapping or mapping-json is requested with flags adding columns.
+const errMappingColumns = constError("output=mapping and mapping-json print only the names of the resources: " +
+	"remove show-counts, show-commands, show-min-k8s, show-server-version, show-versions-served, " +
+	"show-group-priority, and show-usage")
+
+// errMappingJSONNoHeaders is returned when --no-headers is requested with --output=mapping-json.
+const errMappingJSONNoHeaders = constError("no-headers has no effect with output=mapping-json, which never prints " +
+	"headers: remove no-headers")
+
+// resourceNames are the names by which a resource can be referred to, printed with --output=mapping and
+// mapping-json.
+type resourceNames struct {
+	Plural     string   `json:"plural"`
+	Singular   string   `json:"singular"`
+	Kind       string   `json:"kind"`
+	ShortNames []string `json:"shortNames,omitempty"`
+}
+
+// groupVersionNames are the names of the resources of a group version, printed with --output=mapping-json.
+type groupVersionNames struct {
+	// Cluster is the kubeconfig context the group version was discovered in, when listing multiple contexts.
+	Cluster      string          `json:"cluster,omitempty"`
+	GroupVersion string          `json:"groupVersion"`
+	Resources    []resourceNames `json:"resources"`
+}
+
+// mappingOutput returns true if the output prints the names of the resources of each group version.
+func (o *apiResourceVersionsOptions) mappingOutput() bool {
+	return o.Output == mappingOutput || o.Output == mappingJSONOutput
+}
+
+// validateMapping checks that --output=mapping and mapping-json aren't requested with flags which have no effect on
+// them.
+func (o *apiResourceVersionsOptions) validateMapping() error {
+	if !o.mappingOutput() {
+		return nil
+	}
+
+	if o.Stream || o.Watch {
+		return errMappingMode
+	}
+
+	if o.ShowCounts || o.ShowCommands || o.ShowMinK8s || o.ShowServerVersion || o.ShowVersionsServed ||
+		o.ShowGroupPriority || o.ShowUsage {
+		return errMappingColumns
+	}
+
+	if o.Output == mappingJSONOutput && o.NoHeaders {
+		return errMappingJSONNoHeaders
+	}
+
+	return nil
+}
+
+// newResourceNames returns the names of the resource.
+// The singular name is empty in the discovery documents of some resources, in which case it defaults to the lowercase
+// kind, as kubectl does.
+func newResourceNames(resource groupResource) resourceNames {
+	singular := resource.APIResource.SingularName
+	if singular == "" {
+		singular = strings.ToLower(resource.APIResource.Kind)
+	}
+
+	return resourceNames{
+		Plural:     resource.APIResource.Name,
+		Singular:   singular,
+		Kind:       resource.APIResource.Kind,
+		ShortNames: resource.APIResource.ShortNames,
+	}
+}
+
+// groupResourceNames returns the names of the resources grouped by the group version of each cluster, in the order of
+// the resources.
+// Subresources are skipped, as they can't be referred to by a name of their own.
+func groupResourceNames(resources []groupResource, options *apiResourceVersionsOptions) []groupVersionNames {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	indexes := make(map[clusterGroupVersion]int)
+
+	var groupVersions []groupVersionNames
+
+	for _, resource := range resources {
+		if resource.Subresource {
+			continue
+		}
+
+		key := clusterGroupVersion{Cluster: resource.Cluster, GroupVersion: resource.APIGroupVersion}
+
+		index, ok := indexes[key]
+		if !ok {
+			index = len(groupVersions)
+			indexes[key] = index
+			groupVersions = append(groupVersions, groupVersionNames{
+				Cluster:      resource.Cluster,
+				GroupVersion: options.displayGroupVersion(resource.APIGroupVersion),
+				Resources:    nil,
+			})
+		}
+
+		groupVersions[index].Resources = append(groupVersions[index].Resources, newResourceNames(resource))
+	}
+
+	return groupVersions
+}
+
+// printMapping prints the plural, singular, kind, and short names of the resources of each group version as a table.
+func printMapping(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		headers := []string{"APIVERSION", "PLURAL", "SINGULAR", "KIND", "SHORTNAMES"}
+		if len(options.clusters) > 0 {
+			headers = append([]string{"CLUSTER"}, headers...)
+		}
+
+		err := printRow(writer, headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, groupVersion := range groupResourceNames(resources, options) {
+		for _, names := range groupVersion.Resources {
+			columns := []string{
+				groupVersion.GroupVersion, names.Plural, names.Singular, names.Kind, strings.Join(names.ShortNames, ","),
+			}
+			if len(options.clusters) > 0 {
+				columns = append([]string{groupVersion.Cluster}, columns...)
+			}
+
+			err := printRow(writer, columns)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// printMappingJSON prints the names of the resources as a JSON object with the "metadata" and the "groupVersions"
+// list, each with the names of its resources.
+func printMappingJSON(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata      outputMetadata      `json:"metadata"`
+		GroupVersions []groupVersionNames `json:"groupVersions"`
+	}{Metadata: options.outputMetadata(), GroupVersions: groupResourceNames(resources, options)})
+	if err != nil {
+		return fmt.Errorf("couldn't print resource names: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestRunMapping tests printing the names of the resources of each group version as a table, with the singular names
+// missing from the discovery documents defaulted to the lowercase kinds.
+func TestRunMapping(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(mappingOutput).SetIncludeSubresources(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "APIVERSION            PLURAL                     SINGULAR                  KIND                      SHORTNAMES\n" +
+		"autoscaling/v2        horizontalpodautoscalers   horizontalpodautoscaler   HorizontalPodAutoscaler   hpa\n" +
+		"autoscaling/v1        horizontalpodautoscalers   horizontalpodautoscaler   HorizontalPodAutoscaler   hpa\n" +
+		"autoscaling/v2beta2   horizontalpodautoscalers   horizontalpodautoscaler   HorizontalPodAutoscaler   hpa\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
+
+// TestRunMappingJSON tests printing the names of the resources of each group version as JSON.
+func TestRunMappingJSON(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(mappingJSONOutput)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	var got struct {
+		Metadata      outputMetadata      `json:"metadata"`
+		GroupVersions []groupVersionNames `json:"groupVersions"`
+	}
+
+	err = json.Unmarshal(stdout.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	names := []resourceNames{{
+		Plural:     "horizontalpodautoscalers",
+		Singular:   "horizontalpodautoscaler",
+		Kind:       "HorizontalPodAutoscaler",
+		ShortNames: []string{"hpa"},
+	}}
+	want := []groupVersionNames{
+		{Cluster: "", GroupVersion: "autoscaling/v2", Resources: names},
+		{Cluster: "", GroupVersion: "autoscaling/v1", Resources: names},
+		{Cluster: "", GroupVersion: "autoscaling/v2beta2", Resources: names},
+	}
+	if !reflect.DeepEqual(got.GroupVersions, want) {
+		t.Errorf("groupVersions = %+v, want %+v", got.GroupVersions, want)
+	}
+}
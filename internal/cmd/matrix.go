@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// matrixExample is the example text for the matrix command.
+	//
+	//nolint:gochecknoglobals
+	matrixExample = `
+		# Show which resource versions are served by each cluster of the fleet
+		kubectl api-resource-versions matrix --context=prod-eu --context=prod-us --context=staging
+
+		# Show the matrix as a Markdown table
+		kubectl api-resource-versions matrix --context=prod --context=staging --output=markdown`
+)
+
+// matrixCell is whether a resource version is served by a context.
+type matrixCell string
+
+const (
+	// matrixPreferred is used when the resource version is served and preferred.
+	matrixPreferred matrixCell = "preferred"
+	// matrixServed is used when the resource version is served but not preferred.
+	matrixServed matrixCell = "served"
+	// matrixNotServed is used when the resource version is not served.
+	matrixNotServed matrixCell = "-"
+)
+
+// matrixRow is a resource version of the matrix, with a cell for each context.
+type matrixRow struct {
+	// Name is the resource version, e.g. "horizontalpodautoscalers.v2.autoscaling".
+	Name string `json:"name"`
+	// Contexts are the cells of the resource version by context name.
+	Contexts map[string]matrixCell `json:"contexts"`
+
+	group    string
+	resource string
+	version  string
+}
+
+// newCmdMatrix returns a command that shows which resource versions are served by each kubeconfig context.
+func newCmdMatrix(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newMatrixOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "matrix --context=CONTEXT...",
+		Short: "Show which resource versions are served by each context",
+		Long: "Discover the API resources of the kubeconfig contexts concurrently, and show a matrix with a row for each " +
+			"resource version and a column for each context, which tells whether the resource version is served and " +
+			"preferred by the context.\n" +
+			"Subresources are not included.",
+		Example: templates.Examples(matrixExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(configFlags, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runMatrix(cmd.Context(), options))
+		},
+	}
+
+	// The local flag shadows the persistent --context flag of the parent command.
+	cmd.Flags().StringArrayVar(&options.Contexts, "context", options.Contexts,
+		"The name of a kubeconfig context to show as a column, may be given multiple times.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+
+	return cmd
+}
+
+// matrixOptions contains the options for the matrix command.
+type matrixOptions struct {
+	genericiooptions.IOStreams
+
+	Contexts  []string
+	Output    string
+	NoHeaders bool
+
+	discoveryClients []discovery.CachedDiscoveryInterface
+}
+
+// newMatrixOptions returns a new [matrixOptions] with default values.
+func newMatrixOptions(ioStreams genericiooptions.IOStreams) *matrixOptions {
+	return &matrixOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the matrix command.
+func (o *matrixOptions) complete(
+	configFlags *genericclioptions.ConfigFlags,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	if len(o.Contexts) == 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "expected at least one --context")
+	}
+
+	discoveryClients, err := contextDiscoveryClients(configFlags, o.Contexts)
+	if err != nil {
+		return err
+	}
+
+	o.discoveryClients = discoveryClients
+
+	return nil
+}
+
+// errDuplicateContext is returned when a context is given more than once.
+const errDuplicateContext = constError("context given more than once")
+
+// validate checks that options are valid for the matrix command.
+func (o *matrixOptions) validate() error {
+	for i, context := range o.Contexts {
+		if slices.Contains(o.Contexts[:i], context) {
+			return fmt.Errorf("%w: %s", errDuplicateContext, context)
+		}
+	}
+
+	return validateReportOutput(o.Output)
+}
+
+// runMatrix discovers the API resources of the contexts concurrently and prints the matrix.
+func runMatrix(ctx context.Context, options *matrixOptions) error {
+	snapshots, err := contextSnapshots(ctx, options.Contexts, options.discoveryClients, options.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	rows := newMatrixRows(options.Contexts, snapshots)
+
+	switch options.Output {
+	case jsonOutput:
+		return printMatrixJSON(options.Out, options.Contexts, newSnapshotsMetadata(snapshots...), rows)
+	case markdownOutput:
+		return printMatrixMarkdown(options.Out, options.Contexts, rows)
+	default:
+		return printMatrixTable(options.Out, options.Contexts, rows, options.NoHeaders)
+	}
+}
+
+// newMatrixRows returns a row for each resource version served by any of the snapshots, sorted by group, resource,
+// and version priority.
+func newMatrixRows(contexts []string, snapshots []*snapshot) []matrixRow {
+	rowsByName := make(map[string]*matrixRow)
+
+	for i, snap := range snapshots {
+		for _, group := range snap.Groups {
+			for _, groupVersionResources := range group.Versions {
+				groupVersion := schema.GroupVersion{Group: group.Name, Version: groupVersionResources.Version}
+
+				for _, resource := range groupVersionResources.Resources {
+					if _, subName := splitResourceName(resource.Name); subName != nil {
+						continue
+					}
+
+					name := snapshotResourceName(groupVersion, resource.Name)
+
+					row, ok := rowsByName[name]
+					if !ok {
+						row = newMatrixRow(name, groupVersion, resource.Name, contexts)
+						rowsByName[name] = row
+					}
+
+					row.Contexts[contexts[i]] = matrixServed
+					if resource.Preferred {
+						row.Contexts[contexts[i]] = matrixPreferred
+					}
+				}
+			}
+		}
+	}
+
+	rows := make([]matrixRow, 0, len(rowsByName))
+	for _, row := range rowsByName {
+		rows = append(rows, *row)
+	}
+
+	slices.SortFunc(rows, func(a, b matrixRow) int {
+		return cmp.Or(
+			cmp.Compare(a.group, b.group),
+			cmp.Compare(a.resource, b.resource),
+			version.CompareKubeAwareVersionStrings(b.version, a.version),
+		)
+	})
+
+	return rows
+}
+
+// newMatrixRow returns a row of the resource in the group version which isn't served by any context.
+func newMatrixRow(name string, groupVersion schema.GroupVersion, resource string, contexts []string) *matrixRow {
+	row := &matrixRow{
+		Name:     name,
+		Contexts: make(map[string]matrixCell, len(contexts)),
+		group:    groupVersion.Group,
+		resource: resource,
+		version:  groupVersion.Version,
+	}
+
+	for _, context := range contexts {
+		row.Contexts[context] = matrixNotServed
+	}
+
+	return row
+}
+
+// matrixColumns returns the columns of the row in the table and Markdown output formats.
+func matrixColumns(contexts []string, row matrixRow) []string {
+	columns := []string{row.Name}
+	for _, context := range contexts {
+		columns = append(columns, string(row.Contexts[context]))
+	}
+
+	return columns
+}
+
+// printMatrixTable prints the matrix as a table.
+func printMatrixTable(out io.Writer, contexts []string, rows []matrixRow, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		err := printRow(writer, append([]string{"RESOURCE"}, contexts...))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		err := printRow(writer, matrixColumns(contexts, row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printMatrixMarkdown prints the matrix as a Markdown table.
+func printMatrixMarkdown(out io.Writer, contexts []string, rows []matrixRow) error {
+	lines := []string{
+		"| Resource | " + strings.Join(contexts, " | ") + " |",
+		strings.Repeat("| --- ", len(contexts)+1) + "|",
+	}
+	for _, row := range rows {
+		lines = append(lines, "| "+strings.Join(matrixColumns(contexts, row), " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("couldn't write matrix: %w", err)
+	}
+
+	return nil
+}
+
+// printMatrixJSON prints the matrix as a JSON object with the "metadata", and the "contexts" and "resources" lists.
+func printMatrixJSON(out io.Writer, contexts []string, metadata outputMetadata, rows []matrixRow) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata  outputMetadata `json:"metadata"`
+		Contexts  []string       `json:"contexts"`
+		Resources []matrixRow    `json:"resources"`
+	}{Metadata: metadata, Contexts: contexts, Resources: rows})
+	if err != nil {
+		return fmt.Errorf("couldn't write matrix: %w", err)
+	}
+
+	return nil
+}
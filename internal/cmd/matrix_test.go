@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+)
+
+// TestRunMatrix tests showing the resource versions served by each context.
+func TestRunMatrix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Table", runMatrixTest{
+		output: tableOutput,
+		want: "RESOURCE                                       prod        staging\n" +
+			"configmaps.v1.                                 preferred   -\n" +
+			"events.v1.                                     preferred   -\n" +
+			"namespaces.v1.                                 preferred   -\n" +
+			"nodes.v1.                                      preferred   -\n" +
+			"persistentvolumeclaims.v1.                     preferred   -\n" +
+			"persistentvolumes.v1.                          preferred   -\n" +
+			"pods.v1.                                       preferred   -\n" +
+			"secrets.v1.                                    preferred   -\n" +
+			"serviceaccounts.v1.                            preferred   -\n" +
+			"services.v1.                                   preferred   -\n" +
+			"horizontalpodautoscalers.v2.autoscaling        preferred   -\n" +
+			"horizontalpodautoscalers.v1.autoscaling        served      -\n" +
+			"horizontalpodautoscalers.v2beta2.autoscaling   served      -\n" +
+			"resource0.v1.group0                            -           served\n",
+	}.Test)
+	t.Run("Markdown", runMatrixTest{
+		output: markdownOutput,
+		want: "| Resource | prod | staging |\n" +
+			"| --- | --- | --- |\n" +
+			"| configmaps.v1. | preferred | - |\n" +
+			"| events.v1. | preferred | - |\n" +
+			"| namespaces.v1. | preferred | - |\n" +
+			"| nodes.v1. | preferred | - |\n" +
+			"| persistentvolumeclaims.v1. | preferred | - |\n" +
+			"| persistentvolumes.v1. | preferred | - |\n" +
+			"| pods.v1. | preferred | - |\n" +
+			"| secrets.v1. | preferred | - |\n" +
+			"| serviceaccounts.v1. | preferred | - |\n" +
+			"| services.v1. | preferred | - |\n" +
+			"| horizontalpodautoscalers.v2.autoscaling | preferred | - |\n" +
+			"| horizontalpodautoscalers.v1.autoscaling | served | - |\n" +
+			"| horizontalpodautoscalers.v2beta2.autoscaling | served | - |\n" +
+			"| resource0.v1.group0 | - | served |\n",
+	}.Test)
+}
+
+type runMatrixTest struct {
+	output string
+	want   string
+}
+
+func (tt runMatrixTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newMatrixOptions(ioStreams)
+	options.Output = tt.output
+	options.Contexts = []string{"prod", "staging"}
+	options.discoveryClients = []discovery.CachedDiscoveryInterface{
+		discoverytesting.New(),
+		discoverytesting.NewProcedural(1, 1, 1),
+	}
+
+	err := runMatrix(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runMatrix() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runMatrix() output = %q, want %q", got, tt.want)
+	}
+}
+
+// TestMatrixOptionsValidate tests the validation of the matrix options.
+func TestMatrixOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contexts []string
+		output   string
+		wantErr  error
+	}{
+		{name: "Valid", contexts: []string{"prod", "staging"}, output: tableOutput},
+		{name: "DuplicateContext", contexts: []string{"prod", "prod"}, output: tableOutput, wantErr: errDuplicateContext},
+		{name: "InvalidOutput", contexts: []string{"prod"}, output: "yaml", wantErr: errReportOutput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			options := newMatrixOptions(genericiooptions.NewTestIOStreamsDiscard())
+			options.Contexts = tt.contexts
+			options.Output = tt.output
+
+			err := options.validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+)
+
+// outputMetadata is the metadata header of the structured outputs, so that their consumers can attribute and age the
+// data.
+type outputMetadata struct {
+	// Timestamp is the time at which the output was generated.
+	Timestamp time.Time `json:"timestamp"`
+	// Clusters are the clusters from which the data was discovered, in the order of the output.
+	Clusters []clusterMetadata `json:"clusters"`
+}
+
+// clusterMetadata attributes the data of an output to a cluster.
+type clusterMetadata struct {
+	// Context is the kubeconfig context, or the name of the cluster in a clusters file, empty if it is unknown, e.g.
+	// when reading a discovery directory.
+	Context string `json:"context,omitempty"`
+	// ServerVersion is the version of the API server, e.g. "v1.33.1", if it could be determined.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// Timestamp is the time at which the cluster was discovered, when it differs from the time of the output, e.g. for
+	// saved snapshots.
+	Timestamp time.Time `json:"timestamp,omitzero"`
+}
+
+// newOutputMetadata returns the metadata of an output generated now from the clusters.
+func newOutputMetadata(clusters ...clusterMetadata) outputMetadata {
+	return outputMetadata{Timestamp: time.Now().UTC(), Clusters: clusters}
+}
+
+// newClusterMetadata returns the metadata of the cluster of the context, asking its server version.
+func newClusterMetadata(contextName string, discoveryClient discovery.ServerVersionInterface) clusterMetadata {
+	cluster := clusterMetadata{Context: contextName, ServerVersion: "", Timestamp: time.Time{}}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err == nil {
+		cluster.ServerVersion = serverVersion.GitVersion
+	}
+
+	return cluster
+}
+
+// newSnapshotsMetadata returns the metadata of an output generated now from the snapshots.
+func newSnapshotsMetadata(snapshots ...*snapshot) outputMetadata {
+	clusters := make([]clusterMetadata, 0, len(snapshots))
+
+	for _, snap := range snapshots {
+		cluster := clusterMetadata{Context: snap.Context, ServerVersion: "", Timestamp: snap.Timestamp}
+		if snap.ServerVersion != nil {
+			cluster.ServerVersion = snap.ServerVersion.GitVersion
+		}
+
+		clusters = append(clusters, cluster)
+	}
+
+	return newOutputMetadata(clusters...)
+}
+
+// contextName returns the name of the kubeconfig context selected by the REST client getter, if it can be determined,
+// see [fromDumpFlags.contextName].
+func contextName(restClientGetter genericclioptions.RESTClientGetter) string {
+	flags, ok := restClientGetter.(*fromDumpFlags)
+	if !ok {
+		return ""
+	}
+
+	return flags.contextName()
+}
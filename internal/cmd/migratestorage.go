@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	apimachineryerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	defaultMigrateConcurrency = 4
+	defaultMigrateChunkSize   = 500
+)
+
+var (
+	// migrateStorageExample is the example text for the migrate-storage command.
+	//
+	//nolint:gochecknoglobals
+	migrateStorageExample = `
+		# Rewrite all the HorizontalPodAutoscalers at their current storage version
+		kubectl api-resource-versions migrate-storage horizontalpodautoscalers.autoscaling
+
+		# Show how many objects would be rewritten, without modifying them
+		kubectl api-resource-versions migrate-storage horizontalpodautoscalers.autoscaling --dry-run
+
+		# Rewrite the objects of every resource in the apps group
+		kubectl api-resource-versions --api-group=apps --preferred --verbs=list,patch --output=name |
+			xargs kubectl api-resource-versions migrate-storage`
+)
+
+// touchPatch is an empty merge patch, which causes the API server to re-encode the object at the current storage
+// version without otherwise modifying it.
+//
+//nolint:gochecknoglobals
+var touchPatch = []byte("{}")
+
+// newCmdMigrateStorage returns a command that rewrites every object of the selected resources at their current
+// storage version.
+func newCmdMigrateStorage(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newMigrateStorageOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "migrate-storage RESOURCE [RESOURCE...]",
+		Short: "Rewrite objects at the current storage version",
+		Long: "Rewrite every object of the given resources by applying an empty patch, causing the API server to " +
+			"re-encode them in etcd at the current storage version.\n" +
+			"Resources can be given as <resource>.<group> to use the preferred version, or as " +
+			"<resource>.<version>.<group> as printed by --output=name.",
+		Example:           templates.Examples(migrateStorageExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runMigrateStorage(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().IntVar(&options.Concurrency, "concurrency", options.Concurrency,
+		"Number of objects to rewrite concurrently.")
+	cmd.Flags().Int64Var(&options.ChunkSize, "chunk-size", options.ChunkSize,
+		"Return large lists in chunks rather than all at once. Pass 0 to disable.")
+	cmd.Flags().BoolVar(&options.DryRun, "dry-run", options.DryRun,
+		"Only list the objects which would be rewritten, without modifying them.")
+
+	return cmd
+}
+
+// migrateStorageOptions contains the options for the migrate-storage command.
+type migrateStorageOptions struct {
+	genericiooptions.IOStreams
+
+	Concurrency int
+	ChunkSize   int64
+	DryRun      bool
+
+	resources       []schema.GroupVersionResource
+	discoveryClient discovery.DiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newMigrateStorageOptions returns a new [migrateStorageOptions] with default values.
+func newMigrateStorageOptions(ioStreams genericiooptions.IOStreams) *migrateStorageOptions {
+	return &migrateStorageOptions{
+		IOStreams:   ioStreams,
+		Concurrency: defaultMigrateConcurrency,
+		ChunkSize:   defaultMigrateChunkSize,
+	}
+}
+
+// complete completes all the required options for the migrate-storage command.
+func (o *migrateStorageOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) == 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "at least one resource is required")
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	o.resources = make([]schema.GroupVersionResource, 0, len(args))
+
+	for _, arg := range args {
+		gvr, err := resolveResourceArg(o.discoveryClient, arg)
+		if err != nil {
+			return err
+		}
+
+		o.resources = append(o.resources, gvr)
+	}
+
+	return nil
+}
+
+// errConcurrency is returned when the concurrency is not positive.
+const errConcurrency = constError("concurrency must be at least 1")
+
+// errChunkSize is returned when the chunk size is negative.
+const errChunkSize = constError("chunk-size must not be negative")
+
+// validate checks that options are valid for the migrate-storage command.
+func (o *migrateStorageOptions) validate() error {
+	if o.Concurrency < 1 {
+		return fmt.Errorf("%w: %d", errConcurrency, o.Concurrency)
+	}
+
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("%w: %d", errChunkSize, o.ChunkSize)
+	}
+
+	return nil
+}
+
+// errResourceNotFound is returned when a resource argument doesn't match any resource served by the cluster.
+const errResourceNotFound = constError("the server doesn't have a resource type")
+
+// resolveResourceArg resolves a resource argument in the format <resource>.<group> or <resource>.<version>.<group>
+// to a served group version resource.
+// When no version is given, the preferred version of the resource is used.
+func resolveResourceArg(discoveryClient discovery.DiscoveryInterface, arg string) (schema.GroupVersionResource, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(arg)
+
+	if fullySpecified != nil {
+		groupList, err := discoveryClient.ServerGroups()
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("couldn't get server groups: %w", err)
+		}
+
+		servedGroupVersions := sets.New(metav1.ExtractGroupVersions(groupList)...)
+		if servedGroupVersions.Has(fullySpecified.GroupVersion().String()) {
+			return *fullySpecified, nil
+		}
+	}
+
+	preferredVersions, err := apiresourceversions.ServerPreferredVersions(discoveryClient)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("couldn't get preferred resource versions: %w", err)
+	}
+
+	version, ok := preferredVersions[groupResource]
+	if !ok {
+		return schema.GroupVersionResource{}, fmt.Errorf("%w %q", errResourceNotFound, arg)
+	}
+
+	return groupResource.WithVersion(version), nil
+}
+
+// runMigrateStorage rewrites the objects of each of the selected resources.
+func runMigrateStorage(ctx context.Context, options *migrateStorageOptions) error {
+	var errs []error
+
+	for _, gvr := range options.resources {
+		migrated, err := migrateResourceStorage(ctx, gvr, options)
+
+		action := "migrated"
+		if options.DryRun {
+			action = "would migrate"
+		}
+
+		_, writeErr := fmt.Fprintf(options.Out, "%s: %s %d objects\n", gvr.String(), action, migrated)
+		if writeErr != nil {
+			return fmt.Errorf("error printing migration result: %w", writeErr)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return apimachineryerrors.NewAggregate(errs)
+}
+
+// migrateResourceStorage rewrites every object of the resource, listing them in chunks and patching them
+// concurrently.
+// It returns the number of objects which have been rewritten, even if an error occurred.
+func migrateResourceStorage(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	options *migrateStorageOptions,
+) (int64, error) {
+	var migrated atomic.Int64
+
+	resourceClient := options.dynamicClient.Resource(gvr)
+	listOptions := metav1.ListOptions{Limit: options.ChunkSize}
+
+	for {
+		list, err := resourceClient.List(ctx, listOptions)
+		if err != nil {
+			return migrated.Load(), fmt.Errorf("couldn't list %s: %w", gvr.String(), err)
+		}
+
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(options.Concurrency)
+
+		for _, item := range list.Items {
+			if options.DryRun {
+				migrated.Add(1)
+
+				continue
+			}
+
+			group.Go(func() error {
+				_, err := resourceClient.Namespace(item.GetNamespace()).
+					Patch(groupCtx, item.GetName(), types.MergePatchType, touchPatch, metav1.PatchOptions{})
+				if apierrors.IsNotFound(err) {
+					return nil // The object has been deleted since it was listed.
+				} else if err != nil {
+					return fmt.Errorf("couldn't migrate %s %s/%s: %w",
+						gvr.String(), item.GetNamespace(), item.GetName(), err)
+				}
+
+				migrated.Add(1)
+
+				return nil
+			})
+		}
+
+		err = group.Wait()
+		if err != nil {
+			//nolint:wrapcheck
+			return migrated.Load(), err
+		}
+
+		_, _ = fmt.Fprintf(options.ErrOut, "%s: processed %d objects\n", gvr.String(), migrated.Load())
+
+		listOptions.Continue = list.GetContinue()
+		if listOptions.Continue == "" {
+			return migrated.Load(), nil
+		}
+	}
+}
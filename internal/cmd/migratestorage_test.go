@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestResolveResourceArg tests resolving resource arguments to served group version resources.
+func TestResolveResourceArg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		arg     string
+		want    schema.GroupVersionResource
+		wantErr error
+	}{
+		{arg: "pods", want: schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+		{arg: "pods.v1.", want: schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+		{
+			arg:  "horizontalpodautoscalers.autoscaling",
+			want: schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+		},
+		{
+			arg:  "horizontalpodautoscalers.v1.autoscaling",
+			want: schema.GroupVersionResource{Group: "autoscaling", Version: "v1", Resource: "horizontalpodautoscalers"},
+		},
+		{arg: "deployments.apps", wantErr: errResourceNotFound},
+		{arg: "horizontalpodautoscalers.v3.autoscaling", wantErr: errResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveResourceArg(discoverytesting.New(), tt.arg)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("resolveResourceArg(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+		}
+
+		if got != tt.want {
+			t.Errorf("resolveResourceArg(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+// TestMigrateResourceStorage tests rewriting the objects of a resource.
+func TestMigrateResourceStorage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Migrate", migrateResourceStorageTest{
+		dryRun:      false,
+		wantCount:   3,
+		wantPatches: 3,
+	}.Test)
+	t.Run("DryRun", migrateResourceStorageTest{
+		dryRun:      true,
+		wantCount:   3,
+		wantPatches: 0,
+	}.Test)
+}
+
+type migrateResourceStorageTest struct {
+	dryRun      bool
+	wantCount   int64
+	wantPatches int
+}
+
+func (tt migrateResourceStorageTest) Test(t *testing.T) {
+	t.Parallel()
+
+	dynamicClient := discoverytesting.NewDynamic([]runtime.Object{
+		newUnstructured("v1", "Pod", "default", "pod-a"),
+		newUnstructured("v1", "Pod", "default", "pod-b"),
+		newUnstructured("v1", "Pod", "kube-system", "pod-c"),
+		newUnstructured("v1", "ConfigMap", "default", "config"),
+	}...)
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	options := newMigrateStorageOptions(ioStreams)
+	options.dynamicClient = dynamicClient
+	options.DryRun = tt.dryRun
+
+	got, err := migrateResourceStorage(t.Context(), schema.GroupVersionResource{Version: "v1", Resource: "pods"}, options)
+	if err != nil {
+		t.Fatalf("migrateResourceStorage() error = %v", err)
+	}
+
+	if got != tt.wantCount {
+		t.Errorf("migrateResourceStorage() = %d, want %d", got, tt.wantCount)
+	}
+
+	patches := 0
+
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "patch" {
+			patches++
+		}
+	}
+
+	if patches != tt.wantPatches {
+		t.Errorf("migrateResourceStorage() patches = %d, want %d", patches, tt.wantPatches)
+	}
+}
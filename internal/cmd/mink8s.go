@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// noMinK8s is printed in the MIN-K8S column for the resources which aren't built into Kubernetes, e.g. custom
+// resources, or whose release of introduction is unknown.
+const noMinK8s = "<none>"
+
+// errNameMinK8s is returned when --show-min-k8s is requested with --output=name, name0, api-versions, or script,
+// which don't print the releases.
+const errNameMinK8s = constError("show-min-k8s has no effect with output=name, name0, api-versions, or script, " +
+	"which don't print the releases: remove show-min-k8s or use the default or wide output")
+
+// minK8s returns the Kubernetes minor release in which the kind of the resource was introduced in its group version,
+// e.g. "1.23" for autoscaling/v2 HorizontalPodAutoscaler, from the lifecycle of the built-in APIs.
+// It returns an empty string if the resource isn't a built-in API, or if its release of introduction is unknown.
+func (gr groupResource) minK8s() string {
+	gvk := schema.FromAPIVersionAndKind(gr.APIGroupVersion, gr.APIResource.Kind)
+
+	api, known := lifecycle.Lookup(gvk)
+	if !known {
+		return ""
+	}
+
+	return api.Introduced
+}
+
+// minK8sString returns the release in which the resource was introduced, as printed in the MIN-K8S column.
+func (gr groupResource) minK8sString() string {
+	minK8s := gr.minK8s()
+	if minK8s == "" {
+		return noMinK8s
+	}
+
+	return minK8s
+}
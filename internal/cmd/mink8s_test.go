@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestMinK8sString tests the releases in which the resources were introduced.
+func TestMinK8sString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("BuiltIn", minK8sStringTest{
+		resource: groupResource{
+			APIGroup:        &resourceGroup{Name: "autoscaling"},
+			APIGroupVersion: "autoscaling/v2",
+			APIResource:     &metav1.APIResource{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler"},
+		},
+		want: "1.23",
+	}.Test)
+	t.Run("Core", minK8sStringTest{
+		resource: groupResource{
+			APIGroup:        &resourceGroup{Name: ""},
+			APIGroupVersion: "v1",
+			APIResource:     &metav1.APIResource{Name: "pods", Kind: "Pod"},
+		},
+		want: "1.0",
+	}.Test)
+	t.Run("CustomResource", minK8sStringTest{
+		resource: groupResource{
+			APIGroup:        &resourceGroup{Name: "cert-manager.io"},
+			APIGroupVersion: "cert-manager.io/v1",
+			APIResource:     &metav1.APIResource{Name: "certificates", Kind: "Certificate"},
+		},
+		want: noMinK8s,
+	}.Test)
+}
+
+type minK8sStringTest struct {
+	resource groupResource
+	want     string
+}
+
+func (tt minK8sStringTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got := tt.resource.minK8sString()
+	if got != tt.want {
+		t.Errorf("minK8sString() = %v, want %v", got, tt.want)
+	}
+}
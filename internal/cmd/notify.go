@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// notifyFormatGeneric posts the notification as a JSON object with the summary and each of the changes.
+	notifyFormatGeneric = "generic"
+	// notifyFormatSlack posts the notification as a Slack incoming webhook payload, with a text message.
+	notifyFormatSlack = "slack"
+
+	// notifyTimeout is the timeout of the request posting a notification.
+	notifyTimeout = 10 * time.Second
+)
+
+const (
+	// reasonResourceVersionAdded is the reason of the change notified when a resource version is served by --watch.
+	reasonResourceVersionAdded = "ResourceVersionAdded"
+	// reasonResourceVersionRemoved is the reason of the change notified when a resource version isn't served anymore
+	// by --watch.
+	reasonResourceVersionRemoved = "ResourceVersionRemoved"
+	// reasonPreferredVersionChanged is the reason of the change notified when the preferred version of a resource
+	// changes with --watch.
+	reasonPreferredVersionChanged = "PreferredVersionChanged"
+)
+
+// errNotifyFormat is returned when the --notify-format value is not supported.
+const errNotifyFormat = constError("notify-format must be one of: (" + notifyFormatGeneric + ", " +
+	notifyFormatSlack + ")")
+
+// errNotifyURL is returned when the --notify-url value isn't an absolute HTTP or HTTPS URL.
+const errNotifyURL = constError("notify-url must be an http or https URL")
+
+// errNotifyWatch is returned when --notify-url is requested without --watch, so that there are no changes to notify.
+const errNotifyWatch = constError("notify-url requires watch")
+
+// errNotifyStatus is returned when the webhook doesn't accept the notification.
+const errNotifyStatus = constError("webhook responded with an unexpected status")
+
+// notifyOptions are the options posting a notification to a webhook when the API changes, shared between --watch and
+// the controller command.
+type notifyOptions struct {
+	// URL is the URL of the webhook to which the notifications are posted, notifications are disabled if empty.
+	URL string
+	// Format is the format of the payload, see [notifyFormatGeneric] and [notifyFormatSlack].
+	Format string
+}
+
+// newNotifyOptions returns a new [notifyOptions] with default values.
+func newNotifyOptions() notifyOptions {
+	return notifyOptions{Format: notifyFormatGeneric}
+}
+
+// addFlags adds the flags of the notifications to the flag set.
+func (o *notifyOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.URL, "notify-url", o.URL,
+		"URL of a webhook to which a summary is posted whenever the API changes or a deprecated version starts "+
+			"being served.")
+	flags.StringVar(&o.Format, "notify-format", o.Format,
+		"Format of the payload posted to --notify-url. One of: ("+notifyFormatGeneric+", "+notifyFormatSlack+").")
+}
+
+// validate checks that the URL and the format of the notifications are valid.
+func (o *notifyOptions) validate() error {
+	if o.Format != notifyFormatGeneric && o.Format != notifyFormatSlack {
+		return fmt.Errorf("%w: %s is not available", errNotifyFormat, o.Format)
+	}
+
+	if o.URL == "" {
+		return nil
+	}
+
+	webhookURL, err := url.Parse(o.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return fmt.Errorf("%w: got %s", errNotifyURL, o.URL)
+	}
+
+	return nil
+}
+
+// notification is the payload posted with --notify-format=generic.
+type notification struct {
+	// Context is the kubeconfig context of the cluster, if it could be determined.
+	Context string `json:"context,omitempty"`
+	// Summary is a one-line summary of the changes.
+	Summary string `json:"summary"`
+	// Changes are the changes of the API.
+	Changes []reportChange `json:"changes"`
+}
+
+// slackNotification is the payload posted with --notify-format=slack.
+type slackNotification struct {
+	Text string `json:"text"`
+}
+
+// newNotification returns the notification of the changes of the API of the cluster.
+func newNotification(contextName string, changes []reportChange) notification {
+	warnings := 0
+
+	for _, change := range changes {
+		if change.Type == corev1.EventTypeWarning {
+			warnings++
+		}
+	}
+
+	cluster := "the cluster"
+	if contextName != "" {
+		cluster = fmt.Sprintf("the cluster of context %s", contextName)
+	}
+
+	return notification{
+		Context: contextName,
+		Summary: fmt.Sprintf("The API of %s changed: %d changes, %d warnings", cluster, len(changes), warnings),
+		Changes: changes,
+	}
+}
+
+// slackText returns the text of the notification for Slack, the summary followed by a bullet for each change.
+func (n notification) slackText() string {
+	var text strings.Builder
+
+	text.WriteString(n.Summary)
+
+	for _, change := range n.Changes {
+		fmt.Fprintf(&text, "\n• %s: %s", change.Reason, change.Message)
+	}
+
+	return text.String()
+}
+
+// notify posts the notification of the changes to the webhook, unless notifications are disabled or there are no
+// changes.
+func (o *notifyOptions) notify(ctx context.Context, contextName string, changes []reportChange) error {
+	if o.URL == "" || len(changes) == 0 {
+		return nil
+	}
+
+	message := newNotification(contextName, changes)
+
+	var payload any = message
+	if o.Format == notifyFormatSlack {
+		payload = slackNotification{Text: message.slackText()}
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("couldn't encode the notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.URL, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("couldn't create the notification request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("couldn't post the notification: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", errNotifyStatus, response.Status)
+	}
+
+	return nil
+}
+
+// watchReportChanges returns the changes of resource versions observed by --watch as the changes notified, followed
+// by the deprecated versions which are added, according to the kinds served by the cluster.
+func watchReportChanges(changes []watchChange, current []groupResource, kinds *servedKinds) []reportChange {
+	reportChanges := make([]reportChange, 0, len(changes))
+	added := make(map[string]struct{})
+
+	for _, change := range changes {
+		switch change.Change {
+		case resourceAdded:
+			added[change.Name] = struct{}{}
+			reportChanges = append(reportChanges, reportChange{
+				Type:    corev1.EventTypeNormal,
+				Reason:  reasonResourceVersionAdded,
+				Message: fmt.Sprintf("Resource version %s is now served", change.Name),
+			})
+		case resourceRemoved:
+			reportChanges = append(reportChanges, reportChange{
+				Type:    corev1.EventTypeWarning,
+				Reason:  reasonResourceVersionRemoved,
+				Message: fmt.Sprintf("Resource version %s is no longer served", change.Name),
+			})
+		case resourcePreferred:
+			reportChanges = append(reportChanges, reportChange{
+				Type:    corev1.EventTypeNormal,
+				Reason:  reasonPreferredVersionChanged,
+				Message: fmt.Sprintf("Resource version %s is now preferred", change.Name),
+			})
+		}
+	}
+
+	for _, resource := range current {
+		if _, ok := added[resource.fullname()]; !ok || resource.Subresource {
+			continue
+		}
+
+		gvk := resource.groupVersionResource().GroupVersion().WithKind(resource.APIResource.Kind)
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+
+		if !kinds.check(manifestLocation{}, obj).Status.deprecated() {
+			continue
+		}
+
+		reportChanges = append(reportChanges, reportChange{
+			Type:    corev1.EventTypeWarning,
+			Reason:  reasonDeprecatedVersionServed,
+			Message: fmt.Sprintf("Deprecated version %s of %s is now served", gvk.GroupVersion(), gvk.Kind),
+		})
+	}
+
+	return reportChanges
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// testReportChanges are changes of the API notified in the tests.
+//
+//nolint:gochecknoglobals
+var testReportChanges = []reportChange{
+	{Type: corev1.EventTypeNormal, Reason: reasonGroupVersionAdded, Message: "Group version apps/v2 is now served"},
+	{Type: corev1.EventTypeWarning, Reason: reasonGroupVersionRemoved, Message: "Group version apps/v1 is no longer served"},
+}
+
+// TestNotify tests posting the notifications to a webhook in each of the formats.
+func TestNotify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Generic", notifyTest{
+		format: notifyFormatGeneric,
+		want: `{"context":"prod","summary":"The API of the cluster of context prod changed: 2 changes, 1 warnings",` +
+			`"changes":[{"type":"Normal","reason":"GroupVersionAdded","message":"Group version apps/v2 is now served"},` +
+			`{"type":"Warning","reason":"GroupVersionRemoved","message":"Group version apps/v1 is no longer served"}]}`,
+	}.Test)
+	t.Run("Slack", notifyTest{
+		format: notifyFormatSlack,
+		want: `{"text":"The API of the cluster of context prod changed: 2 changes, 1 warnings\n` +
+			`• GroupVersionAdded: Group version apps/v2 is now served\n` +
+			`• GroupVersionRemoved: Group version apps/v1 is no longer served"}`,
+	}.Test)
+}
+
+type notifyTest struct {
+	format string
+	want   string
+}
+
+func (tt notifyTest) Test(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	options := notifyOptions{URL: server.URL, Format: tt.format}
+
+	err := options.notify(t.Context(), "prod", testReportChanges)
+	if err != nil {
+		t.Fatalf("notify() error = %v", err)
+	}
+
+	// The payload is compared after decoding, as the escaping of the bullets is up to the encoder.
+	var got, want any
+
+	err = json.Unmarshal([]byte(<-bodies), &got)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	err = json.Unmarshal([]byte(tt.want), &want)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("notify() payload = %v, want %v", got, want)
+	}
+}
+
+// TestNotifyStatus tests that a webhook rejecting the notification is reported as an error.
+func TestNotifyStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	options := notifyOptions{URL: server.URL, Format: notifyFormatGeneric}
+
+	err := options.notify(t.Context(), "", testReportChanges)
+	if !errors.Is(err, errNotifyStatus) {
+		t.Errorf("notify() error = %v, want %v", err, errNotifyStatus)
+	}
+}
+
+// TestNotifyNoChanges tests that nothing is posted without changes.
+func TestNotifyNoChanges(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("notification posted without changes")
+	}))
+	t.Cleanup(server.Close)
+
+	options := notifyOptions{URL: server.URL, Format: notifyFormatGeneric}
+
+	err := options.notify(t.Context(), "", nil)
+	if err != nil {
+		t.Errorf("notify() error = %v", err)
+	}
+}
+
+// TestWatchReportChanges tests notifying the changes observed by --watch, with the deprecated versions added.
+func TestWatchReportChanges(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := discoverytesting.New()
+	options := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	options.discoveryClient = discoveryClient
+
+	current, err := getGroupResources(t.Context(), options)
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	changes := []watchChange{
+		{Change: resourceAdded, Name: "horizontalpodautoscalers.v2beta2.autoscaling"},
+		{Change: resourceRemoved, Name: "cronjobs.v1beta1.batch"},
+		{Change: resourcePreferred, Name: "horizontalpodautoscalers.v2.autoscaling"},
+	}
+
+	got := watchReportChanges(changes, current, newServedKinds(current, discoveryClient))
+	want := []reportChange{
+		{
+			Type:    corev1.EventTypeNormal,
+			Reason:  reasonResourceVersionAdded,
+			Message: "Resource version horizontalpodautoscalers.v2beta2.autoscaling is now served",
+		},
+		{
+			Type:    corev1.EventTypeWarning,
+			Reason:  reasonResourceVersionRemoved,
+			Message: "Resource version cronjobs.v1beta1.batch is no longer served",
+		},
+		{
+			Type:    corev1.EventTypeNormal,
+			Reason:  reasonPreferredVersionChanged,
+			Message: "Resource version horizontalpodautoscalers.v2.autoscaling is now preferred",
+		},
+		{
+			Type:    corev1.EventTypeWarning,
+			Reason:  reasonDeprecatedVersionServed,
+			Message: "Deprecated version autoscaling/v2beta2 of HorizontalPodAutoscaler is now served",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("watchReportChanges() = %v, want %v", got, want)
+	}
+}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"time"
 
-	"github.com/Izzette/kubectl-api-resource-versions/internal/discoverytesting"
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
 
@@ -23,6 +25,7 @@ func NewTestOptionsBuilder() *APIResourceVersionsOptionsBuilder {
 	options := newAPIResourceVersionsOptions(ioStreams)
 	discoveryClient := discoverytesting.New()
 	options.discoveryClient = discoveryClient
+	options.dynamicClient = discoverytesting.NewDynamic()
 	builder := &APIResourceVersionsOptionsBuilder{
 		options:         options,
 		discoveryClient: discoveryClient,
@@ -54,6 +57,24 @@ func (o *APIResourceVersionsOptionsBuilder) WithDiscoveryClient(
 	return o
 }
 
+// WithStaleDiscoveryClient sets the discovery directory read when the discovery fails, see
+// [apiResourceVersionsOptions.StaleOK].
+func (o *APIResourceVersionsOptionsBuilder) WithStaleDiscoveryClient(directory string) *APIResourceVersionsOptionsBuilder {
+	o.options.StaleOK = true
+	o.options.staleDiscoveryClient = newDirectoryDiscoveryClient(directory)
+
+	return o
+}
+
+// WithDynamicClient overrides the dynamic client for the options.
+func (o *APIResourceVersionsOptionsBuilder) WithDynamicClient(
+	dynamicClient dynamic.Interface,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.dynamicClient = dynamicClient
+
+	return o
+}
+
 // SetOutput sets the output format for the options, see [apiResourceVersionsOptions.Output].
 func (o *APIResourceVersionsOptionsBuilder) SetOutput(output string) *APIResourceVersionsOptionsBuilder {
 	o.options.Output = output
@@ -61,6 +82,13 @@ func (o *APIResourceVersionsOptionsBuilder) SetOutput(output string) *APIResourc
 	return o
 }
 
+// SetOutputFile sets the file to which the output is written, see [apiResourceVersionsOptions.OutputFile].
+func (o *APIResourceVersionsOptionsBuilder) SetOutputFile(outputFile string) *APIResourceVersionsOptionsBuilder {
+	o.options.OutputFile = outputFile
+
+	return o
+}
+
 // SetSortBy sets the sort order for the options, see [apiResourceVersionsOptions.SortBy].
 func (o *APIResourceVersionsOptionsBuilder) SetSortBy(sortBy string) *APIResourceVersionsOptionsBuilder {
 	o.options.SortBy = sortBy
@@ -105,6 +133,97 @@ func (o *APIResourceVersionsOptionsBuilder) SetCached(cached bool) *APIResourceV
 	return o
 }
 
+// SetCacheTTL sets the maximum age of the cached discovery, see [apiResourceVersionsOptions.CacheTTL].
+func (o *APIResourceVersionsOptionsBuilder) SetCacheTTL(cacheTTL time.Duration) *APIResourceVersionsOptionsBuilder {
+	o.options.CacheTTL = cacheTTL
+
+	return o
+}
+
+// SetDiscoveryConcurrency sets the number of group versions discovered concurrently, see
+// [apiResourceVersionsOptions.DiscoveryConcurrency].
+func (o *APIResourceVersionsOptionsBuilder) SetDiscoveryConcurrency(
+	discoveryConcurrency int,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.DiscoveryConcurrency = discoveryConcurrency
+
+	return o
+}
+
+// SetStream sets whether to print the resources as soon as they are discovered, see
+// [apiResourceVersionsOptions.Stream].
+func (o *APIResourceVersionsOptionsBuilder) SetStream(stream bool) *APIResourceVersionsOptionsBuilder {
+	o.options.Stream = stream
+
+	return o
+}
+
+// SetTimeout sets the maximum time to discover the resources, see [apiResourceVersionsOptions.Timeout].
+func (o *APIResourceVersionsOptionsBuilder) SetTimeout(timeout time.Duration) *APIResourceVersionsOptionsBuilder {
+	o.options.Timeout = timeout
+
+	return o
+}
+
+// SetRetries sets the number of retries of the discovery of a group version, see
+// [apiResourceVersionsOptions.Retries].
+func (o *APIResourceVersionsOptionsBuilder) SetRetries(retries int) *APIResourceVersionsOptionsBuilder {
+	o.options.Retries = retries
+
+	return o
+}
+
+// SetRetryBackoff sets the delay before the first retry, see [apiResourceVersionsOptions.RetryBackoff].
+func (o *APIResourceVersionsOptionsBuilder) SetRetryBackoff(
+	retryBackoff time.Duration,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.RetryBackoff = retryBackoff
+
+	return o
+}
+
+// SetCountConcurrency sets the number of resources counted concurrently, see
+// [apiResourceVersionsOptions.CountConcurrency].
+func (o *APIResourceVersionsOptionsBuilder) SetCountConcurrency(
+	countConcurrency int,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.CountConcurrency = countConcurrency
+
+	return o
+}
+
+// SetCountChunkSize sets the number of objects listed per request to count them, see
+// [apiResourceVersionsOptions.CountChunkSize].
+func (o *APIResourceVersionsOptionsBuilder) SetCountChunkSize(countChunkSize int64) *APIResourceVersionsOptionsBuilder {
+	o.options.CountChunkSize = countChunkSize
+
+	return o
+}
+
+// SetCountTimeout sets the maximum time to count the objects of each resource, see
+// [apiResourceVersionsOptions.CountTimeout].
+func (o *APIResourceVersionsOptionsBuilder) SetCountTimeout(
+	countTimeout time.Duration,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.CountTimeout = countTimeout
+
+	return o
+}
+
+// SetMaxWait sets the maximum delay after which a request is retried, see [apiResourceVersionsOptions.MaxWait].
+func (o *APIResourceVersionsOptionsBuilder) SetMaxWait(maxWait time.Duration) *APIResourceVersionsOptionsBuilder {
+	o.options.MaxWait = maxWait
+
+	return o
+}
+
+// SetShowProgress sets whether to display the progress of the discovery, as if stderr were a terminal.
+func (o *APIResourceVersionsOptionsBuilder) SetShowProgress(showProgress bool) *APIResourceVersionsOptionsBuilder {
+	o.options.showProgress = showProgress
+
+	return o
+}
+
 // SetCategories sets the categories for the options, see [apiResourceVersionsOptions.Categories].
 func (o *APIResourceVersionsOptionsBuilder) SetCategories(categories []string) *APIResourceVersionsOptionsBuilder {
 	o.options.Categories = categories
@@ -121,6 +240,22 @@ func (o *APIResourceVersionsOptionsBuilder) SetPreferred(preferred bool) *APIRes
 	return o
 }
 
+// SetUserDefinedOnly sets whether to limit to the API groups which aren't built into Kubernetes, see
+// [apiResourceVersionsOptions.UserDefinedOnly].
+func (o *APIResourceVersionsOptionsBuilder) SetUserDefinedOnly(userDefinedOnly bool) *APIResourceVersionsOptionsBuilder {
+	o.options.UserDefinedOnly = userDefinedOnly
+
+	return o
+}
+
+// SetSystemOnly sets whether to limit to the API groups built into Kubernetes, see
+// [apiResourceVersionsOptions.SystemOnly].
+func (o *APIResourceVersionsOptionsBuilder) SetSystemOnly(systemOnly bool) *APIResourceVersionsOptionsBuilder {
+	o.options.SystemOnly = systemOnly
+
+	return o
+}
+
 // SetIncludeSubresources sets whether to include subresources in the output, see
 // [apiResourceVersionsOptions.IncludeSubresources].
 func (o *APIResourceVersionsOptionsBuilder) SetIncludeSubresources(
@@ -130,3 +265,197 @@ func (o *APIResourceVersionsOptionsBuilder) SetIncludeSubresources(
 
 	return o
 }
+
+// SetShowCounts sets whether to count the objects of each resource, see [apiResourceVersionsOptions.ShowCounts].
+func (o *APIResourceVersionsOptionsBuilder) SetShowCounts(showCounts bool) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowCounts = showCounts
+
+	return o
+}
+
+// SetCoreGroupName sets the name under which the core group is displayed, see
+// [apiResourceVersionsOptions.CoreGroupName].
+func (o *APIResourceVersionsOptionsBuilder) SetCoreGroupName(coreGroupName string) *APIResourceVersionsOptionsBuilder {
+	o.options.CoreGroupName = coreGroupName
+
+	return o
+}
+
+// SetShowEmptyGroups sets whether to list the group versions which have no resources left after filtering, see
+// [apiResourceVersionsOptions.ShowEmptyGroups].
+func (o *APIResourceVersionsOptionsBuilder) SetShowEmptyGroups(showEmptyGroups bool) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowEmptyGroups = showEmptyGroups
+
+	return o
+}
+
+// SetShowVersionsServed sets whether to show the number of versions serving each resource, see
+// [apiResourceVersionsOptions.ShowVersionsServed].
+func (o *APIResourceVersionsOptionsBuilder) SetShowVersionsServed(
+	showVersionsServed bool,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowVersionsServed = showVersionsServed
+
+	return o
+}
+
+// SetShowGroupPriority sets whether to show the priority of the group of each resource, see
+// [apiResourceVersionsOptions.ShowGroupPriority].
+func (o *APIResourceVersionsOptionsBuilder) SetShowGroupPriority(
+	showGroupPriority bool,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowGroupPriority = showGroupPriority
+
+	return o
+}
+
+// SetMaxColumnWidths sets the maximum widths of the columns, see [apiResourceVersionsOptions.MaxColumnWidths].
+func (o *APIResourceVersionsOptionsBuilder) SetMaxColumnWidths(
+	maxColumnWidths ...string,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.MaxColumnWidths = maxColumnWidths
+
+	return o
+}
+
+// SetShowUsage sets whether to show whether each resource has been requested, see
+// [apiResourceVersionsOptions.ShowUsage].
+func (o *APIResourceVersionsOptionsBuilder) SetShowUsage(showUsage bool) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowUsage = showUsage
+
+	return o
+}
+
+// SetShowMinK8s sets whether to show the release in which each resource version was introduced, see
+// [apiResourceVersionsOptions.ShowMinK8s].
+func (o *APIResourceVersionsOptionsBuilder) SetShowMinK8s(showMinK8s bool) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowMinK8s = showMinK8s
+
+	return o
+}
+
+// SetShowServerVersion sets whether to show the server version of the cluster of each resource, see
+// [apiResourceVersionsOptions.ShowServerVersion].
+func (o *APIResourceVersionsOptionsBuilder) SetShowServerVersion(
+	showServerVersion bool,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowServerVersion = showServerVersion
+
+	return o
+}
+
+// SetShowCommands sets whether to show the command reading each resource, see
+// [apiResourceVersionsOptions.ShowCommands].
+func (o *APIResourceVersionsOptionsBuilder) SetShowCommands(showCommands bool) *APIResourceVersionsOptionsBuilder {
+	o.options.ShowCommands = showCommands
+
+	return o
+}
+
+// SetExec sets the command to run for each resource, see [apiResourceVersionsOptions.Exec].
+func (o *APIResourceVersionsOptionsBuilder) SetExec(exec string) *APIResourceVersionsOptionsBuilder {
+	o.options.Exec = exec
+
+	return o
+}
+
+// SetEmptyOnly sets whether to limit to resources without objects, see [apiResourceVersionsOptions.EmptyOnly].
+func (o *APIResourceVersionsOptionsBuilder) SetEmptyOnly(emptyOnly bool) *APIResourceVersionsOptionsBuilder {
+	o.options.EmptyOnly = emptyOnly
+
+	return o
+}
+
+// SetNonEmptyOnly sets whether to limit to resources with objects, see [apiResourceVersionsOptions.NonEmptyOnly].
+func (o *APIResourceVersionsOptionsBuilder) SetNonEmptyOnly(nonEmptyOnly bool) *APIResourceVersionsOptionsBuilder {
+	o.options.NonEmptyOnly = nonEmptyOnly
+
+	return o
+}
+
+// SetWatch sets whether to watch the resources every interval, see [apiResourceVersionsOptions.Watch] and
+// [apiResourceVersionsOptions.Interval].
+func (o *APIResourceVersionsOptionsBuilder) SetWatch(
+	watch bool,
+	interval time.Duration,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.Watch = watch
+	o.options.Interval = interval
+
+	return o
+}
+
+// SetNotify sets the webhook to which the changes are notified and the format of the payload, see
+// [apiResourceVersionsOptions.Notify].
+func (o *APIResourceVersionsOptionsBuilder) SetNotify(url, format string) *APIResourceVersionsOptionsBuilder {
+	o.options.Notify = notifyOptions{URL: url, Format: format}
+
+	return o
+}
+
+// SetContexts sets the contexts to list, see [apiResourceVersionsOptions.AllContexts] and
+// [apiResourceVersionsOptions.Contexts].
+func (o *APIResourceVersionsOptionsBuilder) SetContexts(
+	allContexts bool,
+	contexts []string,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.AllContexts = allContexts
+	o.options.Contexts = contexts
+
+	return o
+}
+
+// WithCluster adds a context to list with its discovery client, as completed from --all-contexts or --contexts.
+func (o *APIResourceVersionsOptionsBuilder) WithCluster(
+	context string,
+	discoveryClient discovery.CachedDiscoveryInterface,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.clusters = append(o.options.clusters, clusterClients{
+		context:         context,
+		discoveryClient: discoveryClient,
+		dynamicClient:   discoverytesting.NewDynamic(),
+	})
+
+	return o
+}
+
+// SetClustersFile sets the clusters file and its selector, see [apiResourceVersionsOptions.ClustersFile] and
+// [apiResourceVersionsOptions.ClusterSelector].
+func (o *APIResourceVersionsOptionsBuilder) SetClustersFile(
+	clustersFile string,
+	clusterSelector string,
+) *APIResourceVersionsOptionsBuilder {
+	o.options.ClustersFile = clustersFile
+	o.options.ClusterSelector = clusterSelector
+
+	return o
+}
+
+// SetCompareRelease sets the stock Kubernetes release to compare with, see
+// [apiResourceVersionsOptions.CompareRelease].
+func (o *APIResourceVersionsOptionsBuilder) SetCompareRelease(compareRelease string) *APIResourceVersionsOptionsBuilder {
+	o.options.CompareRelease = compareRelease
+
+	return o
+}
+
+// SetOffline sets whether to read the kubectl discovery cache, see [apiResourceVersionsOptions.Offline].
+func (o *APIResourceVersionsOptionsBuilder) SetOffline(offline bool) *APIResourceVersionsOptionsBuilder {
+	o.options.Offline = offline
+
+	return o
+}
+
+// SetFromFiles sets the discovery files to read, see [apiResourceVersionsOptions.FromFiles].
+func (o *APIResourceVersionsOptionsBuilder) SetFromFiles(fromFiles ...string) *APIResourceVersionsOptionsBuilder {
+	o.options.FromFiles = fromFiles
+
+	return o
+}
+
+// SetFromDump sets the discovery directory to read, see [apiResourceVersionsOptions.FromDump].
+func (o *APIResourceVersionsOptionsBuilder) SetFromDump(fromDump string) *APIResourceVersionsOptionsBuilder {
+	o.options.FromDump = fromDump
+
+	return o
+}
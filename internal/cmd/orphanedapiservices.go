@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// orphanedAPIServicesExample is the example text for the orphaned-apiservices command.
+	//
+	//nolint:gochecknoglobals
+	orphanedAPIServicesExample = `
+		# List the APIServices whose backing service is gone or whose group version can't be discovered
+		kubectl api-resource-versions orphaned-apiservices
+
+		# Delete them, once their extension API server has been uninstalled
+		kubectl api-resource-versions orphaned-apiservices --no-headers | awk '{print $1}' | \
+			xargs kubectl delete apiservice`
+)
+
+// servicesGVR is the group version resource of the Services backing the APIServices.
+//
+//nolint:gochecknoglobals
+var servicesGVR = corev1.SchemeGroupVersion.WithResource("services")
+
+// endpointSlicesGVR is the group version resource of the EndpointSlices of the Services backing the APIServices.
+//
+//nolint:gochecknoglobals
+var endpointSlicesGVR = discoveryv1.SchemeGroupVersion.WithResource("endpointslices")
+
+// newCmdOrphanedAPIServices returns a command that lists the APIServices whose backing service is gone or whose group
+// version can't be discovered.
+func newCmdOrphanedAPIServices(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newOrphanedAPIServicesOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "orphaned-apiservices",
+		Short: "List the APIServices whose backing service is gone",
+		Long: "List the APIServices of extension API servers whose Service doesn't exist anymore, whose Service has no " +
+			"ready endpoints, or whose group version can't be discovered, along with their Available condition.\n" +
+			"These APIServices are usually left behind by an uninstalled extension API server, e.g. metrics-server, " +
+			"and break the discovery of every client, which then fails or warns about the group version. The " +
+			"command fails if there are any.",
+		Example: templates.Examples(orphanedAPIServicesExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runOrphanedAPIServices(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+
+	return cmd
+}
+
+// orphanedAPIServicesOptions contains the options for the orphaned-apiservices command.
+type orphanedAPIServicesOptions struct {
+	genericiooptions.IOStreams
+
+	NoHeaders bool
+
+	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newOrphanedAPIServicesOptions returns a new [orphanedAPIServicesOptions] with default values.
+func newOrphanedAPIServicesOptions(ioStreams genericiooptions.IOStreams) *orphanedAPIServicesOptions {
+	return &orphanedAPIServicesOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the orphaned-apiservices command.
+func (o *orphanedAPIServicesOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// orphanedAPIService is an APIService of an extension API server with the problems of its backing service.
+type orphanedAPIService struct {
+	// Name is the name of the APIService, e.g. v1beta1.metrics.k8s.io.
+	Name string
+	// Service is the namespace and name of the backing Service, e.g. kube-system/metrics-server.
+	Service string
+	// Available is the status of the Available condition of the APIService, empty if it isn't reported.
+	Available string
+	// Problems are the reasons why the APIService is orphaned, e.g. its Service doesn't exist.
+	Problems []string
+}
+
+// runOrphanedAPIServices prints the orphaned APIServices, and fails if there are any.
+func runOrphanedAPIServices(ctx context.Context, options *orphanedAPIServicesOptions) error {
+	// The group versions are discovered again, as the discovery cache would hide their failures.
+	options.discoveryClient.Invalidate()
+
+	listCtx, listSpan := startSpan(ctx, "read APIServices")
+	list, err := options.dynamicClient.Resource(apiServicesGVR).List(listCtx, metav1.ListOptions{})
+	endSpan(listSpan, err)
+
+	if err != nil {
+		return fmt.Errorf("couldn't list %s: %w", apiServicesGVR.GroupResource(), err)
+	}
+
+	var orphaned []orphanedAPIService
+
+	for _, item := range list.Items {
+		serviceName, found, _ := unstructured.NestedString(item.Object, "spec", "service", "name")
+		if !found {
+			// The APIService is served by the kube-apiserver itself.
+			continue
+		}
+
+		serviceNamespace, _, _ := unstructured.NestedString(item.Object, "spec", "service", "namespace")
+
+		apiService := orphanedAPIService{
+			Name:      item.GetName(),
+			Service:   serviceNamespace + "/" + serviceName,
+			Available: apiServiceAvailable(&item),
+		}
+
+		checkCtx, checkSpan := startSpan(ctx, "check APIService", attribute.String("k8s.apiservice", item.GetName()))
+		apiService.Problems, err = apiServiceProblems(checkCtx, options, &item, serviceNamespace, serviceName)
+		endSpan(checkSpan, err)
+
+		if err != nil {
+			return err
+		}
+
+		if len(apiService.Problems) > 0 {
+			orphaned = append(orphaned, apiService)
+		}
+	}
+
+	err = printOrphanedAPIServices(orphaned, options)
+	if err != nil {
+		return err
+	}
+
+	if len(orphaned) > 0 {
+		return fmt.Errorf("%w: %d APIServices are orphaned", errCheckFailed, len(orphaned))
+	}
+
+	return nil
+}
+
+// apiServiceAvailable returns the status of the Available condition of the APIService, empty if it isn't reported.
+func apiServiceAvailable(apiService *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		if conditionType == "Available" {
+			status, _, _ := unstructured.NestedString(condition, "status")
+
+			return status
+		}
+	}
+
+	return ""
+}
+
+// apiServiceProblems returns why the APIService is orphaned: its Service doesn't exist, has no ready endpoints, or its
+// group version can't be discovered. It is empty if the APIService is healthy.
+func apiServiceProblems(
+	ctx context.Context,
+	options *orphanedAPIServicesOptions,
+	apiService *unstructured.Unstructured,
+	serviceNamespace, serviceName string,
+) ([]string, error) {
+	var problems []string
+
+	service, err := options.dynamicClient.Resource(servicesGVR).Namespace(serviceNamespace).Get(ctx, serviceName,
+		metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		problems = append(problems, "service not found")
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't get service %s/%s: %w", serviceNamespace, serviceName, err)
+	} else {
+		ready, err := serviceHasReadyEndpoints(ctx, options.dynamicClient, service)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ready {
+			problems = append(problems, "no ready endpoints")
+		}
+	}
+
+	group, _, _ := unstructured.NestedString(apiService.Object, "spec", "group")
+	version, _, _ := unstructured.NestedString(apiService.Object, "spec", "version")
+	groupVersion := schema.GroupVersion{Group: group, Version: version}.String()
+
+	_, err = options.discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("discovery failed: %v", err))
+	}
+
+	return problems, nil
+}
+
+// serviceHasReadyEndpoints returns true if one of the EndpointSlices of the Service has an endpoint which isn't
+// reported as not ready.
+// An ExternalName Service is always ready, as it has no endpoints: the API server proxies to its external name.
+func serviceHasReadyEndpoints(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	service *unstructured.Unstructured,
+) (bool, error) {
+	serviceType, _, _ := unstructured.NestedString(service.Object, "spec", "type")
+	if corev1.ServiceType(serviceType) == corev1.ServiceTypeExternalName {
+		return true, nil
+	}
+
+	serviceNamespace, serviceName := service.GetNamespace(), service.GetName()
+
+	list, err := dynamicClient.Resource(endpointSlicesGVR).Namespace(serviceNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		return false, fmt.Errorf("couldn't list endpoint slices of service %s/%s: %w", serviceNamespace, serviceName,
+			err)
+	}
+
+	for _, item := range list.Items {
+		endpoints, _, _ := unstructured.NestedSlice(item.Object, "endpoints")
+		for _, endpointItem := range endpoints {
+			endpoint, ok := endpointItem.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			// A nil ready condition must be interpreted as ready.
+			ready, found, _ := unstructured.NestedBool(endpoint, "conditions", "ready")
+			if !found || ready {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// printOrphanedAPIServices prints the orphaned APIServices as a table.
+func printOrphanedAPIServices(orphaned []orphanedAPIService, options *orphanedAPIServicesOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"NAME", "SERVICE", "AVAILABLE", "PROBLEMS"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, apiService := range orphaned {
+		available := apiService.Available
+		if available == "" {
+			available = "<unknown>"
+		}
+
+		err := printRow(writer, []string{
+			apiService.Name,
+			apiService.Service,
+			available,
+			strings.Join(apiService.Problems, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// TestRunOrphanedAPIServices tests listing the APIServices whose backing service is gone or whose group version can't
+// be discovered.
+func TestRunOrphanedAPIServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Local", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{newAPIService("autoscaling", "v2", 19100, 15)},
+		want:    "NAME   SERVICE   AVAILABLE   PROBLEMS\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("Healthy", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{
+			newBackedAPIService("autoscaling", "v2", "True"),
+			newService("kube-system", "metrics-server"),
+			newEndpointSlice("kube-system", "metrics-server", true),
+		},
+		want:    "NAME   SERVICE   AVAILABLE   PROBLEMS\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("ServiceNotFound", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{newBackedAPIService("autoscaling", "v2", "False")},
+		want: "NAME             SERVICE                      AVAILABLE   PROBLEMS\n" +
+			"v2.autoscaling   kube-system/metrics-server   False       service not found\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	t.Run("NoReadyEndpoints", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{
+			newBackedAPIService("autoscaling", "v2", ""),
+			newService("kube-system", "metrics-server"),
+			newEndpointSlice("kube-system", "metrics-server", false),
+		},
+		want: "NAME             SERVICE                      AVAILABLE   PROBLEMS\n" +
+			"v2.autoscaling   kube-system/metrics-server   <unknown>   no ready endpoints\n",
+		wantErr: errCheckFailed,
+	}.Test)
+	t.Run("ExternalName", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{
+			newBackedAPIService("autoscaling", "v2", "True"),
+			newExternalNameService("kube-system", "metrics-server", "metrics.example.com"),
+		},
+		want:    "NAME   SERVICE   AVAILABLE   PROBLEMS\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("DiscoveryFailed", runOrphanedAPIServicesTest{
+		objects: []runtime.Object{
+			newBackedAPIService("metrics.k8s.io", "v1beta1", "False"),
+			newService("kube-system", "metrics-server"),
+			newEndpointSlice("kube-system", "metrics-server", true),
+		},
+		want: "NAME                     SERVICE                      AVAILABLE   PROBLEMS\n" +
+			"v1beta1.metrics.k8s.io   kube-system/metrics-server   False       discovery failed: " +
+			"the server could not find the requested resource, GroupVersion \"metrics.k8s.io/v1beta1\" not found\n",
+		wantErr: errCheckFailed,
+	}.Test)
+}
+
+type runOrphanedAPIServicesTest struct {
+	objects []runtime.Object
+	want    string
+	wantErr error
+}
+
+func (tt runOrphanedAPIServicesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newOrphanedAPIServicesOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			apiServicesGVR:    "APIServiceList",
+			servicesGVR:       "ServiceList",
+			endpointSlicesGVR: "EndpointSliceList",
+		},
+		tt.objects...,
+	)
+
+	err := runOrphanedAPIServices(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runOrphanedAPIServices() error = %v, want %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runOrphanedAPIServices() output = %q, want %q", got, tt.want)
+	}
+}
+
+// newBackedAPIService returns an APIService of the version of the group backed by the kube-system/metrics-server
+// Service, with the status of its Available condition, if any.
+func newBackedAPIService(group, version, available string) *unstructured.Unstructured {
+	apiService := newAPIService(group, version, 100, 100)
+
+	err := unstructured.SetNestedStringMap(apiService.Object,
+		map[string]string{"namespace": "kube-system", "name": "metrics-server"}, "spec", "service")
+	if err != nil {
+		panic(err)
+	}
+
+	if available != "" {
+		err = unstructured.SetNestedSlice(apiService.Object, []any{
+			map[string]any{"type": "Available", "status": available},
+		}, "status", "conditions")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return apiService
+}
+
+// newService returns a Service.
+func newService(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"namespace": namespace, "name": name},
+	}}
+}
+
+// newExternalNameService returns an ExternalName Service, which has no endpoints.
+func newExternalNameService(namespace, name, externalName string) *unstructured.Unstructured {
+	service := newService(namespace, name)
+
+	err := unstructured.SetNestedStringMap(service.Object,
+		map[string]string{"type": "ExternalName", "externalName": externalName}, "spec")
+	if err != nil {
+		panic(err)
+	}
+
+	return service
+}
+
+// newEndpointSlice returns an EndpointSlice of the Service with a single endpoint, ready or not.
+func newEndpointSlice(namespace, serviceName string, ready bool) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "discovery.k8s.io/v1",
+		"kind":       "EndpointSlice",
+		"metadata": map[string]any{
+			"namespace": namespace,
+			"name":      serviceName + "-abcde",
+			"labels":    map[string]any{"kubernetes.io/service-name": serviceName},
+		},
+		"endpoints": []any{
+			map[string]any{"addresses": []any{"10.0.0.1"}, "conditions": map[string]any{"ready": ready}},
+		},
+	}}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+)
+
+// gatekeeperConstraintKind is the kind of the constraints created by the generated Gatekeeper ConstraintTemplate.
+const gatekeeperConstraintKind = "K8sDisallowedAPIVersions"
+
+// gatekeeperRego is the Rego of the generated Gatekeeper ConstraintTemplate, which reports a violation for each
+// request using one of the API versions given in the constraint parameters.
+const gatekeeperRego = `package k8sdisallowedapiversions
+
+violation[{"msg": msg}] {
+  disallowed := input.parameters.apiVersions[_]
+  input.review.kind.group == disallowed.group
+  input.review.kind.version == disallowed.version
+  input.review.kind.kind == disallowed.kind
+  msg := disallowed.message
+}
+`
+
+// generateGatekeeperPolicy generates a Gatekeeper ConstraintTemplate and a constraint listing the versions.
+func generateGatekeeperPolicy(versions []disallowedVersion, options *generatePolicyOptions) []any {
+	enforcementAction := "deny"
+	if options.Audit {
+		enforcementAction = "warn"
+	}
+
+	kindsByGroup := make(map[string][]string)
+	apiVersions := make([]any, 0, len(versions))
+
+	for _, version := range versions {
+		gvk := version.GroupVersionKind
+		if !slices.Contains(kindsByGroup[gvk.Group], gvk.Kind) {
+			kindsByGroup[gvk.Group] = append(kindsByGroup[gvk.Group], gvk.Kind)
+		}
+
+		apiVersions = append(apiVersions, map[string]any{
+			"group":   gvk.Group,
+			"version": gvk.Version,
+			"kind":    gvk.Kind,
+			"message": version.message(),
+		})
+	}
+
+	matchKinds := make([]any, 0, len(kindsByGroup))
+
+	// The versions are sorted by group, so the groups are matched in order.
+	for _, version := range versions {
+		kinds, ok := kindsByGroup[version.GroupVersionKind.Group]
+		if !ok {
+			continue
+		}
+
+		delete(kindsByGroup, version.GroupVersionKind.Group)
+		slices.Sort(kinds)
+
+		matchKinds = append(matchKinds, map[string]any{
+			"apiGroups": []any{version.GroupVersionKind.Group},
+			"kinds":     kinds,
+		})
+	}
+
+	stringProperty := map[string]any{"type": "string"}
+
+	return []any{
+		map[string]any{
+			"apiVersion": "templates.gatekeeper.sh/v1",
+			"kind":       "ConstraintTemplate",
+			"metadata": map[string]any{
+				"name": strings.ToLower(gatekeeperConstraintKind),
+			},
+			"spec": map[string]any{
+				"crd": map[string]any{
+					"spec": map[string]any{
+						"names": map[string]any{
+							"kind": gatekeeperConstraintKind,
+						},
+						"validation": map[string]any{
+							"openAPIV3Schema": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"apiVersions": map[string]any{
+										"type": "array",
+										"items": map[string]any{
+											"type": "object",
+											"properties": map[string]any{
+												"group":   stringProperty,
+												"version": stringProperty,
+												"kind":    stringProperty,
+												"message": stringProperty,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				"targets": []any{
+					map[string]any{
+						"target": "admission.k8s.gatekeeper.sh",
+						"rego":   gatekeeperRego,
+					},
+				},
+			},
+		},
+		map[string]any{
+			"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+			"kind":       gatekeeperConstraintKind,
+			"metadata": map[string]any{
+				"name": options.Name,
+			},
+			"spec": map[string]any{
+				"enforcementAction": enforcementAction,
+				"match": map[string]any{
+					"kinds": matchKinds,
+				},
+				"parameters": map[string]any{
+					"apiVersions": apiVersions,
+				},
+			},
+		},
+	}
+}
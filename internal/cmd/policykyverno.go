@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+)
+
+// generateKyvernoPolicy generates a Kyverno ClusterPolicy with a rule denying each of the versions.
+func generateKyvernoPolicy(versions []disallowedVersion, options *generatePolicyOptions) []any {
+	validationFailureAction := "Enforce"
+	if options.Audit {
+		validationFailureAction = "Audit"
+	}
+
+	rules := make([]any, 0, len(versions))
+
+	for _, version := range versions {
+		// Kyverno matches kinds as "<group>/<version>/<kind>", or "<version>/<kind>" for the core group.
+		kind := strings.TrimPrefix(strings.Join([]string{
+			version.GroupVersionKind.Group, version.GroupVersionKind.Version, version.GroupVersionKind.Kind,
+		}, "/"), "/")
+
+		rules = append(rules, map[string]any{
+			"name": policyRuleName(version.GroupVersionKind),
+			"match": map[string]any{
+				"any": []any{
+					map[string]any{
+						"resources": map[string]any{
+							"kinds":      []any{kind},
+							"operations": []any{"CREATE", "UPDATE"},
+						},
+					},
+				},
+			},
+			"validate": map[string]any{
+				"message": version.message(),
+				"deny":    map[string]any{},
+			},
+		})
+	}
+
+	return []any{
+		map[string]any{
+			"apiVersion": "kyverno.io/v1",
+			"kind":       "ClusterPolicy",
+			"metadata": map[string]any{
+				"name": options.Name,
+				"annotations": map[string]any{
+					"policies.kyverno.io/title": "Deny deprecated API versions",
+					"policies.kyverno.io/description": "Deny the creation and update of objects using deprecated or " +
+						"non-preferred API versions.",
+				},
+			},
+			"spec": map[string]any{
+				"validationFailureAction": validationFailureAction,
+				"background":              false,
+				"rules":                   rules,
+			},
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+// generateValidatingAdmissionPolicy generates a ValidatingAdmissionPolicy with a validation rejecting each of the
+// versions, and its binding.
+func generateValidatingAdmissionPolicy(versions []disallowedVersion, options *generatePolicyOptions) []any {
+	validationActions := []any{"Deny"}
+	if options.Audit {
+		validationActions = []any{"Warn", "Audit"}
+	}
+
+	resourceRules := make([]any, 0, len(versions))
+	validations := make([]any, 0, len(versions))
+
+	for _, version := range versions {
+		resourceRules = append(resourceRules, map[string]any{
+			"apiGroups":   []any{version.GroupVersionKind.Group},
+			"apiVersions": []any{version.GroupVersionKind.Version},
+			"resources":   []any{version.Resource},
+			"operations":  []any{"CREATE", "UPDATE"},
+		})
+		validations = append(validations, map[string]any{
+			"expression": fmt.Sprintf(
+				"!(request.resource.group == %q && request.resource.version == %q && request.resource.resource == %q)",
+				version.GroupVersionKind.Group, version.GroupVersionKind.Version, version.Resource,
+			),
+			"message": version.message(),
+		})
+	}
+
+	return []any{
+		map[string]any{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       "ValidatingAdmissionPolicy",
+			"metadata": map[string]any{
+				"name": options.Name,
+			},
+			"spec": map[string]any{
+				"failurePolicy": "Fail",
+				"matchConstraints": map[string]any{
+					// Match the version of the request exactly, otherwise the requests for the preferred version
+					// would be converted and matched as well.
+					"matchPolicy":   "Exact",
+					"resourceRules": resourceRules,
+				},
+				"validations": validations,
+			},
+		},
+		map[string]any{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       "ValidatingAdmissionPolicyBinding",
+			"metadata": map[string]any{
+				"name": options.Name,
+			},
+			"spec": map[string]any{
+				"policyName":        options.Name,
+				"validationActions": validationActions,
+			},
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// progressInterval is the interval at which the progress of the discovery is redrawn.
+const progressInterval = 100 * time.Millisecond
+
+// progressSpinner are the frames of the spinner of the progress of the discovery.
+const progressSpinner = `|/-\`
+
+// isTerminal returns true if the writer is a terminal, e.g. stderr isn't redirected to a file or a pipe.
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// discoveryProgress displays the number of group versions discovered so far on a single line of a terminal, so that
+// the discovery of large clusters doesn't look hung.
+type discoveryProgress struct {
+	out     io.Writer
+	total   int
+	fetched atomic.Int64
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startDiscoveryProgress starts displaying the progress of the discovery of the total group versions, or returns nil if
+// the progress isn't displayed.
+// The methods of the progress are no-ops on nil, so that it is only checked once.
+func startDiscoveryProgress(options *apiResourceVersionsOptions, total int) *discoveryProgress {
+	if !options.showProgress {
+		return nil
+	}
+
+	progress := &discoveryProgress{
+		out:     options.ErrOut,
+		total:   total,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go progress.run()
+
+	return progress
+}
+
+// increment records that the discovery of a group version is done, successful or not.
+func (p *discoveryProgress) increment() {
+	if p == nil {
+		return
+	}
+
+	p.fetched.Add(1)
+}
+
+// stop stops displaying the progress and clears its line, so that the output which follows isn't mixed with it.
+func (p *discoveryProgress) stop() {
+	if p == nil {
+		return
+	}
+
+	close(p.done)
+	<-p.stopped
+}
+
+// run renders the progress every [progressInterval] until it is stopped, then clears its line.
+func (p *discoveryProgress) run() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		p.render(frame)
+
+		select {
+		case <-p.done:
+			// Carriage return, then erase the line.
+			_, _ = io.WriteString(p.out, "\r\x1b[K")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// render overwrites the line of the progress with the frame of the spinner and the number of group versions
+// discovered so far.
+func (p *discoveryProgress) render(frame int) {
+	_, _ = fmt.Fprintf(p.out, "\r%c fetched %d/%d group versions",
+		progressSpinner[frame%len(progressSpinner)], p.fetched.Load(), p.total)
+}
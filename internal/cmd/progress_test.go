@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDiscoveryProgress tests displaying the progress of the discovery, and clearing it once the discovery is done.
+func TestDiscoveryProgress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Shown", discoveryProgressTest{showProgress: true, want: "/4 group versions"}.Test)
+	t.Run("Hidden", discoveryProgressTest{showProgress: false, want: ""}.Test)
+}
+
+type discoveryProgressTest struct {
+	showProgress bool
+	want         string
+}
+
+func (tt discoveryProgressTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetShowProgress(tt.showProgress)
+	_, _, stderr := builder.GetBuffers()
+
+	_, err := getGroupResources(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("getGroupResources() error = %v", err)
+	}
+
+	if tt.want == "" {
+		if stderr.Len() != 0 {
+			t.Errorf("getGroupResources() stderr = %q, want none", stderr.String())
+		}
+
+		return
+	}
+
+	if !strings.Contains(stderr.String(), tt.want) {
+		t.Errorf("getGroupResources() stderr = %q, want %q", stderr.String(), tt.want)
+	}
+
+	if !bytes.HasSuffix(stderr.Bytes(), []byte("\r\x1b[K")) {
+		t.Errorf("getGroupResources() stderr = %q, want the progress cleared", stderr.String())
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunQuery tests listing the resources matching the query, best matches first.
+func TestRunQuery(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetOutput(nameOutput)
+	_, stdout, _ := builder.GetBuffers()
+
+	options := builder.APIResourceVersionsOptions()
+	options.query = "po"
+
+	err := runAPIResourceVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "pods.v1.\n" +
+		"horizontalpodautoscalers.v2.autoscaling\n" +
+		"horizontalpodautoscalers.v1.autoscaling\n" +
+		"horizontalpodautoscalers.v2beta2.autoscaling\n" +
+		"persistentvolumeclaims.v1.\n" +
+		"persistentvolumes.v1.\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// renderVersionsContextLines is the number of unchanged lines printed around the changes of the diffs.
+const renderVersionsContextLines = 3
+
+// errAmbiguousResource is returned when the resource argument matches resources of several groups.
+const errAmbiguousResource = constError("the resource matches several groups, qualify it with its group")
+
+var (
+	// renderVersionsExample is the example text for the render-versions command.
+	//
+	//nolint:gochecknoglobals
+	renderVersionsExample = `
+		# Show how the HorizontalPodAutoscaler web is represented in each served version of autoscaling
+		kubectl api-resource-versions render-versions hpa web --namespace=default
+
+		# Include the managed fields, which record the API version used by each field manager
+		kubectl api-resource-versions render-versions flowschemas.flowcontrol.apiserver.k8s.io global-default \
+			--show-managed-fields`
+)
+
+// newCmdRenderVersions returns a command that gets an object in every served version of its resource, and prints
+// the differences between the representations.
+func newCmdRenderVersions(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newRenderVersionsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "render-versions RESOURCE NAME",
+		Short: "Diff an object across the served versions of its resource",
+		Long: "Get the object in every version of its resource served by the cluster, letting the API server " +
+			"convert it, and print a unified diff of the object in each version against the object in the " +
+			"preferred version, showing what a version migration changes for real data.\n" +
+			"The resource is resolved by its plural name, singular name, short name, or kind, optionally followed by " +
+			"its group, e.g. hpa.autoscaling.\n" +
+			"The managed fields are omitted unless --show-managed-fields is given, like kubectl get does.",
+		Example:           templates.Examples(renderVersionsExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runRenderVersions(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.ShowManagedFields, "show-managed-fields", options.ShowManagedFields,
+		"If true, keep the managedFields of the object when rendering it.")
+
+	return cmd
+}
+
+// renderVersionsOptions contains the options for the render-versions command.
+type renderVersionsOptions struct {
+	genericiooptions.IOStreams
+
+	ShowManagedFields bool
+
+	resource string
+	name     string
+	// namespace is the namespace of the object, ignored for cluster-scoped resources.
+	namespace       string
+	discoveryClient discovery.DiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newRenderVersionsOptions returns a new [renderVersionsOptions] with default values.
+func newRenderVersionsOptions(ioStreams genericiooptions.IOStreams) *renderVersionsOptions {
+	return &renderVersionsOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the render-versions command.
+func (o *renderVersionsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	//nolint:mnd
+	if len(args) != 2 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "exactly one resource and one name are required, got: %v", args)
+	}
+
+	o.resource, o.name = args[0], args[1]
+
+	namespace, _, err := restClientGetter.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return fmt.Errorf("couldn't get namespace: %w", err)
+	}
+
+	o.namespace = namespace
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// runRenderVersions gets the object in every served version of its resource, and prints the diff of each version
+// against the preferred version.
+func runRenderVersions(ctx context.Context, options *renderVersionsOptions) error {
+	versions, err := getServedVersions(options.discoveryClient, options.resource)
+	if err != nil {
+		return err
+	}
+
+	groupResources := make([]string, 0, 1)
+	for _, version := range versions {
+		groupResource := version.GroupVersionResource.GroupResource().String()
+		if !slices.Contains(groupResources, groupResource) {
+			groupResources = append(groupResources, groupResource)
+		}
+	}
+
+	if len(groupResources) > 1 {
+		return fmt.Errorf("%w: %s", errAmbiguousResource, strings.Join(groupResources, ", "))
+	}
+
+	// The first version is the base when the resource isn't served in the preferred version of its group.
+	base := max(slices.IndexFunc(versions, func(version servedVersion) bool { return version.Preferred }), 0)
+
+	renderings := make([][]string, len(versions))
+
+	for i, version := range versions {
+		renderings[i], err = renderObjectVersion(ctx, options, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(versions) == 1 {
+		_, _ = fmt.Fprintf(options.ErrOut, "%s is only served in %s, there are no other versions to compare\n",
+			groupResources[0], versions[0].GroupVersionResource.GroupVersion())
+
+		return nil
+	}
+
+	for i, version := range versions {
+		if i == base {
+			continue
+		}
+
+		err = printRenderingDiff(options.Out, versions[base], renderings[base], version, renderings[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderObjectVersion gets the object of the options in the version, and returns its YAML representation split into
+// lines.
+func renderObjectVersion(ctx context.Context, options *renderVersionsOptions, version servedVersion) ([]string, error) {
+	namespaceableClient := options.dynamicClient.Resource(version.GroupVersionResource)
+
+	var resourceClient dynamic.ResourceInterface = namespaceableClient
+	if version.Namespaced {
+		resourceClient = namespaceableClient.Namespace(options.namespace)
+	}
+
+	obj, err := resourceClient.Get(ctx, options.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get %s %s in %s: %w", version.GroupVersionResource.GroupResource(),
+			options.name, version.GroupVersionResource.GroupVersion(), err)
+	}
+
+	if !options.ShowManagedFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	}
+
+	rendering, err := k8syaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't render %s %s in %s: %w", version.GroupVersionResource.GroupResource(),
+			options.name, version.GroupVersionResource.GroupVersion(), err)
+	}
+
+	return slices.Collect(strings.Lines(string(rendering))), nil
+}
+
+// printRenderingDiff prints the unified diff from the rendering of the object in the base version to its rendering
+// in the other version.
+func printRenderingDiff(
+	out io.Writer,
+	baseVersion servedVersion,
+	baseRendering []string,
+	version servedVersion,
+	rendering []string,
+) error {
+	err := difflib.WriteUnifiedDiff(out, difflib.UnifiedDiff{
+		A:        baseRendering,
+		B:        rendering,
+		FromFile: baseVersion.GroupVersionResource.GroupVersion().String(),
+		ToFile:   version.GroupVersionResource.GroupVersion().String(),
+		Context:  renderVersionsContextLines,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't write diff: %w", err)
+	}
+
+	return nil
+}
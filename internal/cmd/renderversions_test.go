@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunRenderVersions tests diffing an object across the served versions of its resource.
+func TestRunRenderVersions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", runRenderVersionsTest{
+		resource: "hpa",
+		want: "--- autoscaling/v2\n" +
+			"+++ autoscaling/v1\n" +
+			"@@ -1,17 +1,11 @@\n" +
+			"-apiVersion: autoscaling/v2\n" +
+			"+apiVersion: autoscaling/v1\n" +
+			" kind: HorizontalPodAutoscaler\n" +
+			" metadata:\n" +
+			"   name: web\n" +
+			"   namespace: default\n" +
+			" spec:\n" +
+			"-  metrics:\n" +
+			"-  - resource:\n" +
+			"-      name: cpu\n" +
+			"-      target:\n" +
+			"-        averageUtilization: 80\n" +
+			"-        type: Utilization\n" +
+			"-    type: Resource\n" +
+			"   scaleTargetRef:\n" +
+			"     apiVersion: apps/v1\n" +
+			"     kind: Deployment\n" +
+			"     name: web\n" +
+			"+  targetCPUUtilizationPercentage: 80\n" +
+			"--- autoscaling/v2\n" +
+			"+++ autoscaling/v2beta2\n" +
+			"@@ -1,4 +1,4 @@\n" +
+			"-apiVersion: autoscaling/v2\n" +
+			"+apiVersion: autoscaling/v2beta2\n" +
+			" kind: HorizontalPodAutoscaler\n" +
+			" metadata:\n" +
+			"   name: web\n",
+	}.Test)
+	t.Run("ShowManagedFields", runRenderVersionsTest{
+		resource:          "horizontalpodautoscalers.autoscaling",
+		showManagedFields: true,
+		want: "--- autoscaling/v2\n" +
+			"+++ autoscaling/v1\n" +
+			"@@ -1,21 +1,15 @@\n" +
+			"-apiVersion: autoscaling/v2\n" +
+			"+apiVersion: autoscaling/v1\n" +
+			" kind: HorizontalPodAutoscaler\n" +
+			" metadata:\n" +
+			"   managedFields:\n" +
+			"-  - apiVersion: autoscaling/v2\n" +
+			"+  - apiVersion: autoscaling/v1\n" +
+			"     manager: kubectl\n" +
+			"     operation: Update\n" +
+			"   name: web\n" +
+			"   namespace: default\n" +
+			" spec:\n" +
+			"-  metrics:\n" +
+			"-  - resource:\n" +
+			"-      name: cpu\n" +
+			"-      target:\n" +
+			"-        averageUtilization: 80\n" +
+			"-        type: Utilization\n" +
+			"-    type: Resource\n" +
+			"   scaleTargetRef:\n" +
+			"     apiVersion: apps/v1\n" +
+			"     kind: Deployment\n" +
+			"     name: web\n" +
+			"+  targetCPUUtilizationPercentage: 80\n" +
+			"--- autoscaling/v2\n" +
+			"+++ autoscaling/v2beta2\n" +
+			"@@ -1,8 +1,8 @@\n" +
+			"-apiVersion: autoscaling/v2\n" +
+			"+apiVersion: autoscaling/v2beta2\n" +
+			" kind: HorizontalPodAutoscaler\n" +
+			" metadata:\n" +
+			"   managedFields:\n" +
+			"-  - apiVersion: autoscaling/v2\n" +
+			"+  - apiVersion: autoscaling/v2beta2\n" +
+			"     manager: kubectl\n" +
+			"     operation: Update\n" +
+			"   name: web\n",
+	}.Test)
+	t.Run("SingleVersion", runRenderVersionsTest{
+		resource:   "pods",
+		objects:    []*unstructured.Unstructured{newUnstructured("v1", "Pod", "default", "web")},
+		wantErrOut: "pods is only served in v1, there are no other versions to compare\n",
+	}.Test)
+	t.Run("NotFound", runRenderVersionsTest{
+		resource: "pods",
+		wantErr:  true,
+	}.Test)
+}
+
+type runRenderVersionsTest struct {
+	resource          string
+	showManagedFields bool
+	// objects are the objects served by the fake dynamic client, the HorizontalPodAutoscaler web in each version of
+	// autoscaling if nil, as the fake dynamic client doesn't convert them.
+	objects    []*unstructured.Unstructured
+	want       string
+	wantErrOut string
+	wantErr    bool
+}
+
+func (tt runRenderVersionsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	objects := tt.objects
+	if objects == nil {
+		objects = []*unstructured.Unstructured{
+			newRenderedHPA("autoscaling/v2", map[string]any{"metrics": []any{map[string]any{
+				"type": "Resource",
+				"resource": map[string]any{
+					"name":   "cpu",
+					"target": map[string]any{"type": "Utilization", "averageUtilization": int64(80)},
+				},
+			}}}),
+			newRenderedHPA("autoscaling/v1", map[string]any{"targetCPUUtilizationPercentage": int64(80)}),
+			newRenderedHPA("autoscaling/v2beta2", map[string]any{"metrics": []any{map[string]any{
+				"type": "Resource",
+				"resource": map[string]any{
+					"name":   "cpu",
+					"target": map[string]any{"type": "Utilization", "averageUtilization": int64(80)},
+				},
+			}}}),
+		}
+	}
+
+	ioStreams, _, stdout, stderr := genericiooptions.NewTestIOStreams()
+	options := newRenderVersionsOptions(ioStreams)
+	options.ShowManagedFields = tt.showManagedFields
+	options.resource = tt.resource
+	options.name = "web"
+	options.namespace = "default"
+	options.discoveryClient = discoverytesting.New()
+
+	dynamicClient := discoverytesting.NewDynamic()
+	for _, obj := range objects {
+		err := dynamicClient.Tracker().Add(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	options.dynamicClient = dynamicClient
+
+	err := runRenderVersions(t.Context(), options)
+	if tt.wantErr {
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("runRenderVersions() error = %v, want NotFound", err)
+		}
+
+		return
+	} else if err != nil {
+		t.Fatalf("runRenderVersions() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runRenderVersions() output = %q, want %q", got, tt.want)
+	}
+
+	if got := stderr.String(); got != tt.wantErrOut {
+		t.Errorf("runRenderVersions() error output = %q, want %q", got, tt.wantErrOut)
+	}
+}
+
+// newRenderedHPA returns the HorizontalPodAutoscaler web in the API version with the spec fields, and the fields
+// managed by kubectl in the same API version.
+func newRenderedHPA(apiVersion string, spec map[string]any) *unstructured.Unstructured {
+	obj := newUnstructured(apiVersion, "HorizontalPodAutoscaler", "default", "web")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: apiVersion},
+	})
+
+	spec["scaleTargetRef"] = map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"}
+	obj.Object["spec"] = spec
+
+	return obj
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"github.com/spf13/cobra"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// reportPriority is the priority of migrating away from a deprecated version in the report.
+type reportPriority string
+
+const (
+	// reportPriorityHigh is the priority of the deprecated versions with live objects which have been requested.
+	reportPriorityHigh reportPriority = "high"
+	// reportPriorityMedium is the priority of the deprecated versions with either live objects or requests.
+	reportPriorityMedium reportPriority = "medium"
+	// reportPriorityLow is the priority of the deprecated versions without live objects nor requests, or whose
+	// objects and requests are unknown.
+	reportPriorityLow reportPriority = "low"
+)
+
+// reportPriorities are the report priorities, ordered by decreasing priority.
+//
+//nolint:gochecknoglobals
+var reportPriorities = []reportPriority{reportPriorityHigh, reportPriorityMedium, reportPriorityLow}
+
+var (
+	// reportExample is the example text for the report command.
+	//
+	//nolint:gochecknoglobals
+	reportExample = `
+		# Report the deprecated API versions served by the cluster, by migration priority
+		kubectl api-resource-versions report
+
+		# Write the report as Markdown for a migration ticket
+		kubectl api-resource-versions report --output=markdown --output-file=deprecated-apis.md`
+)
+
+// reportRow is a deprecated version of a resource in the report.
+type reportRow struct {
+	// Priority is the priority of migrating away from the version.
+	Priority reportPriority `json:"priority"`
+	// Name is the resource version, e.g. "flowschemas.v1beta3.flowcontrol.apiserver.k8s.io".
+	Name string `json:"name"`
+	// Kind is the kind of the resource.
+	Kind string `json:"kind"`
+	// Deprecated is the release in which the version was deprecated, e.g. "1.29".
+	Deprecated string `json:"deprecated,omitempty"`
+	// Removed is the release in which the version is removed, if it is scheduled.
+	Removed string `json:"removed,omitempty"`
+	// Replacement is the group version replacing the version, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// Objects is the approximate number of objects of the resource, nil if they couldn't be counted.
+	Objects *int64 `json:"objects,omitempty"`
+	// Requested is whether the version has been requested since the API server started, nil if the metrics of the API
+	// server couldn't be read.
+	Requested *bool `json:"requested,omitempty"`
+}
+
+// newCmdReport returns a command that reports the deprecated API versions served by the cluster, prioritized by their
+// live objects and their requests.
+func newCmdReport(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newReportOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report the deprecated API versions by migration priority",
+		Long: "Join the lifecycle database of the built-in Kubernetes APIs, the counts of the live objects, and the " +
+			"metrics of the API server into a single report of the deprecated API versions served by the cluster.\n" +
+			"Each deprecated version is " + string(reportPriorityHigh) + " priority if its resource has live objects " +
+			"and the version has been requested since the API server started, " + string(reportPriorityMedium) +
+			" if only one of them holds, and " + string(reportPriorityLow) + " otherwise, including when the objects " +
+			"couldn't be counted or the metrics couldn't be read.\n" +
+			"The objects are counted through the deprecated version, which serves every object of the resource. " +
+			"With the table output format, a summary of the priorities is printed on stderr.",
+		Example: templates.Examples(reportExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runReport(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+	cmd.Flags().StringVar(&options.OutputFile, "output-file", options.OutputFile,
+		"Write the report to this file atomically, rather than to stdout.")
+
+	return cmd
+}
+
+// reportOptions contains the options for the report command.
+type reportOptions struct {
+	genericiooptions.IOStreams
+
+	Output     string
+	NoHeaders  bool
+	OutputFile string
+
+	contextName     string
+	discoveryClient discovery.CachedDiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newReportOptions returns a new [reportOptions] with default values.
+func newReportOptions(ioStreams genericiooptions.IOStreams) *reportOptions {
+	return &reportOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the report command.
+func (o *reportOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+	o.contextName = contextName(restClientGetter)
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// validate checks that options are valid for the report command.
+func (o *reportOptions) validate() error {
+	return validateReportOutput(o.Output)
+}
+
+// runReport discovers the deprecated versions served by the cluster, counts their objects, reads their usage, and
+// prints the report, to the --output-file if requested.
+func runReport(ctx context.Context, options *reportOptions) error {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{ErrOut: options.ErrOut})
+	listOptions.discoveryClient = options.discoveryClient
+	listOptions.dynamicClient = options.dynamicClient
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	cluster := newClusterMetadata(options.contextName, options.discoveryClient)
+	release, _ := lifecycle.ParseRelease(cluster.ServerVersion)
+
+	deprecated := deprecatedGroupResources(resources, release)
+
+	countGroupResources(ctx, deprecated, listOptions)
+	annotateUsage(ctx, deprecated, listOptions)
+
+	rows := newReportRows(deprecated)
+
+	if options.Output == tableOutput {
+		_, _ = fmt.Fprintln(options.ErrOut, reportSummary(rows))
+	}
+
+	if options.OutputFile == "" {
+		return printReport(options.Out, newOutputMetadata(cluster), rows, options)
+	}
+
+	return writeFileAtomically(options.OutputFile, outputFilePermissions, func(out io.Writer) error {
+		return printReport(out, newOutputMetadata(cluster), rows, options)
+	})
+}
+
+// deprecatedGroupResources returns the resources whose version is deprecated in the release according to the
+// lifecycle database, or ever deprecated if the release is unknown.
+// Subresources are skipped, as they are deprecated along with their parent resource.
+func deprecatedGroupResources(resources []groupResource, release *utilversion.Version) []groupResource {
+	var deprecated []groupResource
+
+	for _, resource := range resources {
+		if resource.Subresource {
+			continue
+		}
+
+		gvk := resource.groupVersionResource().GroupVersion().WithKind(resource.APIResource.Kind)
+
+		api, known := lifecycle.Lookup(gvk)
+		if known && api.DeprecatedIn(release) {
+			deprecated = append(deprecated, resource)
+		}
+	}
+
+	return deprecated
+}
+
+// newReportRows returns the report rows of the deprecated resources, by decreasing priority, then by the release in
+// which they are removed, soonest first, then by name.
+func newReportRows(resources []groupResource) []reportRow {
+	rows := make([]reportRow, 0, len(resources))
+
+	for _, resource := range resources {
+		gvk := resource.groupVersionResource().GroupVersion().WithKind(resource.APIResource.Kind)
+		api, _ := lifecycle.Lookup(gvk)
+
+		rows = append(rows, reportRow{
+			Priority:    newReportPriority(resource.Count, resource.Requested),
+			Name:        resource.fullname(),
+			Kind:        resource.APIResource.Kind,
+			Deprecated:  api.Deprecated,
+			Removed:     api.Removed,
+			Replacement: api.Replacement,
+			Objects:     resource.Count,
+			Requested:   resource.Requested,
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b reportRow) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(reportPriorities, a.Priority), slices.Index(reportPriorities, b.Priority)),
+			compareReleases(a.Removed, b.Removed),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	return rows
+}
+
+// newReportPriority returns the priority of a deprecated version with the count of objects and usage.
+func newReportPriority(objects *int64, requested *bool) reportPriority {
+	hasObjects := objects != nil && *objects > 0
+	isRequested := requested != nil && *requested
+
+	switch {
+	case hasObjects && isRequested:
+		return reportPriorityHigh
+	case hasObjects || isRequested:
+		return reportPriorityMedium
+	default:
+		return reportPriorityLow
+	}
+}
+
+// compareReleases compares the releases, the empty release, e.g. when no removal is scheduled, being the last.
+func compareReleases(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	default:
+		result, _ := utilversion.MustParseGeneric(a).Compare(b)
+
+		return result
+	}
+}
+
+// reportSummary returns a sentence summarizing the number of deprecated versions of each priority.
+func reportSummary(rows []reportRow) string {
+	if len(rows) == 0 {
+		return "No deprecated API versions are served."
+	}
+
+	counts := make(map[reportPriority]int, len(reportPriorities))
+	for _, row := range rows {
+		counts[row.Priority]++
+	}
+
+	return fmt.Sprintf("%d deprecated versions have live objects and requests, %d have either, and %d have neither.",
+		counts[reportPriorityHigh], counts[reportPriorityMedium], counts[reportPriorityLow])
+}
+
+// reportColumns returns the columns of the row in the table and Markdown output formats.
+func reportColumns(row reportRow) []string {
+	count := unknownCount
+	if row.Objects != nil {
+		count = strconv.FormatInt(*row.Objects, 10)
+	}
+
+	requested := unknownUsage
+	if row.Requested != nil {
+		requested = strconv.FormatBool(*row.Requested)
+	}
+
+	return []string{
+		string(row.Priority), row.Name, row.Kind, row.Deprecated, row.Removed, row.Replacement, count, requested,
+	}
+}
+
+// reportHeaders are the headers of the report in the table output format.
+//
+//nolint:gochecknoglobals
+var reportHeaders = []string{
+	"PRIORITY", "RESOURCE", "KIND", "DEPRECATED", "REMOVED", "REPLACEMENT", "OBJECTS", "REQUESTED",
+}
+
+// printReport prints the report in the output format of the options.
+func printReport(out io.Writer, metadata outputMetadata, rows []reportRow, options *reportOptions) error {
+	switch options.Output {
+	case jsonOutput:
+		return printReportJSON(out, metadata, rows)
+	case markdownOutput:
+		return printReportMarkdown(out, rows)
+	default:
+		return printReportTable(out, rows, options.NoHeaders)
+	}
+}
+
+// printReportTable prints the report as a table.
+func printReportTable(out io.Writer, rows []reportRow, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		err := printRow(writer, reportHeaders)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		err := printRow(writer, reportColumns(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printReportMarkdown prints the summary of the report followed by the report as a Markdown table.
+func printReportMarkdown(out io.Writer, rows []reportRow) error {
+	lines := []string{
+		reportSummary(rows),
+		"",
+		"| Priority | Resource | Kind | Deprecated | Removed | Replacement | Objects | Requested |",
+		strings.Repeat("| --- ", len(reportHeaders)) + "|",
+	}
+	for _, row := range rows {
+		lines = append(lines, "| "+strings.Join(reportColumns(row), " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("couldn't write report: %w", err)
+	}
+
+	return nil
+}
+
+// printReportJSON prints the report as a JSON object with the "metadata" and the "versions" list.
+func printReportJSON(out io.Writer, metadata outputMetadata, rows []reportRow) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata outputMetadata `json:"metadata"`
+		Versions []reportRow    `json:"versions"`
+	}{Metadata: metadata, Versions: rows})
+	if err != nil {
+		return fmt.Errorf("couldn't write report: %w", err)
+	}
+
+	return nil
+}
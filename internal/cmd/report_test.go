@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/utils/ptr"
+)
+
+// TestRunReport tests reporting the deprecated versions served by the cluster with their objects and usage.
+func TestRunReport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Table", runReportTest{
+		output: tableOutput,
+		want: "PRIORITY   RESOURCE                                       KIND                      DEPRECATED   " +
+			"REMOVED   REPLACEMENT      OBJECTS   REQUESTED\n" +
+			"medium     horizontalpodautoscalers.v2beta2.autoscaling   HorizontalPodAutoscaler   1.23         " +
+			"1.26      autoscaling/v2   2         <unknown>\n",
+		wantErrOut: "Warning: couldn't read the metrics of the API server: no REST client to read the metrics with\n" +
+			"0 deprecated versions have live objects and requests, 1 have either, and 0 have neither.\n",
+	}.Test)
+	t.Run("Markdown", runReportTest{
+		output: markdownOutput,
+		want: "0 deprecated versions have live objects and requests, 1 have either, and 0 have neither.\n" +
+			"\n" +
+			"| Priority | Resource | Kind | Deprecated | Removed | Replacement | Objects | Requested |\n" +
+			"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+			"| medium | horizontalpodautoscalers.v2beta2.autoscaling | HorizontalPodAutoscaler | 1.23 | 1.26 | " +
+			"autoscaling/v2 | 2 | <unknown> |\n",
+		wantErrOut: "Warning: couldn't read the metrics of the API server: no REST client to read the metrics with\n",
+	}.Test)
+}
+
+type runReportTest struct {
+	output     string
+	want       string
+	wantErrOut string
+}
+
+func (tt runReportTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, stderr := genericiooptions.NewTestIOStreams()
+	options := newReportOptions(ioStreams)
+	options.Output = tt.output
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = discoverytesting.NewDynamic(
+		newUnstructured("autoscaling/v2beta2", "HorizontalPodAutoscaler", "default", "hpa-a"),
+		newUnstructured("autoscaling/v2beta2", "HorizontalPodAutoscaler", "kube-system", "hpa-b"),
+	)
+
+	err := runReport(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runReport() output = %q, want %q", got, tt.want)
+	}
+
+	if got := stderr.String(); got != tt.wantErrOut {
+		t.Errorf("runReport() error output = %q, want %q", got, tt.wantErrOut)
+	}
+}
+
+// TestRunReportOutputFile tests writing the report as JSON to the --output-file.
+func TestRunReportOutputFile(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newReportOptions(ioStreams)
+	options.Output = jsonOutput
+	options.OutputFile = filepath.Join(t.TempDir(), "report.json")
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = discoverytesting.NewDynamic()
+
+	err := runReport(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("runReport() output = %q, want none", stdout.String())
+	}
+
+	content, err := os.ReadFile(options.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Versions []reportRow `json:"versions"`
+	}
+
+	err = json.Unmarshal(content, &report)
+	if err != nil {
+		t.Fatalf("couldn't decode the report %q: %v", content, err)
+	}
+
+	if len(report.Versions) != 1 || report.Versions[0].Priority != reportPriorityLow ||
+		report.Versions[0].Objects == nil || *report.Versions[0].Objects != 0 {
+		t.Errorf("runReport() versions = %+v, want the empty autoscaling/v2beta2 version at low priority",
+			report.Versions)
+	}
+}
+
+// TestNewReportPriority tests prioritizing the deprecated versions by their objects and usage, then by their removal.
+func TestNewReportPriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		objects   *int64
+		requested *bool
+		want      reportPriority
+	}{
+		{name: "ObjectsAndRequests", objects: ptr.To[int64](3), requested: ptr.To(true), want: reportPriorityHigh},
+		{name: "Objects", objects: ptr.To[int64](3), requested: ptr.To(false), want: reportPriorityMedium},
+		{name: "Requests", objects: ptr.To[int64](0), requested: ptr.To(true), want: reportPriorityMedium},
+		{name: "Neither", objects: ptr.To[int64](0), requested: ptr.To(false), want: reportPriorityLow},
+		{name: "Unknown", want: reportPriorityLow},
+	}
+
+	for _, tt := range tests {
+		if got := newReportPriority(tt.objects, tt.requested); got != tt.want {
+			t.Errorf("%s: newReportPriority() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	for _, releases := range [][2]string{{"1.25", "1.26"}, {"1.9", "1.10"}, {"1.26", ""}} {
+		if compareReleases(releases[0], releases[1]) >= 0 || compareReleases(releases[1], releases[0]) <= 0 {
+			t.Errorf("compareReleases(%q, %q) doesn't order the first release first", releases[0], releases[1])
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
+)
+
+// defaultRetryBackoff is the default delay before the first retry of a group version discovery.
+const defaultRetryBackoff = apiresourceversions.DefaultRetryBackoff
+
+// errRetries is returned when the number of retries is negative.
+const errRetries = constError("retries must not be negative")
+
+// errRetryBackoff is returned when the retry backoff is not positive.
+const errRetryBackoff = constError("retry-backoff must be positive")
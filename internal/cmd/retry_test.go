@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+// flakyDiscoveryClient is a fake discovery client whose discovery of a group version fails with an error a number of
+// times before succeeding, like a briefly-unavailable aggregated API server.
+type flakyDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+
+	groupVersion string
+	err          error
+	failures     int32
+	calls        atomic.Int32
+}
+
+func (c *flakyDiscoveryClient) ServerGroupsAndResources() (
+	[]*metav1.APIGroup,
+	[]*metav1.APIResourceList,
+	error,
+) {
+	groups, resourceLists, err := c.FakeCachedDiscoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck
+	}
+
+	healthyResourceLists := make([]*metav1.APIResourceList, 0, len(resourceLists))
+
+	for _, resourceList := range resourceLists {
+		if resourceList.GroupVersion != c.groupVersion {
+			healthyResourceLists = append(healthyResourceLists, resourceList)
+		}
+	}
+
+	return groups, healthyResourceLists, &discovery.ErrGroupDiscoveryFailed{
+		Groups: map[schema.GroupVersion]error{schema.FromAPIVersionAndKind(c.groupVersion, "").GroupVersion(): c.err},
+	}
+}
+
+func (c *flakyDiscoveryClient) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if groupVersion == c.groupVersion && c.calls.Add(1) <= c.failures {
+		return nil, c.err
+	}
+
+	//nolint:wrapcheck
+	return c.FakeCachedDiscoveryClient.ServerResourcesForGroupVersion(groupVersion)
+}
+
+// TestGetGroupResourcesRetries tests retrying the discovery of a group version on transient errors only.
+func TestGetGroupResourcesRetries(t *testing.T) {
+	t.Parallel()
+
+	unavailable := apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	forbidden := apierrors.NewForbidden(schema.GroupResource{}, "", errors.New("forbidden"))
+
+	t.Run("NoRetries", getGroupResourcesRetriesTest{err: unavailable, failures: 1, retries: 0, wantErr: true}.Test)
+	t.Run("Recovered", getGroupResourcesRetriesTest{err: unavailable, failures: 2, retries: 2, wantErr: false}.Test)
+	t.Run("Exhausted", getGroupResourcesRetriesTest{err: unavailable, failures: 3, retries: 2, wantErr: true}.Test)
+	t.Run("NotTransient", getGroupResourcesRetriesTest{err: forbidden, failures: 1, retries: 2, wantErr: true}.Test)
+}
+
+type getGroupResourcesRetriesTest struct {
+	err      error
+	failures int32
+	retries  int
+	wantErr  bool
+}
+
+func (tt getGroupResourcesRetriesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := &flakyDiscoveryClient{
+		FakeCachedDiscoveryClient: discoverytesting.New(),
+		groupVersion:              "autoscaling/v1",
+		err:                       tt.err,
+		// The single pass of the discovery fails too.
+		failures: tt.failures - 1,
+	}
+
+	options := NewTestOptionsBuilder().WithDiscoveryClient(discoveryClient).SetAPIGroup("autoscaling").
+		SetRetries(tt.retries).SetRetryBackoff(time.Millisecond).APIResourceVersionsOptions()
+
+	_, err := getGroupResources(t.Context(), options)
+	if (err != nil) != tt.wantErr {
+		t.Errorf("getGroupResources() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if err != nil && !errors.Is(err, tt.err) {
+		t.Errorf("getGroupResources() error = %v, want %v", err, tt.err)
+	}
+}
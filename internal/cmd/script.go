@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// errScriptMode is returned when --output=script is requested with multiple clusters, or with a mode printing the
+// resources as they are discovered.
+const errScriptMode = constError(
+	"output=script is not supported with stream, watch, all-contexts, contexts, or clusters-file")
+
+// scriptHeader is the beginning of the script printed with --output=script, before the list of its resources.
+const scriptHeader = `#!/usr/bin/env bash
+# Generated by kubectl api-resource-versions --output=script.
+#
+# Usage: audit.sh [count | export DIRECTORY]
+#   count    Print the number of objects of each resource version (default).
+#   export   Write the objects of each resource version to DIRECTORY/RESOURCE.yaml.
+#
+# The kubectl binary is read from $KUBECTL, and the cluster from the current context of the kubeconfig.
+set -euo pipefail
+
+KUBECTL="${KUBECTL:-kubectl}"
+mode="${1:-count}"
+
+resources=(
+`
+
+// scriptFooter is the end of the script printed with --output=script, after the list of its resources.
+const scriptFooter = `)
+
+case "$mode" in
+count)
+  for resource in "${resources[@]}"; do
+    count="$("$KUBECTL" get "$resource" --all-namespaces --no-headers --ignore-not-found | wc -l)"
+    printf '%s\t%s\n' "$resource" "$count"
+  done
+  ;;
+export)
+  directory="${2:?usage: $0 export DIRECTORY}"
+  mkdir -p "$directory"
+  for resource in "${resources[@]}"; do
+    "$KUBECTL" get "$resource" --all-namespaces --output=yaml >"$directory/$resource.yaml"
+  done
+  ;;
+*)
+  echo "usage: $0 [count | export DIRECTORY]" >&2
+  exit 1
+  ;;
+esac
+`
+
+// printScript prints a bash script which counts or exports the objects of each resource which can be listed, so that
+// it can be carried to and run in environments where this plugin isn't available.
+// The resources are identified by their fully qualified name, so that the script reads them in the listed version.
+func printScript(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	_, err := io.WriteString(out, scriptHeader)
+	if err != nil {
+		return fmt.Errorf("couldn't write script: %w", err)
+	}
+
+	for _, resource := range resources {
+		if resource.Subresource || !slices.Contains(resource.APIResource.Verbs, "list") {
+			continue
+		}
+
+		_, err = fmt.Fprintf(out, "  %s\n", resource.fullname())
+		if err != nil {
+			return fmt.Errorf("couldn't write script: %w", err)
+		}
+	}
+
+	_, err = io.WriteString(out, scriptFooter)
+	if err != nil {
+		return fmt.Errorf("couldn't write script: %w", err)
+	}
+
+	return nil
+}
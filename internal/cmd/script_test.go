@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunScript tests printing the script reading the objects of the resources which can be listed.
+func TestRunScript(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(scriptOutput)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := scriptHeader +
+		"  horizontalpodautoscalers.v2.autoscaling\n" +
+		"  horizontalpodautoscalers.v1.autoscaling\n" +
+		"  horizontalpodautoscalers.v2beta2.autoscaling\n" +
+		scriptFooter
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// defaultServeAddress is the address on which the HTTP API listens by default.
+	defaultServeAddress = ":8080"
+	// defaultServeRefreshInterval is the interval at which the discovery is refreshed by default.
+	defaultServeRefreshInterval = time.Minute
+)
+
+var (
+	// serveExample is the example text for the serve command.
+	//
+	//nolint:gochecknoglobals
+	serveExample = `
+		# Serve the API resource versions of the cluster over HTTP
+		kubectl api-resource-versions serve --address=:8080
+
+		# Query the preferred versions of the namespaced resources in the apps group which support list
+		curl 'http://localhost:8080/resources?api-group=apps&preferred=true&verbs=list'`
+)
+
+// newCmdServe returns a command that serves the API resource versions over HTTP.
+func newCmdServe(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newServeOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the API resource versions over HTTP",
+		Long: "Serve the API resources and their group versions as JSON over HTTP at /resources, for dashboards and " +
+			"other services.\n" +
+			"The query parameters of /resources mirror the filters of the command: api-group, namespaced, verbs, " +
+			"categories, preferred, include-subresources, and sort-by.\n" +
+			"The discovery is refreshed every --refresh-interval, extended by up to --refresh-jitter times the " +
+			"interval, and the health endpoints are served at /healthz and /readyz.\n" +
+			"On SIGTERM or an interrupt, the server stops accepting connections and waits for the in-flight " +
+			"requests to complete.",
+		Example: templates.Examples(serveExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			checkErr(cmd, runServe(ctx, options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Address, "address", options.Address,
+		"Address on which to serve the HTTP API.")
+	cmd.Flags().DurationVar(&options.RefreshInterval, "refresh-interval", options.RefreshInterval,
+		"Interval at which the discovery is refreshed.")
+	cmd.Flags().Float64Var(&options.RefreshJitter, "refresh-jitter", options.RefreshJitter,
+		"Maximum factor of --refresh-interval by which each refresh is randomly delayed, between 0 and 1.")
+
+	return cmd
+}
+
+// serveOptions contains the options for the serve command.
+type serveOptions struct {
+	genericiooptions.IOStreams
+
+	Address         string
+	RefreshInterval time.Duration
+	RefreshJitter   float64
+
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newServeOptions returns a new [serveOptions] with default values.
+func newServeOptions(ioStreams genericiooptions.IOStreams) *serveOptions {
+	return &serveOptions{
+		IOStreams:       ioStreams,
+		Address:         defaultServeAddress,
+		RefreshInterval: defaultServeRefreshInterval,
+		RefreshJitter:   defaultRefreshJitter,
+	}
+}
+
+// complete completes all the required options for the serve command.
+func (o *serveOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	return nil
+}
+
+// validate checks that options are valid for the serve command.
+func (o *serveOptions) validate() error {
+	if o.RefreshInterval <= 0 {
+		return fmt.Errorf("%w: got %s", errRefreshInterval, o.RefreshInterval)
+	}
+
+	return validateJitter(o.RefreshJitter)
+}
+
+// runServe serves the HTTP API until the context is done.
+func runServe(ctx context.Context, options *serveOptions) error {
+	handler := &inventoryHandler{discoveryClient: options.discoveryClient}
+
+	err := handler.refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	server := newHTTPServer(options.Address, handler.mux())
+
+	go refreshEvery(ctx, options.RefreshInterval, options.RefreshJitter, options.ErrOut, handler.refresh)
+
+	return runHTTPServer(ctx, server, server.ListenAndServe)
+}
+
+// inventoryResource is the JSON representation of a versioned API resource.
+type inventoryResource struct {
+	Name        string   `json:"name"`
+	Group       string   `json:"group"`
+	Version     string   `json:"version"`
+	Kind        string   `json:"kind"`
+	Namespaced  bool     `json:"namespaced"`
+	Preferred   bool     `json:"preferred"`
+	Subresource bool     `json:"subresource,omitempty"`
+	ShortNames  []string `json:"shortNames,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Verbs       []string `json:"verbs"`
+}
+
+// newInventoryResource returns the JSON representation of the resource.
+func newInventoryResource(resource groupResource) inventoryResource {
+	groupVersion, _ := schema.ParseGroupVersion(resource.APIGroupVersion)
+
+	return inventoryResource{
+		Name:        resource.APIResource.Name,
+		Group:       groupVersion.Group,
+		Version:     groupVersion.Version,
+		Kind:        resource.APIResource.Kind,
+		Namespaced:  resource.APIResource.Namespaced,
+		Preferred:   resource.Preferred,
+		Subresource: resource.Subresource,
+		ShortNames:  resource.APIResource.ShortNames,
+		Categories:  resource.APIResource.Categories,
+		Verbs:       resource.APIResource.Verbs,
+	}
+}
+
+// inventory is the JSON response of the /resources endpoint.
+type inventory struct {
+	// RefreshTime is the time at which the discovery was last refreshed.
+	RefreshTime time.Time `json:"refreshTime"`
+	// Resources are the resources matching the query.
+	Resources []inventoryResource `json:"resources"`
+}
+
+// errNotRefreshed is reported by the readiness endpoint until the discovery is refreshed.
+const errNotRefreshed = constError("discovery not refreshed")
+
+// inventoryHandler serves the API resource versions from the cached discovery.
+type inventoryHandler struct {
+	discoveryClient discovery.CachedDiscoveryInterface
+
+	mu          sync.RWMutex
+	refreshTime time.Time
+}
+
+// refresh invalidates the cached discovery and discovers the API resources again.
+func (h *inventoryHandler) refresh(ctx context.Context) error {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = h.discoveryClient
+
+	_, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.refreshTime = time.Now()
+
+	return nil
+}
+
+// lastRefresh returns the time at which the discovery was last refreshed, or the zero time if it never was.
+func (h *inventoryHandler) lastRefresh() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.refreshTime
+}
+
+// mux returns the HTTP handler serving the API resource versions and the health endpoints.
+func (h *inventoryHandler) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /resources", h)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, nil)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if h.lastRefresh().IsZero() {
+			writeHealth(w, errNotRefreshed)
+
+			return
+		}
+
+		writeHealth(w, nil)
+	})
+
+	return mux
+}
+
+// ServeHTTP serves the resources matching the query parameters.
+func (h *inventoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	options, err := inventoryQueryOptions(r.URL.Query())
+	if err == nil {
+		err = options.validate()
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	refreshTime := h.lastRefresh()
+
+	// The discovery is only invalidated by the refreshes, so the request is served from the cache.
+	options.Cached = true
+	options.discoveryClient = h.discoveryClient
+
+	resources, err := getGroupResources(r.Context(), options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	response := inventory{RefreshTime: refreshTime, Resources: make([]inventoryResource, 0, len(resources))}
+	for _, resource := range resources {
+		response.Resources = append(response.Resources, newInventoryResource(resource))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// errQueryParameter is returned when a query parameter is unknown or has an invalid value.
+const errQueryParameter = constError("invalid query parameter")
+
+// inventoryQueryOptions returns the options of the command for the query parameters, which are named after its flags.
+func inventoryQueryOptions(query url.Values) (*apiResourceVersionsOptions, error) {
+	options := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+
+	for key, values := range query {
+		value := values[len(values)-1]
+
+		var err error
+
+		switch key {
+		case "api-group":
+			options.APIGroup = value
+			options.groupChanged = true
+		case "namespaced":
+			options.Namespaced, err = strconv.ParseBool(value)
+			options.nsChanged = true
+		case "verbs":
+			options.Verbs = splitQueryList(values)
+		case "categories":
+			options.Categories = splitQueryList(values)
+		case "preferred":
+			options.Preferred, err = strconv.ParseBool(value)
+			options.preferredChanged = true
+		case "include-subresources":
+			options.IncludeSubresources, err = strconv.ParseBool(value)
+		case "sort-by":
+			options.SortBy = value
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", errQueryParameter, key)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", errQueryParameter, key, err)
+		}
+	}
+
+	return options, nil
+}
+
+// splitQueryList returns the comma-separated values of a query parameter which may be repeated.
+func splitQueryList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		list = append(list, strings.Split(value, ",")...)
+	}
+
+	return list
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+)
+
+// TestInventoryHandler tests serving the API resource versions over HTTP.
+func TestInventoryHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PreferredAutoscaling", inventoryHandlerTest{
+		query:      "api-group=autoscaling&preferred=true",
+		wantStatus: http.StatusOK,
+		wantNames:  []string{"autoscaling/v2 horizontalpodautoscalers"},
+	}.Test)
+	t.Run("AutoscalingSubresources", inventoryHandlerTest{
+		query:      "api-group=autoscaling&include-subresources=true&sort-by=name",
+		wantStatus: http.StatusOK,
+		wantNames: []string{
+			"autoscaling/v2 horizontalpodautoscalers",
+			"autoscaling/v1 horizontalpodautoscalers",
+			"autoscaling/v2beta2 horizontalpodautoscalers",
+			"autoscaling/v2 horizontalpodautoscalers/status",
+			"autoscaling/v1 horizontalpodautoscalers/status",
+			"autoscaling/v2beta2 horizontalpodautoscalers/status",
+		},
+	}.Test)
+	t.Run("UnknownParameter", inventoryHandlerTest{
+		query:      "output=name",
+		wantStatus: http.StatusBadRequest,
+	}.Test)
+	t.Run("InvalidBool", inventoryHandlerTest{
+		query:      "namespaced=maybe",
+		wantStatus: http.StatusBadRequest,
+	}.Test)
+	t.Run("InvalidSortBy", inventoryHandlerTest{
+		query:      "sort-by=version",
+		wantStatus: http.StatusBadRequest,
+	}.Test)
+}
+
+type inventoryHandlerTest struct {
+	query      string
+	wantStatus int
+	wantNames  []string
+}
+
+func (tt inventoryHandlerTest) Test(t *testing.T) {
+	t.Parallel()
+
+	handler := &inventoryHandler{discoveryClient: discoverytesting.New()}
+
+	err := handler.refresh(t.Context())
+	if err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+
+	server := httptest.NewServer(handler.mux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/resources?" + tt.query) //nolint:noctx
+	if err != nil {
+		t.Fatalf("http.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != tt.wantStatus {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+	} else if tt.wantStatus != http.StatusOK {
+		return
+	}
+
+	response := inventory{}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if response.RefreshTime.IsZero() {
+		t.Errorf("refreshTime is zero")
+	}
+
+	names := make([]string, 0, len(response.Resources))
+	for _, resource := range response.Resources {
+		names = append(names, resource.Group+"/"+resource.Version+" "+resource.Name)
+	}
+
+	if !reflect.DeepEqual(names, tt.wantNames) {
+		t.Errorf("resources = %v, want %v", names, tt.wantNames)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"k8s.io/client-go/discovery"
+)
+
+// unknownServerVersion is printed in the SERVER-VERSION column when the version of the API server couldn't be
+// determined.
+const unknownServerVersion = "<unknown>"
+
+// errServerVersionClusters is returned when --show-server-version is requested without multiple clusters.
+const errServerVersionClusters = constError(
+	"show-server-version requires all-contexts, contexts, or clusters-file")
+
+// errNameServerVersion is returned when --show-server-version is requested with --output=name, name0, api-versions,
+// or script, which don't print the server versions.
+const errNameServerVersion = constError("show-server-version has no effect with output=name, name0, api-versions, " +
+	"or script, which don't print the server versions: remove show-server-version or use the default or wide output")
+
+// validateServerVersion checks that --show-server-version is requested with multiple clusters, and with an output
+// printing it.
+func (o *apiResourceVersionsOptions) validateServerVersion() error {
+	if !o.ShowServerVersion {
+		return nil
+	}
+
+	if !o.AllContexts && len(o.Contexts) == 0 && o.ClustersFile == "" && len(o.clusters) == 0 {
+		return errServerVersionClusters
+	}
+
+	if o.namesOnly() || o.Output == apiVersionsOutput || o.Output == scriptOutput {
+		return errNameServerVersion
+	}
+
+	return nil
+}
+
+// clusterServerVersion returns the version of the API server of the cluster, e.g. "v1.33.1", printed in the
+// SERVER-VERSION column, or [unknownServerVersion] if it couldn't be determined.
+func clusterServerVersion(discoveryClient discovery.ServerVersionInterface) string {
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil || serverVersion.GitVersion == "" {
+		return unknownServerVersion
+	}
+
+	return serverVersion.GitVersion
+}
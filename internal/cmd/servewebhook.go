@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// defaultWebhookAddress is the address on which the admission webhook listens by default.
+	defaultWebhookAddress = ":8443"
+	// defaultWebhookRefreshInterval is the interval at which the disallowed versions are re-discovered by default.
+	defaultWebhookRefreshInterval = 10 * time.Minute
+	// maxAdmissionReviewBytes is the maximum size of an AdmissionReview request body.
+	maxAdmissionReviewBytes = 8 << 20
+)
+
+var (
+	// serveWebhookExample is the example text for the serve-webhook command.
+	//
+	//nolint:gochecknoglobals
+	serveWebhookExample = `
+		# Serve an admission webhook rejecting the deprecated and non-preferred API versions of the cluster
+		kubectl api-resource-versions serve-webhook --tls-cert-file=tls.crt --tls-private-key-file=tls.key
+
+		# Serve an admission webhook which only warns about the deprecated API versions
+		kubectl api-resource-versions serve-webhook --tls-cert-file=tls.crt --tls-private-key-file=tls.key \
+			--deprecated-only --warn`
+)
+
+// newCmdServeWebhook returns a command that serves an admission webhook disallowing deprecated API versions.
+func newCmdServeWebhook(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newServeWebhookOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "serve-webhook --tls-cert-file=FILE --tls-private-key-file=FILE",
+		Short: "Serve an admission webhook disallowing deprecated API versions",
+		Long: "Serve a validating admission webhook which rejects, or warns about, the requests using the deprecated " +
+			"or non-preferred versions of the resources discovered in the cluster.\n" +
+			"Deprecated versions are identified from the embedded database of the built-in Kubernetes API " +
+			"lifecycles, and the disallowed versions are re-discovered every --refresh-interval, extended by up to " +
+			"--refresh-jitter times the interval.\n" +
+			"Admission reviews are served over HTTPS at /validate, with the health endpoints /healthz and /readyz.\n" +
+			"The ValidatingWebhookConfiguration should use the Equivalent match policy, so that the webhook is called " +
+			"for the requests in every version of the matched resources.\n" +
+			"On SIGTERM or an interrupt, the server stops accepting connections and waits for the in-flight " +
+			"reviews to complete.",
+		Example: templates.Examples(serveWebhookExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			checkErr(cmd, runServeWebhook(ctx, options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Address, "address", options.Address,
+		"Address on which to serve the admission webhook.")
+	cmd.Flags().StringVar(&options.TLSCertFile, "tls-cert-file", options.TLSCertFile,
+		"File containing the x509 certificate for HTTPS, concatenated with any intermediate certificates.")
+	cmd.Flags().StringVar(&options.TLSPrivateKeyFile, "tls-private-key-file", options.TLSPrivateKeyFile,
+		"File containing the x509 private key matching --tls-cert-file.")
+	cmd.Flags().BoolVar(&options.Warn, "warn", options.Warn,
+		"Allow the requests using the disallowed versions, returning a warning to the client instead of rejecting "+
+			"them.")
+	cmd.Flags().DurationVar(&options.RefreshInterval, "refresh-interval", options.RefreshInterval,
+		"Interval at which the disallowed versions are re-discovered.")
+	cmd.Flags().Float64Var(&options.RefreshJitter, "refresh-jitter", options.RefreshJitter,
+		"Maximum factor of --refresh-interval by which each re-discovery is randomly delayed, between 0 and 1.")
+	options.disallowedVersionsOptions.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// serveWebhookOptions contains the options for the serve-webhook command.
+type serveWebhookOptions struct {
+	genericiooptions.IOStreams
+	disallowedVersionsOptions
+
+	Address           string
+	TLSCertFile       string
+	TLSPrivateKeyFile string
+	Warn              bool
+	RefreshInterval   time.Duration
+	RefreshJitter     float64
+
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newServeWebhookOptions returns a new [serveWebhookOptions] with default values.
+func newServeWebhookOptions(ioStreams genericiooptions.IOStreams) *serveWebhookOptions {
+	return &serveWebhookOptions{
+		IOStreams:       ioStreams,
+		Address:         defaultWebhookAddress,
+		RefreshInterval: defaultWebhookRefreshInterval,
+		RefreshJitter:   defaultRefreshJitter,
+	}
+}
+
+// complete completes all the required options for the serve-webhook command.
+func (o *serveWebhookOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	return nil
+}
+
+// errTLSFiles is returned when the TLS certificate or private key is missing.
+const errTLSFiles = constError("tls-cert-file and tls-private-key-file are required")
+
+// validate checks that options are valid for the serve-webhook command.
+func (o *serveWebhookOptions) validate() error {
+	if o.TLSCertFile == "" || o.TLSPrivateKeyFile == "" {
+		return errTLSFiles
+	}
+
+	if o.RefreshInterval <= 0 {
+		return fmt.Errorf("%w: got %s", errRefreshInterval, o.RefreshInterval)
+	}
+
+	err := validateJitter(o.RefreshJitter)
+	if err != nil {
+		return err
+	}
+
+	return o.disallowedVersionsOptions.validate()
+}
+
+// runServeWebhook serves the admission webhook until the context is done.
+func runServeWebhook(ctx context.Context, options *serveWebhookOptions) error {
+	handler := &admissionHandler{warn: options.Warn}
+
+	err := handler.refresh(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	server := newHTTPServer(options.Address, handler.mux())
+
+	go refreshEvery(ctx, options.RefreshInterval, options.RefreshJitter, options.ErrOut, func(ctx context.Context) error {
+		return handler.refresh(ctx, options)
+	})
+
+	return runHTTPServer(ctx, server, func() error {
+		return server.ListenAndServeTLS(options.TLSCertFile, options.TLSPrivateKeyFile)
+	})
+}
+
+// errDisallowedVersionsNotReady is reported by the readiness endpoint until the disallowed versions are discovered.
+const errDisallowedVersionsNotReady = constError("disallowed versions not discovered")
+
+// admissionHandler reviews the admission requests against the disallowed versions.
+type admissionHandler struct {
+	warn bool
+
+	mu       sync.RWMutex
+	versions map[schema.GroupVersionKind]disallowedVersion
+}
+
+// refresh re-discovers the disallowed versions.
+func (h *admissionHandler) refresh(ctx context.Context, options *serveWebhookOptions) error {
+	versions, err := getDisallowedVersions(ctx, options.discoveryClient, &options.disallowedVersionsOptions)
+	if err != nil {
+		return err
+	}
+
+	h.update(versions)
+
+	return nil
+}
+
+// update replaces the disallowed versions.
+func (h *admissionHandler) update(versions []disallowedVersion) {
+	index := make(map[schema.GroupVersionKind]disallowedVersion, len(versions))
+	for _, version := range versions {
+		index[version.GroupVersionKind] = version
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.versions = index
+}
+
+// lookup returns the disallowed version of the kind, if it is disallowed.
+func (h *admissionHandler) lookup(gvk schema.GroupVersionKind) (disallowedVersion, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	version, ok := h.versions[gvk]
+
+	return version, ok
+}
+
+// ready returns true once the disallowed versions have been discovered.
+func (h *admissionHandler) ready() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.versions != nil
+}
+
+// mux returns the HTTP handler serving the admission reviews and the health endpoints.
+func (h *admissionHandler) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("POST /validate", h)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, nil)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !h.ready() {
+			writeHealth(w, errDisallowedVersionsNotReady)
+
+			return
+		}
+
+		writeHealth(w, nil)
+	})
+
+	return mux
+}
+
+// ServeHTTP reviews an AdmissionReview request.
+func (h *admissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := admissionv1.AdmissionReview{}
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdmissionReviewBytes)).Decode(&review)
+	if err != nil {
+		http.Error(w, "couldn't decode the admission review: "+err.Error(), http.StatusBadRequest)
+
+		return
+	} else if review.Request == nil {
+		http.Error(w, "the admission review has no request", http.StatusBadRequest)
+
+		return
+	}
+
+	review.Response = h.review(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// review returns the response to the admission request, which is allowed unless it creates or updates an object in a
+// disallowed version.
+func (h *admissionHandler) review(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return response
+	}
+
+	// The request kind is the kind of the original request, before any conversion due to the match policy.
+	kind := request.Kind
+	if request.RequestKind != nil {
+		kind = *request.RequestKind
+	}
+
+	version, ok := h.lookup(schema.GroupVersionKind(kind))
+	if !ok {
+		return response
+	}
+
+	if h.warn {
+		response.Warnings = []string{version.message()}
+
+		return response
+	}
+
+	response.Allowed = false
+	response.Result = &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: version.message(),
+	}
+
+	return response
+}
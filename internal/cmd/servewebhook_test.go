@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestValidateServeWebhookOptions tests validation of the serve-webhook command options.
+func TestValidateServeWebhookOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		certFile        string
+		keyFile         string
+		refreshInterval time.Duration
+		refreshJitter   float64
+		wantErr         error
+	}{
+		{name: "Default", certFile: "tls.crt", keyFile: "tls.key"},
+		{name: "NoCertFile", keyFile: "tls.key", wantErr: errTLSFiles},
+		{name: "NoKeyFile", certFile: "tls.crt", wantErr: errTLSFiles},
+		{name: "NegativeRefreshInterval", certFile: "tls.crt", keyFile: "tls.key", refreshInterval: -time.Minute,
+			wantErr: errRefreshInterval},
+		{name: "NegativeRefreshJitter", certFile: "tls.crt", keyFile: "tls.key", refreshJitter: -1,
+			wantErr: errRefreshJitter},
+	}
+
+	for _, tt := range tests {
+		options := newServeWebhookOptions(genericiooptions.NewTestIOStreamsDiscard())
+		options.TLSCertFile = tt.certFile
+		options.TLSPrivateKeyFile = tt.keyFile
+
+		if tt.refreshInterval != 0 {
+			options.RefreshInterval = tt.refreshInterval
+		}
+
+		if tt.refreshJitter != 0 {
+			options.RefreshJitter = tt.refreshJitter
+		}
+
+		err := options.validate()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestAdmissionHandler tests reviewing admission requests.
+func TestAdmissionHandler(t *testing.T) {
+	t.Parallel()
+
+	hpaV2beta2 := metav1.GroupVersionKind{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"}
+	hpaV2 := metav1.GroupVersionKind{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}
+	denied := "autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated, use autoscaling/v2 instead."
+
+	t.Run("DeniedDeprecated", admissionHandlerTest{
+		operation:   admissionv1.Create,
+		kind:        hpaV2beta2,
+		wantAllowed: false,
+		wantMessage: denied,
+	}.Test)
+	t.Run("AllowedPreferred", admissionHandlerTest{
+		operation:   admissionv1.Update,
+		kind:        hpaV2,
+		wantAllowed: true,
+	}.Test)
+	t.Run("AllowedDelete", admissionHandlerTest{
+		operation:   admissionv1.Delete,
+		kind:        hpaV2beta2,
+		wantAllowed: true,
+	}.Test)
+	t.Run("DeniedRequestKind", admissionHandlerTest{
+		operation:   admissionv1.Create,
+		kind:        hpaV2,
+		requestKind: &hpaV2beta2,
+		wantAllowed: false,
+		wantMessage: denied,
+	}.Test)
+	t.Run("Warn", admissionHandlerTest{
+		warn:         true,
+		operation:    admissionv1.Create,
+		kind:         hpaV2beta2,
+		wantAllowed:  true,
+		wantWarnings: []string{denied},
+	}.Test)
+}
+
+type admissionHandlerTest struct {
+	warn         bool
+	operation    admissionv1.Operation
+	kind         metav1.GroupVersionKind
+	requestKind  *metav1.GroupVersionKind
+	wantAllowed  bool
+	wantMessage  string
+	wantWarnings []string
+}
+
+func (tt admissionHandlerTest) Test(t *testing.T) {
+	t.Parallel()
+
+	options := newServeWebhookOptions(genericiooptions.NewTestIOStreamsDiscard())
+	options.discoveryClient = discoverytesting.New()
+	options.DeprecatedOnly = true
+
+	handler := &admissionHandler{warn: tt.warn}
+
+	err := handler.refresh(t.Context(), options)
+	if err != nil {
+		t.Fatalf("refresh() error = %v", err)
+	}
+
+	server := httptest.NewServer(handler.mux())
+	defer server.Close()
+
+	request := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:         "uid",
+			Kind:        tt.kind,
+			RequestKind: tt.requestKind,
+			Operation:   tt.operation,
+		},
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	resp, err := http.Post(server.URL+"/validate", "application/json", bytes.NewReader(body)) //nolint:noctx
+	if err != nil {
+		t.Fatalf("http.Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	review := admissionv1.AdmissionReview{}
+
+	err = json.NewDecoder(resp.Body).Decode(&review)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if review.Response == nil || review.Response.UID != "uid" {
+		t.Fatalf("review response = %+v, want UID uid", review.Response)
+	}
+
+	if review.Response.Allowed != tt.wantAllowed {
+		t.Errorf("review allowed = %v, want %v", review.Response.Allowed, tt.wantAllowed)
+	}
+
+	var message string
+	if review.Response.Result != nil {
+		message = review.Response.Result.Message
+	}
+
+	if message != tt.wantMessage {
+		t.Errorf("review message = %q, want %q", message, tt.wantMessage)
+	}
+
+	if !reflect.DeepEqual(review.Response.Warnings, tt.wantWarnings) {
+		t.Errorf("review warnings = %v, want %v", review.Response.Warnings, tt.wantWarnings)
+	}
+}
+
+// TestAdmissionHandlerReady tests the readiness endpoint before and after the disallowed versions are discovered.
+func TestAdmissionHandlerReady(t *testing.T) {
+	t.Parallel()
+
+	handler := &admissionHandler{}
+
+	server := httptest.NewServer(handler.mux())
+	defer server.Close()
+
+	getStatus := func() int {
+		resp, err := http.Get(server.URL + "/readyz") //nolint:noctx
+		if err != nil {
+			t.Fatalf("http.Get() error = %v", err)
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	if got := getStatus(); got != http.StatusServiceUnavailable {
+		t.Errorf("readyz status before refresh = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	handler.update(nil)
+
+	if got := getStatus(); got != http.StatusOK {
+		t.Errorf("readyz status after refresh = %d, want %d", got, http.StatusOK)
+	}
+}
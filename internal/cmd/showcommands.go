@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"path"
+	"slices"
+)
+
+// Placeholders of the raw paths printed in the COMMAND column with --show-commands.
+const (
+	// namespacePlaceholder is replaced by the namespace of the objects of a namespaced resource.
+	namespacePlaceholder = "{namespace}"
+	// namePlaceholder is replaced by the name of the object of a subresource.
+	namePlaceholder = "{name}"
+)
+
+// command returns the kubectl invocation reading the resource, printed in the COMMAND column with --show-commands:
+// kubectl get with the fully qualified name of the resource if it can be listed, e.g.
+// "kubectl get deployments.v1.apps -A", or else kubectl get --raw with its path, e.g.
+// "kubectl get --raw /api/v1/namespaces/{namespace}/pods/{name}/log".
+func (gr groupResource) command() string {
+	if !gr.Subresource && slices.Contains(gr.APIResource.Verbs, "list") {
+		command := "kubectl get " + gr.fullname()
+		if gr.APIResource.Namespaced {
+			command += " -A"
+		}
+
+		return command
+	}
+
+	return "kubectl get --raw " + gr.rawPath()
+}
+
+// rawPath returns the path of the resource on the API server, with placeholders for the namespace of the namespaced
+// resources and the name of the object of the subresources.
+func (gr groupResource) rawPath() string {
+	groupVersionResource := gr.groupVersionResource()
+
+	elements := []string{"/apis", gr.APIGroupVersion}
+	if groupVersionResource.Group == "" {
+		elements[0] = "/api"
+	}
+
+	if gr.APIResource.Namespaced {
+		elements = append(elements, "namespaces", namespacePlaceholder)
+	}
+
+	elements = append(elements, groupVersionResource.Resource)
+
+	_, subName := splitResourceName(gr.APIResource.Name)
+	if subName != nil {
+		elements = append(elements, namePlaceholder, *subName)
+	}
+
+	return path.Join(elements...)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestCommand tests the kubectl invocations reading the resources.
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	apiGroup := &resourceGroup{Name: "apps"}
+	coreAPIGroup := &resourceGroup{Name: ""}
+
+	t.Run("NamespacedResource", commandTest{
+		resource: groupResource{
+			APIGroup:        apiGroup,
+			APIGroupVersion: "apps/v1",
+			APIResource:     &metav1.APIResource{Name: "deployments", Namespaced: true, Verbs: []string{"get", "list"}},
+		},
+		want: "kubectl get deployments.v1.apps -A",
+	}.Test)
+	t.Run("ClusterResource", commandTest{
+		resource: groupResource{
+			APIGroup:        coreAPIGroup,
+			APIGroupVersion: "v1",
+			APIResource:     &metav1.APIResource{Name: "nodes", Verbs: []string{"get", "list"}},
+		},
+		want: "kubectl get nodes.v1.",
+	}.Test)
+	t.Run("UnlistableResource", commandTest{
+		resource: groupResource{
+			APIGroup:        apiGroup,
+			APIGroupVersion: "apps/v1",
+			APIResource:     &metav1.APIResource{Name: "controllerrevisions", Verbs: []string{"create"}},
+		},
+		want: "kubectl get --raw /apis/apps/v1/controllerrevisions",
+	}.Test)
+	t.Run("Subresource", commandTest{
+		resource: groupResource{
+			APIGroup:        coreAPIGroup,
+			APIGroupVersion: "v1",
+			APIResource:     &metav1.APIResource{Name: "pods/log", Namespaced: true, Verbs: []string{"get"}},
+			Subresource:     true,
+		},
+		want: "kubectl get --raw /api/v1/namespaces/{namespace}/pods/{name}/log",
+	}.Test)
+}
+
+type commandTest struct {
+	resource groupResource
+	want     string
+}
+
+func (tt commandTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got := tt.resource.command()
+	if got != tt.want {
+		t.Errorf("command() = %v, want %v", got, tt.want)
+	}
+}
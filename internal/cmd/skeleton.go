@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+var (
+	// skeletonExample is the example text for the skeleton command.
+	//
+	//nolint:gochecknoglobals
+	skeletonExample = `
+		# Print a minimal manifest of a HorizontalPodAutoscaler in a version which isn't preferred
+		kubectl api-resource-versions skeleton hpa --api-version=autoscaling/v2beta2
+
+		# Start a new manifest of a custom resource in its preferred version
+		kubectl api-resource-versions skeleton widgets.example.com > widget.yaml`
+)
+
+// newCmdSkeleton returns a command that prints a minimal manifest of a resource in a group version.
+func newCmdSkeleton(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newSkeletonOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "skeleton RESOURCE",
+		Short: "Print a minimal manifest of a resource in any of its served versions",
+		Long: "Print a minimal YAML manifest of the resource in the group version, with its apiVersion, kind, " +
+			"metadata, and spec, and the fields required by the OpenAPI v3 schema of the group version set to " +
+			"placeholder values.\n" +
+			"The resource is resolved like the versions command. The preferred version is used without --api-version.",
+		Example:           templates.Examples(skeletonExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runSkeleton(options))
+		},
+	}
+
+	cmd.Flags().StringVar(&options.APIVersion, "api-version", options.APIVersion,
+		"The group version of the manifest, e.g. autoscaling/v2beta2. Defaults to the preferred version.")
+
+	return cmd
+}
+
+// skeletonOptions contains the options for the skeleton command.
+type skeletonOptions struct {
+	genericiooptions.IOStreams
+
+	APIVersion string
+
+	resource        string
+	discoveryClient discovery.DiscoveryInterface
+	openAPIClient   openapi.Client
+}
+
+// newSkeletonOptions returns a new [skeletonOptions] with default values.
+func newSkeletonOptions(ioStreams genericiooptions.IOStreams) *skeletonOptions {
+	return &skeletonOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the skeleton command.
+func (o *skeletonOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 1 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "exactly one resource is required, got: %v", args)
+	}
+
+	o.resource = args[0]
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	o.openAPIClient = discoveryClient.OpenAPIV3()
+	if o.openAPIClient == nil {
+		return fmt.Errorf("%w, e.g. with --from-dump", errOpenAPIUnavailable)
+	}
+
+	return nil
+}
+
+// runSkeleton prints the minimal manifest of the resource in the selected group version.
+func runSkeleton(options *skeletonOptions) error {
+	kind, err := getKindSchema(options.discoveryClient, options.openAPIClient, options.resource, options.APIVersion)
+	if err != nil {
+		return err
+	}
+
+	manifest := map[string]any{
+		"apiVersion": kind.GroupVersionKind.GroupVersion().String(),
+		"kind":       kind.GroupVersionKind.Kind,
+		"metadata":   map[string]any{"name": ""},
+	}
+
+	if specSchema, ok := kind.Schema.Properties["spec"]; ok {
+		manifest["spec"] = skeletonValue(kind.Components, &specSchema, sets.New[string]())
+	}
+
+	content, err := k8syaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal manifest: %w", err)
+	}
+
+	_, err = options.Out.Write(content)
+	if err != nil {
+		return fmt.Errorf("couldn't write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// skeletonValue returns the placeholder value of a field: the zero value of its type, the first value of its enum,
+// or an object with its required fields.
+// The references being expanded are skipped to stop at recursive schemas, which are left empty.
+func skeletonValue(schemas map[string]*spec.Schema, fieldSchema *spec.Schema, expanding sets.Set[string]) any {
+	if len(fieldSchema.Enum) > 0 {
+		return fieldSchema.Enum[0]
+	}
+
+	switch {
+	case fieldSchema.Items != nil:
+		return []any{}
+	case fieldSchema.AdditionalProperties != nil && fieldSchema.AdditionalProperties.Schema != nil:
+		return map[string]any{}
+	}
+
+	ref := schemaRef(fieldSchema)
+	if ref != "" {
+		if expanding.Has(ref) {
+			return map[string]any{}
+		}
+
+		expanding.Insert(ref)
+		defer expanding.Delete(ref)
+
+		referenced, ok := schemas[ref[len(schemaRefPrefix):]]
+		if !ok {
+			return map[string]any{}
+		}
+
+		fieldSchema = referenced
+	}
+
+	switch schemaTypeName(fieldSchema) {
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+
+	object := map[string]any{}
+
+	for name, property := range fieldSchema.Properties {
+		if slices.Contains(fieldSchema.Required, name) {
+			object[name] = skeletonValue(schemas, &property, expanding)
+		}
+	}
+
+	return object
+}
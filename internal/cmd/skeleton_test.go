@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+// TestRunSkeleton tests printing a minimal manifest of a resource from the OpenAPI v3 schema of its version.
+func TestRunSkeleton(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Preferred", runSkeletonTest{
+		resource: "hpa",
+		want: "apiVersion: autoscaling/v2\n" +
+			"kind: HorizontalPodAutoscaler\n" +
+			"metadata:\n" +
+			"  name: \"\"\n" +
+			"spec:\n" +
+			"  maxReplicas: 0\n" +
+			"  scaleTargetRef:\n" +
+			"    kind: \"\"\n" +
+			"    name: \"\"\n",
+	}.Test)
+	t.Run("NotServed", runSkeletonTest{
+		resource:   "hpa",
+		apiVersion: "autoscaling/v3",
+		wantErr:    errAPIVersionNotServed,
+	}.Test)
+	t.Run("NoSchema", runSkeletonTest{
+		resource: "pods",
+		wantErr:  errOpenAPIUnavailable,
+	}.Test)
+}
+
+type runSkeletonTest struct {
+	resource   string
+	apiVersion string
+	want       string
+	wantErr    error
+}
+
+func (tt runSkeletonTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newSkeletonOptions(ioStreams)
+	options.resource = tt.resource
+	options.APIVersion = tt.apiVersion
+	options.discoveryClient = discoverytesting.New()
+	options.openAPIClient = openapitest.NewFileClient("testdata/openapi")
+
+	err := runSkeleton(options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runSkeleton() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.want {
+		t.Errorf("runSkeleton() output = %q, want %q", got, tt.want)
+	}
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// snapshotAPIVersion is the version of the snapshot schema, which is changed on incompatible changes.
+	snapshotAPIVersion = "api-resource-versions.izzette.github.io/v1"
+	// snapshotFilePermissions are the permissions of the snapshot files.
+	snapshotFilePermissions = 0o644
+	// stdoutFilename is the filename used to write the snapshot to stdout.
+	stdoutFilename = "-"
+)
+
+var (
+	// snapshotSaveExample is the example text for the snapshot save command.
+	//
+	//nolint:gochecknoglobals
+	snapshotSaveExample = `
+		# Save the API resource versions of the cluster to a file
+		kubectl api-resource-versions snapshot save --file=cluster-api.json
+
+		# Save the API resource versions of the cluster to a file as YAML
+		kubectl api-resource-versions snapshot save --output=yaml --file=cluster-api.yaml
+
+		# Save the API resource versions of the production cluster to stdout
+		kubectl api-resource-versions --context=prod snapshot save`
+)
+
+// snapshot is the discovery result of a cluster, serialized in a stable schema.
+type snapshot struct {
+	// APIVersion is the version of the snapshot schema, see [snapshotAPIVersion].
+	APIVersion string `json:"apiVersion"`
+	// Timestamp is the time at which the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+	// Context is the kubeconfig context of the cluster, if it could be determined.
+	Context string `json:"context,omitempty"`
+	// ServerVersion is the version of the API server, if it could be determined.
+	ServerVersion *version.Info `json:"serverVersion,omitempty"`
+	// Groups are the API groups served by the cluster, sorted by name.
+	Groups []snapshotGroup `json:"groups"`
+}
+
+// snapshotGroup is an API group in a [snapshot].
+type snapshotGroup struct {
+	// Name is the name of the group, empty for the core group.
+	Name string `json:"name"`
+	// PreferredVersion is the preferred version of the group.
+	PreferredVersion string `json:"preferredVersion"`
+	// Versions are the versions of the group, in the order of priority of the server.
+	Versions []snapshotVersion `json:"versions"`
+}
+
+// snapshotVersion is a version of an API group in a [snapshot].
+type snapshotVersion struct {
+	// Version is the version, e.g. "v1".
+	Version string `json:"version"`
+	// Resources are the resources of the group version, including subresources, sorted by name.
+	Resources []snapshotResource `json:"resources"`
+}
+
+// snapshotResource is a resource of a group version in a [snapshot].
+type snapshotResource struct {
+	Name         string   `json:"name"`
+	SingularName string   `json:"singularName,omitempty"`
+	Kind         string   `json:"kind"`
+	Namespaced   bool     `json:"namespaced"`
+	Preferred    bool     `json:"preferred"`
+	Verbs        []string `json:"verbs"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Categories   []string `json:"categories,omitempty"`
+}
+
+// newCmdSnapshot returns a command grouping the snapshot commands.
+func newCmdSnapshot(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save and compare snapshots of the API resource versions",
+		Long: "Save snapshots of the API resources and their group versions to files, and compare them with each other " +
+			"or with the cluster.",
+	}
+
+	cmd.AddCommand(newCmdSnapshotSave(restClientGetter, ioStreams))
+	cmd.AddCommand(newCmdSnapshotDiff(restClientGetter, ioStreams))
+
+	return cmd
+}
+
+// newCmdSnapshotSave returns a command that saves a snapshot of the API resource versions to a file.
+func newCmdSnapshotSave(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newSnapshotSaveOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "save",
+		Short: "Save a snapshot of the API resource versions",
+		Long: "Save the groups, versions, and resources served by the cluster, with their preferred versions, the " +
+			"version of the server, and the time of the snapshot, as JSON or YAML.\n" +
+			"Subresources are included.",
+		Example: templates.Examples(snapshotSaveExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runSnapshotSave(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Filename, "file", "f", options.Filename,
+		"File to which the snapshot is written, or - for stdout.")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+jsonOutput+", "+yamlOutput+").")
+
+	return cmd
+}
+
+// snapshotSaveOptions contains the options for the snapshot save command.
+type snapshotSaveOptions struct {
+	genericiooptions.IOStreams
+
+	Filename string
+	Output   string
+
+	contextName     string
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newSnapshotSaveOptions returns a new [snapshotSaveOptions] with default values.
+func newSnapshotSaveOptions(ioStreams genericiooptions.IOStreams) *snapshotSaveOptions {
+	return &snapshotSaveOptions{
+		IOStreams: ioStreams,
+		Filename:  stdoutFilename,
+		Output:    jsonOutput,
+	}
+}
+
+// complete completes all the required options for the snapshot save command.
+func (o *snapshotSaveOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+	o.contextName = contextName(restClientGetter)
+
+	return nil
+}
+
+// errSnapshotOutput is returned when the output format of the snapshot save command is not supported.
+const errSnapshotOutput = constError("output must be one of: (" + jsonOutput + ", " + yamlOutput + ")")
+
+// validate checks that options are valid for the snapshot save command.
+func (o *snapshotSaveOptions) validate() error {
+	if o.Output != jsonOutput && o.Output != yamlOutput {
+		return fmt.Errorf("%w: %s is not available", errSnapshotOutput, o.Output)
+	}
+
+	return nil
+}
+
+// runSnapshotSave takes a snapshot of the API resource versions and writes it to the file.
+func runSnapshotSave(ctx context.Context, options *snapshotSaveOptions) error {
+	snap, err := newSnapshot(ctx, options.discoveryClient, options.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	snap.Context = options.contextName
+
+	if options.Filename == stdoutFilename {
+		return writeSnapshot(options.Out, options.Output, snap)
+	}
+
+	// The snapshot is written atomically, so that a snapshot taken periodically is never left partially written.
+	err = writeFileAtomically(options.Filename, snapshotFilePermissions, func(out io.Writer) error {
+		return writeSnapshot(out, options.Output, snap)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't write snapshot %s: %w", options.Filename, err)
+	}
+
+	return nil
+}
+
+// newSnapshot discovers the API resources of the cluster, including subresources, and returns their snapshot.
+// Every group version of the group list is included, even without resources, and a warning is written to errOut if
+// the version of the server couldn't be determined.
+func newSnapshot(
+	ctx context.Context,
+	discoveryClient discovery.CachedDiscoveryInterface,
+	errOut io.Writer,
+) (*snapshot, error) {
+	listOptions := newAPIResourceVersionsOptions(genericiooptions.IOStreams{})
+	listOptions.discoveryClient = discoveryClient
+	listOptions.IncludeSubresources = true
+
+	resources, err := getGroupResources(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// The group list was cached by the discovery of the resources.
+	groupList, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get server groups: %w", err)
+	}
+
+	snap := &snapshot{APIVersion: snapshotAPIVersion, Timestamp: time.Now().UTC()}
+
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Warning: couldn't get the server version, the snapshot won't include it: %v\n", err)
+	} else {
+		snap.ServerVersion = serverVersion
+	}
+
+	groups := make(map[string]*snapshotGroup, len(groupList.Groups))
+	for i := range groupList.Groups {
+		groups[groupList.Groups[i].Name] = newSnapshotGroup(&groupList.Groups[i])
+	}
+
+	for _, resource := range resources {
+		group, ok := groups[resource.APIGroup.Name]
+		if !ok {
+			// The group was served after the discovery of the group list.
+			continue
+		}
+
+		groupVersion, err := schema.ParseGroupVersion(resource.APIGroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse group version %s: %w", resource.APIGroupVersion, err)
+		}
+
+		i := slices.IndexFunc(group.Versions, func(v snapshotVersion) bool { return v.Version == groupVersion.Version })
+		if i < 0 {
+			group.Versions = append(group.Versions, snapshotVersion{Version: groupVersion.Version})
+			i = len(group.Versions) - 1
+		}
+
+		group.Versions[i].Resources = append(group.Versions[i].Resources, snapshotResource{
+			Name:         resource.APIResource.Name,
+			SingularName: resource.APIResource.SingularName,
+			Kind:         resource.APIResource.Kind,
+			Namespaced:   resource.APIResource.Namespaced,
+			Preferred:    resource.Preferred,
+			Verbs:        resource.APIResource.Verbs,
+			ShortNames:   resource.APIResource.ShortNames,
+			Categories:   resource.APIResource.Categories,
+		})
+	}
+
+	snap.Groups = make([]snapshotGroup, 0, len(groups))
+	for _, group := range groups {
+		for _, groupVersion := range group.Versions {
+			slices.SortFunc(groupVersion.Resources, func(a, b snapshotResource) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+		}
+
+		snap.Groups = append(snap.Groups, *group)
+	}
+
+	slices.SortFunc(snap.Groups, func(a, b snapshotGroup) int { return strings.Compare(a.Name, b.Name) })
+
+	return snap, nil
+}
+
+// newSnapshotGroup returns the snapshot of the group, with every version of the group.
+func newSnapshotGroup(apiGroup *metav1.APIGroup) *snapshotGroup {
+	group := &snapshotGroup{
+		Name:             apiGroup.Name,
+		PreferredVersion: apiGroup.PreferredVersion.Version,
+		Versions:         make([]snapshotVersion, 0, len(apiGroup.Versions)),
+	}
+
+	for _, groupVersion := range apiGroup.Versions {
+		group.Versions = append(group.Versions, snapshotVersion{
+			Version:   groupVersion.Version,
+			Resources: []snapshotResource{},
+		})
+	}
+
+	return group
+}
+
+// writeSnapshot writes the snapshot as indented JSON, or as a YAML document with the yaml output.
+func writeSnapshot(out io.Writer, output string, snap *snapshot) error {
+	if output == yamlOutput {
+		encoder := yamlutil.NewDocumentEncoder(out)
+
+		err := encoder.Encode(snap)
+		if err == nil {
+			err = encoder.Close()
+		}
+
+		if err != nil {
+			return fmt.Errorf("couldn't write snapshot: %w", err)
+		}
+
+		return nil
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(snap)
+	if err != nil {
+		return fmt.Errorf("couldn't write snapshot: %w", err)
+	}
+
+	return nil
+}
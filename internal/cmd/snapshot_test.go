@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// TestRunSnapshotSave tests saving a snapshot of the test discovery to a file.
+func TestRunSnapshotSave(t *testing.T) {
+	t.Parallel()
+
+	options := newSnapshotSaveOptions(genericiooptions.NewTestIOStreamsDiscard())
+	options.discoveryClient = discoverytesting.New()
+	options.Filename = filepath.Join(t.TempDir(), "cluster-api.json")
+
+	err := runSnapshotSave(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runSnapshotSave() error = %v", err)
+	}
+
+	content, err := os.ReadFile(options.Filename)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	snap := snapshot{}
+
+	err = json.Unmarshal(content, &snap)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if snap.APIVersion != snapshotAPIVersion {
+		t.Errorf("snapshot apiVersion = %q, want %q", snap.APIVersion, snapshotAPIVersion)
+	}
+
+	if snap.Timestamp.IsZero() {
+		t.Errorf("snapshot timestamp is zero")
+	}
+
+	var groups []string
+	for _, group := range snap.Groups {
+		groups = append(groups, group.Name+"@"+group.PreferredVersion)
+	}
+
+	if want := []string{"@v1", "autoscaling@v2"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("snapshot groups = %v, want %v", groups, want)
+	}
+
+	autoscaling := snap.Groups[1]
+
+	var versions []string
+	for _, version := range autoscaling.Versions {
+		versions = append(versions, version.Version)
+	}
+
+	if want := []string{"v2", "v1", "v2beta2"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("snapshot autoscaling versions = %v, want %v", versions, want)
+	}
+
+	var resources []string
+	for _, resource := range autoscaling.Versions[0].Resources {
+		resources = append(resources, resource.Name)
+	}
+
+	if want := []string{"horizontalpodautoscalers", "horizontalpodautoscalers/status"}; !reflect.DeepEqual(resources, want) {
+		t.Errorf("snapshot autoscaling/v2 resources = %v, want %v", resources, want)
+	}
+
+	if !autoscaling.Versions[0].Resources[0].Preferred || autoscaling.Versions[1].Resources[0].Preferred {
+		t.Errorf("snapshot autoscaling preferred = %v, want only autoscaling/v2 preferred", autoscaling.Versions)
+	}
+}
+
+// TestRunSnapshotSaveYAML tests saving a snapshot as YAML, which is read back by the snapshot diff command.
+func TestRunSnapshotSaveYAML(t *testing.T) {
+	t.Parallel()
+
+	options := newSnapshotSaveOptions(genericiooptions.NewTestIOStreamsDiscard())
+	options.discoveryClient = discoverytesting.New()
+	options.Filename = filepath.Join(t.TempDir(), "cluster-api.yaml")
+	options.Output = yamlOutput
+
+	err := runSnapshotSave(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runSnapshotSave() error = %v", err)
+	}
+
+	snap, err := readSnapshot(options.Filename)
+	if err != nil {
+		t.Fatalf("readSnapshot() error = %v", err)
+	}
+
+	if snap.Timestamp.IsZero() {
+		t.Errorf("snapshot timestamp is zero")
+	}
+
+	var groups []string
+	for _, group := range snap.Groups {
+		groups = append(groups, group.Name+"@"+group.PreferredVersion)
+	}
+
+	if want := []string{"@v1", "autoscaling@v2"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("snapshot groups = %v, want %v", groups, want)
+	}
+}
+
+// TestNewSnapshotEmptyGroupVersion tests that the group versions without resources are kept in the snapshot, and that
+// the snapshot is taken without the server version if it can't be determined.
+func TestNewSnapshotEmptyGroupVersion(t *testing.T) {
+	t.Parallel()
+
+	client := discoverytesting.New()
+
+	version := metav1.GroupVersionForDiscovery{GroupVersion: "stable.example.com/v1", Version: "v1"}
+	client.Groups = append(client.Groups, &metav1.APIGroup{
+		Name:             "stable.example.com",
+		Versions:         []metav1.GroupVersionForDiscovery{version},
+		PreferredVersion: version,
+	})
+	client.Resources = append(client.Resources, &metav1.APIResourceList{GroupVersion: version.GroupVersion})
+
+	client.DiscoveryInterface.(*fake.FakeDiscovery).PrependReactor("get", "version",
+		func(clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewServiceUnavailable("version unavailable")
+		})
+
+	errOut := &bytes.Buffer{}
+
+	snap, err := newSnapshot(t.Context(), client, errOut)
+	if err != nil {
+		t.Fatalf("newSnapshot() error = %v", err)
+	}
+
+	i := slices.IndexFunc(snap.Groups, func(group snapshotGroup) bool { return group.Name == "stable.example.com" })
+	if i < 0 {
+		t.Fatalf("snapshot groups = %v, want stable.example.com", snap.Groups)
+	}
+
+	want := []snapshotVersion{{Version: "v1", Resources: []snapshotResource{}}}
+	if !reflect.DeepEqual(snap.Groups[i].Versions, want) {
+		t.Errorf("snapshot stable.example.com versions = %v, want %v", snap.Groups[i].Versions, want)
+	}
+
+	if snap.ServerVersion != nil {
+		t.Errorf("snapshot serverVersion = %v, want nil", snap.ServerVersion)
+	}
+
+	if !strings.Contains(errOut.String(), "Warning: couldn't get the server version") {
+		t.Errorf("newSnapshot() warnings = %q, want the server version warning", errOut.String())
+	}
+}
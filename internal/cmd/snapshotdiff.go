@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// tableOutput prints the changes as a table.
+	tableOutput = "table"
+	// jsonOutput prints the changes as JSON.
+	jsonOutput = "json"
+	// markdownOutput prints the changes as a Markdown table.
+	markdownOutput = "markdown"
+)
+
+var (
+	// snapshotDiffExample is the example text for the snapshot diff command.
+	//
+	//nolint:gochecknoglobals
+	snapshotDiffExample = `
+		# Compare two snapshots
+		kubectl api-resource-versions snapshot diff before.json after.json
+
+		# Compare a snapshot with the cluster, as a Markdown table for a pull request comment
+		kubectl api-resource-versions snapshot diff before.json --output=markdown`
+)
+
+// snapshotChangeType is the type of the API element which changed between two snapshots.
+type snapshotChangeType string
+
+const (
+	// snapshotChangeGroupVersion is used for the changes of group versions.
+	snapshotChangeGroupVersion snapshotChangeType = "group-version"
+	// snapshotChangeResource is used for the changes of resources.
+	snapshotChangeResource snapshotChangeType = "resource"
+)
+
+// snapshotChangeKind is the kind of change of an API element between two snapshots.
+type snapshotChangeKind string
+
+const (
+	// snapshotAdded is used when the element is only in the new snapshot.
+	snapshotAdded snapshotChangeKind = "added"
+	// snapshotRemoved is used when the element is only in the old snapshot.
+	snapshotRemoved snapshotChangeKind = "removed"
+	// snapshotPreferred is used when the preferred version of a resource flips.
+	snapshotPreferred snapshotChangeKind = "preferred"
+	// snapshotVerbs is used when the verbs of a resource change.
+	snapshotVerbs snapshotChangeKind = "verbs"
+	// snapshotCategories is used when the categories of a resource change.
+	snapshotCategories snapshotChangeKind = "categories"
+)
+
+// snapshotChange is a change of an API element between two snapshots.
+type snapshotChange struct {
+	// Change is the kind of change.
+	Change snapshotChangeKind `json:"change"`
+	// Type is the type of the element.
+	Type snapshotChangeType `json:"type"`
+	// Name is the group version, e.g. "autoscaling/v2", or the resource, e.g. "horizontalpodautoscalers.v2.autoscaling".
+	// The name of a preferred version flip has no version, e.g. "horizontalpodautoscalers.autoscaling".
+	Name string `json:"name"`
+	// Old is the value in the old snapshot, for preferred, verbs, and categories changes.
+	Old string `json:"old,omitempty"`
+	// New is the value in the new snapshot, for preferred, verbs, and categories changes.
+	New string `json:"new,omitempty"`
+}
+
+// newCmdSnapshotDiff returns a command that compares two snapshots, or a snapshot with the cluster.
+func newCmdSnapshotDiff(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newSnapshotDiffOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "diff OLD [NEW]",
+		Short: "Compare two snapshots of the API resource versions",
+		Long: "Report the group versions and resources which are added or removed, the preferred versions which flip, " +
+			"and the verbs and categories which change between two snapshots.\n" +
+			"If only one snapshot is given, it is compared with the cluster.",
+		Example: templates.Examples(snapshotDiffExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runSnapshotDiff(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+
+	return cmd
+}
+
+// snapshotDiffOptions contains the options for the snapshot diff command.
+type snapshotDiffOptions struct {
+	genericiooptions.IOStreams
+
+	Output    string
+	NoHeaders bool
+
+	oldFilename     string
+	newFilename     string
+	contextName     string
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newSnapshotDiffOptions returns a new [snapshotDiffOptions] with default values.
+func newSnapshotDiffOptions(ioStreams genericiooptions.IOStreams) *snapshotDiffOptions {
+	return &snapshotDiffOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the snapshot diff command.
+func (o *snapshotDiffOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	//nolint:mnd
+	if len(args) < 1 || len(args) > 2 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "expected one or two snapshots, got %d arguments", len(args))
+	}
+
+	o.oldFilename = args[0]
+	if len(args) > 1 {
+		o.newFilename = args[1]
+
+		return nil
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+	o.contextName = contextName(restClientGetter)
+
+	return nil
+}
+
+// errReportOutput is returned when the output format is not one of the table, JSON, or Markdown formats.
+const errReportOutput = constError(
+	"output must be one of: (" + tableOutput + ", " + jsonOutput + ", " + markdownOutput + ")")
+
+// validate checks that options are valid for the snapshot diff command.
+func (o *snapshotDiffOptions) validate() error {
+	return validateReportOutput(o.Output)
+}
+
+// validateReportOutput checks that the output format is one of the table, JSON, or Markdown formats.
+func validateReportOutput(output string) error {
+	if !sets.New(tableOutput, jsonOutput, markdownOutput).Has(output) {
+		return fmt.Errorf("%w: %s is not available", errReportOutput, output)
+	}
+
+	return nil
+}
+
+// runSnapshotDiff compares the snapshots and prints the changes.
+func runSnapshotDiff(ctx context.Context, options *snapshotDiffOptions) error {
+	before, err := readSnapshot(options.oldFilename)
+	if err != nil {
+		return err
+	}
+
+	var after *snapshot
+	if options.newFilename != "" {
+		after, err = readSnapshot(options.newFilename)
+	} else {
+		after, err = newSnapshot(ctx, options.discoveryClient, options.ErrOut)
+		if err == nil {
+			after.Context = options.contextName
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return printSnapshotChanges(options.Out, options.Output, options.NoHeaders, newSnapshotsMetadata(before, after),
+		diffSnapshots(before, after))
+}
+
+// errSnapshotVersion is returned when a snapshot has an unsupported schema version.
+const errSnapshotVersion = constError("unsupported snapshot version")
+
+// readSnapshot reads a snapshot saved by the snapshot save command, as JSON or YAML.
+func readSnapshot(filename string) (*snapshot, error) {
+	content, err := os.ReadFile(filename) //nolint:gosec // Reading user-provided snapshots is the purpose of the command.
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read snapshot %s: %w", filename, err)
+	}
+
+	snap := &snapshot{}
+
+	err = k8syaml.Unmarshal(content, snap)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode snapshot %s: %w", filename, err)
+	}
+
+	if snap.APIVersion != snapshotAPIVersion {
+		return nil, fmt.Errorf("%w: %s has version %q, want %q", errSnapshotVersion, filename, snap.APIVersion,
+			snapshotAPIVersion)
+	}
+
+	return snap, nil
+}
+
+// snapshotIndex indexes the group versions and resources of a snapshot.
+type snapshotIndex struct {
+	// groupVersions are the group versions, e.g. "autoscaling/v2".
+	groupVersions sets.Set[string]
+	// resources are the resources by their name, e.g. "horizontalpodautoscalers.v2.autoscaling".
+	resources map[string]snapshotResource
+	// preferredVersions are the preferred versions of the resources by their unversioned name, e.g.
+	// "horizontalpodautoscalers.autoscaling".
+	preferredVersions map[string]string
+}
+
+// newSnapshotIndex indexes the snapshot.
+func newSnapshotIndex(snap *snapshot) *snapshotIndex {
+	index := &snapshotIndex{
+		groupVersions:     sets.New[string](),
+		resources:         make(map[string]snapshotResource),
+		preferredVersions: make(map[string]string),
+	}
+
+	for _, group := range snap.Groups {
+		for _, version := range group.Versions {
+			groupVersion := schema.GroupVersion{Group: group.Name, Version: version.Version}
+			index.groupVersions.Insert(groupVersion.String())
+
+			for _, resource := range version.Resources {
+				index.resources[snapshotResourceName(groupVersion, resource.Name)] = resource
+
+				baseName, subName := splitResourceName(resource.Name)
+				if resource.Preferred && subName == nil {
+					index.preferredVersions[baseName+"."+group.Name] = version.Version
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// snapshotResourceName returns the name of the resource in the group version in the format of
+// [groupResource.fullname].
+func snapshotResourceName(groupVersion schema.GroupVersion, resourceName string) string {
+	baseName, subName := splitResourceName(resourceName)
+
+	name := fmt.Sprintf("%s.%s.%s", baseName, groupVersion.Version, groupVersion.Group)
+	if subName != nil {
+		name = fmt.Sprintf("%s %s", name, *subName)
+	}
+
+	return name
+}
+
+// diffSnapshots returns the changes between the before and after snapshots, sorted by type and name.
+func diffSnapshots(before, after *snapshot) []snapshotChange {
+	oldIndex := newSnapshotIndex(before)
+	newIndex := newSnapshotIndex(after)
+
+	var changes []snapshotChange
+
+	for _, groupVersion := range newIndex.groupVersions.Difference(oldIndex.groupVersions).UnsortedList() {
+		changes = append(changes, snapshotChange{
+			Change: snapshotAdded, Type: snapshotChangeGroupVersion, Name: groupVersion,
+		})
+	}
+
+	for _, groupVersion := range oldIndex.groupVersions.Difference(newIndex.groupVersions).UnsortedList() {
+		changes = append(changes, snapshotChange{
+			Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: groupVersion,
+		})
+	}
+
+	for name, newResource := range newIndex.resources {
+		oldResource, ok := oldIndex.resources[name]
+		if !ok {
+			changes = append(changes, snapshotChange{Change: snapshotAdded, Type: snapshotChangeResource, Name: name})
+
+			continue
+		}
+
+		changes = appendListChange(changes, snapshotVerbs, name, oldResource.Verbs, newResource.Verbs)
+		changes = appendListChange(changes, snapshotCategories, name, oldResource.Categories, newResource.Categories)
+	}
+
+	for name := range oldIndex.resources {
+		if _, ok := newIndex.resources[name]; !ok {
+			changes = append(changes, snapshotChange{Change: snapshotRemoved, Type: snapshotChangeResource, Name: name})
+		}
+	}
+
+	for name, newVersion := range newIndex.preferredVersions {
+		oldVersion, ok := oldIndex.preferredVersions[name]
+		if ok && oldVersion != newVersion {
+			changes = append(changes, snapshotChange{
+				Change: snapshotPreferred, Type: snapshotChangeResource, Name: name, Old: oldVersion, New: newVersion,
+			})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b snapshotChange) int {
+		return cmp.Or(
+			strings.Compare(string(a.Type), string(b.Type)),
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(string(a.Change), string(b.Change)),
+		)
+	})
+
+	return changes
+}
+
+// appendListChange appends a change if the old and new lists have different elements.
+func appendListChange(
+	changes []snapshotChange,
+	change snapshotChangeKind,
+	name string,
+	oldList, newList []string,
+) []snapshotChange {
+	oldSet, newSet := sets.New(oldList...), sets.New(newList...)
+	if oldSet.Equal(newSet) {
+		return changes
+	}
+
+	return append(changes, snapshotChange{
+		Change: change,
+		Type:   snapshotChangeResource,
+		Name:   name,
+		Old:    strings.Join(sets.List(oldSet), ","),
+		New:    strings.Join(sets.List(newSet), ","),
+	})
+}
+
+// printSnapshotChanges prints the changes in the output format, with the metadata of the snapshots in the JSON format.
+func printSnapshotChanges(
+	out io.Writer,
+	output string,
+	noHeaders bool,
+	metadata outputMetadata,
+	changes []snapshotChange,
+) error {
+	switch output {
+	case jsonOutput:
+		return printSnapshotChangesJSON(out, metadata, changes)
+	case markdownOutput:
+		return printSnapshotChangesMarkdown(out, changes)
+	default:
+		return printSnapshotChangesTable(out, changes, noHeaders)
+	}
+}
+
+// snapshotChangeColumns returns the columns of the change in the table and Markdown output formats.
+func snapshotChangeColumns(change snapshotChange) []string {
+	return []string{string(change.Change), string(change.Type), change.Name, change.Old, change.New}
+}
+
+// printSnapshotChangesTable prints the changes as a table.
+func printSnapshotChangesTable(out io.Writer, changes []snapshotChange, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		err := printRow(writer, []string{"CHANGE", "TYPE", "NAME", "OLD", "NEW"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		err := printRow(writer, snapshotChangeColumns(change))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printSnapshotChangesMarkdown prints the changes as a Markdown table.
+func printSnapshotChangesMarkdown(out io.Writer, changes []snapshotChange) error {
+	lines := []string{"| Change | Type | Name | Old | New |", "| --- | --- | --- | --- | --- |"}
+	for _, change := range changes {
+		lines = append(lines, "| "+strings.Join(snapshotChangeColumns(change), " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("couldn't write changes: %w", err)
+	}
+
+	return nil
+}
+
+// printSnapshotChangesJSON prints the changes as a JSON object with the "metadata" and a "changes" list.
+func printSnapshotChangesJSON(out io.Writer, metadata outputMetadata, changes []snapshotChange) error {
+	if changes == nil {
+		changes = []snapshotChange{}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata outputMetadata   `json:"metadata"`
+		Changes  []snapshotChange `json:"changes"`
+	}{Metadata: metadata, Changes: changes})
+	if err != nil {
+		return fmt.Errorf("couldn't write changes: %w", err)
+	}
+
+	return nil
+}
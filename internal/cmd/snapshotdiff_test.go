@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunSnapshotDiff tests comparing snapshots.
+func TestRunSnapshotDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Table", runSnapshotDiffTest{
+		output:      tableOutput,
+		newFilename: "testdata/snapshots/after.json",
+		want: "CHANGE       TYPE            NAME                                           OLD        NEW\n" +
+			"added        group-version   autoscaling/v2                                            \n" +
+			"removed      group-version   autoscaling/v2beta1                                       \n" +
+			"preferred    resource        horizontalpodautoscalers.autoscaling           v1         v2\n" +
+			"categories   resource        horizontalpodautoscalers.v1.autoscaling        all        \n" +
+			"added        resource        horizontalpodautoscalers.v2.autoscaling                   \n" +
+			"removed      resource        horizontalpodautoscalers.v2beta1.autoscaling              \n" +
+			"verbs        resource        pods.v1.                                       get,list   get,list,watch\n" +
+			"added        resource        pods.v1. status                                           \n",
+	}.Test)
+	t.Run("Markdown", runSnapshotDiffTest{
+		output:      markdownOutput,
+		newFilename: "testdata/snapshots/before.json",
+		want:        "| Change | Type | Name | Old | New |\n| --- | --- | --- | --- | --- |\n",
+	}.Test)
+	t.Run("Live", runSnapshotDiffTest{
+		output: jsonOutput,
+		wantChanges: []snapshotChange{
+			{Change: snapshotAdded, Type: snapshotChangeGroupVersion, Name: "autoscaling/v2"},
+			{Change: snapshotRemoved, Type: snapshotChangeGroupVersion, Name: "autoscaling/v2beta1"},
+			{Change: snapshotAdded, Type: snapshotChangeGroupVersion, Name: "autoscaling/v2beta2"},
+		},
+	}.Test)
+	t.Run("InvalidSnapshot", runSnapshotDiffTest{
+		output:      tableOutput,
+		newFilename: "testdata/manifests/list.json",
+		wantErr:     errSnapshotVersion,
+	}.Test)
+}
+
+type runSnapshotDiffTest struct {
+	output      string
+	newFilename string
+	want        string
+	wantChanges []snapshotChange
+	wantErr     error
+}
+
+func (tt runSnapshotDiffTest) Test(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newSnapshotDiffOptions(ioStreams)
+	options.Output = tt.output
+	options.oldFilename = "testdata/snapshots/before.json"
+	options.newFilename = tt.newFilename
+	options.discoveryClient = discoverytesting.New()
+
+	err := runSnapshotDiff(t.Context(), options)
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runSnapshotDiff() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if tt.wantChanges == nil {
+		if got := stdout.String(); got != tt.want {
+			t.Errorf("runSnapshotDiff() output = %q, want %q", got, tt.want)
+		}
+
+		return
+	}
+
+	result := struct {
+		Changes []snapshotChange `json:"changes"`
+	}{}
+
+	err = json.Unmarshal(stdout.Bytes(), &result)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	// Only the group version changes are compared, the resources of the test discovery differ in many ways.
+	var got []snapshotChange
+
+	for _, change := range result.Changes {
+		if change.Type == snapshotChangeGroupVersion {
+			got = append(got, change)
+		}
+	}
+
+	if len(got) != len(tt.wantChanges) {
+		t.Fatalf("runSnapshotDiff() group version changes = %v, want %v", got, tt.wantChanges)
+	}
+
+	for i := range got {
+		if got[i] != tt.wantChanges[i] {
+			t.Errorf("runSnapshotDiff() change %d = %v, want %v", i, got[i], tt.wantChanges[i])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// errStaleOK is returned when --stale-ok is requested without contacting the API server.
+const errStaleOK = constError("stale-ok is not supported with offline, from-dump, or from-file")
+
+// getStaleGroupResources retrieves the API resources from the kubectl discovery cache after the discovery failed,
+// printing a prominent warning that the resources may be stale.
+func getStaleGroupResources(
+	ctx context.Context,
+	options *apiResourceVersionsOptions,
+	discoverErr error,
+) ([]groupResource, error) {
+	staleOptions := *options
+	staleOptions.discoveryClient = options.staleDiscoveryClient
+
+	// The discovery may have failed because the context is done, the cache is read regardless.
+	resources, err := getGroupResources(context.WithoutCancel(ctx), &staleOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w; the discovery cache isn't available either: %w", discoverErr, err)
+	}
+
+	cachedAt := "at an unknown time"
+
+	info, err := os.Stat(filepath.Join(options.staleDiscoveryClient.directory, serverGroupsFilename))
+	if err == nil {
+		cachedAt = fmt.Sprintf("%s ago", time.Since(info.ModTime()).Round(time.Second))
+	}
+
+	_, _ = fmt.Fprintf(options.ErrOut, "WARNING: STALE: couldn't discover the resources: %v\n", discoverErr)
+	_, _ = fmt.Fprintf(options.ErrOut, "WARNING: STALE: showing the resources cached in %s %s\n",
+		options.staleDiscoveryClient.directory, cachedAt)
+
+	return resources, nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestStaleGroupResources tests falling back to the discovery cache when the discovery fails.
+func TestStaleGroupResources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Cached", staleGroupResourcesTest{
+		staleDirectory: "testdata/discovery",
+		wantOutput: "NAME          SHORTNAMES   APIVERSION   NAMESPACED   KIND         PREFERRED\n" +
+			"configmaps    cm           v1           true         ConfigMap    true\n" +
+			"pods          po           v1           true         Pod          true\n" +
+			"deployments   deploy       apps/v1      true         Deployment   true\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("NotCached", staleGroupResourcesTest{
+		staleDirectory: "testdata/nonexistent",
+		wantOutput:     "",
+		wantErr:        errDiscoveryDirectory,
+	}.Test)
+}
+
+type staleGroupResourcesTest struct {
+	staleDirectory string
+	wantOutput     string
+	wantErr        error
+}
+
+func (tt staleGroupResourcesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	// The empty directory fails the discovery like an unreachable API server.
+	builder := NewTestOptionsBuilder().
+		WithDiscoveryClient(newDirectoryDiscoveryClient(t.TempDir())).
+		WithStaleDiscoveryClient(tt.staleDirectory)
+	_, stdout, stderr := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runAPIResourceVersions() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.wantOutput {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, tt.wantOutput)
+	}
+
+	if gotStale := strings.Contains(stderr.String(), "WARNING: STALE:"); gotStale != (tt.wantErr == nil) {
+		t.Errorf("runAPIResourceVersions() stderr = %q, want a STALE warning: %v", stderr.String(), tt.wantErr == nil)
+	}
+}
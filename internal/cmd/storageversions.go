@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
+	"github.com/spf13/cobra"
+	apiserverinternalv1alpha1 "k8s.io/api/apiserverinternal/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	// storageVersionsExample is the example text for the storage-versions command.
+	//
+	//nolint:gochecknoglobals
+	storageVersionsExample = `
+		# Print the storage versions reported by the API servers for every resource
+		kubectl api-resource-versions storage-versions
+
+		# Print only the resources which are not yet encoded in their preferred version
+		kubectl api-resource-versions storage-versions --outdated-only`
+)
+
+// storageVersionsGVR is the group version resource of the StorageVersion API.
+//
+//nolint:gochecknoglobals
+var storageVersionsGVR = apiserverinternalv1alpha1.SchemeGroupVersion.WithResource("storageversions")
+
+// newCmdStorageVersions returns a command that audits the encoding versions reported by the StorageVersion API.
+func newCmdStorageVersions(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newStorageVersionsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "storage-versions",
+		Short: "Compare the storage versions of resources with their preferred versions",
+		Long: "List the versions which the API servers use to encode each resource in etcd, as reported by the " +
+			"StorageVersion API (" + storageVersionsGVR.GroupVersion().String() + "), alongside the preferred version " +
+			"of the resource.\n" +
+			"Resources whose encoding version differs from their preferred version have objects which must be " +
+			"migrated before the older version can be removed.\n" +
+			"The StorageVersion API requires the StorageVersionAPI feature gate and the " +
+			storageVersionsGVR.GroupVersion().String() + " runtime config to be enabled on the API server.",
+		Example: templates.Examples(storageVersionsExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runStorageVersions(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+	cmd.Flags().BoolVar(&options.OutdatedOnly, "outdated-only", options.OutdatedOnly,
+		"Limit to resources which aren't encoded in their preferred version by every API server.")
+
+	return cmd
+}
+
+// storageVersionsOptions contains the options for the storage-versions command.
+type storageVersionsOptions struct {
+	genericiooptions.IOStreams
+
+	NoHeaders    bool
+	OutdatedOnly bool
+
+	discoveryClient discovery.DiscoveryInterface
+	dynamicClient   dynamic.Interface
+}
+
+// newStorageVersionsOptions returns a new [storageVersionsOptions] with default values.
+func newStorageVersionsOptions(ioStreams genericiooptions.IOStreams) *storageVersionsOptions {
+	return &storageVersionsOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the storage-versions command.
+func (o *storageVersionsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// storageVersionReport is the storage version status of a single resource.
+type storageVersionReport struct {
+	// GroupResource is the group and resource name of the resource.
+	GroupResource schema.GroupResource
+	// CommonEncodingVersion is the encoding version shared by all the API servers, if any.
+	CommonEncodingVersion string
+	// EncodingVersions are the distinct encoding versions reported by the API servers, e.g. "apps/v1".
+	EncodingVersions []string
+	// DecodableVersions are the versions which every API server can decode.
+	DecodableVersions []string
+	// PreferredVersion is the preferred group version of the resource, or empty if it's not served.
+	PreferredVersion string
+}
+
+// UpToDate returns true if every API server encodes the resource in its preferred version.
+func (r storageVersionReport) UpToDate() bool {
+	return len(r.EncodingVersions) == 1 && r.EncodingVersions[0] == r.PreferredVersion
+}
+
+// errStorageVersionAPIUnavailable is returned when the StorageVersion API is not served by the cluster.
+const errStorageVersionAPIUnavailable = constError(
+	"the StorageVersion API is not enabled, see --help for the required API server configuration")
+
+// runStorageVersions prints the storage versions of every resource reported by the StorageVersion API.
+func runStorageVersions(ctx context.Context, options *storageVersionsOptions) error {
+	reports, err := getStorageVersionReports(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	if options.OutdatedOnly {
+		filtered := reports[:0]
+
+		for _, report := range reports {
+			if !report.UpToDate() {
+				filtered = append(filtered, report)
+			}
+		}
+
+		reports = filtered
+	}
+
+	return printStorageVersionReports(reports, options)
+}
+
+// getStorageVersionReports lists the StorageVersion objects and joins them with the preferred resource versions.
+func getStorageVersionReports(ctx context.Context, options *storageVersionsOptions) ([]storageVersionReport, error) {
+	list, err := options.dynamicClient.Resource(storageVersionsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %w", errStorageVersionAPIUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("couldn't list storage versions: %w", err)
+	}
+
+	preferredVersions, err := apiresourceversions.ServerPreferredVersions(options.discoveryClient)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get preferred resource versions: %w", err)
+	}
+
+	reports := make([]storageVersionReport, 0, len(list.Items))
+
+	for _, item := range list.Items {
+		storageVersion := &apiserverinternalv1alpha1.StorageVersion{}
+
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, storageVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't convert storage version %s: %w", item.GetName(), err)
+		}
+
+		reports = append(reports, newStorageVersionReport(storageVersion, preferredVersions))
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		left, right := reports[i].GroupResource, reports[j].GroupResource
+		if left.Group != right.Group {
+			return left.Group < right.Group
+		}
+
+		return left.Resource < right.Resource
+	})
+
+	return reports, nil
+}
+
+// newStorageVersionReport creates a [storageVersionReport] from the StorageVersion object, using the preferred
+// versions in the format returned by [apiresourceversions.ServerPreferredVersions].
+func newStorageVersionReport(
+	storageVersion *apiserverinternalv1alpha1.StorageVersion,
+	preferredVersions map[schema.GroupResource]string,
+) storageVersionReport {
+	groupResource := storageVersionGroupResource(storageVersion.Name)
+
+	report := storageVersionReport{GroupResource: groupResource}
+
+	if storageVersion.Status.CommonEncodingVersion != nil {
+		report.CommonEncodingVersion = *storageVersion.Status.CommonEncodingVersion
+	}
+
+	encodingVersions := sets.New[string]()
+
+	var decodableVersions sets.Set[string]
+
+	for _, serverStorageVersion := range storageVersion.Status.StorageVersions {
+		encodingVersions.Insert(serverStorageVersion.EncodingVersion)
+
+		if decodableVersions == nil {
+			decodableVersions = sets.New(serverStorageVersion.DecodableVersions...)
+		} else {
+			decodableVersions = decodableVersions.Intersection(sets.New(serverStorageVersion.DecodableVersions...))
+		}
+	}
+
+	report.EncodingVersions = sets.List(encodingVersions)
+	report.DecodableVersions = sets.List(decodableVersions)
+
+	if version, ok := preferredVersions[groupResource]; ok {
+		report.PreferredVersion = schema.GroupVersion{Group: groupResource.Group, Version: version}.String()
+	}
+
+	return report
+}
+
+// storageVersionGroupResource parses the name of a StorageVersion object, which is in the format
+// "<group>.<resource>".
+// The core group may be represented either by an empty group or by "core".
+func storageVersionGroupResource(name string) schema.GroupResource {
+	group, resource := "", name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		group, resource = name[:i], name[i+1:]
+	}
+
+	if group == "core" {
+		group = ""
+	}
+
+	return schema.GroupResource{Group: group, Resource: resource}
+}
+
+// printStorageVersionReports prints the storage version reports as a table.
+func printStorageVersionReports(reports []storageVersionReport, options *storageVersionsOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	if !options.NoHeaders {
+		err := printRow(writer, []string{"NAME", "ENCODINGVERSIONS", "DECODABLEVERSIONS", "PREFERREDVERSION", "UPTODATE"})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, report := range reports {
+		err := printStorageVersionReport(writer, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printStorageVersionReport prints a single storage version report as a table row.
+func printStorageVersionReport(writer io.Writer, report storageVersionReport) error {
+	return printRow(writer, []string{
+		report.GroupResource.String(),
+		strings.Join(report.EncodingVersions, ","),
+		strings.Join(report.DecodableVersions, ","),
+		report.PreferredVersion,
+		strconv.FormatBool(report.UpToDate()),
+	})
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apiserverinternalv1alpha1 "k8s.io/api/apiserverinternal/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newStorageVersion returns a new StorageVersion object reported by one API server per encoding version.
+func newStorageVersion(name string, encodingVersions ...string) runtime.Object {
+	storageVersion := &apiserverinternalv1alpha1.StorageVersion{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiserverinternalv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "StorageVersion",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+
+	for i, encodingVersion := range encodingVersions {
+		storageVersion.Status.StorageVersions = append(storageVersion.Status.StorageVersions,
+			apiserverinternalv1alpha1.ServerStorageVersion{
+				APIServerID:       "apiserver-" + string(rune('a'+i)),
+				EncodingVersion:   encodingVersion,
+				DecodableVersions: []string{encodingVersion, "autoscaling/v1"},
+			})
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(storageVersion)
+	if err != nil {
+		panic(err)
+	}
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+// newStorageVersionsTestOptions returns [storageVersionsOptions] serving the given StorageVersion objects.
+func newStorageVersionsTestOptions(objects ...runtime.Object) *storageVersionsOptions {
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	options := newStorageVersionsOptions(ioStreams)
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{storageVersionsGVR: "StorageVersionList"},
+		objects...,
+	)
+
+	return options
+}
+
+// TestGetStorageVersionReports tests joining StorageVersion objects with the preferred resource versions.
+func TestGetStorageVersionReports(t *testing.T) {
+	t.Parallel()
+
+	options := newStorageVersionsTestOptions(
+		newStorageVersion("autoscaling.horizontalpodautoscalers", "autoscaling/v1", "autoscaling/v2"),
+		newStorageVersion("core.pods", "v1"),
+	)
+
+	got, err := getStorageVersionReports(t.Context(), options)
+	if err != nil {
+		t.Fatalf("getStorageVersionReports() error = %v", err)
+	}
+
+	want := []storageVersionReport{
+		{
+			GroupResource:     schema.GroupResource{Resource: "pods"},
+			EncodingVersions:  []string{"v1"},
+			DecodableVersions: []string{"autoscaling/v1", "v1"},
+			PreferredVersion:  "v1",
+		},
+		{
+			GroupResource:     schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"},
+			EncodingVersions:  []string{"autoscaling/v1", "autoscaling/v2"},
+			DecodableVersions: []string{"autoscaling/v1"},
+			PreferredVersion:  "autoscaling/v2",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getStorageVersionReports() = %#v, want %#v", got, want)
+	}
+
+	if !got[0].UpToDate() {
+		t.Errorf("UpToDate() for %s = false, want true", got[0].GroupResource)
+	}
+
+	if got[1].UpToDate() {
+		t.Errorf("UpToDate() for %s = true, want false", got[1].GroupResource)
+	}
+}
+
+// TestGetStorageVersionReportsUnavailable tests the error returned when the StorageVersion API isn't served.
+func TestGetStorageVersionReportsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	options := newStorageVersionsTestOptions()
+
+	dynamicClient, _ := options.dynamicClient.(*dynamicfake.FakeDynamicClient)
+	dynamicClient.PrependReactor("list", "storageversions",
+		func(clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(storageVersionsGVR.GroupResource(), "")
+		})
+
+	_, err := getStorageVersionReports(t.Context(), options)
+	if !errors.Is(err, errStorageVersionAPIUnavailable) {
+		t.Errorf("getStorageVersionReports() error = %v, want %v", err, errStorageVersionAPIUnavailable)
+	}
+}
+
+// TestStorageVersionGroupResource tests parsing the names of StorageVersion objects.
+func TestStorageVersionGroupResource(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]schema.GroupResource{
+		"core.pods":                           {Resource: "pods"},
+		".pods":                               {Resource: "pods"},
+		"apps.deployments":                    {Group: "apps", Resource: "deployments"},
+		"networking.k8s.io.ingresses":         {Group: "networking.k8s.io", Resource: "ingresses"},
+		"example.com.widgets":                 {Group: "example.com", Resource: "widgets"},
+		"apiextensions.k8s.io.customresource": {Group: "apiextensions.k8s.io", Resource: "customresource"},
+	}
+
+	for name, want := range tests {
+		if got := storageVersionGroupResource(name); got != want {
+			t.Errorf("storageVersionGroupResource(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// errStream is returned when --stream is requested with an option which requires all the resources to be discovered
+// before they are printed.
+const errStream = constError(
+	"stream is not supported with sort-by, show-counts, empty-only, non-empty-only, output=counts, show-usage, " +
+		"show-group-priority, watch, compare-release, all-contexts, contexts, or clusters-file")
+
+// validateStream checks that --stream isn't requested with an option which requires all the resources to be
+// discovered before they are printed.
+func (o *apiResourceVersionsOptions) validateStream() error {
+	if !o.Stream {
+		return nil
+	}
+
+	if o.SortBy != "" || o.countsRequired() || o.ShowUsage || o.ShowGroupPriority || o.Watch || o.CompareRelease != "" ||
+		len(o.clusters) > 0 || o.AllContexts || len(o.Contexts) > 0 || o.ClustersFile != "" {
+		return errStream
+	}
+
+	return nil
+}
+
+// runStreamAPIResourceVersions lists the API resources like [runAPIResourceVersions], but prints the resources of
+// each group version as soon as they are discovered, in the discovery order instead of sorted.
+// The columns are aligned for each group version, as the widths of the following ones aren't known yet.
+func runStreamAPIResourceVersions(ctx context.Context, options *apiResourceVersionsOptions) error {
+	writer := printers.GetNewTabWriter(options.Out)
+	defer mustFlushWriter(writer)
+
+	printedHeaders := options.NoHeaders || options.namesOnly()
+	columns := make([]string, 0, maxRowColumns)
+
+	streamOptions := *options
+	streamOptions.streamResources = func(resources []groupResource) error {
+		if len(resources) == 0 {
+			return nil
+		}
+
+		if !printedHeaders {
+			err := printHeaders(writer, options)
+			if err != nil {
+				return err
+			}
+
+			printedHeaders = true
+		}
+
+		for _, resource := range resources {
+			var err error
+
+			columns, err = printGroupResource(writer, columns, resource, options)
+			if err != nil {
+				return err
+			}
+		}
+
+		//nolint:wrapcheck
+		return writer.Flush()
+	}
+
+	resources, err := listGroupResources(ctx, &streamOptions)
+	if err != nil {
+		return err
+	}
+
+	if len(resources) == 0 && !options.namesOnly() {
+		return errNoResourcesFound
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRunStreamAPIResourceVersions tests printing the resources of each group version as soon as they are discovered.
+func TestRunStreamAPIResourceVersions(t *testing.T) {
+	t.Parallel()
+
+	// The columns are aligned for each group version.
+	t.Run("Default", streamTest{
+		builder: NewTestOptionsBuilder().SetAPIGroup("autoscaling"),
+		wantOutput: "NAME                       SHORTNAMES   APIVERSION       " +
+			"NAMESPACED   KIND                      PREFERRED\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v1   true         HorizontalPodAutoscaler   false\n" +
+			"horizontalpodautoscalers   hpa          autoscaling/v2beta2   true         HorizontalPodAutoscaler   false\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("Name", streamTest{
+		builder: NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(nameOutput),
+		wantOutput: "horizontalpodautoscalers.v2.autoscaling\n" +
+			"horizontalpodautoscalers.v1.autoscaling\n" +
+			"horizontalpodautoscalers.v2beta2.autoscaling\n",
+		wantErr: nil,
+	}.Test)
+	t.Run("NoResources", streamTest{
+		builder:    NewTestOptionsBuilder().SetAPIGroup("nonexistent"),
+		wantOutput: "",
+		wantErr:    errNoResourcesFound,
+	}.Test)
+}
+
+type streamTest struct {
+	builder    *APIResourceVersionsOptionsBuilder
+	wantOutput string
+	wantErr    error
+}
+
+func (tt streamTest) Test(t *testing.T) {
+	t.Parallel()
+
+	_, stdout, _ := tt.builder.GetBuffers()
+
+	err := runStreamAPIResourceVersions(t.Context(), tt.builder.SetStream(true).APIResourceVersionsOptions())
+	if !errors.Is(err, tt.wantErr) {
+		t.Fatalf("runStreamAPIResourceVersions() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if got := stdout.String(); got != tt.wantOutput {
+		t.Errorf("runStreamAPIResourceVersions() output = %q, want %q", got, tt.wantOutput)
+	}
+}
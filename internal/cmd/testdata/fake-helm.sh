@@ -0,0 +1,11 @@
+#!/bin/sh
+# Fake Helm binary which prints a manifest recording the arguments it was called with.
+cat <<MANIFEST
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: helm-args
+  annotations:
+    args: "$*"
+MANIFEST
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/metrics"
+)
+
+// clientSideThrottlingThreshold is the minimum time a request waits for the client-side rate limiter to be reported
+// as throttled, like client-go does in its logs.
+const clientSideThrottlingThreshold = 50 * time.Millisecond
+
+// errMaxWait is returned when the --max-wait value is negative.
+const errMaxWait = constError("max-wait must not be negative")
+
+//nolint:gochecknoglobals
+var (
+	// registerThrottlingMetrics registers the client-go metrics observing the client-side throttling once, as they
+	// can only be registered once per process.
+	registerThrottlingMetrics sync.Once
+	// activeThrottleMonitor is the monitor to which the client-side throttling is reported, if any.
+	activeThrottleMonitor atomic.Pointer[throttleMonitor]
+)
+
+// throttleMonitor monitors the requests delayed by the client-side rate limiter, and the responses of the API server
+// asking the client to retry later, e.g. 429 Too Many Requests from API Priority and Fairness, so that the users
+// understand why the discovery is slow rather than the command appearing hung.
+// The responses asking to retry are reported as they are received, and the throttling is summarized after the output.
+type throttleMonitor struct {
+	errOut io.Writer
+	// maxWait is the maximum time a request is retried after, 0 for no maximum, see [throttleRoundTripper].
+	maxWait time.Duration
+
+	mutex sync.Mutex
+	// throttledRequests is the number of requests delayed by the client-side rate limiter, for a total of
+	// throttledTime.
+	throttledRequests int
+	throttledTime     time.Duration
+	// retryAfterResponses is the number of responses asking to retry the request later.
+	retryAfterResponses int
+}
+
+// newThrottleMonitor returns a new [throttleMonitor] writing to errOut, and reports the client-side throttling of the
+// process to it.
+func newThrottleMonitor(errOut io.Writer, maxWait time.Duration) *throttleMonitor {
+	monitor := &throttleMonitor{errOut: errOut, maxWait: maxWait}
+
+	registerThrottlingMetrics.Do(func() {
+		metrics.Register(metrics.RegisterOpts{RateLimiterLatency: rateLimiterLatencyMetric{}})
+	})
+	activeThrottleMonitor.Store(monitor)
+
+	return monitor
+}
+
+// rateLimiterLatencyMetric reports the time waited for the client-side rate limiter to the [activeThrottleMonitor].
+type rateLimiterLatencyMetric struct{}
+
+// Observe implements [metrics.LatencyMetric].
+func (rateLimiterLatencyMetric) Observe(_ context.Context, _ string, _ url.URL, latency time.Duration) {
+	activeThrottleMonitor.Load().observeClientSideThrottling(latency)
+}
+
+// observeClientSideThrottling records the time a request waited for the client-side rate limiter, if it was
+// throttled.
+// Latencies observed by a nil monitor are dropped.
+func (m *throttleMonitor) observeClientSideThrottling(latency time.Duration) {
+	if m == nil || latency < clientSideThrottlingThreshold {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.throttledRequests++
+	m.throttledTime += latency
+}
+
+// observeRetryAfter reports a response of the API server asking to retry the request after a delay, and returns
+// whether the request may be retried within --max-wait.
+func (m *throttleMonitor) observeRetryAfter(request *http.Request, status string, retryAfter time.Duration) bool {
+	m.mutex.Lock()
+	m.retryAfterResponses++
+	m.mutex.Unlock()
+
+	if m.maxWait > 0 && retryAfter > m.maxWait {
+		_, _ = fmt.Fprintf(m.errOut,
+			"Warning: the API server responded to %s %s with %s, asking to retry after %s, which exceeds --max-wait\n",
+			request.Method, request.URL.Path, status, retryAfter)
+
+		return false
+	}
+
+	_, _ = fmt.Fprintf(m.errOut, "Warning: the API server responded to %s %s with %s, retrying after %s\n",
+		request.Method, request.URL.Path, status, retryAfter)
+
+	return true
+}
+
+// wrapConfig returns a function installing the monitor in the transport of the REST configs, after applying the
+// previous wrapConfig function if it isn't nil, for [genericclioptions.ConfigFlags.WrapConfigFn].
+func (m *throttleMonitor) wrapConfig(
+	wrapConfig func(*restclient.Config) *restclient.Config,
+) func(*restclient.Config) *restclient.Config {
+	return func(config *restclient.Config) *restclient.Config {
+		if wrapConfig != nil {
+			config = wrapConfig(config)
+		}
+
+		config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+			return &throttleRoundTripper{monitor: m, next: next}
+		})
+
+		return config
+	}
+}
+
+// print prints a warning summarizing the throttling of the requests, if any were throttled.
+func (m *throttleMonitor) print(writer io.Writer) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.throttledRequests > 0 {
+		_, _ = fmt.Fprintf(writer, "Warning: %d requests waited %s in total due to client-side throttling\n",
+			m.throttledRequests, m.throttledTime.Round(time.Millisecond))
+	}
+
+	if m.retryAfterResponses > 0 {
+		_, _ = fmt.Fprintf(writer, "Warning: the API server asked to retry %d requests later, e.g. due to API "+
+			"Priority and Fairness\n", m.retryAfterResponses)
+	}
+}
+
+// throttleRoundTripper reports the responses asking to retry the request later, which client-go retries after the
+// delay of their Retry-After header, to the monitor.
+// The Retry-After header of the responses asking to retry after more than --max-wait is removed, so that the request
+// fails immediately rather than waiting.
+type throttleRoundTripper struct {
+	monitor *throttleMonitor
+	next    http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (rt *throttleRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := rt.next.RoundTrip(request)
+	if err != nil {
+		//nolint:wrapcheck
+		return response, err
+	}
+
+	// client-go only retries the 429 and 5xx responses, after a delay in seconds.
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode < http.StatusInternalServerError {
+		return response, nil
+	}
+
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return response, nil //nolint:nilerr // Responses without a valid Retry-After header aren't retried.
+	}
+
+	if !rt.monitor.observeRetryAfter(request, response.Status, time.Duration(seconds)*time.Second) {
+		response.Header.Del("Retry-After")
+	}
+
+	return response, nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+)
+
+// TestThrottleMonitor tests reporting the responses of the API server asking to retry the requests later.
+func TestThrottleMonitor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Retried", throttleMonitorTest{
+		retryAfter: "0",
+		wantOutput: "Warning: the API server responded to GET /version with 429 Too Many Requests, retrying after 0s\n",
+		wantSummary: "Warning: the API server asked to retry 1 requests later, e.g. due to API Priority and " +
+			"Fairness\n",
+	}.Test)
+	t.Run("ExceedsMaxWait", throttleMonitorTest{
+		retryAfter: "60",
+		maxWait:    time.Second,
+		wantErr:    true,
+		wantOutput: "Warning: the API server responded to GET /version with 429 Too Many Requests, asking to " +
+			"retry after 1m0s, which exceeds --max-wait\n",
+		wantSummary: "Warning: the API server asked to retry 1 requests later, e.g. due to API Priority and " +
+			"Fairness\n",
+	}.Test)
+	t.Run("NoRetryAfter", throttleMonitorTest{
+		wantErr: true,
+	}.Test)
+}
+
+type throttleMonitorTest struct {
+	retryAfter  string
+	maxWait     time.Duration
+	wantErr     bool
+	wantOutput  string
+	wantSummary string
+}
+
+func (tt throttleMonitorTest) Test(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major": "1", "minor": "33", "gitVersion": "v1.33.0"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	var output bytes.Buffer
+
+	monitor := &throttleMonitor{errOut: &output, maxWait: tt.maxWait}
+	config := monitor.wrapConfig(nil)(&restclient.Config{Host: server.URL})
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = discoveryClient.ServerVersion()
+	if tt.wantErr {
+		if !apierrors.IsTooManyRequests(err) {
+			t.Errorf("ServerVersion() error = %v, want TooManyRequests", err)
+		}
+	} else if err != nil {
+		t.Errorf("ServerVersion() error = %v", err)
+	}
+
+	if output.String() != tt.wantOutput {
+		t.Errorf("output = %q, want %q", output.String(), tt.wantOutput)
+	}
+
+	var summary bytes.Buffer
+
+	monitor.print(&summary)
+
+	if summary.String() != tt.wantSummary {
+		t.Errorf("print() = %q, want %q", summary.String(), tt.wantSummary)
+	}
+}
+
+// TestThrottleMonitorClientSide tests summarizing the requests delayed by the client-side rate limiter.
+func TestThrottleMonitorClientSide(t *testing.T) {
+	t.Parallel()
+
+	monitor := &throttleMonitor{}
+	monitor.observeClientSideThrottling(time.Millisecond)
+	monitor.observeClientSideThrottling(time.Second)
+	monitor.observeClientSideThrottling(500 * time.Millisecond)
+
+	var summary bytes.Buffer
+
+	monitor.print(&summary)
+
+	want := "Warning: 2 requests waited 1.5s in total due to client-side throttling\n"
+	if summary.String() != want {
+		t.Errorf("print() = %q, want %q", summary.String(), want)
+	}
+
+	var nilMonitor *throttleMonitor
+
+	nilMonitor.observeClientSideThrottling(time.Second)
+	nilMonitor.print(&summary)
+
+	if strings.Count(summary.String(), "\n") != 1 {
+		t.Errorf("print() of a nil monitor = %q, want none", strings.TrimPrefix(summary.String(), want))
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// errTimeout is the cause of the context when the --timeout is exceeded.
+const errTimeout = constError("timeout exceeded")
+
+// errNegativeTimeout is returned when the timeout is negative.
+const errNegativeTimeout = constError("timeout must not be negative")
+
+// withTimeout returns a context which is canceled after the timeout, or the context itself if the timeout is 0.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w: %s", errTimeout, timeout))
+}
+
+// applyRequestTimeout sets the timeout of each request of the clients to the timeout, unless --request-timeout is set,
+// so that the requests which would have exceeded the timeout don't keep running in the background.
+// It must be called before the clients are created.
+func applyRequestTimeout(configFlags *genericclioptions.ConfigFlags, timeout time.Duration) {
+	if timeout <= 0 || configFlags.Timeout == nil || (*configFlags.Timeout != "" && *configFlags.Timeout != "0") {
+		return
+	}
+
+	requestTimeout := timeout.String()
+	configFlags.Timeout = &requestTimeout
+}
+
+// callWithContext returns the result of the call, or the cause of the context if it is done first.
+// The discovery client doesn't support contexts, so the call keeps running in the background in the latter case.
+func callWithContext[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	if ctx.Done() == nil {
+		// The context is never done.
+		return call()
+	}
+
+	results := make(chan result, 1)
+
+	go func() {
+		value, err := call()
+		results <- result{value, err}
+	}()
+
+	select {
+	case res := <-results:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero T
+
+		return zero, context.Cause(ctx)
+	}
+}
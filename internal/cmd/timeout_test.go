@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+// hangingDiscoveryClient is a fake discovery client whose discovery of the groups and resources hangs until released,
+// like a wedged aggregated API server.
+type hangingDiscoveryClient struct {
+	*cmdtesting.FakeCachedDiscoveryClient
+
+	released chan struct{}
+}
+
+func (c *hangingDiscoveryClient) ServerGroupsAndResources() (
+	[]*metav1.APIGroup,
+	[]*metav1.APIResourceList,
+	error,
+) {
+	<-c.released
+
+	return c.FakeCachedDiscoveryClient.ServerGroupsAndResources()
+}
+
+// TestListGroupResourcesTimeout tests that the discovery is abandoned once the timeout is exceeded.
+func TestListGroupResourcesTimeout(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := &hangingDiscoveryClient{
+		FakeCachedDiscoveryClient: discoverytesting.New(),
+		released:                  make(chan struct{}),
+	}
+	t.Cleanup(func() { close(discoveryClient.released) })
+
+	options := NewTestOptionsBuilder().WithDiscoveryClient(discoveryClient).SetTimeout(10 * time.Millisecond).
+		APIResourceVersionsOptions()
+
+	_, err := listGroupResources(t.Context(), options)
+	if !errors.Is(err, errTimeout) {
+		t.Errorf("listGroupResources() error = %v, want %v", err, errTimeout)
+	}
+}
+
+// TestCallWithContext tests returning the result of the call, or the cause of the context if it is done first.
+func TestCallWithContext(t *testing.T) {
+	t.Parallel()
+
+	got, err := callWithContext(t.Context(), func() (int, error) { return 1, nil })
+	if got != 1 || err != nil {
+		t.Errorf("callWithContext() = %d, %v, want 1, nil", got, err)
+	}
+
+	ctx, cancel := context.WithCancelCause(t.Context())
+	cancel(errTimeout)
+
+	released := make(chan struct{})
+	defer close(released)
+
+	_, err = callWithContext(ctx, func() (int, error) {
+		<-released
+
+		return 1, nil
+	})
+	if !errors.Is(err, errTimeout) {
+		t.Errorf("callWithContext() error = %v, want %v", err, errTimeout)
+	}
+}
+
+// TestApplyRequestTimeout tests bounding the requests by the timeout, unless --request-timeout is set.
+func TestApplyRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unset", applyRequestTimeoutTest{requestTimeout: "0", timeout: 30 * time.Second, want: "30s"}.Test)
+	t.Run("Set", applyRequestTimeoutTest{requestTimeout: "5s", timeout: 30 * time.Second, want: "5s"}.Test)
+	t.Run("NoTimeout", applyRequestTimeoutTest{requestTimeout: "0", timeout: 0, want: "0"}.Test)
+}
+
+type applyRequestTimeoutTest struct {
+	requestTimeout string
+	timeout        time.Duration
+	want           string
+}
+
+func (tt applyRequestTimeoutTest) Test(t *testing.T) {
+	t.Parallel()
+
+	configFlags := genericclioptions.NewConfigFlags(false)
+	configFlags.Timeout = &tt.requestTimeout
+
+	applyRequestTimeout(configFlags, tt.timeout)
+
+	if got := *configFlags.Timeout; got != tt.want {
+		t.Errorf("applyRequestTimeout() request timeout = %q, want %q", got, tt.want)
+	}
+}
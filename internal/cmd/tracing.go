@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	restclient "k8s.io/client-go/rest"
+)
+
+const (
+	// otelEndpointFlag is the name of the flag setting the OTLP endpoint to which the spans are exported.
+	otelEndpointFlag = "otel-endpoint"
+	// otlpTracesPath is the path of the traces on an OTLP/HTTP endpoint.
+	otlpTracesPath = "/v1/traces"
+	// otelServiceName is the default service.name of the spans, overridden by OTEL_SERVICE_NAME.
+	otelServiceName = "kubectl-api-resource-versions"
+	// otelScopeName is the name of the instrumentation scope of the spans.
+	otelScopeName = "github.com/Izzette/kubectl-api-resource-versions"
+	// otelShutdownTimeout is the timeout of the export of the remaining spans when the command finishes.
+	otelShutdownTimeout = 10 * time.Second
+)
+
+// otelEndpointEnvs are the environment variables of the OTLP exporter which enable the tracing without
+// --otel-endpoint.
+//
+//nolint:gochecknoglobals
+var otelEndpointEnvs = []string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"}
+
+// errOTelEndpoint is returned when the --otel-endpoint value isn't an absolute HTTP or HTTPS URL.
+const errOTelEndpoint = constError("otel-endpoint must be an http or https URL, e.g. http://localhost:4318")
+
+// addTracingFlag adds the --otel-endpoint flag to the flag set.
+func addTracingFlag(flags *pflag.FlagSet) {
+	flags.String(otelEndpointFlag, "",
+		"OTLP/HTTP endpoint, e.g. http://localhost:4318, to which OpenTelemetry spans of the discovery, the "+
+			"enrichment, the requests to the API server, and the printing are exported. The tracing is also enabled "+
+			"by OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, and the exporter is configured by "+
+			"the other OTEL_EXPORTER_OTLP_* and OTEL_SERVICE_NAME environment variables.")
+}
+
+// tracerContextKey is the key of the [tracer] in the context.
+type tracerContextKey struct{}
+
+// tracer is the tracer provider of the command, whose spans are exported in batches to an OTLP endpoint and flushed
+// once the command finishes.
+type tracer struct {
+	provider *sdktrace.TracerProvider
+	root     trace.Span
+	finished sync.Once
+}
+
+// startTracing starts the root span of the command in its context if --otel-endpoint or the OTLP endpoint environment
+// variables are set, and traces the requests of the clients created from the config flags.
+func startTracing(cmd *cobra.Command, configFlags *genericclioptions.ConfigFlags) error {
+	exporterOptions, enabled, err := otlpExporterOptions(cmd)
+	if err != nil || !enabled {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	// The exporter retries the failed exports, and doesn't connect until the first batch is exported.
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return fmt.Errorf("couldn't create the OTLP exporter: %w", err)
+	}
+
+	// The attributes of OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service.name.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", otelServiceName)),
+		resource.WithFromEnv())
+	if err != nil {
+		return fmt.Errorf("couldn't read the OpenTelemetry resource: %w", err)
+	}
+
+	t := &tracer{provider: sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))}
+
+	ctx, t.root = t.provider.Tracer(otelScopeName, trace.WithInstrumentationVersion(getBuildInfo().Version)).
+		Start(ctx, cmd.CommandPath())
+	cmd.SetContext(context.WithValue(ctx, tracerContextKey{}, t))
+
+	configFlags.WrapConfigFn = t.wrapConfig(configFlags.WrapConfigFn)
+
+	return nil
+}
+
+// otlpExporterOptions returns the options of the OTLP exporter, and whether the tracing is enabled by --otel-endpoint
+// or the environment variables, which the exporter reads itself.
+func otlpExporterOptions(cmd *cobra.Command) ([]otlptracehttp.Option, bool, error) {
+	flag := cmd.Flag(otelEndpointFlag)
+	if flag == nil || flag.Value.String() == "" {
+		for _, env := range otelEndpointEnvs {
+			if os.Getenv(env) != "" {
+				return nil, true, nil
+			}
+		}
+
+		return nil, false, nil
+	}
+
+	endpoint, err := url.Parse(flag.Value.String())
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, false, invalidArgument(fmt.Errorf("%w: got %s", errOTelEndpoint, flag.Value.String()))
+	}
+
+	// The flag takes precedence over the endpoint environment variables, like the OTLP exporter options.
+	endpointURL := strings.TrimSuffix(endpoint.String(), "/") + otlpTracesPath
+
+	return []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpointURL)}, true, nil
+}
+
+// finishTracing ends the root span of the command with its error, and exports the remaining spans, once.
+// A failed export is reported as a warning, as it doesn't affect the result of the command.
+func finishTracing(cmd *cobra.Command, err error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		return
+	}
+
+	t, ok := ctx.Value(tracerContextKey{}).(*tracer)
+	if !ok {
+		return
+	}
+
+	t.finished.Do(func() {
+		endSpan(t.root, err)
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), otelShutdownTimeout)
+		defer cancel()
+
+		shutdownErr := t.provider.Shutdown(shutdownCtx)
+		if shutdownErr != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: couldn't export the spans: %v\n", shutdownErr)
+		}
+	})
+}
+
+// startSpan starts a span as a child of the current span of the context, and returns the context of the span.
+// Without tracing, the span is a non-recording span which ignores all operations, so that the code doesn't need to
+// check whether tracing is enabled.
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().
+		Tracer(otelScopeName, trace.WithInstrumentationVersion(getBuildInfo().Version)).
+		Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan ends the span, failed if err isn't nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// wrapConfig returns a function tracing the requests of the REST configs, after applying the previous wrapConfig
+// function if it isn't nil, for [genericclioptions.ConfigFlags.WrapConfigFn].
+// The trace context is propagated to the API server, which may record its own spans of the requests.
+func (t *tracer) wrapConfig(
+	wrapConfig func(*restclient.Config) *restclient.Config,
+) func(*restclient.Config) *restclient.Config {
+	return func(config *restclient.Config) *restclient.Config {
+		if wrapConfig != nil {
+			config = wrapConfig(config)
+		}
+
+		config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+			return &tracingRoundTripper{
+				root: t.root,
+				next: otelhttp.NewTransport(next,
+					otelhttp.WithTracerProvider(t.provider),
+					otelhttp.WithPropagators(propagation.TraceContext{})),
+			}
+		})
+
+		return config
+	}
+}
+
+// tracingRoundTripper parents the spans of the requests without a span in their context to the root span of the
+// command, as the discovery client doesn't take a context.
+type tracingRoundTripper struct {
+	root trace.Span
+	next http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *tracingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !trace.SpanContextFromContext(request.Context()).IsValid() {
+		request = request.WithContext(trace.ContextWithSpan(request.Context(), r.root))
+	}
+
+	//nolint:wrapcheck
+	return r.next.RoundTrip(request)
+}
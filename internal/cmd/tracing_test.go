@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	restclient "k8s.io/client-go/rest"
+)
+
+// newTracingTestCommand returns a command with the --otel-endpoint flag set to the endpoint.
+func newTracingTestCommand(t *testing.T, endpoint string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{Use: "api-resource-versions"}
+	addTracingFlag(cmd.PersistentFlags())
+	cmd.SetContext(t.Context())
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.PersistentFlags().Set(otelEndpointFlag, endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cmd
+}
+
+// TestTracing tests exporting the spans of the command to the OTLP endpoint, with their parents and errors.
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	paths := make(chan string, 1)
+	bodies := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		paths <- r.URL.Path
+		bodies <- body
+	}))
+	t.Cleanup(server.Close)
+
+	cmd := newTracingTestCommand(t, server.URL+"/")
+
+	err := startTracing(cmd, genericclioptions.NewConfigFlags(false))
+	if err != nil {
+		t.Fatalf("startTracing() error = %v", err)
+	}
+
+	ctx, discoverySpan := startSpan(cmd.Context(), "discover resources")
+	_, versionSpan := startSpan(ctx, "discover group version", attribute.String("k8s.group_version", "apps/v1"))
+	endSpan(versionSpan, errors.New("forbidden"))
+	endSpan(discoverySpan, nil)
+
+	finishTracing(cmd, nil)
+	// The spans are exported once, even if the command finishes twice, e.g. failing after its post run.
+	finishTracing(cmd, errors.New("failed"))
+
+	if path := <-paths; path != otlpTracesPath {
+		t.Errorf("export path = %s, want %s", path, otlpTracesPath)
+	}
+
+	var exported coltracepb.ExportTraceServiceRequest
+
+	err = proto.Unmarshal(<-bodies, &exported)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	resourceSpans := exported.GetResourceSpans()[0]
+	if serviceName := resourceAttribute(resourceSpans, "service.name"); serviceName != otelServiceName {
+		t.Errorf("service.name = %s, want %s", serviceName, otelServiceName)
+	}
+
+	spans := make(map[string]*tracepb.Span)
+
+	for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+		for _, span := range scopeSpans.GetSpans() {
+			spans[span.GetName()] = span
+		}
+	}
+
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+
+	root, discovery, version := spans["api-resource-versions"], spans["discover resources"],
+		spans["discover group version"]
+
+	if len(root.GetParentSpanId()) != 0 || root.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("root span = %v, want api-resource-versions without parent nor error", root)
+	}
+
+	if !bytes.Equal(discovery.GetParentSpanId(), root.GetSpanId()) {
+		t.Errorf("discover resources parent = %x, want %x", discovery.GetParentSpanId(), root.GetSpanId())
+	}
+
+	if !bytes.Equal(version.GetParentSpanId(), discovery.GetSpanId()) {
+		t.Errorf("discover group version parent = %x, want %x", version.GetParentSpanId(), discovery.GetSpanId())
+	}
+
+	if status := version.GetStatus(); status.GetCode() != tracepb.Status_STATUS_CODE_ERROR ||
+		status.GetMessage() != "forbidden" {
+		t.Errorf("discover group version status = %v, want error forbidden", status)
+	}
+
+	attributes := version.GetAttributes()
+	if len(attributes) != 1 || attributes[0].GetValue().GetStringValue() != "apps/v1" {
+		t.Errorf("discover group version attributes = %v, want k8s.group_version apps/v1", attributes)
+	}
+
+	for name, span := range spans {
+		if !bytes.Equal(span.GetTraceId(), root.GetTraceId()) {
+			t.Errorf("span %s trace ID = %x, want %x", name, span.GetTraceId(), root.GetTraceId())
+		}
+	}
+}
+
+// resourceAttribute returns the string value of the attribute of the resource of the spans.
+func resourceAttribute(resourceSpans *tracepb.ResourceSpans, key string) string {
+	for _, attribute := range resourceSpans.GetResource().GetAttributes() {
+		if attribute.GetKey() == key {
+			return attribute.GetValue().GetStringValue()
+		}
+	}
+
+	return ""
+}
+
+// TestTracingRequests tests propagating the trace of the command to the API server in the requests of the clients.
+func TestTracingRequests(t *testing.T) {
+	t.Parallel()
+
+	traceParents := make(chan string, 1)
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceParents <- r.Header.Get("Traceparent")
+	}))
+	t.Cleanup(apiServer.Close)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(collector.Close)
+
+	cmd := newTracingTestCommand(t, collector.URL)
+	configFlags := genericclioptions.NewConfigFlags(false)
+
+	err := startTracing(cmd, configFlags)
+	if err != nil {
+		t.Fatalf("startTracing() error = %v", err)
+	}
+
+	t.Cleanup(func() { finishTracing(cmd, nil) })
+
+	transport, err := restclient.TransportFor(configFlags.WrapConfigFn(&restclient.Config{Host: apiServer.URL}))
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+
+	// The request has no span in its context, like those of the discovery client.
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, apiServer.URL+"/apis", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+
+	_ = response.Body.Close()
+
+	traceID := trace.SpanContextFromContext(cmd.Context()).TraceID()
+
+	traceParent := <-traceParents
+	if !strings.Contains(traceParent, hex.EncodeToString(traceID[:])) {
+		t.Errorf("traceparent = %q, want the trace %s of the command", traceParent, traceID)
+	}
+}
+
+// TestTracingDisabled tests that spans are ignored without --otel-endpoint.
+func TestTracingDisabled(t *testing.T) {
+	t.Parallel()
+
+	_, span := startSpan(t.Context(), "discover resources")
+	if span.IsRecording() {
+		t.Errorf("startSpan() = %v, want a non-recording span", span)
+	}
+
+	endSpan(span, nil)
+}
+
+// TestTracingEndpoint tests that the OTLP endpoint must be an HTTP URL.
+func TestTracingEndpoint(t *testing.T) {
+	t.Parallel()
+
+	err := startTracing(newTracingTestCommand(t, "localhost:4318"), genericclioptions.NewConfigFlags(false))
+	if !errors.Is(err, errOTelEndpoint) {
+		t.Errorf("startTracing() error = %v, want %v", err, errOTelEndpoint)
+	}
+}
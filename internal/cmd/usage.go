@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	restclient "k8s.io/client-go/rest"
+)
+
+// Metrics of the API server reporting the requested APIs, read with --show-usage.
+const (
+	// requestedDeprecatedAPIsMetric is set for each deprecated API which has been requested since the API server
+	// started.
+	requestedDeprecatedAPIsMetric = "apiserver_requested_deprecated_apis"
+	// requestTotalMetric counts the requests to each API since the API server started.
+	requestTotalMetric = "apiserver_request_total"
+)
+
+// metricsPath is the path of the metrics of the API server.
+const metricsPath = "/metrics"
+
+// unknownUsage is printed in the REQUESTED column when the metrics of the API server couldn't be read.
+const unknownUsage = "<unknown>"
+
+// errOfflineUsage is returned when --show-usage is requested with --offline, --from-dump, or --from-file.
+const errOfflineUsage = constError("show-usage is not supported with offline, from-dump, or from-file")
+
+// errNameUsage is returned when --show-usage is requested with --output=name, name0, api-versions, or script, which
+// don't print whether the resources have been requested.
+const errNameUsage = constError("show-usage has no effect with output=name, name0, api-versions, or script, " +
+	"which don't print whether the resources have been requested: remove show-usage or use the default or wide output")
+
+// errNoMetricsClient is returned when the discovery client has no REST client to read the metrics with.
+const errNoMetricsClient = constError("no REST client to read the metrics with")
+
+// errMetricSample is returned when a sample of the metrics can't be parsed.
+const errMetricSample = constError("malformed metric sample")
+
+// requestedString returns whether the resource has been requested, as printed in the REQUESTED column.
+func (gr groupResource) requestedString() string {
+	if gr.Requested == nil {
+		return unknownUsage
+	}
+
+	return strconv.FormatBool(*gr.Requested)
+}
+
+// annotateUsage sets whether each resource has been requested since the API server started, according to its
+// metrics. If the metrics can't be read, e.g. as reading /metrics is forbidden, a warning is printed and the resources
+// are left without usage.
+func annotateUsage(ctx context.Context, resources []groupResource, options *apiResourceVersionsOptions) {
+	requested, err := getRequestedAPIs(ctx, options.discoveryClient.RESTClient())
+	if err != nil {
+		_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't read the metrics of the API server: %v\n", err)
+
+		return
+	}
+
+	for i := range resources {
+		isRequested := requested.Has(resources[i].groupVersionResource())
+		resources[i].Requested = &isRequested
+	}
+}
+
+// getRequestedAPIs returns the group version resources which have been requested since the API server started,
+// according to its metrics.
+func getRequestedAPIs(ctx context.Context, client restclient.Interface) (sets.Set[schema.GroupVersionResource], error) {
+	if client == nil {
+		return nil, errNoMetricsClient
+	}
+
+	body, err := client.Get().AbsPath(metricsPath).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get %s: %w", metricsPath, err)
+	}
+
+	return parseRequestedAPIs(body)
+}
+
+// parseRequestedAPIs returns the group version resources of the samples of the [requestTotalMetric] and
+// [requestedDeprecatedAPIsMetric] metrics with a positive value, from the metrics in the Prometheus text format.
+func parseRequestedAPIs(metrics []byte) (sets.Set[schema.GroupVersionResource], error) {
+	requested := sets.New[schema.GroupVersionResource]()
+
+	scanner := bufio.NewScanner(bytes.NewReader(metrics))
+	// The lines of the histograms of the API server may be longer than the default limit of the scanner.
+	scanner.Buffer(nil, len(metrics)+1)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, requestTotalMetric+"{") && !strings.HasPrefix(line, requestedDeprecatedAPIsMetric+"{") {
+			continue
+		}
+
+		labels, value, err := parseSample(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if value > 0 && labels["resource"] != "" {
+			requested.Insert(schema.GroupVersionResource{
+				Group:    labels["group"],
+				Version:  labels["version"],
+				Resource: labels["resource"],
+			})
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read metrics: %w", err)
+	}
+
+	return requested, nil
+}
+
+// parseSample parses the labels and the value of a sample in the Prometheus text format, e.g.
+// `apiserver_request_total{group="apps",resource="deployments",version="v1"} 42`.
+func parseSample(line string) (map[string]string, float64, error) {
+	_, rest, _ := strings.Cut(line, "{")
+	labels := make(map[string]string)
+
+	for {
+		rest = strings.TrimLeft(rest, ", ")
+		if strings.HasPrefix(rest, "}") {
+			rest = rest[1:]
+
+			break
+		}
+
+		name, value, remaining, ok := cutLabel(rest)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %s", errMetricSample, line)
+		}
+
+		labels[name] = value
+		rest = remaining
+	}
+
+	// The sample may be followed by a timestamp.
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return nil, 0, fmt.Errorf("%w: %s", errMetricSample, line)
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s", errMetricSample, line)
+	}
+
+	return labels, value, nil
+}
+
+// cutLabel cuts the first label of the labels of a sample, `name="value"`, unescaping its value, and returns the
+// remaining labels.
+func cutLabel(labels string) (string, string, string, bool) {
+	name, rest, ok := strings.Cut(labels, `="`)
+	if !ok {
+		return "", "", "", false
+	}
+
+	var value strings.Builder
+
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '"':
+			return strings.TrimSpace(name), value.String(), rest[i+1:], true
+		case '\\':
+			i++
+			if i == len(rest) {
+				return "", "", "", false
+			}
+
+			if rest[i] == 'n' {
+				value.WriteByte('\n')
+			} else {
+				value.WriteByte(rest[i])
+			}
+		default:
+			value.WriteByte(rest[i])
+		}
+	}
+
+	return "", "", "", false
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+)
+
+// testMetrics are metrics of the API server in the Prometheus text format.
+const testMetrics = `# HELP apiserver_request_total [STABLE] Counter of apiserver requests.
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="apps",resource="deployments",` +
+	`scope="cluster",subresource="",verb="LIST",version="v1"} 12
+apiserver_request_total{code="200",component="apiserver",dry_run="",group="",resource="pods",scope="namespace",` +
+	`subresource="log",verb="CONNECT",version="v1"} 3 1700000000000
+apiserver_request_total{code="404",component="apiserver",dry_run="",group="",resource="",scope="",` +
+	`subresource="",verb="GET",version=""} 7
+apiserver_request_total{code="0",component="apiserver",dry_run="",group="batch",resource="cronjobs",` +
+	`scope="cluster",subresource="",verb="WATCH",version="v1"} 0
+# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="autoscaling",removed_release="1.26",resource="horizontalpodautoscalers",` +
+	`subresource="",version="v2beta2"} 1
+apiserver_request_duration_seconds_bucket{group="policy",resource="poddisruptionbudgets",version="v1",le="0.1"} 5
+`
+
+// TestParseRequestedAPIs tests parsing the requested resources from the metrics of the API server.
+func TestParseRequestedAPIs(t *testing.T) {
+	t.Parallel()
+
+	requested, err := parseRequestedAPIs([]byte(testMetrics))
+	if err != nil {
+		t.Fatalf("parseRequestedAPIs() error = %v", err)
+	}
+
+	want := sets.New(
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+		schema.GroupVersionResource{Group: "autoscaling", Version: "v2beta2", Resource: "horizontalpodautoscalers"},
+	)
+	if !requested.Equal(want) {
+		t.Errorf("parseRequestedAPIs() = %v, want %v", requested.UnsortedList(), want.UnsortedList())
+	}
+}
+
+type parseSampleTest struct {
+	line       string
+	wantLabels map[string]string
+	wantValue  float64
+	wantErr    bool
+}
+
+func (tt parseSampleTest) Test(t *testing.T) {
+	t.Parallel()
+
+	labels, value, err := parseSample(tt.line)
+	if (err != nil) != tt.wantErr {
+		t.Fatalf("parseSample() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if tt.wantErr {
+		return
+	}
+
+	if len(labels) != len(tt.wantLabels) {
+		t.Errorf("parseSample() labels = %v, want %v", labels, tt.wantLabels)
+	}
+
+	for name, want := range tt.wantLabels {
+		if labels[name] != want {
+			t.Errorf("parseSample() label %s = %q, want %q", name, labels[name], want)
+		}
+	}
+
+	if value != tt.wantValue {
+		t.Errorf("parseSample() value = %v, want %v", value, tt.wantValue)
+	}
+}
+
+// TestParseSample tests parsing the labels and the value of a sample of the metrics.
+func TestParseSample(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Labels", parseSampleTest{
+		line:       `apiserver_request_total{group="apps",resource="deployments",version="v1"} 42`,
+		wantLabels: map[string]string{"group": "apps", "resource": "deployments", "version": "v1"},
+		wantValue:  42,
+	}.Test)
+	t.Run("EscapedValue", parseSampleTest{
+		line:       `apiserver_request_total{path="a\"b\\c\nd",resource="pods",} 1e3`,
+		wantLabels: map[string]string{"path": "a\"b\\c\nd", "resource": "pods"},
+		wantValue:  1000,
+	}.Test)
+	t.Run("Timestamp", parseSampleTest{
+		line:       `apiserver_request_total{resource="pods"} 2 1700000000000`,
+		wantLabels: map[string]string{"resource": "pods"},
+		wantValue:  2,
+	}.Test)
+	t.Run("UnterminatedValue", parseSampleTest{
+		line:    `apiserver_request_total{resource="pods} 2`,
+		wantErr: true,
+	}.Test)
+	t.Run("MissingValue", parseSampleTest{
+		line:    `apiserver_request_total{resource="pods"}`,
+		wantErr: true,
+	}.Test)
+}
+
+// TestGetRequestedAPIs tests reading the metrics from the /metrics endpoint of the API server.
+func TestGetRequestedAPIs(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(testMetrics))
+	}))
+	t.Cleanup(server.Close)
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(&restclient.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requested, err := getRequestedAPIs(t.Context(), discoveryClient.RESTClient())
+	if err != nil {
+		t.Fatalf("getRequestedAPIs() error = %v", err)
+	}
+
+	if requested.Len() != 3 {
+		t.Errorf("getRequestedAPIs() = %v, want 3 resources", requested.UnsortedList())
+	}
+}
+
+// TestRunShowUsageUnknown tests that the REQUESTED column is unknown when the metrics can't be read, with a warning.
+func TestRunShowUsageUnknown(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetPreferred(true).SetShowUsage(true)
+	_, stdout, stderr := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED   " +
+		"REQUESTED\n" +
+		"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true        " +
+		"<unknown>\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+
+	wantWarning := "Warning: couldn't read the metrics of the API server: no REST client to read the metrics with\n"
+	if got := stderr.String(); got != wantWarning {
+		t.Errorf("runAPIResourceVersions() error output = %q, want %q", got, wantWarning)
+	}
+}
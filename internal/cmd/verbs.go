@@ -0,0 +1,432 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// verbSupported is the cell of a verb supported by the resource version.
+	verbSupported = "✓"
+	// verbUnsupported is the cell of a verb which isn't supported by the resource version.
+	verbUnsupported = "-"
+	// noVerbsDiff is the cell of the missing or extra verbs of a version when there are none.
+	noVerbsDiff = "<none>"
+)
+
+var (
+	// verbsExample is the example text for the verbs command.
+	//
+	//nolint:gochecknoglobals
+	verbsExample = `
+		# Show the verbs supported by each resource version
+		kubectl api-resource-versions verbs
+
+		# Show only the resources whose verbs differ between their versions, as a Markdown table
+		kubectl api-resource-versions verbs --differences-only --output=markdown
+
+		# Show the verbs each version of a resource is missing or adds compared with its preferred version
+		kubectl api-resource-versions verbs --diff`
+
+	// matrixVerbs are the columns of the verb matrix, the verbs of the resources served by the API server.
+	//
+	//nolint:gochecknoglobals
+	matrixVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}
+)
+
+// verbsRow is a resource version of the verb matrix.
+type verbsRow struct {
+	// Name is the resource version, e.g. "horizontalpodautoscalers.v2.autoscaling".
+	Name string `json:"name"`
+	// Verbs are the verbs supported by the resource version.
+	Verbs []string `json:"verbs"`
+
+	group    string
+	resource string
+	version  string
+}
+
+// verbsDiff is the difference between the verbs of a resource version and the verbs of the first version of the
+// resource in the order of priority, usually its preferred version.
+type verbsDiff struct {
+	// Resource is the group resource, e.g. "horizontalpodautoscalers.autoscaling".
+	Resource string `json:"resource"`
+	// Version is the version of the resource which differs, e.g. "v1".
+	Version string `json:"version"`
+	// BaseVersion is the version of the resource compared with, e.g. "v2".
+	BaseVersion string `json:"baseVersion"`
+	// Missing are the verbs supported by the base version but not by the version.
+	Missing []string `json:"missing"`
+	// Extra are the verbs supported by the version but not by the base version.
+	Extra []string `json:"extra"`
+}
+
+// newCmdVerbs returns a command that shows the verbs supported by each resource version.
+func newCmdVerbs(
+	restClientGetter genericclioptions.RESTClientGetter,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	options := newVerbsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "verbs",
+		Short: "Show the verbs supported by each resource version",
+		Long: "Show a matrix with a row for each resource version and a column for each verb, which tells whether " +
+			"the verb is supported by the resource version, making the differences between the versions of a " +
+			"resource visible.\n" +
+			"The columns are the verbs " + strings.Join(matrixVerbs, ", ") + ". Subresources are not included.\n" +
+			"With --diff, list instead the verbs each version of a resource is missing or adds compared with the first " +
+			"version of the resource in the order of priority, usually its preferred version, including the verbs " +
+			"which aren't columns of the matrix. Verbs silently differing between versions, e.g. a version without " +
+			"deletecollection, can break tooling after migrating to that version.",
+		Example: templates.Examples(verbsExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runVerbs(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output,
+		"Output format. One of: ("+tableOutput+", "+jsonOutput+", "+markdownOutput+").")
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"When using the table output format, don't print headers (default print headers).")
+	cmd.Flags().BoolVar(&options.DifferencesOnly, "differences-only", options.DifferencesOnly,
+		"Limit to resources whose versions don't all support the same verbs.")
+	cmd.Flags().BoolVar(&options.Diff, "diff", options.Diff,
+		"List the verbs each version of a resource is missing or adds compared with its preferred version, instead "+
+			"of the verb matrix.")
+
+	return cmd
+}
+
+// verbsOptions contains the options for the verbs command.
+type verbsOptions struct {
+	genericiooptions.IOStreams
+
+	Output          string
+	NoHeaders       bool
+	DifferencesOnly bool
+	Diff            bool
+
+	contextName     string
+	discoveryClient discovery.CachedDiscoveryInterface
+}
+
+// newVerbsOptions returns a new [verbsOptions] with default values.
+func newVerbsOptions(ioStreams genericiooptions.IOStreams) *verbsOptions {
+	return &verbsOptions{
+		IOStreams: ioStreams,
+		Output:    tableOutput,
+	}
+}
+
+// complete completes all the required options for the verbs command.
+func (o *verbsOptions) complete(
+	restClientGetter genericclioptions.RESTClientGetter,
+	cmd *cobra.Command,
+	args []string,
+) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+	o.contextName = contextName(restClientGetter)
+
+	return nil
+}
+
+// validate checks that options are valid for the verbs command.
+func (o *verbsOptions) validate() error {
+	return validateReportOutput(o.Output)
+}
+
+// runVerbs discovers the API resources and prints the verb matrix.
+func runVerbs(ctx context.Context, options *verbsOptions) error {
+	snap, err := newSnapshot(ctx, options.discoveryClient, options.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	snap.Context = options.contextName
+
+	rows := newVerbsRows(snap)
+	if options.Diff {
+		return printVerbsDiffs(options, newSnapshotsMetadata(snap), newVerbsDiffs(rows))
+	}
+
+	if options.DifferencesOnly {
+		rows = verbsDifferences(rows)
+	}
+
+	switch options.Output {
+	case jsonOutput:
+		return printVerbsJSON(options.Out, newSnapshotsMetadata(snap), rows)
+	case markdownOutput:
+		return printVerbsMarkdown(options.Out, rows)
+	default:
+		return printVerbsTable(options.Out, rows, options.NoHeaders)
+	}
+}
+
+// newVerbsRows returns a row for each resource version of the snapshot, sorted by group, resource, and version
+// priority.
+func newVerbsRows(snap *snapshot) []verbsRow {
+	var rows []verbsRow
+
+	for _, group := range snap.Groups {
+		for _, groupVersionResources := range group.Versions {
+			groupVersion := schema.GroupVersion{Group: group.Name, Version: groupVersionResources.Version}
+
+			for _, resource := range groupVersionResources.Resources {
+				if _, subName := splitResourceName(resource.Name); subName != nil {
+					continue
+				}
+
+				verbs := slices.Clone(resource.Verbs)
+				slices.Sort(verbs)
+
+				rows = append(rows, verbsRow{
+					Name:     snapshotResourceName(groupVersion, resource.Name),
+					Verbs:    verbs,
+					group:    group.Name,
+					resource: resource.Name,
+					version:  groupVersionResources.Version,
+				})
+			}
+		}
+	}
+
+	// The versions of the groups are already in the order of priority.
+	slices.SortStableFunc(rows, func(a, b verbsRow) int {
+		return cmp.Or(cmp.Compare(a.group, b.group), cmp.Compare(a.resource, b.resource))
+	})
+
+	return rows
+}
+
+// verbsDifferences returns the rows of the resources whose versions don't all support the same verbs.
+func verbsDifferences(rows []verbsRow) []verbsRow {
+	var differences []verbsRow
+
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && rows[end].group == rows[start].group && rows[end].resource == rows[start].resource {
+			end++
+		}
+
+		for _, row := range rows[start+1 : end] {
+			if !slices.Equal(row.Verbs, rows[start].Verbs) {
+				differences = append(differences, rows[start:end]...)
+
+				break
+			}
+		}
+
+		start = end
+	}
+
+	return differences
+}
+
+// newVerbsDiffs returns the differences between the verbs of each resource version and the verbs of the first version
+// of the resource, omitting the versions which support the same verbs.
+func newVerbsDiffs(rows []verbsRow) []verbsDiff {
+	var diffs []verbsDiff
+
+	for start := 0; start < len(rows); {
+		base := rows[start]
+
+		end := start + 1
+		for ; end < len(rows) && rows[end].group == base.group && rows[end].resource == base.resource; end++ {
+			row := rows[end]
+
+			missing := slices.DeleteFunc(slices.Clone(base.Verbs), func(verb string) bool {
+				return slices.Contains(row.Verbs, verb)
+			})
+			extra := slices.DeleteFunc(slices.Clone(row.Verbs), func(verb string) bool {
+				return slices.Contains(base.Verbs, verb)
+			})
+
+			if len(missing) == 0 && len(extra) == 0 {
+				continue
+			}
+
+			diffs = append(diffs, verbsDiff{
+				Resource:    schema.GroupResource{Group: base.group, Resource: base.resource}.String(),
+				Version:     row.version,
+				BaseVersion: base.version,
+				Missing:     missing,
+				Extra:       extra,
+			})
+		}
+
+		start = end
+	}
+
+	return diffs
+}
+
+// verbsDiffColumns returns the columns of the difference in the table and Markdown output formats.
+func verbsDiffColumns(diff verbsDiff) []string {
+	missing, extra := noVerbsDiff, noVerbsDiff
+	if len(diff.Missing) > 0 {
+		missing = strings.Join(diff.Missing, ",")
+	}
+
+	if len(diff.Extra) > 0 {
+		extra = strings.Join(diff.Extra, ",")
+	}
+
+	return []string{diff.Resource, diff.Version, diff.BaseVersion, missing, extra}
+}
+
+// printVerbsDiffs prints the differences between the verbs of the resource versions in the output format.
+func printVerbsDiffs(options *verbsOptions, metadata outputMetadata, diffs []verbsDiff) error {
+	headers := []string{"RESOURCE", "VERSION", "BASE-VERSION", "MISSING", "EXTRA"}
+
+	var err error
+
+	switch options.Output {
+	case jsonOutput:
+		encoder := json.NewEncoder(options.Out)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(struct {
+			Metadata    outputMetadata `json:"metadata"`
+			Differences []verbsDiff    `json:"differences"`
+		}{Metadata: metadata, Differences: diffs})
+	case markdownOutput:
+		lines := []string{
+			"| Resource | Version | Base version | Missing | Extra |",
+			strings.Repeat("| --- ", len(headers)) + "|",
+		}
+		for _, diff := range diffs {
+			lines = append(lines, "| "+strings.Join(verbsDiffColumns(diff), " | ")+" |")
+		}
+
+		_, err = fmt.Fprintln(options.Out, strings.Join(lines, "\n"))
+	default:
+		writer := printers.GetNewTabWriter(options.Out)
+		defer mustFlushWriter(writer)
+
+		if !options.NoHeaders {
+			err = printRow(writer, headers)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, diff := range diffs {
+			err = printRow(writer, verbsDiffColumns(diff))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("couldn't write verb differences: %w", err)
+	}
+
+	return nil
+}
+
+// verbsColumns returns the columns of the row in the table and Markdown output formats.
+func verbsColumns(row verbsRow) []string {
+	columns := []string{row.Name}
+
+	for _, verb := range matrixVerbs {
+		cell := verbUnsupported
+		if slices.Contains(row.Verbs, verb) {
+			cell = verbSupported
+		}
+
+		columns = append(columns, cell)
+	}
+
+	return columns
+}
+
+// printVerbsTable prints the verb matrix as a table.
+func printVerbsTable(out io.Writer, rows []verbsRow, noHeaders bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		headers := []string{"RESOURCE"}
+		for _, verb := range matrixVerbs {
+			headers = append(headers, strings.ToUpper(verb))
+		}
+
+		err := printRow(writer, headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		err := printRow(writer, verbsColumns(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printVerbsMarkdown prints the verb matrix as a Markdown table.
+func printVerbsMarkdown(out io.Writer, rows []verbsRow) error {
+	lines := []string{
+		"| Resource | " + strings.Join(matrixVerbs, " | ") + " |",
+		strings.Repeat("| --- ", len(matrixVerbs)+1) + "|",
+	}
+	for _, row := range rows {
+		lines = append(lines, "| "+strings.Join(verbsColumns(row), " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	if err != nil {
+		return fmt.Errorf("couldn't write verb matrix: %w", err)
+	}
+
+	return nil
+}
+
+// printVerbsJSON prints the verb matrix as a JSON object with the "metadata" and the "resources" list, including the
+// verbs which aren't columns of the table.
+func printVerbsJSON(out io.Writer, metadata outputMetadata, rows []verbsRow) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Metadata  outputMetadata `json:"metadata"`
+		Resources []verbsRow     `json:"resources"`
+	}{Metadata: metadata, Resources: rows})
+	if err != nil {
+		return fmt.Errorf("couldn't write verb matrix: %w", err)
+	}
+
+	return nil
+}
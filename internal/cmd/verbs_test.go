@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestRunVerbs tests showing the verbs supported by each resource version.
+func TestRunVerbs(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newVerbsOptions(ioStreams)
+	options.Output = markdownOutput
+	options.discoveryClient = discoverytesting.New()
+
+	err := runVerbs(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runVerbs() error = %v", err)
+	}
+
+	want := "| Resource | get | list | watch | create | update | patch | delete | deletecollection |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"
+	if got := stdout.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("runVerbs() output = %q, want it to start with %q", got, want)
+	}
+}
+
+// TestVerbsDifferences tests limiting the verb matrix to the resources whose versions support different verbs.
+func TestVerbsDifferences(t *testing.T) {
+	t.Parallel()
+
+	snap := &snapshot{Groups: []snapshotGroup{
+		{
+			Name: "autoscaling",
+			Versions: []snapshotVersion{
+				{Version: "v2", Resources: []snapshotResource{
+					{Name: "horizontalpodautoscalers", Verbs: []string{"list", "get", "watch"}},
+					{Name: "horizontalpodautoscalers/status", Verbs: []string{"get"}},
+				}},
+				{Version: "v1", Resources: []snapshotResource{
+					{Name: "horizontalpodautoscalers", Verbs: []string{"get", "list"}},
+				}},
+			},
+		},
+		{
+			Name: "apps",
+			Versions: []snapshotVersion{
+				{Version: "v1", Resources: []snapshotResource{
+					{Name: "deployments", Verbs: []string{"get"}},
+				}},
+			},
+		},
+	}}
+
+	rows := newVerbsRows(snap)
+
+	var got []string
+	for _, row := range verbsDifferences(rows) {
+		got = append(got, row.Name)
+	}
+
+	want := []string{"horizontalpodautoscalers.v2.autoscaling", "horizontalpodautoscalers.v1.autoscaling"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("verbsDifferences() = %v, want %v", got, want)
+	}
+
+	if len(rows) != 3 || rows[0].Name != "deployments.v1.apps" {
+		t.Errorf("newVerbsRows() = %v, want the deployments first and no subresources", rows)
+	}
+}
+
+// TestNewVerbsDiffs tests comparing the verbs of each resource version with the first version of the resource.
+func TestNewVerbsDiffs(t *testing.T) {
+	t.Parallel()
+
+	snap := &snapshot{Groups: []snapshotGroup{
+		{
+			Name: "autoscaling",
+			Versions: []snapshotVersion{
+				{Version: "v2", Resources: []snapshotResource{
+					{Name: "horizontalpodautoscalers", Verbs: []string{"get", "list", "deletecollection"}},
+				}},
+				{Version: "v1", Resources: []snapshotResource{
+					{Name: "horizontalpodautoscalers", Verbs: []string{"get", "list", "patch"}},
+				}},
+				{Version: "v2beta2", Resources: []snapshotResource{
+					{Name: "horizontalpodautoscalers", Verbs: []string{"list", "get", "deletecollection"}},
+				}},
+			},
+		},
+	}}
+
+	got := newVerbsDiffs(newVerbsRows(snap))
+
+	want := []verbsDiff{{
+		Resource:    "horizontalpodautoscalers.autoscaling",
+		Version:     "v1",
+		BaseVersion: "v2",
+		Missing:     []string{"deletecollection"},
+		Extra:       []string{"patch"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newVerbsDiffs() = %+v, want %+v", got, want)
+	}
+}
+
+// TestRunVerbsDiff tests listing the verb differences of the resource versions as a table.
+func TestRunVerbsDiff(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newVerbsOptions(ioStreams)
+	options.Diff = true
+	options.discoveryClient = discoverytesting.New()
+
+	err := runVerbs(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runVerbs() error = %v", err)
+	}
+
+	want := "RESOURCE   VERSION   BASE-VERSION   MISSING   EXTRA\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runVerbs() output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// Build metadata of the plugin, injected by the release with -ldflags, e.g.
+// -X github.com/Izzette/kubectl-api-resource-versions/internal/cmd.buildVersion=v1.2.3.
+// When they aren't injected, e.g. with go install, they are read from the build info of the binary instead.
+//
+//nolint:gochecknoglobals
+var (
+	// buildVersion is the version of the plugin.
+	buildVersion string
+	// buildCommit is the git commit the plugin was built from.
+	buildCommit string
+	// buildDate is the date the plugin was built at, or of the git commit without -ldflags.
+	buildDate string
+)
+
+// unknownBuildInfo is the build metadata which couldn't be determined.
+const unknownBuildInfo = "unknown"
+
+var (
+	// versionExample is the example text for the version command.
+	//
+	//nolint:gochecknoglobals
+	versionExample = `
+		# Print the version of the plugin
+		kubectl api-resource-versions version
+
+		# Print the version of the plugin as JSON, e.g. to check it in a script
+		kubectl api-resource-versions version -o json | jq -r .version`
+)
+
+// buildInfo is the build metadata of the plugin printed by the version command.
+type buildInfo struct {
+	// Version is the version of the plugin, e.g. v1.2.3.
+	Version string `json:"version"`
+	// GitCommit is the git commit the plugin was built from.
+	GitCommit string `json:"gitCommit"`
+	// BuildDate is the date the plugin was built at.
+	BuildDate string `json:"buildDate"`
+	// GoVersion is the version of Go the plugin was built with.
+	GoVersion string `json:"goVersion"`
+	// Platform is the OS and architecture of the plugin, e.g. linux/amd64.
+	Platform string `json:"platform"`
+	// ClientGoVersion is the version of k8s.io/client-go the plugin was built against.
+	ClientGoVersion string `json:"clientGoVersion"`
+	// APIMachineryVersion is the version of k8s.io/apimachinery the plugin was built against.
+	APIMachineryVersion string `json:"apimachineryVersion"`
+}
+
+// getBuildInfo returns the build metadata of the plugin, from -ldflags if they were injected, or else from the build
+// info of the binary.
+func getBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:             buildVersion,
+		GitCommit:           buildCommit,
+		BuildDate:           buildDate,
+		GoVersion:           runtime.Version(),
+		Platform:            runtime.GOOS + "/" + runtime.GOARCH,
+		ClientGoVersion:     unknownBuildInfo,
+		APIMachineryVersion: unknownBuildInfo,
+	}
+
+	if binaryInfo, ok := debug.ReadBuildInfo(); ok {
+		info.fromBinaryInfo(binaryInfo)
+	}
+
+	for _, field := range []*string{&info.Version, &info.GitCommit, &info.BuildDate} {
+		if *field == "" {
+			*field = unknownBuildInfo
+		}
+	}
+
+	return info
+}
+
+// fromBinaryInfo completes the build metadata which wasn't injected with -ldflags from the build info of the binary.
+func (i *buildInfo) fromBinaryInfo(binaryInfo *debug.BuildInfo) {
+	// The version of the main module is (devel) when built from a working copy rather than with go install.
+	if i.Version == "" && binaryInfo.Main.Version != "(devel)" {
+		i.Version = binaryInfo.Main.Version
+	}
+
+	for _, setting := range binaryInfo.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && i.GitCommit == "":
+			i.GitCommit = setting.Value
+		case setting.Key == "vcs.time" && i.BuildDate == "":
+			i.BuildDate = setting.Value
+		}
+	}
+
+	for _, dependency := range binaryInfo.Deps {
+		switch dependency.Path {
+		case "k8s.io/client-go":
+			i.ClientGoVersion = dependency.Version
+		case "k8s.io/apimachinery":
+			i.APIMachineryVersion = dependency.Version
+		}
+	}
+}
+
+// newCmdVersion returns a command that prints the build metadata of the plugin.
+func newCmdVersion(ioStreams genericiooptions.IOStreams) *cobra.Command {
+	options := newVersionOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the plugin",
+		Long: "Print the version of the plugin, the git commit and date it was built from, and the versions of " +
+			"client-go and apimachinery it was built against.",
+		Example: templates.Examples(versionExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(cmd, args))
+			checkErr(cmd, invalidArgument(options.validate()))
+			checkErr(cmd, runVersion(options))
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Output, "output", "o", options.Output, "Output format. One of: ("+jsonOutput+").")
+
+	return cmd
+}
+
+// versionOptions contains the options for the version command.
+type versionOptions struct {
+	genericiooptions.IOStreams
+
+	Output string
+}
+
+// newVersionOptions returns a new [versionOptions] with default values.
+func newVersionOptions(ioStreams genericiooptions.IOStreams) *versionOptions {
+	return &versionOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the version command.
+func (o *versionOptions) complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	return nil
+}
+
+// errVersionOutput is returned when the output format of the version command is not supported.
+const errVersionOutput = constError("output must be one of: (" + jsonOutput + ")")
+
+// validate checks that options are valid for the version command.
+func (o *versionOptions) validate() error {
+	if o.Output != "" && o.Output != jsonOutput {
+		return fmt.Errorf("%w: got %s", errVersionOutput, o.Output)
+	}
+
+	return nil
+}
+
+// runVersion prints the build metadata of the plugin.
+func runVersion(options *versionOptions) error {
+	info := getBuildInfo()
+
+	if options.Output == jsonOutput {
+		encoder := json.NewEncoder(options.Out)
+		encoder.SetIndent("", "  ")
+
+		//nolint:wrapcheck
+		return encoder.Encode(info)
+	}
+
+	_, err := fmt.Fprintf(options.Out,
+		"Version: %s\nGitCommit: %s\nBuildDate: %s\nGoVersion: %s\nPlatform: %s\nClientGo: %s\nAPIMachinery: %s\n",
+		info.Version, info.GitCommit, info.BuildDate, info.GoVersion, info.Platform, info.ClientGoVersion,
+		info.APIMachineryVersion)
+
+	//nolint:wrapcheck
+	return err
+}
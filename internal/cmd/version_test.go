@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+// TestBuildInfoFromBinaryInfo tests completing the build metadata which wasn't injected with -ldflags.
+func TestBuildInfoFromBinaryInfo(t *testing.T) {
+	t.Parallel()
+
+	binaryInfo := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.3"},
+		Deps: []*debug.Module{
+			{Path: "k8s.io/client-go", Version: "v0.36.2"},
+			{Path: "k8s.io/apimachinery", Version: "v0.36.1"},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef"},
+			{Key: "vcs.time", Value: "2026-10-16T00:00:00Z"},
+		},
+	}
+
+	info := buildInfo{GitCommit: "fedcba9876543210"}
+	info.fromBinaryInfo(binaryInfo)
+
+	want := buildInfo{
+		Version:             "v1.2.3",
+		GitCommit:           "fedcba9876543210", // Injected with -ldflags.
+		BuildDate:           "2026-10-16T00:00:00Z",
+		ClientGoVersion:     "v0.36.2",
+		APIMachineryVersion: "v0.36.1",
+	}
+	if info != want {
+		t.Errorf("fromBinaryInfo() = %+v, want %+v", info, want)
+	}
+
+	info = buildInfo{}
+	info.fromBinaryInfo(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}})
+
+	if info.Version != "" {
+		t.Errorf("fromBinaryInfo() version = %q, want none for a development build", info.Version)
+	}
+}
+
+// TestRunVersion tests printing the build metadata as JSON.
+func TestRunVersion(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newVersionOptions(ioStreams)
+	options.Output = jsonOutput
+
+	err := runVersion(options)
+	if err != nil {
+		t.Fatalf("runVersion() error = %v", err)
+	}
+
+	var info buildInfo
+
+	err = json.Unmarshal(stdout.Bytes(), &info)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if info != getBuildInfo() {
+		t.Errorf("runVersion() = %+v, want %+v", info, getBuildInfo())
+	}
+
+	options.Output = "yaml"
+
+	err = options.validate()
+	if !errors.Is(err, errVersionOutput) {
+		t.Errorf("validate() error = %v, want %v", err, errVersionOutput)
+	}
+}
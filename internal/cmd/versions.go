@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/openapi"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// unknownStorage is printed in the STORAGE column when the storage version of the resource isn't reported.
+const unknownStorage = "<unknown>"
+
+var (
+	// versionsExample is the example text for the versions command.
+	//
+	//nolint:gochecknoglobals
+	versionsExample = `
+		# Print every served version of the Deployments
+		kubectl api-resource-versions versions deployments
+
+		# Resolve the resource by its singular name, short name, or kind, with an optional group
+		kubectl api-resource-versions versions hpa
+		kubectl api-resource-versions versions HorizontalPodAutoscaler.autoscaling
+
+		# Print which versions of the HorizontalPodAutoscalers have identical schemas
+		kubectl api-resource-versions versions hpa --identical-schemas`
+)
+
+// customResourceDefinitionsGVR is the resource of the CustomResourceDefinitions, which report the storage version
+// and the deprecated versions of the custom resources.
+//
+//nolint:gochecknoglobals
+var customResourceDefinitionsGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// newCmdVersions returns a command that prints every served version of a resource.
+func newCmdVersions(restClientGetter *fromDumpFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	options := newVersionsOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "versions RESOURCE",
+		Short: "Print every served version of a resource",
+		Long: "Print every version of the resource served by the cluster, along with whether it is the preferred " +
+			"version, the storage version, or deprecated.\n" +
+			"The resource is resolved by its plural name, singular name, short name, or kind, optionally followed by " +
+			"its group, e.g. deploy.apps.\n" +
+			"The storage version is reported by the CustomResourceDefinition of custom resources, or by the " +
+			"StorageVersion API if it is enabled, and is " + unknownStorage + " otherwise.\n" +
+			"With --identical-schemas, the IDENTICAL-TO column lists the other versions whose OpenAPI v3 schema has " +
+			"the same structure, ignoring the descriptions, so that the version bumps which changed nothing stand out.",
+		Example:           templates.Examples(versionsExample),
+		ValidArgsFunction: completeResources(restClientGetter),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkErr(cmd, options.complete(restClientGetter, cmd, args))
+			checkErr(cmd, runVersions(cmd.Context(), options))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", options.NoHeaders,
+		"Don't print headers (default print headers).")
+	cmd.Flags().BoolVar(&options.IdenticalSchemas, "identical-schemas", options.IdenticalSchemas,
+		"Print the other versions with an identical OpenAPI v3 schema in the IDENTICAL-TO column.")
+
+	return cmd
+}
+
+// versionsOptions contains the options for the versions command.
+type versionsOptions struct {
+	genericiooptions.IOStreams
+
+	NoHeaders        bool
+	IdenticalSchemas bool
+
+	resource        string
+	discoveryClient discovery.DiscoveryInterface
+	// dynamicClient gets the storage versions, nil with --from-dump or --from-file.
+	dynamicClient dynamic.Interface
+	// openAPIClient gets the schemas of the versions with --identical-schemas.
+	openAPIClient openapi.Client
+}
+
+// newVersionsOptions returns a new [versionsOptions] with default values.
+func newVersionsOptions(ioStreams genericiooptions.IOStreams) *versionsOptions {
+	return &versionsOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// complete completes all the required options for the versions command.
+func (o *versionsOptions) complete(restClientGetter *fromDumpFlags, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		//nolint:wrapcheck
+		return cmdutil.UsageErrorf(cmd, "exactly one resource is required, got: %v", args)
+	}
+
+	o.resource = args[0]
+
+	discoveryClient, err := restClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return fmt.Errorf("couldn't create discovery client: %w", err)
+	}
+
+	o.discoveryClient = discoveryClient
+
+	if o.IdenticalSchemas {
+		o.openAPIClient = discoveryClient.OpenAPIV3()
+		if o.openAPIClient == nil {
+			return fmt.Errorf("%w, e.g. with --from-dump", errOpenAPIUnavailable)
+		}
+	}
+
+	if restClientGetter.fromDocuments() {
+		// The storage versions aren't part of the discovery documents.
+		return nil
+	}
+
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("couldn't get REST config: %w", err)
+	}
+
+	o.dynamicClient, err = dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("couldn't create dynamic client: %w", err)
+	}
+
+	return nil
+}
+
+// servedVersion is a version of a resource served by the cluster.
+type servedVersion struct {
+	// GroupVersionResource is the group, version, and resource name of the resource.
+	GroupVersionResource schema.GroupVersionResource
+	// Kind is the kind of the resource in the version.
+	Kind string
+	// Namespaced is true if the objects of the resource are namespaced.
+	Namespaced bool
+	// Preferred is true if the version is the preferred version of the group.
+	Preferred bool
+	// Storage is true if the objects of the resource are encoded in etcd in this version, nil if it's unknown.
+	Storage *bool
+	// Deprecated is true if the version of the resource is deprecated.
+	Deprecated bool
+	// SchemaHash is the hash of the structure of the OpenAPI v3 schema of the version, empty if it's unknown.
+	SchemaHash string
+}
+
+// runVersions prints every served version of the resource of the options.
+func runVersions(ctx context.Context, options *versionsOptions) error {
+	versions, err := getServedVersions(options.discoveryClient, options.resource)
+	if err != nil {
+		return err
+	}
+
+	annotateCtx, annotateSpan := startSpan(ctx, "annotate served versions")
+	err = annotateServedVersions(annotateCtx, options, versions)
+	endSpan(annotateSpan, err)
+
+	if err != nil {
+		return err
+	}
+
+	if options.IdenticalSchemas {
+		_, schemasSpan := startSpan(ctx, "read schemas")
+		err = annotateSchemaHashes(options.openAPIClient, versions)
+		endSpan(schemasSpan, err)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return printServedVersions(options.Out, versions, options.NoHeaders, options.IdenticalSchemas)
+}
+
+// getServedVersions returns every served version of the resources matching the argument, in the priority order of
+// the group versions.
+// An argument without a group matches the resources of every group, e.g. events.
+func getServedVersions(discoveryClient discovery.DiscoveryInterface, arg string) ([]servedVersion, error) {
+	groups, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("couldn't get server groups and resources: %w", err)
+	}
+
+	resourcesByGroupVersion := make(map[string][]metav1.APIResource, len(resourceLists))
+	for _, resourceList := range resourceLists {
+		resourcesByGroupVersion[resourceList.GroupVersion] = resourceList.APIResources
+	}
+
+	name, group, hasGroup := strings.Cut(strings.ToLower(arg), ".")
+
+	var versions []servedVersion
+
+	for _, apiGroup := range groups {
+		if hasGroup && apiGroup.Name != group {
+			continue
+		}
+
+		for _, groupVersion := range apiGroup.Versions {
+			for _, resource := range resourcesByGroupVersion[groupVersion.GroupVersion] {
+				if strings.Contains(resource.Name, "/") || !resourceMatches(resource, name) {
+					continue
+				}
+
+				versions = append(versions, servedVersion{
+					GroupVersionResource: schema.GroupVersionResource{
+						Group:    apiGroup.Name,
+						Version:  groupVersion.Version,
+						Resource: resource.Name,
+					},
+					Kind:       resource.Kind,
+					Namespaced: resource.Namespaced,
+					Preferred:  groupVersion.Version == apiGroup.PreferredVersion.Version,
+				})
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w %q", errResourceNotFound, arg)
+	}
+
+	return versions, nil
+}
+
+// resourceMatches returns true if the lower case name is the plural name, singular name, a short name, or the kind
+// of the resource.
+func resourceMatches(resource metav1.APIResource, name string) bool {
+	if name == "" {
+		// The singular name is empty in the discovery documents of some resources.
+		return false
+	}
+
+	return resource.Name == name || resource.SingularName == name || strings.ToLower(resource.Kind) == name ||
+		slices.Contains(resource.ShortNames, name)
+}
+
+// annotateServedVersions sets whether the served versions are the storage version, and whether they are deprecated
+// in the release of the cluster or by their CustomResourceDefinition.
+func annotateServedVersions(ctx context.Context, options *versionsOptions, versions []servedVersion) error {
+	var release *utilversion.Version
+
+	serverVersion, err := options.discoveryClient.ServerVersion()
+	if err == nil {
+		release, _ = lifecycle.ParseRelease(serverVersion.GitVersion)
+	}
+
+	storages := make(map[schema.GroupResource]resourceStorage)
+
+	for i := range versions {
+		version := &versions[i]
+		groupResource := version.GroupVersionResource.GroupResource()
+
+		storage, ok := storages[groupResource]
+		if !ok {
+			storageCtx, storageSpan := startSpan(ctx, "get resource storage",
+				attribute.String("k8s.group_resource", groupResource.String()))
+			storage, err = getResourceStorage(storageCtx, options.dynamicClient, groupResource)
+			endSpan(storageSpan, err)
+
+			if err != nil {
+				return err
+			}
+
+			storages[groupResource] = storage
+		}
+
+		if storage.Version != "" {
+			isStorage := storage.Version == version.GroupVersionResource.Version
+			version.Storage = &isStorage
+		}
+
+		api, known := lifecycle.Lookup(version.GroupVersionResource.GroupVersion().WithKind(version.Kind))
+		version.Deprecated = (known && api.DeprecatedIn(release)) ||
+			storage.DeprecatedVersions.Has(version.GroupVersionResource.Version)
+	}
+
+	return nil
+}
+
+// resourceStorage is the storage of a resource, as reported by its CustomResourceDefinition or the StorageVersion
+// API.
+type resourceStorage struct {
+	// Version is the version in which the objects are encoded in etcd, empty if it's unknown.
+	Version string
+	// DeprecatedVersions are the deprecated versions of a custom resource.
+	DeprecatedVersions sets.Set[string]
+}
+
+// getResourceStorage returns the storage of the resource from its CustomResourceDefinition, or else from the
+// StorageVersion API.
+// The storage is unknown if neither report it, or if they can't be read, e.g. with --from-dump.
+func getResourceStorage(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	groupResource schema.GroupResource,
+) (resourceStorage, error) {
+	if dynamicClient == nil {
+		return resourceStorage{}, nil
+	}
+
+	if groupResource.Group != "" {
+		crd, err := dynamicClient.Resource(customResourceDefinitionsGVR).Get(ctx, groupResource.String(),
+			metav1.GetOptions{})
+		if err == nil {
+			return customResourceStorage(crd), nil
+		} else if !isStorageUnavailable(err) {
+			return resourceStorage{}, fmt.Errorf("couldn't get custom resource definition %s: %w", groupResource, err)
+		}
+	}
+
+	storageVersionName := groupResource.Group + "." + groupResource.Resource
+	if groupResource.Group == "" {
+		storageVersionName = "core." + groupResource.Resource
+	}
+
+	storageVersion, err := dynamicClient.Resource(storageVersionsGVR).Get(ctx, storageVersionName, metav1.GetOptions{})
+	if isStorageUnavailable(err) {
+		return resourceStorage{}, nil
+	} else if err != nil {
+		return resourceStorage{}, fmt.Errorf("couldn't get storage version %s: %w", storageVersionName, err)
+	}
+
+	commonEncodingVersion, _, _ := unstructured.NestedString(storageVersion.Object, "status", "commonEncodingVersion")
+	if commonEncodingVersion == "" {
+		return resourceStorage{}, nil
+	}
+
+	groupVersion, err := schema.ParseGroupVersion(commonEncodingVersion)
+	if err != nil {
+		return resourceStorage{}, fmt.Errorf("couldn't parse encoding version of %s: %w", storageVersionName, err)
+	}
+
+	return resourceStorage{Version: groupVersion.Version}, nil
+}
+
+// isStorageUnavailable returns true if the error means that the storage of the resource isn't reported, e.g. because
+// the resource isn't a custom resource or the StorageVersion API isn't enabled.
+func isStorageUnavailable(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err)
+}
+
+// customResourceStorage returns the storage of the custom resource from its CustomResourceDefinition.
+func customResourceStorage(crd *unstructured.Unstructured) resourceStorage {
+	storage := resourceStorage{DeprecatedVersions: sets.New[string]()}
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, item := range versions {
+		version, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(version, "name")
+
+		isStorage, _, _ := unstructured.NestedBool(version, "storage")
+		if isStorage {
+			storage.Version = name
+		}
+
+		deprecated, _, _ := unstructured.NestedBool(version, "deprecated")
+		if deprecated {
+			storage.DeprecatedVersions.Insert(name)
+		}
+	}
+
+	return storage
+}
+
+// printServedVersions prints the served versions as a table, with the IDENTICAL-TO column if identicalSchemas is
+// true.
+func printServedVersions(out io.Writer, versions []servedVersion, noHeaders, identicalSchemas bool) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	if !noHeaders {
+		headers := []string{"NAME", "APIVERSION", "KIND", "PREFERRED", "STORAGE", "DEPRECATED"}
+		if identicalSchemas {
+			headers = append(headers, "IDENTICAL-TO")
+		}
+
+		err := printRow(writer, headers)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, version := range versions {
+		storage := unknownStorage
+		if version.Storage != nil {
+			storage = strconv.FormatBool(*version.Storage)
+		}
+
+		row := []string{
+			version.GroupVersionResource.GroupResource().String(),
+			version.GroupVersionResource.GroupVersion().String(),
+			version.Kind,
+			strconv.FormatBool(version.Preferred),
+			storage,
+			strconv.FormatBool(version.Deprecated),
+		}
+		if identicalSchemas {
+			row = append(row, identicalVersions(versions, version))
+		}
+
+		err := printRow(writer, row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+// TestRunVersions tests printing every served version of a resource resolved by its kind.
+func TestRunVersions(t *testing.T) {
+	t.Parallel()
+
+	storageVersion := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "internal.apiserver.k8s.io/v1alpha1",
+		"kind":       "StorageVersion",
+		"metadata":   map[string]any{"name": "autoscaling.horizontalpodautoscalers"},
+		"status":     map[string]any{"commonEncodingVersion": "autoscaling/v2"},
+	}}
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newVersionsOptions(ioStreams)
+	options.resource = "HorizontalPodAutoscaler.autoscaling"
+	options.discoveryClient = discoverytesting.New()
+	options.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			storageVersionsGVR:           "StorageVersionList",
+			customResourceDefinitionsGVR: "CustomResourceDefinitionList",
+		},
+		storageVersion,
+	)
+
+	err := runVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runVersions() error = %v", err)
+	}
+
+	want := "NAME                                   APIVERSION            KIND                      PREFERRED   " +
+		"STORAGE   DEPRECATED\n" +
+		"horizontalpodautoscalers.autoscaling   autoscaling/v2        HorizontalPodAutoscaler   true        " +
+		"true      false\n" +
+		"horizontalpodautoscalers.autoscaling   autoscaling/v1        HorizontalPodAutoscaler   false       " +
+		"false     false\n" +
+		"horizontalpodautoscalers.autoscaling   autoscaling/v2beta2   HorizontalPodAutoscaler   false       " +
+		"false     true\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runVersions() output = %q, want %q", got, want)
+	}
+}
+
+// TestRunVersionsIdenticalSchemas tests marking the served versions whose OpenAPI v3 schemas differ only by their
+// descriptions.
+func TestRunVersionsIdenticalSchemas(t *testing.T) {
+	t.Parallel()
+
+	ioStreams, _, stdout, _ := genericiooptions.NewTestIOStreams()
+	options := newVersionsOptions(ioStreams)
+	options.NoHeaders = true
+	options.IdenticalSchemas = true
+	options.resource = "hpa"
+	options.discoveryClient = discoverytesting.New()
+	options.openAPIClient = openapitest.NewFileClient("testdata/openapi")
+
+	err := runVersions(t.Context(), options)
+	if err != nil {
+		t.Fatalf("runVersions() error = %v", err)
+	}
+
+	want := "horizontalpodautoscalers.autoscaling   autoscaling/v2        HorizontalPodAutoscaler   true    " +
+		"<unknown>   false   autoscaling/v2beta2\n" +
+		"horizontalpodautoscalers.autoscaling   autoscaling/v1        HorizontalPodAutoscaler   false   " +
+		"<unknown>   false   <none>\n" +
+		"horizontalpodautoscalers.autoscaling   autoscaling/v2beta2   HorizontalPodAutoscaler   false   " +
+		"<unknown>   true    autoscaling/v2\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runVersions() output = %q, want %q", got, want)
+	}
+}
+
+// TestGetServedVersions tests resolving the resource by its names.
+func TestGetServedVersions(t *testing.T) {
+	t.Parallel()
+
+	for _, arg := range []string{"pods", "pod", "po", "Pod", "pods."} {
+		versions, err := getServedVersions(discoverytesting.New(), arg)
+		if err != nil {
+			t.Errorf("getServedVersions(%q) error = %v", arg, err)
+
+			continue
+		}
+
+		want := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		if len(versions) != 1 || versions[0].GroupVersionResource != want {
+			t.Errorf("getServedVersions(%q) = %v, want %v", arg, versions, want)
+		}
+	}
+
+	for _, arg := range []string{"deployments", "pods.apps", ""} {
+		_, err := getServedVersions(discoverytesting.New(), arg)
+		if !errors.Is(err, errResourceNotFound) {
+			t.Errorf("getServedVersions(%q) error = %v, want %v", arg, err, errResourceNotFound)
+		}
+	}
+}
+
+// TestCustomResourceStorage tests reading the storage and deprecated versions of a CustomResourceDefinition.
+func TestCustomResourceStorage(t *testing.T) {
+	t.Parallel()
+
+	crd := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"versions": []any{
+			map[string]any{"name": "v1alpha1", "served": true, "deprecated": true},
+			map[string]any{"name": "v1", "served": true, "storage": true},
+		}},
+	}}
+
+	got := customResourceStorage(crd)
+
+	want := resourceStorage{Version: "v1", DeprecatedVersions: sets.New("v1alpha1")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("customResourceStorage() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// errNameVersionsServed is returned when --show-versions-served is requested with --output=name, name0,
+// api-versions, or script, which don't print the number of versions.
+const errNameVersionsServed = constError("show-versions-served has no effect with output=name, name0, api-versions, " +
+	"or script, which don't print the number of versions: remove show-versions-served or use the default or wide " +
+	"output")
+
+// errVersionsServedStream is returned when --show-versions-served is requested with --stream, as the versions of a
+// resource are only known once all the versions of its group have been discovered.
+const errVersionsServedStream = constError("show-versions-served is not supported with stream")
+
+// servedVersionCounts returns the number of versions of the group serving each resource, keyed by the name of the
+// resource, e.g. "deployments" or "deployments/status", given the resource lists of the versions of the group.
+func servedVersionCounts(resourceLists []*metav1.APIResourceList) map[string]int {
+	counts := make(map[string]int)
+
+	for _, resourceList := range resourceLists {
+		for _, apiResource := range resourceList.APIResources {
+			counts[apiResource.Name]++
+		}
+	}
+
+	return counts
+}
+
+// versionsServedString returns the number of versions serving the resource, as printed in the VERSIONS-SERVED column.
+func (gr groupResource) versionsServedString() string {
+	return strconv.Itoa(gr.VersionsServed)
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// TestRunVersionsServed tests the VERSIONS-SERVED column, which counts the versions filtered out.
+func TestRunVersionsServed(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetPreferred(true).SetShowVersionsServed(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	want := "NAME                       SHORTNAMES   APIVERSION       NAMESPACED   KIND                      PREFERRED   " +
+		"VERSIONS-SERVED\n" +
+		"horizontalpodautoscalers   hpa          autoscaling/v2   true         HorizontalPodAutoscaler   true        3\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("runAPIResourceVersions() output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	restclient "k8s.io/client-go/rest"
+)
+
+// warningCollector collects the warnings returned by the API server in the Warning headers of the responses, e.g. the
+// deprecation warnings of the deprecated versions listed by --show-counts, so that they are printed once in a WARNINGS
+// section after the output rather than logged as they are received.
+type warningCollector struct {
+	mutex sync.Mutex
+	// warnings are the distinct warnings, in the order they were received.
+	warnings []string
+	seen     map[string]struct{}
+}
+
+// newWarningCollector returns a new [warningCollector] without warnings.
+func newWarningCollector() *warningCollector {
+	return &warningCollector{seen: make(map[string]struct{})}
+}
+
+// HandleWarningHeader implements [restclient.WarningHandler].
+func (c *warningCollector) HandleWarningHeader(code int, _ string, text string) {
+	// Only the 299 warn-code is used by the API server, see [restclient.WarningLogger].
+	//nolint:mnd
+	if code != 299 || text == "" {
+		return
+	}
+
+	c.add(text)
+}
+
+// add collects a warning, unless it has already been collected, e.g. a warning about the discovery documents.
+// Warnings added to a nil collector are dropped.
+func (c *warningCollector) add(text string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.seen[text]; ok {
+		return
+	}
+
+	c.seen[text] = struct{}{}
+	c.warnings = append(c.warnings, text)
+}
+
+// wrapConfig returns a function installing the collector as the warning handler of the REST configs, after applying
+// the previous wrapConfig function if it isn't nil, for [genericclioptions.ConfigFlags.WrapConfigFn].
+func (c *warningCollector) wrapConfig(
+	wrapConfig func(*restclient.Config) *restclient.Config,
+) func(*restclient.Config) *restclient.Config {
+	return func(config *restclient.Config) *restclient.Config {
+		if wrapConfig != nil {
+			config = wrapConfig(config)
+		}
+
+		config.WarningHandler = c
+		config.WarningHandlerWithContext = nil
+
+		return config
+	}
+}
+
+// print prints the WARNINGS section with the warnings collected so far, if any.
+func (c *warningCollector) print(writer io.Writer) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.warnings) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(writer, "WARNINGS:")
+
+	for _, warning := range c.warnings {
+		_, _ = fmt.Fprintf(writer, "  %s\n", warning)
+	}
+}
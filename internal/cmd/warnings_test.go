@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/discovery"
+	restclient "k8s.io/client-go/rest"
+)
+
+// TestWarningCollector tests collecting the distinct warnings of the API server responses and printing them once.
+func TestWarningCollector(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("Warning", `299 - "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+"`)
+		w.Header().Add("Warning", `299 - "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major": "1", "minor": "24", "gitVersion": "v1.24.0"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	warnings := newWarningCollector()
+	config := warnings.wrapConfig(nil)(&restclient.Config{Host: server.URL})
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		_, err = discoveryClient.ServerVersion()
+		if err != nil {
+			t.Fatalf("ServerVersion() error = %v", err)
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	warnings.print(&buffer)
+
+	want := "WARNINGS:\n  policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+\n"
+	if buffer.String() != want {
+		t.Errorf("print() = %q, want %q", buffer.String(), want)
+	}
+}
+
+// TestWarningCollectorEmpty tests that the WARNINGS section isn't printed without warnings.
+func TestWarningCollectorEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	newWarningCollector().print(&buffer)
+
+	if buffer.Len() != 0 {
+		t.Errorf("print() = %q, want none", buffer.String())
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// defaultWatchInterval is the interval at which the resources are re-discovered with --watch by default.
+const defaultWatchInterval = time.Minute
+
+// resourceChange is the kind of change of a resource version between two discoveries.
+type resourceChange string
+
+const (
+	// resourceAdded is used when a resource version is served which wasn't served before.
+	resourceAdded resourceChange = "added"
+	// resourceRemoved is used when a resource version isn't served anymore.
+	resourceRemoved resourceChange = "removed"
+	// resourcePreferred is used when a resource version becomes the preferred version of a resource which was served
+	// before with another preferred version.
+	resourcePreferred resourceChange = "preferred"
+)
+
+// watchChange is a change of a resource version between two discoveries.
+type watchChange struct {
+	// Change is the kind of change.
+	Change resourceChange
+	// Name is the full name of the resource version, see [groupResource.fullname].
+	Name string
+}
+
+// runWatch prints the API resources and their group versions, then re-discovers them every interval and prints the
+// additions, removals, and changes of preferred version, until the context is done.
+func runWatch(ctx context.Context, options *apiResourceVersionsOptions) error {
+	previous, err := listGroupResources(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	err = printGroupResources(slices.Clone(previous), options)
+	if err != nil {
+		return err
+	}
+
+	// The discovery must be refreshed for the changes to be observed.
+	options.Cached = false
+	options.CacheTTL = 0
+
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := listGroupResources(ctx, options)
+		if err != nil {
+			_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't re-discover the resources: %v\n", err)
+
+			continue
+		}
+
+		changes := diffGroupResources(previous, current)
+
+		err = printWatchChanges(options.Out, changes)
+		if err != nil {
+			return err
+		}
+
+		if options.Notify.URL != "" && len(changes) > 0 {
+			kinds := newServedKinds(current, options.discoveryClient)
+
+			err = options.Notify.notify(ctx, options.contextName, watchReportChanges(changes, current, kinds))
+			if err != nil {
+				_, _ = fmt.Fprintf(options.ErrOut, "Warning: couldn't notify the changes: %v\n", err)
+			}
+		}
+
+		previous = current
+	}
+}
+
+// diffGroupResources returns the resource versions added to or removed from the current resources, and the
+// resource versions which became preferred, compared with the previous resources.
+func diffGroupResources(previous, current []groupResource) []watchChange {
+	previousNames, previousPreferred := indexGroupResources(previous)
+	currentNames, currentPreferred := indexGroupResources(current)
+
+	var changes []watchChange
+
+	for _, name := range slices.Sorted(maps.Keys(currentNames)) {
+		if _, ok := previousNames[name]; !ok {
+			changes = append(changes, watchChange{Change: resourceAdded, Name: name})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(previousNames)) {
+		if _, ok := currentNames[name]; !ok {
+			changes = append(changes, watchChange{Change: resourceRemoved, Name: name})
+		}
+	}
+
+	for _, resource := range slices.Sorted(maps.Keys(currentPreferred)) {
+		previousName, ok := previousPreferred[resource]
+		if ok && previousName != currentPreferred[resource] {
+			changes = append(changes, watchChange{Change: resourcePreferred, Name: currentPreferred[resource]})
+		}
+	}
+
+	return changes
+}
+
+// indexGroupResources returns the set of the full names of the resource versions, and the full name of the preferred
+// version of each resource, see [unversionedResourceName].
+func indexGroupResources(resources []groupResource) (map[string]struct{}, map[string]string) {
+	names := make(map[string]struct{}, len(resources))
+	preferred := make(map[string]string)
+
+	for _, resource := range resources {
+		name := resource.fullname()
+		names[name] = struct{}{}
+
+		if resource.Preferred && !resource.Subresource {
+			resourceName, _ := unversionedResourceName(*resource.APIResource)
+			preferred[resourceName] = name
+		}
+	}
+
+	return names, preferred
+}
+
+// printWatchChanges prints the changes of resource versions, one per line.
+func printWatchChanges(out io.Writer, changes []watchChange) error {
+	writer := printers.GetNewTabWriter(out)
+	defer mustFlushWriter(writer)
+
+	for _, change := range changes {
+		err := printRow(writer, []string{string(change.Change), change.Name})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newWatchTestResource returns a [groupResource] of the resource in the group version.
+func newWatchTestResource(group, version, name string, preferred bool) groupResource {
+	groupVersion := version
+	if group != "" {
+		groupVersion = group + "/" + version
+	}
+
+	return groupResource{
+		APIGroup:        &resourceGroup{Name: group},
+		APIGroupVersion: groupVersion,
+		APIResource:     &metav1.APIResource{Name: name, Group: group},
+		Preferred:       preferred,
+	}
+}
+
+// TestDiffGroupResources tests the changes printed with --watch.
+func TestDiffGroupResources(t *testing.T) {
+	t.Parallel()
+
+	previous := []groupResource{
+		newWatchTestResource("", "v1", "pods", true),
+		newWatchTestResource("autoscaling", "v1", "horizontalpodautoscalers", true),
+		newWatchTestResource("autoscaling", "v2beta2", "horizontalpodautoscalers", false),
+		newWatchTestResource("batch", "v1beta1", "cronjobs", true),
+	}
+	current := []groupResource{
+		newWatchTestResource("", "v1", "pods", true),
+		newWatchTestResource("autoscaling", "v1", "horizontalpodautoscalers", false),
+		newWatchTestResource("autoscaling", "v2", "horizontalpodautoscalers", true),
+		newWatchTestResource("example.com", "v1", "widgets", true),
+	}
+
+	got := diffGroupResources(previous, current)
+	want := []watchChange{
+		{Change: resourceAdded, Name: "horizontalpodautoscalers.v2.autoscaling"},
+		{Change: resourceAdded, Name: "widgets.v1.example.com"},
+		{Change: resourceRemoved, Name: "cronjobs.v1beta1.batch"},
+		{Change: resourceRemoved, Name: "horizontalpodautoscalers.v2beta2.autoscaling"},
+		{Change: resourcePreferred, Name: "horizontalpodautoscalers.v2.autoscaling"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffGroupResources() = %v, want %v", got, want)
+	}
+
+	if changes := diffGroupResources(current, current); len(changes) != 0 {
+		t.Errorf("diffGroupResources() without changes = %v, want none", changes)
+	}
+
+	out := &bytes.Buffer{}
+
+	err := printWatchChanges(out, want)
+	if err != nil {
+		t.Fatalf("printWatchChanges() error = %v", err)
+	}
+
+	wantOut := "added       horizontalpodautoscalers.v2.autoscaling\n" +
+		"added       widgets.v1.example.com\n" +
+		"removed     cronjobs.v1beta1.batch\n" +
+		"removed     horizontalpodautoscalers.v2beta2.autoscaling\n" +
+		"preferred   horizontalpodautoscalers.v2.autoscaling\n"
+	if out.String() != wantOut {
+		t.Errorf("printWatchChanges() output = %q, want %q", out.String(), wantOut)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+)
+
+// errYAMLMode is returned when --output=yaml is requested with a mode printing the resources as they are discovered.
+const errYAMLMode = constError("output=yaml is not supported with stream or watch")
+
+// resourceDocument is a resource printed with --output=yaml, with the columns of the wide output.
+type resourceDocument struct {
+	// Cluster is the kubeconfig context the resource was discovered in, when listing multiple contexts.
+	Cluster string `json:"cluster,omitempty"`
+	// ServerVersion is the version of the API server of the cluster, with --show-server-version.
+	ServerVersion  string   `json:"serverVersion,omitempty"`
+	Name           string   `json:"name"`
+	ShortNames     []string `json:"shortNames,omitempty"`
+	APIVersion     string   `json:"apiVersion"`
+	Namespaced     bool     `json:"namespaced"`
+	Kind           string   `json:"kind"`
+	Preferred      bool     `json:"preferred"`
+	GroupPreferred bool     `json:"groupPreferred"`
+	Verbs          []string `json:"verbs"`
+	Categories     []string `json:"categories,omitempty"`
+	// VersionsServed is the number of versions of the group serving the resource, with --show-versions-served.
+	VersionsServed int `json:"versionsServed,omitempty"`
+	// GroupPriority is the priority of the group of the resource, with --show-group-priority.
+	GroupPriority *int64 `json:"groupPriority,omitempty"`
+	// MinK8s is the Kubernetes release in which the built-in resource version was introduced, with --show-min-k8s.
+	MinK8s string `json:"minK8s,omitempty"`
+	// Requested is whether the resource has been requested since the API server started, with --show-usage.
+	Requested *bool `json:"requested,omitempty"`
+	// Count is the approximate number of objects of the resource, if they have been counted.
+	Count *int64 `json:"count,omitempty"`
+	// Command is the kubectl command reading the objects of the resource, with --show-commands.
+	Command string `json:"command,omitempty"`
+}
+
+// newResourceDocument returns the document printed for the resource with --output=yaml.
+func newResourceDocument(resource groupResource, options *apiResourceVersionsOptions) resourceDocument {
+	document := resourceDocument{
+		Cluster:        resource.Cluster,
+		ServerVersion:  resource.ServerVersion,
+		Name:           resource.APIResource.Name,
+		ShortNames:     resource.APIResource.ShortNames,
+		APIVersion:     options.displayGroupVersion(resource.APIGroupVersion),
+		Namespaced:     resource.APIResource.Namespaced,
+		Kind:           resource.APIResource.Kind,
+		Preferred:      resource.Preferred,
+		GroupPreferred: resource.PreferredGroupVersion(),
+		Verbs:          resource.APIResource.Verbs,
+		Categories:     resource.APIResource.Categories,
+		VersionsServed: resource.VersionsServed,
+		GroupPriority:  resource.GroupPriority,
+		MinK8s:         "",
+		Requested:      resource.Requested,
+		Count:          resource.Count,
+		Command:        "",
+	}
+
+	if options.ShowMinK8s {
+		document.MinK8s = resource.minK8s()
+	}
+
+	if options.ShowCommands {
+		document.Command = resource.command()
+	}
+
+	return document
+}
+
+// metadataDocument is the first document printed with --output=yaml, before the resources.
+type metadataDocument struct {
+	Metadata outputMetadata `json:"metadata"`
+}
+
+// printYAML prints the API resources as a stream of YAML documents, one for each resource, after a
+// [metadataDocument].
+func printYAML(out io.Writer, resources []groupResource, options *apiResourceVersionsOptions) error {
+	sort.Stable(sortableResource{resources, options.SortBy})
+
+	encoder := yamlutil.NewDocumentEncoder(out)
+
+	err := encoder.Encode(metadataDocument{Metadata: options.outputMetadata()})
+	if err != nil {
+		return fmt.Errorf("couldn't print metadata: %w", err)
+	}
+
+	for _, resource := range resources {
+		err := encoder.Encode(newResourceDocument(resource, options))
+		if err != nil {
+			return fmt.Errorf("couldn't print resource %s: %w", resource.fullname(), err)
+		}
+	}
+
+	//nolint:wrapcheck
+	return encoder.Close()
+}
+
+// outputMetadata returns the metadata of the output, with the server version of each of the clusters.
+func (o *apiResourceVersionsOptions) outputMetadata() outputMetadata {
+	if len(o.clusters) == 0 {
+		return newOutputMetadata(newClusterMetadata(o.contextName, o.discoveryClient))
+	}
+
+	clusters := make([]clusterMetadata, 0, len(o.clusters))
+	for _, cluster := range o.clusters {
+		clusters = append(clusters, newClusterMetadata(cluster.context, cluster.discoveryClient))
+	}
+
+	return newOutputMetadata(clusters...)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
+)
+
+// TestRunYAML tests printing the resources as a stream of YAML documents.
+func TestRunYAML(t *testing.T) {
+	t.Parallel()
+
+	builder := NewTestOptionsBuilder().SetAPIGroup("autoscaling").SetOutput(yamlOutput).SetShowCommands(true)
+	_, stdout, _ := builder.GetBuffers()
+
+	err := runAPIResourceVersions(t.Context(), builder.APIResourceVersionsOptions())
+	if err != nil {
+		t.Fatalf("runAPIResourceVersions() error = %v", err)
+	}
+
+	var (
+		metadata  metadataDocument
+		documents []resourceDocument
+	)
+
+	for result := range yamlutil.YAMLDocumentsToJSON(stdout) {
+		decoder, err := result.GetDecoder()
+		if err != nil {
+			t.Fatalf("GetDecoder() error = %v", err)
+		}
+
+		if metadata.Metadata.Timestamp.IsZero() {
+			err = decoder.Decode(&metadata)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			continue
+		}
+
+		document := resourceDocument{}
+
+		err = decoder.Decode(&document)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		documents = append(documents, document)
+	}
+
+	if len(metadata.Metadata.Clusters) != 1 {
+		t.Errorf("metadata clusters = %v, want the current context", metadata.Metadata.Clusters)
+	}
+
+	var apiVersions []string
+	for _, document := range documents {
+		apiVersions = append(apiVersions, document.APIVersion)
+	}
+
+	if want := []string{"autoscaling/v2", "autoscaling/v1", "autoscaling/v2beta2"}; !reflect.DeepEqual(apiVersions, want) {
+		t.Fatalf("documents apiVersions = %v, want %v", apiVersions, want)
+	}
+
+	want := resourceDocument{
+		Cluster:        "",
+		Name:           "horizontalpodautoscalers",
+		ShortNames:     []string{"hpa"},
+		APIVersion:     "autoscaling/v2",
+		Namespaced:     true,
+		Kind:           "HorizontalPodAutoscaler",
+		Preferred:      true,
+		GroupPreferred: true,
+		Verbs:          []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"},
+		Categories:     []string{"all"},
+		Count:          nil,
+		Command:        "kubectl get horizontalpodautoscalers.v2.autoscaling -A",
+	}
+	if !reflect.DeepEqual(documents[0], want) {
+		t.Errorf("documents[0] = %+v, want %+v", documents[0], want)
+	}
+}
@@ -0,0 +1,165 @@
+// Package lifecycle provides the lifecycle of the built-in Kubernetes APIs, i.e. the Kubernetes releases in which
+// each kind was introduced, deprecated, and removed from a group version.
+// Only the APIs enabled by default are included, so the APIs served in a release are those of a stock cluster.
+package lifecycle
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"slices"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+//go:embed data/lifecycle.yaml
+var lifecycleYAML []byte
+
+// API is the lifecycle of a kind in a built-in group version.
+// Releases are Kubernetes minor releases in the format "<major>.<minor>", e.g. "1.22", and are empty if unknown or
+// not applicable.
+type API struct {
+	// Group is the API group of the kind, empty for the core group.
+	Group string `json:"group"`
+	// Version is the API version of the kind, e.g. "v1beta1".
+	Version string `json:"version"`
+	// Kind is the kind, e.g. "Deployment".
+	Kind string `json:"kind"`
+	// Introduced is the release in which the kind was introduced in the group version.
+	Introduced string `json:"introduced,omitempty"`
+	// Deprecated is the release in which the kind was deprecated in the group version.
+	Deprecated string `json:"deprecated,omitempty"`
+	// Removed is the release in which the kind is no longer served in the group version.
+	Removed string `json:"removed,omitempty"`
+	// Replacement is the group version which replaces this one for the kind, e.g. "apps/v1".
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// GroupVersionKind returns the group, version, and kind of the API.
+func (a API) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: a.Group, Version: a.Version, Kind: a.Kind}
+}
+
+// DeprecatedIn returns true if the API is deprecated in the given release.
+// If the release is nil, it returns true if the API is ever deprecated.
+func (a API) DeprecatedIn(release *version.Version) bool {
+	return reachedIn(a.Deprecated, release) || a.RemovedIn(release)
+}
+
+// RemovedIn returns true if the API is no longer served in the given release.
+// If the release is nil, it returns true if the API is ever removed.
+func (a API) RemovedIn(release *version.Version) bool {
+	return reachedIn(a.Removed, release)
+}
+
+// ServedIn returns true if the API is served by default in the given release, i.e. it has been introduced, if the
+// release in which it was introduced is known, and it has not been removed.
+func (a API) ServedIn(release *version.Version) bool {
+	return (a.Introduced == "" || reachedIn(a.Introduced, release)) && !a.RemovedIn(release)
+}
+
+// reachedIn returns true if the lifecycle milestone is at or before the given release.
+func reachedIn(milestone string, release *version.Version) bool {
+	if milestone == "" {
+		return false
+	} else if release == nil {
+		return true
+	}
+
+	milestoneVersion := version.MustParseGeneric(milestone)
+
+	return release.AtLeast(milestoneVersion)
+}
+
+// disabledGroups are the built-in API groups whose APIs are all disabled by default, so missing from the lifecycle
+// database.
+//
+//nolint:gochecknoglobals
+var disabledGroups = []string{"internal.apiserver.k8s.io", "storagemigration.k8s.io"}
+
+// groups returns the built-in API groups, those of the lifecycle database and the disabled ones.
+//
+//nolint:gochecknoglobals
+var groups = sync.OnceValue(func() map[string]struct{} {
+	index := make(map[string]struct{})
+	for gvk := range apis() {
+		index[gvk.Group] = struct{}{}
+	}
+
+	for _, group := range disabledGroups {
+		index[group] = struct{}{}
+	}
+
+	return index
+})
+
+// BuiltInGroup returns true if the API group is served by the Kubernetes API server itself, e.g. "" for the core
+// group or "apps", rather than by custom resource definitions or aggregated API servers.
+// Groups under k8s.io which aren't served by the API server, e.g. "metrics.k8s.io" or "gateway.networking.k8s.io",
+// aren't built-in.
+func BuiltInGroup(group string) bool {
+	_, ok := groups()[group]
+
+	return ok
+}
+
+// apis returns the lifecycle database indexed by group, version, and kind.
+//
+//nolint:gochecknoglobals
+var apis = sync.OnceValue(func() map[schema.GroupVersionKind]API {
+	var list []API
+
+	err := k8syaml.Unmarshal(lifecycleYAML, &list)
+	if err != nil {
+		panic(fmt.Errorf("failed to unmarshal the lifecycle database: %w", err))
+	}
+
+	index := make(map[schema.GroupVersionKind]API, len(list))
+	for _, api := range list {
+		index[api.GroupVersionKind()] = api
+	}
+
+	return index
+})
+
+// Lookup returns the lifecycle of the kind in the group version, if it is known.
+func Lookup(gvk schema.GroupVersionKind) (API, bool) {
+	api, ok := apis()[gvk]
+
+	return api, ok
+}
+
+// All returns the lifecycle of every known API, sorted by group, version, and kind.
+// Use [API.ServedIn] to select the APIs served by a release.
+func All() []API {
+	index := apis()
+
+	all := make([]API, 0, len(index))
+	for _, api := range index {
+		all = append(all, api)
+	}
+
+	slices.SortFunc(all, func(left, right API) int {
+		return cmp.Or(
+			cmp.Compare(left.Group, right.Group),
+			cmp.Compare(left.Version, right.Version),
+			cmp.Compare(left.Kind, right.Kind),
+		)
+	})
+
+	return all
+}
+
+// ParseRelease parses a Kubernetes release or server version, e.g. "1.33" or "v1.33.1-gke.100", ignoring the patch
+// version and any pre-release or build metadata.
+func ParseRelease(release string) (*version.Version, error) {
+	parsed, err := version.ParseGeneric(release)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse release %q: %w", release, err)
+	}
+
+	return version.MajorMinor(parsed.Major(), parsed.Minor()), nil
+}
@@ -0,0 +1,125 @@
+package lifecycle_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/internal/lifecycle"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func ExampleLookup() {
+	api, ok := lifecycle.Lookup(schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"})
+	if !ok {
+		panic("CronJob batch/v1beta1 not found")
+	}
+
+	release, err := lifecycle.ParseRelease("v1.24.3")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("deprecated in %s: %t\n", release, api.DeprecatedIn(release))
+	fmt.Printf("removed in %s: %t\n", release, api.RemovedIn(release))
+	fmt.Printf("replacement: %s\n", api.Replacement)
+	// Output:
+	// deprecated in 1.24: true
+	// removed in 1.24: false
+	// replacement: batch/v1
+}
+
+// TestLifecycleDatabase tests that every entry of the lifecycle database is consistent.
+func TestLifecycleDatabase(t *testing.T) {
+	t.Parallel()
+
+	for _, gvk := range []schema.GroupVersionKind{
+		{Group: "extensions", Version: "v1beta1", Kind: "Ingress"},
+		{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"},
+		{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Kind: "FlowSchema"},
+	} {
+		api, ok := lifecycle.Lookup(gvk)
+		if !ok {
+			t.Errorf("Lookup(%v) not found", gvk)
+
+			continue
+		}
+
+		if !api.RemovedIn(nil) || !api.DeprecatedIn(nil) {
+			t.Errorf("Lookup(%v) = %+v, want deprecated and removed", gvk, api)
+		}
+	}
+
+	for _, api := range lifecycle.All() {
+		for _, milestone := range []string{api.Introduced, api.Deprecated, api.Removed} {
+			if milestone == "" {
+				continue
+			}
+
+			_, err := lifecycle.ParseRelease(milestone)
+			if err != nil {
+				t.Errorf("%v: %v", api.GroupVersionKind(), err)
+			}
+		}
+
+		if api.Removed != "" && api.Deprecated == "" {
+			t.Errorf("%v: removed without being deprecated", api.GroupVersionKind())
+		}
+	}
+
+	api, ok := lifecycle.Lookup(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	if !ok || api.DeprecatedIn(nil) {
+		t.Errorf("Lookup(apps/v1 Deployment) = %+v, %t, want served and never deprecated", api, ok)
+	}
+
+	_, ok = lifecycle.Lookup(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})
+	if ok {
+		t.Errorf("Lookup(example.com/v1 Widget) found, want not found")
+	}
+}
+
+// TestServedIn tests the selection of the APIs served by a release.
+func TestServedIn(t *testing.T) {
+	t.Parallel()
+
+	release, err := lifecycle.ParseRelease("v1.25")
+	if err != nil {
+		t.Fatalf("ParseRelease() error = %v", err)
+	}
+
+	for gvk, want := range map[schema.GroupVersionKind]bool{
+		{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}:      true,
+		{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"}: true,
+		{Group: "autoscaling", Version: "v2beta1", Kind: "HorizontalPodAutoscaler"}: false,
+		{Group: "flowcontrol.apiserver.k8s.io", Version: "v1", Kind: "FlowSchema"}:  false,
+	} {
+		api, ok := lifecycle.Lookup(gvk)
+		if !ok {
+			t.Errorf("Lookup(%v) not found", gvk)
+
+			continue
+		}
+
+		if got := api.ServedIn(release); got != want {
+			t.Errorf("ServedIn(%s) of %v = %t, want %t", release, gvk, got, want)
+		}
+	}
+}
+
+// TestBuiltInGroup tests the selection of the API groups served by the Kubernetes API server itself.
+func TestBuiltInGroup(t *testing.T) {
+	t.Parallel()
+
+	for group, want := range map[string]bool{
+		"":                          true,
+		"apps":                      true,
+		"apiextensions.k8s.io":      true,
+		"storagemigration.k8s.io":   true,
+		"metrics.k8s.io":            false,
+		"gateway.networking.k8s.io": false,
+		"cert-manager.io":           false,
+	} {
+		if got := lifecycle.BuiltInGroup(group); got != want {
+			t.Errorf("BuiltInGroup(%q) = %t, want %t", group, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package yamlutil
+
+import (
+	"errors"
+	"io"
+	"iter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// concurrentDocumentsToJSON converts a stream of YAML documents into a sequence of JSON documents like
+// [YAMLDocumentsToJSONWithOptions], but transcodes up to [DecodeOptions.Concurrency] documents concurrently while
+// the next documents are decoded.
+// Decoding the stream is inherently sequential, but checking the limits of the documents and marshalling them to
+// JSON are not.
+// If the iteration is stopped early, the decoder stops after the document it is decoding, so the stream may be read
+// further than the last document yielded.
+func concurrentDocumentsToJSON(yamlStream io.Reader, options DecodeOptions) iter.Seq[YAMLToJSON] {
+	return func(yield func(YAMLToJSON) bool) {
+		// Each pending document has its own result channel, so the results are yielded in the order of the stream,
+		// and the buffer of pending documents bounds the number of documents transcoded concurrently.
+		// The document awaited by the loop below is no longer buffered, hence the buffer of one less document.
+		pending := make(chan chan YAMLToJSON, options.Concurrency-1)
+		done := make(chan struct{})
+
+		defer close(done)
+
+		go decodeDocuments(yamlStream, options, pending, done)
+
+		for result := range pending {
+			if !yield(<-result) {
+				return
+			}
+		}
+	}
+}
+
+// decodeDocuments decodes the documents of the stream, and starts transcoding each of them in its own goroutine,
+// until the end of the stream, a syntax error, or done is closed.
+// The result channel of each document is sent to pending in the order of the stream, which is closed at the end.
+func decodeDocuments(
+	yamlStream io.Reader,
+	options DecodeOptions,
+	pending chan<- chan YAMLToJSON,
+	done <-chan struct{},
+) {
+	defer close(pending)
+
+	decoder := yaml.NewDecoder(yamlStream)
+
+	for document := 0; ; document++ {
+		result := make(chan YAMLToJSON, 1)
+
+		node := &yaml.Node{}
+
+		err := decoder.Decode(node)
+		if errors.Is(err, io.EOF) {
+			return // End of documents
+		}
+
+		select {
+		case pending <- result:
+		case <-done:
+			return
+		}
+
+		if err != nil {
+			result <- &yamlToJSONErr{err: newSyntaxError(document, err)}
+
+			return // We can't continue if we can't decode the document, as we won't necessarily be able to find the next one.
+		}
+
+		go func() {
+			jsonBytes, at, err := nodeToJSON(node, options)
+			if err != nil {
+				result <- &yamlToJSONErr{err: newNodeError(document, at, err)}
+
+				return
+			}
+
+			result <- &yamlToJSON{data: jsonBytes}
+		}()
+	}
+}
@@ -0,0 +1,71 @@
+package yamlutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const yamlIndent = 2
+
+// DocumentEncoder writes a sequence of Go values as a stream of YAML documents separated by `---`.
+// Values are marshalled through encoding/json first, so that the json struct tags and json.Marshaler implementations
+// are honoured, and the field order of structs is preserved.
+type DocumentEncoder struct {
+	encoder *yaml.Encoder
+}
+
+// NewDocumentEncoder returns a DocumentEncoder writing to w.
+// The caller must call Close once all the documents have been encoded to flush the stream.
+func NewDocumentEncoder(w io.Writer) *DocumentEncoder {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(yamlIndent)
+
+	return &DocumentEncoder{encoder: encoder}
+}
+
+// Encode writes v as the next YAML document of the stream.
+func (e *DocumentEncoder) Encode(v any) error {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document to JSON: %w", err)
+	}
+
+	var node yaml.Node
+
+	// JSON is a subset of YAML, decoding it into a node keeps the order of the keys.
+	err = yaml.Unmarshal(jsonBytes, &node)
+	if err != nil {
+		return fmt.Errorf("failed to decode JSON document as YAML: %w", err)
+	}
+
+	resetStyle(&node)
+
+	err = e.encoder.Encode(&node)
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML document: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes the stream of documents.
+func (e *DocumentEncoder) Close() error {
+	err := e.encoder.Close()
+	if err != nil {
+		return fmt.Errorf("failed to flush YAML documents: %w", err)
+	}
+
+	return nil
+}
+
+// resetStyle drops the flow and quoting styles inherited from the JSON source, so the documents are written in the
+// block style, quoting only the scalars which require it.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
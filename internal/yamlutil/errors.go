@@ -0,0 +1,78 @@
+package yamlutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentError is the error of a document of a stream, with its position in the stream.
+type DocumentError struct {
+	// Document is the index of the document in the stream, starting at 0.
+	Document int
+	// Line is the line of the stream at which the error occurred, starting at 1, or 0 if it is unknown.
+	Line int
+	// Column is the column of the line at which the error occurred, starting at 1, or 0 if it is unknown.
+	Column int
+	// Err is the error of the document.
+	Err error
+}
+
+// Error returns the error with its position, e.g. "document 1, line 3, column 5: ...".
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("document %d, %s: %v", e.Document, e.Position(), e.Err)
+}
+
+// Unwrap returns the error of the document.
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// Position returns the line and column of the error, e.g. "line 3, column 5", omitting them when they are unknown.
+func (e *DocumentError) Position() string {
+	switch {
+	case e.Line == 0:
+		return "unknown position"
+	case e.Column == 0:
+		return "line " + strconv.Itoa(e.Line)
+	default:
+		return "line " + strconv.Itoa(e.Line) + ", column " + strconv.Itoa(e.Column)
+	}
+}
+
+// newNodeError returns the error of the document at the position of the node, if any.
+func newNodeError(document int, node *yaml.Node, err error) *DocumentError {
+	documentErr := &DocumentError{Document: document, Line: 0, Column: 0, Err: err}
+	if node != nil {
+		documentErr.Line = node.Line
+		documentErr.Column = node.Column
+	}
+
+	return documentErr
+}
+
+// syntaxErrorPattern matches the syntax errors of gopkg.in/yaml.v3, which only expose their line in their message.
+//
+//nolint:gochecknoglobals
+var syntaxErrorPattern = regexp.MustCompile(`(?s)^yaml: line (\d+): (.*)$`)
+
+// newSyntaxError returns the error of the document for a syntax error of the decoder, with its line if known.
+func newSyntaxError(document int, err error) *DocumentError {
+	documentErr := &DocumentError{Document: document, Line: 0, Column: 0, Err: nil}
+
+	match := syntaxErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		documentErr.Err = fmt.Errorf("failed to decode YAML document: %w", err)
+
+		return documentErr
+	}
+
+	// The original error is an unexported type of the decoder which only provides its message, so only the message
+	// without its line is kept.
+	documentErr.Line, _ = strconv.Atoi(match[1])
+	documentErr.Err = fmt.Errorf("failed to decode YAML document: %s", match[2]) //nolint:err113
+
+	return documentErr
+}
@@ -0,0 +1,180 @@
+package yamlutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// isJSONStream returns true if the first character of the stream, after any whitespace, starts a JSON object or
+// array, without consuming the stream.
+// YAML streams can start with a flow mapping or sequence too, which [jsonDocuments] falls back to.
+func isJSONStream(reader *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		peeked, _ := reader.Peek(n)
+		if len(peeked) < n {
+			return false // End of the stream, or an error which the decoder will report.
+		}
+
+		switch peeked[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// jsonDocuments passes a stream of JSON values through as JSON documents, without transcoding them, rejecting the
+// documents which exceed the size or depth limits of the options.
+// If the first value isn't valid JSON, the stream is decoded as YAML, as it may be a YAML flow mapping or sequence.
+func jsonDocuments(reader io.Reader, options DecodeOptions) iter.Seq[YAMLToJSON] {
+	return func(yield func(YAMLToJSON) bool) {
+		stream := &jsonStream{reader: reader, recorded: &bytes.Buffer{}, lines: nil, offset: 0}
+		decoder := json.NewDecoder(stream)
+
+		for document := 0; ; document++ {
+			var raw json.RawMessage
+
+			err := decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				return // End of documents
+			}
+
+			if err != nil && document == 0 {
+				for result := range yamlDocumentsToJSON(io.MultiReader(stream.recorded, reader), options) {
+					if !yield(result) {
+						return
+					}
+				}
+
+				return
+			}
+
+			stream.recorded = nil // The stream is JSON, it no longer needs to be replayed.
+
+			if err != nil {
+				yield(&yamlToJSONErr{err: stream.syntaxError(document, err)})
+
+				return // We can't continue if we can't decode the value, as we won't find the next one.
+			}
+
+			err = checkJSONLimits(raw, options)
+			if err != nil {
+				line, column := stream.position(decoder.InputOffset() - int64(len(raw)))
+				if !yield(&yamlToJSONErr{err: &DocumentError{Document: document, Line: line, Column: column, Err: err}}) {
+					return
+				}
+
+				continue
+			}
+
+			if !yield(&yamlToJSON{data: raw}) {
+				return
+			}
+		}
+	}
+}
+
+// checkJSONLimits checks the size and depth of the JSON document against the limits of the options.
+// JSON has no aliases, so its size is the size of the document itself.
+func checkJSONLimits(raw json.RawMessage, options DecodeOptions) error {
+	if options.MaxDocumentSize > 0 && len(raw) > options.MaxDocumentSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrDocumentTooLarge, options.MaxDocumentSize)
+	}
+
+	if options.MaxDepth <= 0 {
+		return nil
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+
+	for _, c := range raw {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > options.MaxDepth {
+				return fmt.Errorf("%w: more than %d levels", ErrDocumentTooDeep, options.MaxDepth)
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// jsonStream reads a JSON stream, recording the bytes read until the stream is known to be JSON so that it can be
+// replayed as YAML, and the offsets of its lines so that the errors can point at their line and column.
+type jsonStream struct {
+	reader io.Reader
+	// recorded are the bytes read while the first value is decoded, or nil once it has been decoded.
+	recorded *bytes.Buffer
+	// lines are the offsets of the newlines of the stream.
+	lines []int64
+	// offset is the number of bytes read from the stream.
+	offset int64
+}
+
+// Read reads from the stream, recording what was read.
+func (s *jsonStream) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	if s.recorded != nil {
+		s.recorded.Write(p[:n])
+	}
+
+	for i, c := range p[:n] {
+		if c == '\n' {
+			s.lines = append(s.lines, s.offset+int64(i))
+		}
+	}
+
+	s.offset += int64(n)
+
+	return n, err //nolint:wrapcheck // The errors of the reader are returned as is to the JSON decoder.
+}
+
+// position returns the line and column of the offset in the stream, starting at 1.
+func (s *jsonStream) position(offset int64) (int, int) {
+	line, _ := slices.BinarySearch(s.lines, offset)
+
+	lineStart := int64(0)
+	if line > 0 {
+		lineStart = s.lines[line-1] + 1
+	}
+
+	return line + 1, int(offset-lineStart) + 1
+}
+
+// syntaxError returns the error of the document for a syntax error of the JSON decoder, with its position if known.
+func (s *jsonStream) syntaxError(document int, err error) *DocumentError {
+	documentErr := &DocumentError{
+		Document: document,
+		Line:     0,
+		Column:   0,
+		Err:      fmt.Errorf("failed to decode JSON document: %w", err),
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		documentErr.Line, documentErr.Column = s.position(syntaxErr.Offset - 1)
+	}
+
+	return documentErr
+}
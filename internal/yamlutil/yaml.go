@@ -1,6 +1,7 @@
 package yamlutil
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -33,30 +34,91 @@ func (y *yamlToJSON) GetDecoder() (*json.Decoder, error) {
 	return json.NewDecoder(bytes.NewReader(y.data)), nil
 }
 
-// YAMLDocumentsToJSON converts a stream of YAML documents into a sequence of JSON documents.
+// DecodeOptions are the options of [YAMLDocumentsToJSONWithOptions].
+// The limits protect against untrusted documents which are small, but expand to huge JSON documents through their
+// aliases, e.g. the "billion laughs" attack.
+// A zero limit disables the limit.
+type DecodeOptions struct {
+	// MaxDocumentSize is the maximum size in bytes of the scalars of a document once its aliases are expanded, which
+	// approximates the size of the JSON document.
+	MaxDocumentSize int
+	// MaxDepth is the maximum nesting depth of the sequences and mappings of a document.
+	MaxDepth int
+	// MaxAliasExpansion is the maximum number of nodes of a document reached through its aliases.
+	MaxAliasExpansion int
+	// Concurrency is the number of documents transcoded to JSON concurrently, while the stream is being decoded.
+	// The documents are still yielded in the order of the stream.
+	// Zero or one transcodes the documents one at a time, as they are decoded.
+	Concurrency int
+}
+
+// limited returns true if any of the limits is enabled.
+func (o DecodeOptions) limited() bool {
+	return o.MaxDocumentSize > 0 || o.MaxDepth > 0 || o.MaxAliasExpansion > 0
+}
+
+var (
+	// ErrDocumentTooLarge is returned when a document exceeds [DecodeOptions.MaxDocumentSize].
+	ErrDocumentTooLarge = errors.New("document too large")
+	// ErrDocumentTooDeep is returned when a document exceeds [DecodeOptions.MaxDepth].
+	ErrDocumentTooDeep = errors.New("document too deeply nested")
+	// ErrAliasExpansion is returned when a document exceeds [DecodeOptions.MaxAliasExpansion].
+	ErrAliasExpansion = errors.New("document expands too many aliases")
+)
+
+// YAMLDocumentsToJSON converts a stream of YAML documents into a sequence of JSON documents, without limits.
 func YAMLDocumentsToJSON(yamlStream io.Reader) iter.Seq[YAMLToJSON] {
+	return YAMLDocumentsToJSONWithOptions(yamlStream, DecodeOptions{})
+}
+
+// YAMLDocumentsToJSONWithOptions converts a stream of YAML documents into a sequence of JSON documents, rejecting the
+// documents which exceed the limits of the options.
+// Streams of JSON values, e.g. the output of kubectl get -o json, are passed through without being transcoded, see
+// [jsonDocuments].
+// The errors are [*DocumentError]s, with the index and position of the document which failed.
+func YAMLDocumentsToJSONWithOptions(yamlStream io.Reader, options DecodeOptions) iter.Seq[YAMLToJSON] {
+	return func(yield func(YAMLToJSON) bool) {
+		reader := bufio.NewReader(yamlStream)
+
+		documents := yamlDocumentsToJSON(reader, options)
+		if isJSONStream(reader) {
+			documents = jsonDocuments(reader, options)
+		}
+
+		for document := range documents {
+			if !yield(document) {
+				return
+			}
+		}
+	}
+}
+
+// yamlDocumentsToJSON transcodes the stream of YAML documents, see [YAMLDocumentsToJSONWithOptions].
+func yamlDocumentsToJSON(yamlStream io.Reader, options DecodeOptions) iter.Seq[YAMLToJSON] {
+	if options.Concurrency > 1 {
+		return concurrentDocumentsToJSON(yamlStream, options)
+	}
+
 	return func(yield func(YAMLToJSON) bool) {
 		decoder := yaml.NewDecoder(yamlStream)
 
-		for {
-			var doc any
+		for document := 0; ; document++ {
+			var node yaml.Node
 
-			err := decoder.Decode(&doc)
+			err := decoder.Decode(&node)
 			if err != nil {
 				if errors.Is(err, io.EOF) {
 					break // End of documents
 				}
 
-				err = fmt.Errorf("failed to decode YAML document: %w", err)
-				yield(&yamlToJSONErr{err: err})
+				yield(&yamlToJSONErr{err: newSyntaxError(document, err)})
 
 				break // We can't continue if we can't decode the document, as we won't necessarily be able to find the next one.
 			}
 
-			jsonBytes, err := json.Marshal(doc)
+			jsonBytes, at, err := nodeToJSON(&node, options)
 			if err != nil {
-				err = fmt.Errorf("failed to marshal YAML document to JSON: %w", err)
-				if !yield(&yamlToJSONErr{err: err}) {
+				if !yield(&yamlToJSONErr{err: newNodeError(document, at, err)}) {
 					break // Stop iteration if yield returns false
 				}
 
@@ -69,3 +131,84 @@ func YAMLDocumentsToJSON(yamlStream io.Reader) iter.Seq[YAMLToJSON] {
 		}
 	}
 }
+
+// nodeToJSON checks the document against the limits of the options, and marshals it to JSON.
+// On error, the node at which the error occurred is returned as well.
+func nodeToJSON(node *yaml.Node, options DecodeOptions) ([]byte, *yaml.Node, error) {
+	if options.limited() {
+		limits := &documentLimits{options: options}
+
+		err := limits.check(node, 0, nil)
+		if err != nil {
+			return nil, limits.at, err
+		}
+	}
+
+	var doc any
+
+	err := node.Decode(&doc)
+	if err != nil {
+		return nil, node, fmt.Errorf("failed to decode YAML document: %w", err)
+	}
+
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, node, fmt.Errorf("failed to marshal YAML document to JSON: %w", err)
+	}
+
+	return jsonBytes, nil, nil
+}
+
+// documentLimits walks a document with its aliases expanded, stopping as soon as a limit is exceeded, so that the
+// walk is bounded even for documents which expand exponentially.
+type documentLimits struct {
+	options DecodeOptions
+	size    int
+	aliased int
+	// at is the node at which a limit was exceeded, which is the alias for the aliased nodes.
+	at *yaml.Node
+}
+
+// check walks the node at the depth, reached through the alias unless it is nil.
+func (l *documentLimits) check(node *yaml.Node, depth int, alias *yaml.Node) error {
+	l.at = node
+	if alias != nil {
+		l.at = alias
+
+		l.aliased++
+		if l.options.MaxAliasExpansion > 0 && l.aliased > l.options.MaxAliasExpansion {
+			return fmt.Errorf("%w: more than %d nodes expanded", ErrAliasExpansion, l.options.MaxAliasExpansion)
+		}
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		if alias == nil {
+			alias = node
+		}
+
+		return l.check(node.Alias, depth, alias)
+	case yaml.ScalarNode:
+		l.size += len(node.Value)
+		if l.options.MaxDocumentSize > 0 && l.size > l.options.MaxDocumentSize {
+			return fmt.Errorf("%w: more than %d bytes", ErrDocumentTooLarge, l.options.MaxDocumentSize)
+		}
+
+		return nil
+	case yaml.SequenceNode, yaml.MappingNode:
+		depth++
+		if l.options.MaxDepth > 0 && depth > l.options.MaxDepth {
+			return fmt.Errorf("%w: more than %d levels", ErrDocumentTooDeep, l.options.MaxDepth)
+		}
+	case yaml.DocumentNode:
+	}
+
+	for _, child := range node.Content {
+		err := l.check(child, depth, alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,7 +3,13 @@ package yamlutil_test
 import (
 	"bytes"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
 
 	"github.com/Izzette/kubectl-api-resource-versions/internal/yamlutil"
 )
@@ -34,3 +40,287 @@ func ExampleYAMLDocumentsToJSON() {
 	// map[string]interface {}{"other":"value", "with":map[string]interface {}{"different":"structure"}}
 	// <nil>
 }
+
+func ExampleDocumentEncoder() {
+	type item struct {
+		Name    string   `json:"name"`
+		Version string   `json:"version,omitempty"`
+		Verbs   []string `json:"verbs"`
+	}
+
+	encoder := yamlutil.NewDocumentEncoder(os.Stdout)
+	for _, doc := range []any{
+		item{Name: "pods", Version: "v1", Verbs: []string{"get", "list"}},
+		item{Name: "true", Verbs: nil},
+		nil,
+	} {
+		err := encoder.Encode(doc)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	err := encoder.Close()
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// name: pods
+	// version: v1
+	// verbs:
+	//   - get
+	//   - list
+	// ---
+	// name: "true"
+	// verbs: null
+	// ---
+	// null
+}
+
+// laughs is a small document whose aliases expand to 9^5 scalars.
+const laughs = `a: &a ["lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+`
+
+type yamlDocumentsToJSONWithOptionsTest struct {
+	document string
+	options  yamlutil.DecodeOptions
+	wantErrs []error
+}
+
+func (tt yamlDocumentsToJSONWithOptionsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	var errs []error
+
+	for result := range yamlutil.YAMLDocumentsToJSONWithOptions(strings.NewReader(tt.document), tt.options) {
+		_, err := result.GetDecoder()
+		errs = append(errs, err)
+	}
+
+	if len(errs) != len(tt.wantErrs) {
+		t.Fatalf("YAMLDocumentsToJSONWithOptions() errors = %v, want %v", errs, tt.wantErrs)
+	}
+
+	for i, err := range errs {
+		if !errors.Is(err, tt.wantErrs[i]) {
+			t.Errorf("YAMLDocumentsToJSONWithOptions() document %d error = %v, want %v", i, err, tt.wantErrs[i])
+		}
+	}
+}
+
+func TestYAMLDocumentsToJSONWithOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unlimited", yamlDocumentsToJSONWithOptionsTest{
+		document: document,
+		options:  yamlutil.DecodeOptions{},
+		wantErrs: []error{nil, nil, nil},
+	}.Test)
+	t.Run("WithinLimits", yamlDocumentsToJSONWithOptionsTest{
+		document: document,
+		options:  yamlutil.DecodeOptions{MaxDocumentSize: 64, MaxDepth: 2, MaxAliasExpansion: 1},
+		wantErrs: []error{nil, nil, nil},
+	}.Test)
+	t.Run("AliasExpansion", yamlDocumentsToJSONWithOptionsTest{
+		document: laughs + "---\nother: value\n",
+		options:  yamlutil.DecodeOptions{MaxDocumentSize: 0, MaxDepth: 0, MaxAliasExpansion: 1000},
+		wantErrs: []error{yamlutil.ErrAliasExpansion, nil},
+	}.Test)
+	t.Run("DocumentTooLarge", yamlDocumentsToJSONWithOptionsTest{
+		document: laughs,
+		options:  yamlutil.DecodeOptions{MaxDocumentSize: 1024, MaxDepth: 0, MaxAliasExpansion: 0},
+		wantErrs: []error{yamlutil.ErrDocumentTooLarge},
+	}.Test)
+	t.Run("DocumentTooDeep", yamlDocumentsToJSONWithOptionsTest{
+		document: "a: {b: {c: [d]}}\n",
+		options:  yamlutil.DecodeOptions{MaxDocumentSize: 0, MaxDepth: 3, MaxAliasExpansion: 0},
+		wantErrs: []error{yamlutil.ErrDocumentTooDeep},
+	}.Test)
+}
+
+func TestYAMLDocumentsToJSONPositions(t *testing.T) {
+	t.Parallel()
+
+	stream := "a: b\n" +
+		"---\n" +
+		"a: [1, 2]\n" +
+		"b: {c: [d, e]}\n" +
+		"---\n" +
+		"a: b\n" +
+		"  c: d\n"
+	options := yamlutil.DecodeOptions{MaxDocumentSize: 0, MaxDepth: 2, MaxAliasExpansion: 0}
+
+	var errs []string
+
+	for result := range yamlutil.YAMLDocumentsToJSONWithOptions(strings.NewReader(stream), options) {
+		_, err := result.GetDecoder()
+		if err == nil {
+			continue
+		}
+
+		var documentErr *yamlutil.DocumentError
+		if !errors.As(err, &documentErr) {
+			t.Fatalf("GetDecoder() error = %v, want a *DocumentError", err)
+		}
+
+		errs = append(errs, err.Error())
+	}
+
+	want := []string{
+		"document 1, line 4, column 8: document too deeply nested: more than 2 levels",
+		"document 2, line 7: failed to decode YAML document: mapping values are not allowed in this context",
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() errors = %q, want %q", errs, want)
+	}
+}
+
+func TestYAMLDocumentsToJSONConcurrency(t *testing.T) {
+	t.Parallel()
+
+	const documents = 1000
+
+	var stream strings.Builder
+	for i := range documents {
+		fmt.Fprintf(&stream, "---\nindex: %d\nitems: &items [a, b, c]\ncopy: *items\n", i)
+	}
+
+	stream.WriteString("---\nbroken: [\n")
+
+	options := yamlutil.DecodeOptions{MaxDocumentSize: 0, MaxDepth: 0, MaxAliasExpansion: 4, Concurrency: 8}
+
+	var (
+		got  []int
+		errs []error
+	)
+
+	for result := range yamlutil.YAMLDocumentsToJSONWithOptions(strings.NewReader(stream.String()), options) {
+		decoder, err := result.GetDecoder()
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		var doc struct {
+			Index int `json:"index"`
+		}
+
+		err = decoder.Decode(&doc)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		got = append(got, doc.Index)
+	}
+
+	for i, index := range got {
+		if index != i {
+			t.Fatalf("YAMLDocumentsToJSONWithOptions() document %d has index %d, want the order of the stream", i, index)
+		}
+	}
+
+	if len(got) != documents {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() yielded %d documents, want %d", len(got), documents)
+	}
+
+	var documentErr *yamlutil.DocumentError
+	if len(errs) != 1 || !errors.As(errs[0], &documentErr) || documentErr.Document != documents {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() errors = %v, want a syntax error in document %d", errs, documents)
+	}
+}
+
+func TestYAMLDocumentsToJSONConcurrencyStop(t *testing.T) {
+	t.Parallel()
+
+	stream := strings.Repeat("---\nkey: value\n", 100)
+	options := yamlutil.DecodeOptions{MaxDocumentSize: 0, MaxDepth: 0, MaxAliasExpansion: 0, Concurrency: 4}
+
+	yielded := 0
+
+	for range yamlutil.YAMLDocumentsToJSONWithOptions(strings.NewReader(stream), options) {
+		yielded++
+		if yielded == 3 {
+			break
+		}
+	}
+
+	if yielded != 3 {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() yielded %d documents, want 3", yielded)
+	}
+}
+
+type jsonPassthroughTest struct {
+	stream   string
+	options  yamlutil.DecodeOptions
+	want     []string
+	wantErrs []string
+}
+
+func (tt jsonPassthroughTest) Test(t *testing.T) {
+	t.Parallel()
+
+	var got, errs []string
+
+	for result := range yamlutil.YAMLDocumentsToJSONWithOptions(strings.NewReader(tt.stream), tt.options) {
+		decoder, err := result.GetDecoder()
+		if err != nil {
+			errs = append(errs, err.Error())
+
+			continue
+		}
+
+		var raw json.RawMessage
+
+		err = decoder.Decode(&raw)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+
+		got = append(got, string(raw))
+	}
+
+	if !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() documents = %q, want %q", got, tt.want)
+	}
+
+	if !reflect.DeepEqual(errs, tt.wantErrs) {
+		t.Errorf("YAMLDocumentsToJSONWithOptions() errors = %q, want %q", errs, tt.wantErrs)
+	}
+}
+
+func TestYAMLDocumentsToJSONPassthrough(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stream", jsonPassthroughTest{
+		stream:   "\n  {\"kind\": \"List\", \"items\": []}\n{\"b\":  2}[1,2]",
+		options:  yamlutil.DecodeOptions{},
+		want:     []string{`{"kind": "List", "items": []}`, `{"b":  2}`, `[1,2]`},
+		wantErrs: nil,
+	}.Test)
+	t.Run("YAMLFlowMapping", jsonPassthroughTest{
+		stream:   "{a: b}\n---\nc: d\n",
+		options:  yamlutil.DecodeOptions{},
+		want:     []string{`{"a":"b"}`, `{"c":"d"}`},
+		wantErrs: nil,
+	}.Test)
+	t.Run("SyntaxError", jsonPassthroughTest{
+		stream:   "{\"a\": 1}\n{\n  \"b\": 2,\n  \"c\" 3\n}\n",
+		options:  yamlutil.DecodeOptions{},
+		want:     []string{`{"a": 1}`},
+		wantErrs: []string{"document 1, line 4, column 7: failed to decode JSON document: invalid character '3' after object key"},
+	}.Test)
+	t.Run("Limits", jsonPassthroughTest{
+		stream:  "{\"a\": [[\"[\"]]}\n{\"a\": [[[]]]}\n{\"a\": \"long string\"}\n",
+		options: yamlutil.DecodeOptions{MaxDocumentSize: 16, MaxDepth: 3, MaxAliasExpansion: 0},
+		want:    []string{`{"a": [["["]]}`},
+		wantErrs: []string{
+			"document 1, line 2, column 1: document too deeply nested: more than 3 levels",
+			"document 2, line 3, column 1: document too large: more than 16 bytes",
+		},
+	}.Test)
+}
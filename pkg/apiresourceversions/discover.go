@@ -0,0 +1,117 @@
+package apiresourceversions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// DefaultConcurrency is the default number of API group versions which are discovered concurrently.
+const DefaultConcurrency = 16
+
+// Options are the options of [Discover].
+type Options struct {
+	// Filter selects the resources.
+	Filter Filter
+	// SortBy is the order of the resources.
+	SortBy SortBy
+	// Concurrency is the number of API group versions which are discovered concurrently, [DefaultConcurrency] if it
+	// isn't positive.
+	Concurrency int
+	// Retries is the number of times the discovery of a group version is retried on transient errors.
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled after each of them, [DefaultRetryBackoff] if it isn't
+	// positive.
+	RetryBackoff time.Duration
+}
+
+// Discover returns the resources served by the cluster in every one of their versions which match the filter, in
+// the order of the options.
+// The groups are discovered with [DiscoverGroups], through the aggregated discovery if the server serves it, and
+// their versions with [DiscoverGroupVersions], which abandons the discovery when the context is done.
+// The group versions of the matching groups which couldn't be discovered are an error, as the resources would be
+// missing.
+func Discover(ctx context.Context, discoveryClient discovery.DiscoveryInterface, options Options) ([]Resource, error) {
+	groups, err := DiscoverGroups(discoveryClient)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get server groups: %w", err)
+	}
+
+	preferredVersions := groups.PreferredVersions()
+	if preferredVersions == nil {
+		preferredVersions, err = ServerPreferredVersions(discoveryClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matchGroup := func(group *metav1.APIGroup) bool { return options.Filter.MatchesGroup(group.Name) }
+	apiGroups := groups.Select(matchGroup)
+
+	resourceLists, err := DiscoverGroupVersions(ctx, discoveryClient, groups, matchGroup, GroupVersionOptions{
+		Concurrency:  options.Concurrency,
+		Retries:      options.Retries,
+		RetryBackoff: options.RetryBackoff,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []Resource
+
+	for i, group := range apiGroups {
+		for j, version := range group.Versions {
+			resources = appendResources(resources, group, version, resourceLists[i][j], options.Filter, preferredVersions)
+		}
+	}
+
+	Sort(resources, options.SortBy)
+
+	return resources, nil
+}
+
+// appendResources appends the resources of the group version which match the filter.
+func appendResources(
+	resources []Resource,
+	group *metav1.APIGroup,
+	version metav1.GroupVersionForDiscovery,
+	resourceList *metav1.APIResourceList,
+	filter Filter,
+	preferredVersions map[schema.GroupResource]string,
+) []Resource {
+	query := strings.ToLower(filter.Query)
+
+	for i := range resourceList.APIResources {
+		apiResource := resourceList.APIResources[i]
+		apiResource.Group = group.Name // The group isn't set by the discovery.
+
+		baseName, _, isSubresource := strings.Cut(apiResource.Name, "/")
+		preferredVersion, ok := preferredVersions[schema.GroupResource{Group: group.Name, Resource: baseName}]
+
+		resource := Resource{
+			GroupVersion:          schema.GroupVersion{Group: group.Name, Version: version.Version},
+			APIResource:           &apiResource,
+			Preferred:             ok && preferredVersion == version.Version,
+			PreferredGroupVersion: version.GroupVersion == group.PreferredVersion.GroupVersion,
+			Subresource:           isSubresource,
+		}
+
+		// The score is computed before the filter, which reuses it.
+		if query != "" {
+			resource.QueryScore = QueryScore(resource.APIResource, query)
+		}
+
+		if !filter.Matches(resource) {
+			continue
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
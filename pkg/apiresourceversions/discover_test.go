@@ -0,0 +1,63 @@
+package apiresourceversions
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	"k8s.io/utils/ptr"
+)
+
+// TestDiscover tests discovering, filtering, and sorting the resources in every one of their versions.
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Group", discoverTest{
+		options: Options{Filter: Filter{APIGroup: ptr.To("autoscaling")}},
+		want: []string{
+			"horizontalpodautoscalers.v2.autoscaling",
+			"horizontalpodautoscalers.v1.autoscaling",
+			"horizontalpodautoscalers.v2beta2.autoscaling",
+		},
+	}.Test)
+	t.Run("NotPreferred", discoverTest{
+		options: Options{Filter: Filter{APIGroup: ptr.To("autoscaling"), Preferred: ptr.To(false)}},
+		want: []string{
+			"horizontalpodautoscalers.v1.autoscaling",
+			"horizontalpodautoscalers.v2beta2.autoscaling",
+		},
+	}.Test)
+	t.Run("Query", discoverTest{
+		options: Options{Filter: Filter{Query: "PO", Namespaced: ptr.To(true)}},
+		want: []string{
+			"pods.v1.",
+			"horizontalpodautoscalers.v2.autoscaling",
+			"horizontalpodautoscalers.v1.autoscaling",
+			"horizontalpodautoscalers.v2beta2.autoscaling",
+			"persistentvolumeclaims.v1.",
+		},
+	}.Test)
+}
+
+type discoverTest struct {
+	options Options
+	want    []string
+}
+
+func (tt discoverTest) Test(t *testing.T) {
+	t.Parallel()
+
+	resources, err := Discover(t.Context(), discoverytesting.New(), tt.options)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	got := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		got = append(got, resource.FullName())
+	}
+
+	if !slices.Equal(got, tt.want) {
+		t.Errorf("Discover() = %v, want %v", got, tt.want)
+	}
+}
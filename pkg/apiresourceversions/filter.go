@@ -0,0 +1,68 @@
+package apiresourceversions
+
+import (
+	"slices"
+	"strings"
+)
+
+// Filter selects the resources, like the flags of kubectl api-resource-versions.
+// The zero value selects every resource but the subresources.
+type Filter struct {
+	// APIGroup limits to the resources of the API group if it isn't nil, "" being the core group.
+	APIGroup *string
+	// Namespaced limits to the namespaced resources, or to the cluster resources, if it isn't nil.
+	Namespaced *bool
+	// Verbs limits to the resources which support all the verbs.
+	Verbs []string
+	// Categories limits to the resources which belong to all the categories.
+	Categories []string
+	// Preferred limits to the server preferred versions of the resources, or to their other versions, if it isn't
+	// nil.
+	Preferred *bool
+	// IncludeSubresources includes the subresources, e.g. deployments/status.
+	IncludeSubresources bool
+	// Query limits to the resources whose plural name, singular name, short names, or kind fuzzily match it, see
+	// [QueryScore], if it isn't empty.
+	Query string
+}
+
+// MatchesGroup returns true if the resources of the API group can match the filter.
+func (f Filter) MatchesGroup(group string) bool {
+	return f.APIGroup == nil || *f.APIGroup == group
+}
+
+// Matches returns true if the resource matches the filter.
+// The [Resource.QueryScore] of the resource is reused if it is already set, rather than scoring the resource again.
+func (f Filter) Matches(resource Resource) bool {
+	switch {
+	case !f.MatchesGroup(resource.GroupVersion.Group):
+		return false
+	case f.Namespaced != nil && *f.Namespaced != resource.APIResource.Namespaced:
+		return false
+	case !containsAll(resource.APIResource.Verbs, f.Verbs):
+		return false
+	case !containsAll(resource.APIResource.Categories, f.Categories):
+		return false
+	case f.Preferred != nil && *f.Preferred != resource.Preferred:
+		return false
+	case !f.IncludeSubresources && resource.Subresource:
+		return false
+	case f.Query != "" && resource.QueryScore == NoMatch &&
+		QueryScore(resource.APIResource, strings.ToLower(f.Query)) == NoMatch:
+		return false
+	default:
+		return true
+	}
+}
+
+// containsAll returns true if values contains all the wanted values, without allocating, as the verbs and categories
+// of a resource are only a few.
+func containsAll(values, wanted []string) bool {
+	for _, value := range wanted {
+		if !slices.Contains(values, value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,59 @@
+package apiresourceversions
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestFilterMatches tests selecting the resources by their verbs, categories, and query.
+func TestFilterMatches(t *testing.T) {
+	t.Parallel()
+
+	deployments := Resource{
+		GroupVersion: schema.GroupVersion{Group: "apps", Version: "v1"},
+		APIResource: &metav1.APIResource{
+			Name:       "deployments",
+			ShortNames: []string{"deploy"},
+			Kind:       "Deployment",
+			Verbs:      []string{"get", "list", "watch"},
+			Categories: []string{"all"},
+		},
+	}
+
+	scored := deployments
+	scored.QueryScore = ExactMatch
+
+	t.Run("Zero", filterMatchesTest{resource: deployments, want: true}.Test)
+	t.Run("Verbs", filterMatchesTest{
+		filter: Filter{Verbs: []string{"list", "get"}}, resource: deployments, want: true,
+	}.Test)
+	t.Run("MissingVerb", filterMatchesTest{
+		filter: Filter{Verbs: []string{"get", "delete"}}, resource: deployments, want: false,
+	}.Test)
+	t.Run("Categories", filterMatchesTest{
+		filter: Filter{Categories: []string{"all"}}, resource: deployments, want: true,
+	}.Test)
+	t.Run("MissingCategory", filterMatchesTest{
+		filter: Filter{Categories: []string{"all", "api-extensions"}}, resource: deployments, want: false,
+	}.Test)
+	t.Run("Query", filterMatchesTest{filter: Filter{Query: "Deploy"}, resource: deployments, want: true}.Test)
+	t.Run("QueryNoMatch", filterMatchesTest{filter: Filter{Query: "pods"}, resource: deployments, want: false}.Test)
+	// The score of the resource is reused rather than computed again for the query.
+	t.Run("QueryScore", filterMatchesTest{filter: Filter{Query: "pods"}, resource: scored, want: true}.Test)
+}
+
+type filterMatchesTest struct {
+	filter   Filter
+	resource Resource
+	want     bool
+}
+
+func (tt filterMatchesTest) Test(t *testing.T) {
+	t.Parallel()
+
+	if got := tt.filter.Matches(tt.resource); got != tt.want {
+		t.Errorf("Matches() = %v, want %v", got, tt.want)
+	}
+}
@@ -0,0 +1,156 @@
+package apiresourceversions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Groups are the API groups of the server, along with the resources of their group versions discovered in a single
+// pass.
+type Groups struct {
+	// List are the API groups of the server.
+	List *metav1.APIGroupList
+	// Aggregated is true if the resources were discovered through the aggregated discovery (apidiscovery.k8s.io/v2).
+	Aggregated bool
+
+	// resources are the resources of the group versions, nil if they weren't discovered along with the groups.
+	resources map[schema.GroupVersion]*metav1.APIResourceList
+	// failed are the errors of the group versions whose resources couldn't be discovered, e.g. an unavailable
+	// aggregated API server.
+	failed map[schema.GroupVersion]error
+}
+
+// DiscoverGroups retrieves the API groups of the server along with the resources of all their group versions, in a
+// single request if the discovery client supports the aggregated discovery and the server serves it (Kubernetes 1.27+),
+// or else with [discovery.ServerResourcesInterface.ServerGroupsAndResources].
+// The group versions which couldn't be discovered are only an error if their resources are requested, see
+// [Groups.ServerResourcesForGroupVersion].
+//
+// The kubectl disk cached discovery client doesn't implement [discovery.AggregatedDiscoveryInterface], however it
+// delegates to an in-memory cached discovery client which does: its resources are discovered along with the groups
+// too, and then read from memory by ServerGroupsAndResources.
+func DiscoverGroups(discoveryClient discovery.DiscoveryInterface) (*Groups, error) {
+	aggregatedClient, ok := discoveryClient.(discovery.AggregatedDiscoveryInterface)
+	if ok {
+		groupList, resources, failed, err := aggregatedClient.GroupsAndMaybeResources()
+		if err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+
+		if resources != nil {
+			return &Groups{List: groupList, Aggregated: true, resources: resources, failed: failed}, nil
+		}
+	}
+
+	groups, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+
+	var failed map[schema.GroupVersion]error
+
+	failedGroups := &discovery.ErrGroupDiscoveryFailed{}
+	if errors.As(err, &failedGroups) {
+		failed = failedGroups.Groups
+	} else if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	discovered := &Groups{
+		List:      &metav1.APIGroupList{Groups: make([]metav1.APIGroup, 0, len(groups))},
+		resources: make(map[schema.GroupVersion]*metav1.APIResourceList, len(resourceLists)),
+		failed:    failed,
+	}
+
+	for _, group := range groups {
+		discovered.List.Groups = append(discovered.List.Groups, *group)
+	}
+
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse group version %s: %w", resourceList.GroupVersion, err)
+		}
+
+		discovered.resources[groupVersion] = resourceList
+	}
+
+	return discovered, nil
+}
+
+// Select returns the API groups which match, or all of them if match is nil, in the order of the discovery.
+func (g *Groups) Select(match func(group *metav1.APIGroup) bool) []*metav1.APIGroup {
+	selected := make([]*metav1.APIGroup, 0, len(g.List.Groups))
+
+	for i := range g.List.Groups {
+		group := &g.List.Groups[i]
+		if match == nil || match(group) {
+			selected = append(selected, group)
+		}
+	}
+
+	return selected
+}
+
+// ServerResourcesForGroupVersion returns the resources of the group version, from the single pass if it was
+// discovered then, or else by discovering the group version on its own.
+func (g *Groups) ServerResourcesForGroupVersion(
+	discoveryClient discovery.DiscoveryInterface,
+	groupVersion string,
+) (*metav1.APIResourceList, error) {
+	if g.resources != nil {
+		gv, err := schema.ParseGroupVersion(groupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse group version %s: %w", groupVersion, err)
+		}
+
+		if err, ok := g.failed[gv]; ok {
+			return nil, err
+		}
+
+		if resourceList, ok := g.resources[gv]; ok {
+			return resourceList, nil
+		}
+	}
+
+	//nolint:wrapcheck
+	return discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+}
+
+// PreferredVersions returns the preferred versions of the resources from the aggregated discovery in the same format
+// as [PreferredVersions], or nil if the resources weren't discovered through it.
+// The preferred version of a resource is its first version in the order of the versions of its group, as for
+// [discovery.ServerPreferredResources].
+func (g *Groups) PreferredVersions() map[schema.GroupResource]string {
+	if !g.Aggregated {
+		return nil
+	}
+
+	preferredVersions := make(map[schema.GroupResource]string)
+
+	for _, group := range g.List.Groups {
+		for _, version := range group.Versions {
+			resourceList, ok := g.resources[schema.GroupVersion{Group: group.Name, Version: version.Version}]
+			if !ok {
+				continue
+			}
+
+			for _, resource := range resourceList.APIResources {
+				if strings.Contains(resource.Name, "/") {
+					continue
+				}
+
+				groupResource := schema.GroupResource{Group: group.Name, Resource: resource.Name}
+				if _, ok := preferredVersions[groupResource]; !ok {
+					preferredVersions[groupResource] = version.Version
+				}
+			}
+		}
+	}
+
+	return preferredVersions
+}
@@ -0,0 +1,228 @@
+package apiresourceversions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// DefaultRetryBackoff is the default delay before the first retry of a group version discovery.
+const DefaultRetryBackoff = time.Second
+
+// tracerName is the name of the instrumentation scope of the spans of the discovery.
+const tracerName = "github.com/Izzette/kubectl-api-resource-versions/pkg/apiresourceversions"
+
+// GroupVersionOptions are the options of [DiscoverGroupVersions].
+type GroupVersionOptions struct {
+	// Concurrency is the number of API group versions which are discovered concurrently, [DefaultConcurrency] if it
+	// isn't positive.
+	Concurrency int
+	// Retries is the number of times the discovery of a group version is retried on transient errors, e.g. a
+	// briefly-unavailable aggregated API server.
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled after each of them, [DefaultRetryBackoff] if it isn't
+	// positive.
+	RetryBackoff time.Duration
+	// OnGroupVersion is called with the resource list of each group version as soon as it and all the group versions
+	// before it are discovered, in the order of the groups and of their versions, if it isn't nil.
+	// The group versions following a failed one aren't handed over, and an error stops handing them over and is
+	// returned once the discovery is done.
+	OnGroupVersion func(groupIndex, versionIndex int, resourceList *metav1.APIResourceList) error
+	// OnRetry is called before each retry of the discovery of a group version, e.g. to log it, if it isn't nil.
+	OnRetry func(groupVersion string, retry int, backoff time.Duration, err error)
+	// OnDiscovered is called once the discovery of each group version is done, successful or not, e.g. to report the
+	// progress, if it isn't nil.
+	OnDiscovered func(groupVersion string, duration time.Duration, err error)
+}
+
+// DiscoverGroupVersions retrieves the resources of every version of the API groups which match, or of all of them if
+// matchGroup is nil, from the single pass of the groups when they were discovered then, with at most
+// [GroupVersionOptions.Concurrency] group versions discovered concurrently.
+// The resource lists are returned in the order of the groups selected by [Groups.Select] with matchGroup, and of
+// their versions, the same order as the indexes handed to [GroupVersionOptions.OnGroupVersion].
+// The discovery is abandoned when the context is done, without waiting for the pending requests, as the discovery
+// client doesn't take a context.
+// A span is recorded for each group version in the trace of the context, if any.
+func DiscoverGroupVersions(
+	ctx context.Context,
+	discoveryClient discovery.DiscoveryInterface,
+	groups *Groups,
+	matchGroup func(group *metav1.APIGroup) bool,
+	options GroupVersionOptions,
+) ([][]*metav1.APIResourceList, error) {
+	apiGroups := groups.Select(matchGroup)
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	groupResourceLists := make([][]*metav1.APIResourceList, len(apiGroups))
+	// discovered is closed for each group version once its discovery is done, successful or not.
+	discovered := make([][]chan struct{}, len(apiGroups))
+
+	for i, group := range apiGroups {
+		groupResourceLists[i] = make([]*metav1.APIResourceList, len(group.Versions))
+		discovered[i] = make([]chan struct{}, len(group.Versions))
+
+		for j := range group.Versions {
+			discovered[i][j] = make(chan struct{})
+		}
+	}
+
+	var errGroup errgroup.Group
+	errGroup.SetLimit(concurrency)
+
+	// The group versions are started in the background, as starting them blocks while the limit is reached.
+	started := make(chan struct{})
+
+	go func() {
+		defer close(started)
+
+		for i, group := range apiGroups {
+			for j, version := range group.Versions {
+				errGroup.Go(func() error {
+					defer close(discovered[i][j])
+
+					select {
+					case <-ctx.Done():
+						return context.Cause(ctx)
+					default:
+					}
+
+					resourceList, err := discoverGroupVersion(ctx, discoveryClient, groups, version.GroupVersion, options)
+					if err != nil {
+						return fmt.Errorf("couldn't get server resources for group version %s: %w", version.GroupVersion, err)
+					}
+
+					groupResourceLists[i][j] = resourceList
+
+					return nil
+				})
+			}
+		}
+	}()
+
+	var onGroupVersionErr error
+
+	streaming := options.OnGroupVersion != nil
+
+	for i, group := range apiGroups {
+		for j := range group.Versions {
+			select {
+			case <-discovered[i][j]:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("couldn't get server resources: %w", context.Cause(ctx))
+			}
+
+			// The group versions following a failed one aren't handed over, to keep them in order.
+			streaming = streaming && groupResourceLists[i][j] != nil && onGroupVersionErr == nil
+			if streaming {
+				onGroupVersionErr = options.OnGroupVersion(i, j, groupResourceLists[i][j])
+			}
+		}
+	}
+
+	<-started
+
+	err := errGroup.Wait()
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	if onGroupVersionErr != nil {
+		return nil, onGroupVersionErr
+	}
+
+	return groupResourceLists, nil
+}
+
+// discoverGroupVersion returns the resources of the group version like [Groups.ServerResourcesForGroupVersion],
+// retrying up to [GroupVersionOptions.Retries] times on transient errors.
+// The retries discover the group version on its own, as the result of the single pass is the failure.
+func discoverGroupVersion(
+	ctx context.Context,
+	discoveryClient discovery.DiscoveryInterface,
+	groups *Groups,
+	groupVersion string,
+	options GroupVersionOptions,
+) (*metav1.APIResourceList, error) {
+	start := time.Now()
+
+	ctx, span := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName).Start(ctx, "discover group version",
+		trace.WithAttributes(attribute.String("k8s.group_version", groupVersion)))
+	defer span.End()
+
+	resourceList, err := groups.ServerResourcesForGroupVersion(discoveryClient, groupVersion)
+
+	backoff := options.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	for retry := 0; retry < options.Retries && err != nil && isTransientError(err); retry++ {
+		if options.OnRetry != nil {
+			options.OnRetry(groupVersion, retry+1, backoff, err)
+		}
+
+		err = sleepWithContext(ctx, backoff)
+		if err != nil {
+			resourceList = nil
+
+			break
+		}
+
+		backoff *= 2
+
+		resourceList, err = discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if options.OnDiscovered != nil {
+		options.OnDiscovered(groupVersion, time.Since(start), err)
+	}
+
+	//nolint:wrapcheck
+	return resourceList, err
+}
+
+// sleepWithContext waits for the duration, or returns the cause of the context if it is done first.
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isTransientError returns true if the discovery error is likely to go away when retried, e.g. a briefly-unavailable
+// aggregated API server or a timeout.
+func isTransientError(err error) bool {
+	if apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) || apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) || apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
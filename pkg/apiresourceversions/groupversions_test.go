@@ -0,0 +1,84 @@
+package apiresourceversions
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestIsTransientError tests classifying the discovery errors worth retrying.
+func TestIsTransientError(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"ServiceUnavailable": {err: apierrors.NewServiceUnavailable("unavailable"), want: true},
+		"InternalError":      {err: apierrors.NewInternalError(errors.New("internal")), want: true},
+		"Timeout":            {err: apierrors.NewTimeoutError("timeout", 1), want: true},
+		"TooManyRequests":    {err: apierrors.NewTooManyRequests("throttled", 1), want: true},
+		"NotFound":           {err: apierrors.NewNotFound(schema.GroupResource{}, "v1"), want: false},
+		"Other":              {err: errors.New("other"), want: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiscoverGroupVersions tests discovering the versions of the groups which match, in the order of the groups and
+// of their versions.
+func TestDiscoverGroupVersions(t *testing.T) {
+	t.Parallel()
+
+	discoveryClient := discoverytesting.New()
+
+	groups, err := DiscoverGroups(discoveryClient)
+	if err != nil {
+		t.Fatalf("DiscoverGroups() error = %v", err)
+	}
+
+	matchGroup := func(group *metav1.APIGroup) bool { return group.Name == "autoscaling" }
+
+	var handed []string
+
+	resourceLists, err := DiscoverGroupVersions(t.Context(), discoveryClient, groups, matchGroup, GroupVersionOptions{
+		OnGroupVersion: func(_, _ int, resourceList *metav1.APIResourceList) error {
+			handed = append(handed, resourceList.GroupVersion)
+
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("DiscoverGroupVersions() error = %v", err)
+	}
+
+	want := []string{"autoscaling/v2", "autoscaling/v1", "autoscaling/v2beta2"}
+
+	if len(resourceLists) != 1 {
+		t.Fatalf("DiscoverGroupVersions() = %d groups, want 1", len(resourceLists))
+	}
+
+	var got []string
+	for _, resourceList := range resourceLists[0] {
+		got = append(got, resourceList.GroupVersion)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("DiscoverGroupVersions() = %v, want %v", got, want)
+	}
+
+	if !slices.Equal(handed, want) {
+		t.Errorf("OnGroupVersion() calls = %v, want %v", handed, want)
+	}
+}
@@ -0,0 +1,70 @@
+package apiresourceversions
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Scores of the match of a query with a name of a resource, the best of which ranks the resource.
+const (
+	// NoMatch is the score of the resources which don't match the query, which are excluded.
+	NoMatch = iota
+	// SubsequenceMatch is the score of a name containing the characters of the query in order, e.g. hpa in
+	// horizontalpodautoscalers.
+	SubsequenceMatch
+	// SubstringMatch is the score of a name containing the query, e.g. autoscaler in horizontalpodautoscalers.
+	SubstringMatch
+	// PrefixMatch is the score of a name starting with the query, e.g. deploy in deployments.
+	PrefixMatch
+	// ExactMatch is the score of a name equal to the query, e.g. a short name.
+	ExactMatch
+)
+
+// QueryScore returns how well the lower case query matches the plural name, singular name, short names, or kind of
+// the resource, the best match of which ranks the resource.
+// The subresources are matched by the name of their parent resource.
+func QueryScore(resource *metav1.APIResource, query string) int {
+	baseName, _, _ := strings.Cut(resource.Name, "/")
+	names := append([]string{baseName, resource.SingularName, strings.ToLower(resource.Kind)}, resource.ShortNames...)
+
+	best := NoMatch
+
+	for _, name := range names {
+		best = max(best, nameScore(name, query))
+	}
+
+	return best
+}
+
+// nameScore returns how well the lower case query matches the name.
+func nameScore(name, query string) int {
+	switch {
+	case name == "":
+		return NoMatch
+	case name == query:
+		return ExactMatch
+	case strings.HasPrefix(name, query):
+		return PrefixMatch
+	case strings.Contains(name, query):
+		return SubstringMatch
+	case isSubsequence(name, query):
+		return SubsequenceMatch
+	default:
+		return NoMatch
+	}
+}
+
+// isSubsequence returns true if the characters of the query appear in the name in the same order.
+func isSubsequence(name, query string) bool {
+	for _, char := range query {
+		i := strings.IndexRune(name, char)
+		if i < 0 {
+			return false
+		}
+
+		name = name[i+len(string(char)):]
+	}
+
+	return true
+}
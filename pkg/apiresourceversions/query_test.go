@@ -0,0 +1,45 @@
+package apiresourceversions
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestQueryScore tests ranking the matches of the query with the names of a resource.
+func TestQueryScore(t *testing.T) {
+	t.Parallel()
+
+	hpa := &metav1.APIResource{
+		Name:       "horizontalpodautoscalers",
+		ShortNames: []string{"hpa"},
+		Kind:       "HorizontalPodAutoscaler",
+	}
+
+	t.Run("ShortName", queryScoreTest{resource: hpa, query: "hpa", want: ExactMatch}.Test)
+	t.Run("Kind", queryScoreTest{resource: hpa, query: "horizontalpodautoscaler", want: ExactMatch}.Test)
+	t.Run("Prefix", queryScoreTest{resource: hpa, query: "horizontal", want: PrefixMatch}.Test)
+	t.Run("Substring", queryScoreTest{resource: hpa, query: "autoscaler", want: SubstringMatch}.Test)
+	t.Run("Subsequence", queryScoreTest{resource: hpa, query: "hpas", want: SubsequenceMatch}.Test)
+	t.Run("NoMatch", queryScoreTest{resource: hpa, query: "deploy", want: NoMatch}.Test)
+	t.Run("Subresource", queryScoreTest{
+		resource: &metav1.APIResource{Name: "pods/log", Kind: "Pod"},
+		query:    "pods",
+		want:     ExactMatch,
+	}.Test)
+}
+
+type queryScoreTest struct {
+	resource *metav1.APIResource
+	query    string
+	want     int
+}
+
+func (tt queryScoreTest) Test(t *testing.T) {
+	t.Parallel()
+
+	got := QueryScore(tt.resource, tt.query)
+	if got != tt.want {
+		t.Errorf("QueryScore(%q) = %v, want %v", tt.query, got, tt.want)
+	}
+}
@@ -0,0 +1,89 @@
+// Package apiresourceversions discovers the API resources served by a Kubernetes cluster in every one of their
+// versions, rather than only in their preferred version like kubectl api-resources.
+// It is the library behind the kubectl api-resource-versions plugin, for the other plugins and controllers which
+// need to filter and sort the resource versions without shelling out to it.
+package apiresourceversions
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Resource is an API resource in one of the versions served by the cluster.
+type Resource struct {
+	// GroupVersion is the group version of the resource, e.g. apps/v1.
+	GroupVersion schema.GroupVersion
+	// APIResource is the API resource in this version, with its group set.
+	APIResource *metav1.APIResource
+	// Preferred is true if this is the server preferred version of the resource.
+	Preferred bool
+	// PreferredGroupVersion is true if this is the preferred version of the API group.
+	// Note that this is not the same as the preferred version of the resource.
+	PreferredGroupVersion bool
+	// Subresource is true if the resource is a subresource, e.g. deployments/status.
+	Subresource bool
+	// QueryScore is how well the resource matches the query of the filter, see [QueryScore], or [NoMatch] without a
+	// query.
+	QueryScore int
+}
+
+// FullName returns the name of the resource with its version and API group in the format expected by kubectl, e.g.
+// deployments.v1.apps, followed by the name of the subresource if any, e.g. "deployments.v1.apps status".
+func (r Resource) FullName() string {
+	baseName, subName, isSubresource := strings.Cut(r.APIResource.Name, "/")
+
+	fullname := fmt.Sprintf("%s.%s.%s", baseName, r.GroupVersion.Version, r.GroupVersion.Group)
+	if isSubresource {
+		fullname = fmt.Sprintf("%s %s", fullname, subName)
+	}
+
+	return fullname
+}
+
+// GroupVersionResource returns the group, version, and resource name of the resource.
+// Subresources are mapped to the group version resource of their parent resource.
+func (r Resource) GroupVersionResource() schema.GroupVersionResource {
+	baseName, _, _ := strings.Cut(r.APIResource.Name, "/")
+
+	return r.GroupVersion.WithResource(baseName)
+}
+
+// ServerPreferredVersions retrieves the server preferred resources with
+// [discovery.ServerResourcesInterface.ServerPreferredResources], and returns their versions like [PreferredVersions].
+// The group versions which couldn't be discovered, e.g. of an unavailable aggregated API server, are left out rather
+// than failing the discovery of the others: their resources are an error when they are requested.
+func ServerPreferredVersions(discoveryClient discovery.DiscoveryInterface) (map[schema.GroupResource]string, error) {
+	preferredResources, err := discoveryClient.ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("couldn't get server preferred resources: %w", err)
+	}
+
+	return PreferredVersions(preferredResources)
+}
+
+// PreferredVersions returns the versions of the server preferred resources, keyed by their group and resource name.
+// The subresources are skipped.
+func PreferredVersions(preferredResources []*metav1.APIResourceList) (map[schema.GroupResource]string, error) {
+	preferredVersions := make(map[schema.GroupResource]string, len(preferredResources))
+
+	for _, resourceList := range preferredResources {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse group version %s: %w", resourceList.GroupVersion, err)
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+
+			preferredVersions[groupVersion.WithResource(resource.Name).GroupResource()] = groupVersion.Version
+		}
+	}
+
+	return preferredVersions, nil
+}
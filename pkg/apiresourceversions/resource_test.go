@@ -0,0 +1,88 @@
+package apiresourceversions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Izzette/kubectl-api-resource-versions/pkg/discoverytesting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// failedGroupsDiscoveryClient is a discovery client whose preferred resources are missing the failed group versions.
+type failedGroupsDiscoveryClient struct {
+	discovery.DiscoveryInterface
+
+	err error
+}
+
+// ServerPreferredResources implements [discovery.ServerResourcesInterface].
+func (c *failedGroupsDiscoveryClient) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	preferredResources, _ := c.DiscoveryInterface.ServerPreferredResources()
+
+	return preferredResources, c.err
+}
+
+// TestServerPreferredVersions tests retrieving the preferred versions of the resources, without the group versions
+// which couldn't be discovered.
+func TestServerPreferredVersions(t *testing.T) {
+	t.Parallel()
+
+	preferredResources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Kind: "Deployment"},
+				{Name: "deployments/status", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "autoscaling/v2",
+			APIResources: []metav1.APIResource{
+				{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"},
+			},
+		},
+	}
+	want := map[schema.GroupResource]string{
+		{Group: "apps", Resource: "deployments"}:                     "v1",
+		{Group: "autoscaling", Resource: "horizontalpodautoscalers"}: "v2",
+	}
+
+	errFailed := errors.New("connection refused")
+
+	t.Run("All", serverPreferredVersionsTest{preferredResources: preferredResources, want: want}.Test)
+	t.Run("FailedGroups", serverPreferredVersionsTest{
+		preferredResources: preferredResources,
+		err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+			{Group: "metrics.k8s.io", Version: "v1beta1"}: errFailed,
+		}},
+		want: want,
+	}.Test)
+	t.Run("Failed", serverPreferredVersionsTest{err: errFailed, wantErr: errFailed}.Test)
+}
+
+type serverPreferredVersionsTest struct {
+	preferredResources []*metav1.APIResourceList
+	err                error
+	want               map[schema.GroupResource]string
+	wantErr            error
+}
+
+func (tt serverPreferredVersionsTest) Test(t *testing.T) {
+	t.Parallel()
+
+	builder := discoverytesting.NewFakeCachedDiscoveryClientBuilder()
+	builder.PreferredResources = tt.preferredResources
+	discoveryClient := &failedGroupsDiscoveryClient{DiscoveryInterface: builder.CachedDiscoveryInterface(), err: tt.err}
+
+	got, err := ServerPreferredVersions(discoveryClient)
+	if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+		t.Fatalf("ServerPreferredVersions() error = %v, wantErr %v", err, tt.wantErr)
+	}
+
+	if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+		t.Errorf("ServerPreferredVersions() = %v, want %v", got, tt.want)
+	}
+}
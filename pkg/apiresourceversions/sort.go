@@ -0,0 +1,46 @@
+package apiresourceversions
+
+import (
+	"sort"
+)
+
+// SortBy is the order of the resources.
+type SortBy string
+
+// Orders of the resources.
+const (
+	// SortByGroup sorts the resources by API group, then by name.
+	SortByGroup SortBy = ""
+	// SortByName sorts the resources by name.
+	SortByName SortBy = "name"
+	// SortByKind sorts the resources by kind.
+	SortByKind SortBy = "kind"
+)
+
+// Less returns true if the left resource comes before the right one in the order.
+// The best matches of the query come first when the resources are sorted by group, the default order.
+func Less(left, right Resource, sortBy SortBy) bool {
+	if sortBy == SortByGroup && left.QueryScore != right.QueryScore {
+		return left.QueryScore > right.QueryScore
+	}
+
+	switch sortBy {
+	case SortByName:
+		return left.APIResource.Name < right.APIResource.Name
+	case SortByKind:
+		return left.APIResource.Kind < right.APIResource.Kind
+	default:
+		if left.GroupVersion.Group != right.GroupVersion.Group {
+			return left.GroupVersion.Group < right.GroupVersion.Group
+		}
+
+		return left.APIResource.Name < right.APIResource.Name
+	}
+}
+
+// Sort sorts the resources in the order, keeping the discovery order of the versions of a resource.
+func Sort(resources []Resource, sortBy SortBy) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		return Less(resources[i], resources[j], sortBy)
+	})
+}
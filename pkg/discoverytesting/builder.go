@@ -46,3 +46,28 @@ func (c *FakeCachedDiscoveryClientBuilder) CachedDiscoveryInterface() *cmdtestin
 
 	return cached
 }
+
+// AddGroupYAML adds the API group, the resource lists of its versions, and those of its preferred version to the
+// builder, decoded from YAML fixtures, see [LoadGroup] and [LoadResourceLists].
+func (c *FakeCachedDiscoveryClientBuilder) AddGroupYAML(groupYAML, resourcesYAML []byte) error {
+	group, err := LoadGroup(groupYAML)
+	if err != nil {
+		return err
+	}
+
+	resources, err := LoadResourceLists(resourcesYAML)
+	if err != nil {
+		return err
+	}
+
+	preferred, err := PreferredResources(group, resources)
+	if err != nil {
+		return err
+	}
+
+	c.Groups = append(c.Groups, group)
+	c.Resources = append(c.Resources, resources...)
+	c.PreferredResources = append(c.PreferredResources, preferred)
+
+	return nil
+}
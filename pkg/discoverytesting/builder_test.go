@@ -0,0 +1,48 @@
+package discoverytesting
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAddGroupYAML tests building a discovery client from the YAML fixtures of a group.
+func TestAddGroupYAML(t *testing.T) {
+	t.Parallel()
+
+	builder := NewFakeCachedDiscoveryClientBuilder()
+
+	err := builder.AddGroupYAML(autoscalingGroupYAML, autoscalingResourcesYAML)
+	if err != nil {
+		t.Fatalf("AddGroupYAML() error = %v", err)
+	}
+
+	client := builder.CachedDiscoveryInterface()
+
+	groups, err := client.ServerGroups()
+	if err != nil {
+		t.Fatalf("ServerGroups() error = %v", err)
+	}
+
+	if len(groups.Groups) != 1 || groups.Groups[0].Name != "autoscaling" {
+		t.Errorf("ServerGroups() = %v, want the autoscaling group", groups.Groups)
+	}
+
+	resourceList, err := client.ServerResourcesForGroupVersion("autoscaling/v1")
+	if err != nil {
+		t.Fatalf("ServerResourcesForGroupVersion() error = %v", err)
+	}
+
+	if len(resourceList.APIResources) == 0 || resourceList.APIResources[0].Name != "horizontalpodautoscalers" {
+		t.Errorf("ServerResourcesForGroupVersion() = %v, want horizontalpodautoscalers", resourceList.APIResources)
+	}
+}
+
+// TestAddGroupYAMLPreferredNotFound tests rejecting the fixtures missing the preferred version of the group.
+func TestAddGroupYAMLPreferredNotFound(t *testing.T) {
+	t.Parallel()
+
+	err := NewFakeCachedDiscoveryClientBuilder().AddGroupYAML(autoscalingGroupYAML, coreResourcesYAML)
+	if !errors.Is(err, errPreferredResourcesNotFound) {
+		t.Errorf("AddGroupYAML() error = %v, want %v", err, errPreferredResourcesNotFound)
+	}
+}
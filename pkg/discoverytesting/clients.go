@@ -1,10 +1,16 @@
+// Package discoverytesting builds fake cached discovery clients for the tests of kubectl plugins, from the YAML
+// fixtures of API groups and resource lists, or generated procedurally to benchmark large clusters.
 package discoverytesting
 
 import (
 	_ "embed"
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
 
@@ -13,13 +19,15 @@ import (
 func New() *cmdtesting.FakeCachedDiscoveryClient {
 	cached := NewFakeCachedDiscoveryClientBuilder()
 
-	cached.Groups = append(cached.Groups, getCoreGroup(), getAutoscalingGroup())
-
-	cached.Resources = append(cached.Resources, getCoreResources()...)
-	cached.Resources = append(cached.Resources, getAutoscalingResources()...)
-
-	cached.PreferredResources = append(cached.PreferredResources, getCorePreferredResources())
-	cached.PreferredResources = append(cached.PreferredResources, getAutoscalingPreferredResources())
+	for _, fixture := range [][2][]byte{
+		{coreGroupYAML, coreResourcesYAML},
+		{autoscalingGroupYAML, autoscalingResourcesYAML},
+	} {
+		err := cached.AddGroupYAML(fixture[0], fixture[1])
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return cached.CachedDiscoveryInterface()
 }
@@ -86,3 +94,29 @@ func NewProcedural(groups, versionsPerGroup, resourcesPerVersion int) *cmdtestin
 
 	return builder.CachedDiscoveryInterface()
 }
+
+// NewDynamic returns a new [dynamicfake.FakeDynamicClient] which can list all the resources returned by [New].
+// The client is pre-populated with the given objects.
+func NewDynamic(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	resources := getCoreResources()
+	resources = append(resources, getAutoscalingResources()...)
+
+	gvrToListKind := make(map[schema.GroupVersionResource]string)
+
+	for _, resourceList := range resources {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			panic(fmt.Errorf("failed to parse group version %s: %w", resourceList.GroupVersion, err))
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue // Subresources can't be listed.
+			}
+
+			gvrToListKind[groupVersion.WithResource(resource.Name)] = resource.Kind + "List"
+		}
+	}
+
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, objects...)
+}
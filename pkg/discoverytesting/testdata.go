@@ -50,30 +50,62 @@ func getAutoscalingPreferredResources() *metav1.APIResourceList {
 }
 
 func getGroup(groupYAML []byte) *metav1.APIGroup {
+	group, err := LoadGroup(groupYAML)
+	if err != nil {
+		panic(err)
+	}
+
+	return group
+}
+
+func getResources(resourcesYAML []byte) []*metav1.APIResourceList {
+	resources, err := LoadResourceLists(resourcesYAML)
+	if err != nil {
+		panic(err)
+	}
+
+	return resources
+}
+
+func getPreferredResources(group *metav1.APIGroup, resources []*metav1.APIResourceList) *metav1.APIResourceList {
+	preferred, err := PreferredResources(group, resources)
+	if err != nil {
+		panic(err)
+	}
+
+	return preferred
+}
+
+// errPreferredResourcesNotFound is returned when the resource list of the preferred version of a group is missing.
+var errPreferredResourcesNotFound = errors.New("preferred resources not found")
+
+// LoadGroup decodes the API group of the YAML document, e.g. the output of kubectl get --raw /apis/apps saved as
+// YAML.
+func LoadGroup(groupYAML []byte) (*metav1.APIGroup, error) {
 	groupJSON, err := k8syaml.YAMLToJSON(groupYAML)
 	if err != nil {
-		panic(fmt.Errorf("failed to convert group YAML to JSON: %w", err))
+		return nil, fmt.Errorf("failed to convert group YAML to JSON: %w", err)
 	}
 
 	group := &metav1.APIGroup{}
 
 	err = json.Unmarshal(groupJSON, &group)
 	if err != nil {
-		panic(fmt.Errorf("failed to unmarshal group: %w", err))
+		return nil, fmt.Errorf("failed to unmarshal group: %w", err)
 	}
 
-	return group
+	return group, nil
 }
 
-func getResources(resourcesYAML []byte) []*metav1.APIResourceList {
-	resourcesYAMLBuf := bytes.NewBuffer(resourcesYAML)
-
+// LoadResourceLists decodes the API resource lists of the YAML documents, one per group version, e.g. the outputs of
+// kubectl get --raw /apis/apps/v1 saved as YAML and separated by "---".
+func LoadResourceLists(resourcesYAML []byte) ([]*metav1.APIResourceList, error) {
 	resources := make([]*metav1.APIResourceList, 0)
 
-	for result := range yamlutil.YAMLDocumentsToJSON(resourcesYAMLBuf) {
+	for result := range yamlutil.YAMLDocumentsToJSON(bytes.NewBuffer(resourcesYAML)) {
 		decoder, err := result.GetDecoder()
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("failed to read resources: %w", err)
 		}
 
 		resource := &metav1.APIResourceList{}
@@ -84,21 +116,25 @@ func getResources(resourcesYAML []byte) []*metav1.APIResourceList {
 				break // End of resources
 			}
 
-			panic(fmt.Errorf("failed to decode resources: %w", err))
+			return nil, fmt.Errorf("failed to decode resources: %w", err)
 		}
 
 		resources = append(resources, resource)
 	}
 
-	return resources
+	return resources, nil
 }
 
-func getPreferredResources(group *metav1.APIGroup, resources []*metav1.APIResourceList) *metav1.APIResourceList {
+// PreferredResources returns the resource list of the preferred version of the group.
+func PreferredResources(
+	group *metav1.APIGroup,
+	resources []*metav1.APIResourceList,
+) (*metav1.APIResourceList, error) {
 	for _, r := range resources {
 		if r.GroupVersion == group.PreferredVersion.GroupVersion {
-			return r
+			return r, nil
 		}
 	}
 
-	panic(fmt.Sprintf("preferred resources for group %#v not found", group.Name))
+	return nil, fmt.Errorf("%w for group %q", errPreferredResourcesNotFound, group.Name)
 }